@@ -0,0 +1,196 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"data-analyzer/config"
+
+	"google.golang.org/genai"
+)
+
+// Embedding backends
+const (
+	EmbeddingBackendGemini = "gemini"
+	EmbeddingBackendLocal  = "local"
+)
+
+// geminiEmbeddingBatchSize is the maximum number of texts sent in a single embedding request
+const geminiEmbeddingBatchSize = 100
+
+// Embedder turns texts into vectors whose cosine similarity reflects how similar the texts are
+type Embedder interface {
+	// Name identifies the backend and model; vectors of different embedders are never compared
+	Name() string
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+}
+
+// NewEmbedder creates the embedder selected by EMBEDDING_BACKEND. Without a backend configured,
+// Gemini is used when the agent is enabled and the local embedder otherwise.
+func NewEmbedder(cfg *config.Config, client *Client) (Embedder, error) {
+	backend := cfg.EmbeddingBackend
+	if backend == "" {
+		backend = EmbeddingBackendLocal
+		if client.Enabled() {
+			backend = EmbeddingBackendGemini
+		}
+	}
+
+	switch backend {
+	case EmbeddingBackendGemini:
+		if !client.Enabled() {
+			return nil, fmt.Errorf("the gemini embedding backend requires SHOULD_RUN_AGENT=true")
+		}
+		return &GeminiEmbedder{client: client, model: cfg.EmbeddingModel, dimensions: cfg.EmbeddingDimensions}, nil
+	case EmbeddingBackendLocal:
+		return &LocalEmbedder{dimensions: cfg.EmbeddingDimensions}, nil
+	default:
+		return nil, fmt.Errorf("unknown embedding backend %q, use %q or %q", backend, EmbeddingBackendGemini, EmbeddingBackendLocal)
+	}
+}
+
+// GeminiEmbedder embeds texts with a Gemini embedding model
+type GeminiEmbedder struct {
+	client     *Client
+	model      string
+	dimensions int
+}
+
+func (e *GeminiEmbedder) Name() string {
+	return fmt.Sprintf("%s:%s:%d", EmbeddingBackendGemini, e.model, e.dimensions)
+}
+
+func (e *GeminiEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += geminiEmbeddingBatchSize {
+		batch, err := e.embed(ctx, texts[start:min(start+geminiEmbeddingBatchSize, len(texts))], "RETRIEVAL_DOCUMENT")
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (e *GeminiEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := e.embed(ctx, []string{text}, "RETRIEVAL_QUERY")
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func (e *GeminiEmbedder) embed(ctx context.Context, texts []string, taskType string) ([][]float32, error) {
+	contents := make([]*genai.Content, len(texts))
+	for i, text := range texts {
+		contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+	}
+
+	result, err := e.client.client.Models.EmbedContent(ctx, e.model, contents, &genai.EmbedContentConfig{
+		TaskType:             taskType,
+		OutputDimensionality: genai.Ptr(int32(e.dimensions)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed content: %w", err)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(result.Embeddings))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, embedding := range result.Embeddings {
+		// reduced dimensions are not normalized by the API
+		vectors[i] = normalizeVector(embedding.Values)
+	}
+	return vectors, nil
+}
+
+// LocalEmbedder embeds texts without any network calls by hashing words and word pairs into a
+// fixed number of dimensions. It captures shared vocabulary rather than meaning, which is enough
+// to find related jobs when no embedding model is available.
+type LocalEmbedder struct {
+	dimensions int
+}
+
+func (e *LocalEmbedder) Name() string {
+	return fmt.Sprintf("%s:hashing:%d", EmbeddingBackendLocal, e.dimensions)
+}
+
+func (e *LocalEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *LocalEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return e.embed(text), nil
+}
+
+func (e *LocalEmbedder) embed(text string) []float32 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+
+	counts := make(map[string]int)
+	for i, word := range words {
+		counts[word]++
+		if i > 0 {
+			counts[words[i-1]+" "+word]++
+		}
+	}
+
+	vector := make([]float32, e.dimensions)
+	for feature, count := range counts {
+		hasher := fnv.New64a()
+		hasher.Write([]byte(feature))
+		hash := hasher.Sum64()
+		// the sign bit spreads collisions out instead of always adding them up
+		sign := float32(1)
+		if hash>>63 == 1 {
+			sign = -1
+		}
+		vector[hash%uint64(e.dimensions)] += sign * float32(1+math.Log(float64(count)))
+	}
+	return normalizeVector(vector)
+}
+
+// normalizeVector scales a vector to unit length, so the dot product equals the cosine similarity
+func normalizeVector(vector []float32) []float32 {
+	norm := 0.0
+	for _, value := range vector {
+		norm += float64(value) * float64(value)
+	}
+	if norm == 0 {
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	normalized := make([]float32, len(vector))
+	for i, value := range vector {
+		normalized[i] = float32(float64(value) / norm)
+	}
+	return normalized
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors of the same length
+func CosineSimilarity(a []float32, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	dot, normA, normB := 0.0, 0.0, 0.0
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"data-analyzer/config"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		a    []float32
+		b    []float32
+		want float64
+	}{
+		{[]float32{1, 0}, []float32{1, 0}, 1},
+		{[]float32{1, 0}, []float32{0, 1}, 0},
+		{[]float32{1, 2}, []float32{-1, -2}, -1},
+		{[]float32{3, 4}, []float32{6, 8}, 1},
+		{[]float32{1, 1}, []float32{1, 0}, 1 / math.Sqrt2},
+		{[]float32{0, 0}, []float32{1, 0}, 0},
+		{[]float32{1, 0}, []float32{1, 0, 0}, 0},
+		{nil, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := CosineSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("CosineSimilarity(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLocalEmbedder(t *testing.T) {
+	embedder := &LocalEmbedder{dimensions: 256}
+	ctx := context.Background()
+
+	vectors, err := embedder.EmbedDocuments(ctx, []string{
+		"Senior Go engineer building payment services with PostgreSQL",
+		"Backend engineer, Go and PostgreSQL, payment services",
+		"Marketing manager for retail brand campaigns",
+		"",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, err := embedder.EmbedQuery(ctx, "go payment services")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, vector := range vectors[:3] {
+		if len(vector) != 256 {
+			t.Fatalf("vector %d has %d dimensions, want 256", i, len(vector))
+		}
+		if norm := CosineSimilarity(vector, vector); math.Abs(norm-1) > 1e-6 {
+			t.Errorf("vector %d is not normalized", i)
+		}
+	}
+	if related, unrelated := CosineSimilarity(vectors[0], vectors[1]), CosineSimilarity(vectors[0], vectors[2]); related <= unrelated {
+		t.Errorf("similarity of related jobs %v, want it above unrelated jobs %v", related, unrelated)
+	}
+	if CosineSimilarity(query, vectors[1]) <= CosineSimilarity(query, vectors[2]) {
+		t.Errorf("query is closer to the unrelated job")
+	}
+	if CosineSimilarity(vectors[3], vectors[0]) != 0 {
+		t.Errorf("empty text has a non-zero similarity")
+	}
+	if again := embedder.embed("Backend engineer, Go and PostgreSQL, payment services"); CosineSimilarity(again, vectors[1]) < 1-1e-6 {
+		t.Errorf("embedding the same text twice gives different vectors")
+	}
+}
+
+func TestNewEmbedder(t *testing.T) {
+	tests := []struct {
+		backend  string
+		wantName string
+		wantErr  bool
+	}{
+		{"", "local:hashing:64", false},
+		{EmbeddingBackendLocal, "local:hashing:64", false},
+		// the gemini backend needs the agent
+		{EmbeddingBackendGemini, "", true},
+		{"openai", "", true},
+	}
+	for _, tt := range tests {
+		cfg := &config.Config{EmbeddingBackend: tt.backend, EmbeddingDimensions: 64}
+		embedder, err := NewEmbedder(cfg, nil)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewEmbedder(%q) error = %v, want error %v", tt.backend, err, tt.wantErr)
+			continue
+		}
+		if err == nil && embedder.Name() != tt.wantName {
+			t.Errorf("NewEmbedder(%q) = %q, want %q", tt.backend, embedder.Name(), tt.wantName)
+		}
+	}
+}
@@ -12,6 +12,10 @@ import (
 type Client struct {
 	client    *genai.Client
 	ModelName string
+	// TokenBudget is the maximum number of prompt tokens sent in a single batch request
+	TokenBudget int
+	// MaxConcurrentRequests limits how many batch requests run in parallel
+	MaxConcurrentRequests int
 }
 
 // NewClient creates a new Gemini client with the provided API key
@@ -33,11 +37,18 @@ func NewClient(ctx context.Context, cfg *config.Config) (*Client, error) {
 	}
 
 	return &Client{
-		client:    client,
-		ModelName: cfg.GeminiModel,
+		client:                client,
+		ModelName:             cfg.GeminiModel,
+		TokenBudget:           cfg.BatchTokenBudget,
+		MaxConcurrentRequests: cfg.BatchConcurrency,
 	}, nil
 }
 
+// Enabled reports whether the client is connected, which requires SHOULD_RUN_AGENT=true
+func (g *Client) Enabled() bool {
+	return g != nil && g.client != nil
+}
+
 // Close closes the Gemini client connection
 func (g *Client) Close() error {
 	// The new genai client doesn't require explicit closing
@@ -66,3 +77,19 @@ func (g *Client) GenerateContent(ctx context.Context, prompt string, temperature
 	}
 	return result, nil
 }
+
+// CountTokens returns the number of tokens the model uses for the given text
+func (g *Client) CountTokens(ctx context.Context, text string) (int, error) {
+	content := []*genai.Content{
+		{
+			Parts: []*genai.Part{
+				{Text: text},
+			},
+		},
+	}
+	result, err := g.client.Models.CountTokens(ctx, g.ModelName, content, nil)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.TotalTokens), nil
+}
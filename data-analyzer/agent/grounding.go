@@ -0,0 +1,75 @@
+package agent
+
+import "google.golang.org/genai"
+
+// GroundingChunk is a web page the model's answer was grounded on
+type GroundingChunk struct {
+	Index  int    `json:"index"`
+	URI    string `json:"uri"`
+	Title  string `json:"title"`
+	Domain string `json:"domain,omitempty"`
+}
+
+// GroundingSupport ties a segment of the answer to the chunks backing it. Offsets are bytes into the
+// text of the part.
+type GroundingSupport struct {
+	PartIndex    int       `json:"part_index"`
+	StartIndex   int       `json:"start_index"`
+	EndIndex     int       `json:"end_index"`
+	Text         string    `json:"text"`
+	ChunkIndices []int     `json:"chunk_indices"`
+	Confidence   []float32 `json:"confidence,omitempty"`
+}
+
+// Grounding is the grounding metadata of a response generated with Google Search
+type Grounding struct {
+	WebSearchQueries []string           `json:"web_search_queries"`
+	Chunks           []GroundingChunk   `json:"chunks"`
+	Supports         []GroundingSupport `json:"supports"`
+}
+
+// GroundingFromResponse extracts the grounding metadata of the first candidate. It is empty when the
+// response was not grounded.
+func GroundingFromResponse(resp *genai.GenerateContentResponse) Grounding {
+	grounding := Grounding{
+		WebSearchQueries: []string{},
+		Chunks:           []GroundingChunk{},
+		Supports:         []GroundingSupport{},
+	}
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].GroundingMetadata == nil {
+		return grounding
+	}
+	metadata := resp.Candidates[0].GroundingMetadata
+
+	grounding.WebSearchQueries = append(grounding.WebSearchQueries, metadata.WebSearchQueries...)
+	for i, chunk := range metadata.GroundingChunks {
+		// chunks keep their index even when they are not web pages, supports refer to them by it
+		groundingChunk := GroundingChunk{Index: i}
+		if chunk != nil && chunk.Web != nil {
+			groundingChunk.URI = chunk.Web.URI
+			groundingChunk.Title = chunk.Web.Title
+			groundingChunk.Domain = chunk.Web.Domain
+		}
+		grounding.Chunks = append(grounding.Chunks, groundingChunk)
+	}
+	for _, support := range metadata.GroundingSupports {
+		if support == nil || support.Segment == nil {
+			continue
+		}
+		groundingSupport := GroundingSupport{
+			PartIndex:    int(support.Segment.PartIndex),
+			StartIndex:   int(support.Segment.StartIndex),
+			EndIndex:     int(support.Segment.EndIndex),
+			Text:         support.Segment.Text,
+			ChunkIndices: []int{},
+			Confidence:   support.ConfidenceScores,
+		}
+		for _, index := range support.GroundingChunkIndices {
+			if int(index) >= 0 && int(index) < len(grounding.Chunks) {
+				groundingSupport.ChunkIndices = append(groundingSupport.ChunkIndices, int(index))
+			}
+		}
+		grounding.Supports = append(grounding.Supports, groundingSupport)
+	}
+	return grounding
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestGroundingFromResponse(t *testing.T) {
+	grounded := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		GroundingMetadata: &genai.GroundingMetadata{
+			WebSearchQueries: []string{"acme engineering blog"},
+			GroundingChunks: []*genai.GroundingChunk{
+				{Web: &genai.GroundingChunkWeb{URI: "https://vertexaisearch.cloud.google.com/a", Title: "acme.io", Domain: "acme.io"}},
+				// not a web page, but supports still count it
+				{},
+				{Web: &genai.GroundingChunkWeb{URI: "https://vertexaisearch.cloud.google.com/b", Title: "reuters.com"}},
+			},
+			GroundingSupports: []*genai.GroundingSupport{
+				{
+					Segment:               &genai.Segment{StartIndex: 10, EndIndex: 30, Text: "Microservices in Go"},
+					GroundingChunkIndices: []int32{2, 0, 7},
+					ConfidenceScores:      []float32{0.9, 0.7, 0.1},
+				},
+				{GroundingChunkIndices: []int32{0}},
+				nil,
+			},
+		},
+	}}}
+
+	want := Grounding{
+		WebSearchQueries: []string{"acme engineering blog"},
+		Chunks: []GroundingChunk{
+			{Index: 0, URI: "https://vertexaisearch.cloud.google.com/a", Title: "acme.io", Domain: "acme.io"},
+			{Index: 1},
+			{Index: 2, URI: "https://vertexaisearch.cloud.google.com/b", Title: "reuters.com"},
+		},
+		Supports: []GroundingSupport{
+			{StartIndex: 10, EndIndex: 30, Text: "Microservices in Go", ChunkIndices: []int{2, 0}, Confidence: []float32{0.9, 0.7, 0.1}},
+		},
+	}
+
+	empty := Grounding{WebSearchQueries: []string{}, Chunks: []GroundingChunk{}, Supports: []GroundingSupport{}}
+
+	tests := []struct {
+		name string
+		resp *genai.GenerateContentResponse
+		want Grounding
+	}{
+		{"grounded", grounded, want},
+		{"nil response", nil, empty},
+		{"no candidates", &genai.GenerateContentResponse{}, empty},
+		{"not grounded", &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{}}}, empty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GroundingFromResponse(tt.resp); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroundingFromResponse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package agent
 import (
 	"regexp"
 	"strings"
+	"unicode/utf8"
 )
 
 // SanitizeText removes sensitive information like emails and phone numbers from text
@@ -31,3 +32,22 @@ func SanitizeAgentJSONResponse(agentResponse string) string {
 
 	return agentResponse
 }
+
+// TruncateText keeps the first maxRunes runes of text, never splitting a UTF-8 character, and appends
+// "..." when it cut anything, so a truncated text is three runes longer than maxRunes
+func TruncateText(text string, maxRunes int) string {
+	if maxRunes < 0 {
+		maxRunes = 0
+	}
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+	runes := 0
+	for i := range text {
+		if runes == maxRunes {
+			return text[:i] + "..."
+		}
+		runes++
+	}
+	return text
+}
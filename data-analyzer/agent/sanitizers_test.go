@@ -0,0 +1,25 @@
+package agent
+
+import "testing"
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text     string
+		maxRunes int
+		want     string
+	}{
+		{"Go developer", 20, "Go developer"},
+		{"Go developer", 12, "Go developer"},
+		{"Go developer", 2, "Go..."},
+		{"Café Müller", 4, "Café..."},
+		{"日本語のテキスト", 3, "日本語..."},
+		{"text", 0, "..."},
+		{"text", -1, "..."},
+		{"", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := TruncateText(tt.text, tt.maxRunes); got != tt.want {
+			t.Errorf("TruncateText(%q, %d) = %q, want %q", tt.text, tt.maxRunes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"data-analyzer/agent"
+)
+
+// maxTruncateAttempts bounds how many times an oversized item is shortened and re-counted
+const maxTruncateAttempts = 3
+
+// BatchItem is the text of a single job as it is rendered inside a batch prompt
+type BatchItem struct {
+	JobID int
+	Text  string
+}
+
+// BatchOutcome holds the results of one chunk of a batch run
+type BatchOutcome[T any] struct {
+	Items   []BatchItem
+	Results []T
+	Err     error
+}
+
+// TokenCounter counts the tokens of a text as the model sees them. agent.Client implements it.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, text string) (int, error)
+}
+
+// BatchPlanner packs batch items into chunks that fit under the model's token budget
+type BatchPlanner struct {
+	counter     TokenCounter
+	tokenBudget int
+}
+
+// NewBatchPlanner creates a planner using the token budget configured on the client
+func NewBatchPlanner(client *agent.Client) *BatchPlanner {
+	return &BatchPlanner{
+		counter:     client,
+		tokenBudget: client.TokenBudget,
+	}
+}
+
+// Plan splits the items into chunks so that the header plus every chunk stays under the token budget.
+// Items that do not fit on their own are truncated on rune boundaries as a last resort. Items that
+// still do not fit are left out of the chunks and returned with their error, keyed by job ID, so one
+// oversized job does not fail the whole batch.
+func (p *BatchPlanner) Plan(ctx context.Context, header string, items []BatchItem) ([][]BatchItem, map[int]error, error) {
+	if len(items) == 0 {
+		return nil, nil, nil
+	}
+
+	headerTokens, err := p.counter.CountTokens(ctx, header)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count prompt tokens: %w", err)
+	}
+	available := p.tokenBudget - headerTokens
+	if available <= 0 {
+		return nil, nil, fmt.Errorf("prompt uses %d tokens, which exceeds the batch budget of %d", headerTokens, p.tokenBudget)
+	}
+
+	var chunks [][]BatchItem
+	var current []BatchItem
+	currentTokens := 0
+	skipped := make(map[int]error)
+	for _, item := range items {
+		tokens, err := p.counter.CountTokens(ctx, item.Text)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to count tokens for job %d: %w", item.JobID, err)
+		}
+		if tokens > available {
+			item, tokens, err = p.truncate(ctx, item, tokens, available)
+			if err != nil {
+				skipped[item.JobID] = err
+				continue
+			}
+		}
+
+		if len(current) > 0 && currentTokens+tokens > available {
+			chunks = append(chunks, current)
+			current = nil
+			currentTokens = 0
+		}
+		current = append(current, item)
+		currentTokens += tokens
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	return chunks, skipped, nil
+}
+
+// truncate shortens an item that exceeds the limit, scaling its length by the token overshoot
+func (p *BatchPlanner) truncate(ctx context.Context, item BatchItem, tokens int, limit int) (BatchItem, int, error) {
+	text := item.Text
+	for attempt := 0; attempt < maxTruncateAttempts && tokens > limit; attempt++ {
+		// keep a small margin since the rune to token ratio is not uniform across the text
+		keep := int(float64(utf8.RuneCountInString(text)) * float64(limit) / float64(tokens) * 0.9)
+		text = agent.TruncateText(text, keep)
+
+		var err error
+		tokens, err = p.counter.CountTokens(ctx, text)
+		if err != nil {
+			return item, 0, fmt.Errorf("failed to count tokens for job %d: %w", item.JobID, err)
+		}
+	}
+	if tokens > limit {
+		return item, 0, fmt.Errorf("job %d does not fit in the batch budget even after truncation", item.JobID)
+	}
+
+	item.Text = text
+	return item, tokens, nil
+}
+
+// RunBatches executes fn for every chunk concurrently, limited by the client's MaxConcurrentRequests.
+// The outcomes are returned in the same order as the chunks.
+func RunBatches[T any](ctx context.Context, client *agent.Client, chunks [][]BatchItem, fn func(ctx context.Context, chunk []BatchItem) ([]T, error)) []BatchOutcome[T] {
+	limit := client.MaxConcurrentRequests
+	if limit <= 0 {
+		limit = 1
+	}
+	semaphore := make(chan struct{}, limit)
+
+	outcomes := make([]BatchOutcome[T], len(chunks))
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []BatchItem) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			results, err := fn(ctx, chunk)
+			outcomes[i] = BatchOutcome[T]{Items: chunk, Results: results, Err: err}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	return outcomes
+}
+
+// joinBatchItems concatenates the text of the items in a chunk
+func joinBatchItems(chunk []BatchItem) string {
+	var builder strings.Builder
+	for _, item := range chunk {
+		builder.WriteString(item.Text)
+	}
+	return builder.String()
+}
@@ -0,0 +1,203 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"data-analyzer/agent"
+)
+
+func TestJoinBatchItems(t *testing.T) {
+	tests := []struct {
+		chunk []BatchItem
+		want  string
+	}{
+		{nil, ""},
+		{[]BatchItem{{JobID: 1, Text: "Job 1: Go\n"}}, "Job 1: Go\n"},
+		{[]BatchItem{{JobID: 1, Text: "Job 1: Go\n"}, {JobID: 2, Text: "Job 2: Rust\n"}}, "Job 1: Go\nJob 2: Rust\n"},
+	}
+	for _, tt := range tests {
+		if got := joinBatchItems(tt.chunk); got != tt.want {
+			t.Errorf("joinBatchItems(%v) = %q, want %q", tt.chunk, got, tt.want)
+		}
+	}
+}
+
+// wordCounter counts one token per word. The stuck text and every truncation of it count 100 tokens,
+// and texts containing "broken" cannot be counted.
+type wordCounter struct {
+	stuck      string
+	stuckCalls int
+}
+
+func (c *wordCounter) CountTokens(ctx context.Context, text string) (int, error) {
+	switch {
+	case strings.Contains(text, "broken"):
+		return 0, errors.New("count failed")
+	case c.stuck != "" && strings.HasPrefix(c.stuck, strings.TrimSuffix(text, "...")):
+		c.stuckCalls++
+		return 100, nil
+	}
+	return len(strings.Fields(text)), nil
+}
+
+func TestBatchPlannerPlan(t *testing.T) {
+	// the header is 3 tokens, leaving 7 of the budget of 10 to the items
+	const header = "Find red flags"
+	long := strings.Repeat("w ", 20)
+
+	tests := []struct {
+		name        string
+		budget      int
+		stuck       string
+		items       []BatchItem
+		wantChunks  [][]int
+		wantTexts   map[int]string
+		wantSkipped []int
+		wantErr     bool
+	}{
+		{name: "no items", budget: 10},
+		{
+			name:       "packs items until the budget is reached",
+			budget:     10,
+			items:      []BatchItem{{1, "a b"}, {2, "c d e"}, {3, "f g"}, {4, "h"}},
+			wantChunks: [][]int{{1, 2, 3}, {4}},
+		},
+		{
+			name:       "an item filling the budget gets its own chunk",
+			budget:     10,
+			items:      []BatchItem{{1, "a"}, {2, "a b c d e f g"}, {3, "h"}},
+			wantChunks: [][]int{{1}, {2}, {3}},
+		},
+		{
+			// 39 runes at 20 tokens for 7 available keep 12 runes: six words and the ellipsis
+			name:       "oversize items are truncated",
+			budget:     10,
+			items:      []BatchItem{{1, long}, {2, "a"}},
+			wantChunks: [][]int{{1}, {2}},
+			wantTexts:  map[int]string{1: "w w w w w w ..."},
+		},
+		{
+			name:        "an item that never fits is skipped",
+			budget:      10,
+			stuck:       "stuck",
+			items:       []BatchItem{{1, "a b"}, {2, "stuck"}, {3, "c"}},
+			wantChunks:  [][]int{{1, 3}},
+			wantSkipped: []int{2},
+		},
+		{
+			name:    "header over the budget",
+			budget:  3,
+			items:   []BatchItem{{1, "a"}},
+			wantErr: true,
+		},
+		{
+			name:    "counting failure",
+			budget:  10,
+			items:   []BatchItem{{1, "a"}, {2, "broken"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := &wordCounter{stuck: tt.stuck}
+			planner := &BatchPlanner{counter: counter, tokenBudget: tt.budget}
+			chunks, skipped, err := planner.Plan(context.Background(), header, tt.items)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Plan() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			gotChunks := [][]int{}
+			texts := make(map[int]string)
+			for _, chunk := range chunks {
+				ids := []int{}
+				for _, item := range chunk {
+					ids = append(ids, item.JobID)
+					texts[item.JobID] = item.Text
+				}
+				gotChunks = append(gotChunks, ids)
+			}
+			if !slices.EqualFunc(gotChunks, append([][]int{}, tt.wantChunks...), slices.Equal) {
+				t.Errorf("Plan() chunks = %v, want %v", gotChunks, tt.wantChunks)
+			}
+			for id, want := range tt.wantTexts {
+				if texts[id] != want {
+					t.Errorf("Plan() text of job %d = %q, want %q", id, texts[id], want)
+				}
+			}
+
+			gotSkipped := []int{}
+			for id, err := range skipped {
+				if err == nil {
+					t.Errorf("Plan() skipped job %d without an error", id)
+				}
+				gotSkipped = append(gotSkipped, id)
+			}
+			if !slices.Equal(gotSkipped, append([]int{}, tt.wantSkipped...)) {
+				t.Errorf("Plan() skipped = %v, want %v", gotSkipped, tt.wantSkipped)
+			}
+			// an item that never fits is counted once and after each truncation attempt
+			if len(tt.wantSkipped) > 0 && counter.stuckCalls != 1+maxTruncateAttempts {
+				t.Errorf("counted the stuck item %d times, want %d", counter.stuckCalls, 1+maxTruncateAttempts)
+			}
+		})
+	}
+}
+
+func TestRunBatches(t *testing.T) {
+	chunks := [][]BatchItem{
+		{{JobID: 1}, {JobID: 2}},
+		{{JobID: 3}},
+		{{JobID: 4}, {JobID: 5}},
+	}
+
+	tests := []struct {
+		name        string
+		concurrency int
+	}{
+		{"sequential", 1},
+		{"unset limit", 0},
+		{"parallel", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &agent.Client{MaxConcurrentRequests: tt.concurrency}
+			var running, peak atomic.Int32
+			outcomes := RunBatches(context.Background(), client, chunks, func(ctx context.Context, chunk []BatchItem) ([]int, error) {
+				current := running.Add(1)
+				defer running.Add(-1)
+				for {
+					seen := peak.Load()
+					if current <= seen || peak.CompareAndSwap(seen, current) {
+						break
+					}
+				}
+				if chunk[0].JobID == 3 {
+					return nil, errors.New("chunk failed")
+				}
+				ids := []int{}
+				for _, item := range chunk {
+					ids = append(ids, item.JobID)
+				}
+				return ids, nil
+			})
+
+			if len(outcomes) != len(chunks) {
+				t.Fatalf("RunBatches() returned %d outcomes, want %d", len(outcomes), len(chunks))
+			}
+			if !slices.Equal(outcomes[0].Results, []int{1, 2}) || !slices.Equal(outcomes[2].Results, []int{4, 5}) {
+				t.Errorf("RunBatches() results = %v, %v, want them in chunk order", outcomes[0].Results, outcomes[2].Results)
+			}
+			if outcomes[1].Err == nil || outcomes[1].Items[0].JobID != 3 {
+				t.Errorf("RunBatches() outcome = %+v, want the error of the failed chunk", outcomes[1])
+			}
+			if limit := max(tt.concurrency, 1); int(peak.Load()) > limit {
+				t.Errorf("RunBatches() ran %d chunks at once, want at most %d", peak.Load(), limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"data-analyzer/agent"
+	"data-analyzer/emails"
+	"data-analyzer/models"
+)
+
+const CLASSIFY_EMAILS_PROMPT = `
+	You are an assistant sorting the emails a software engineer receives from recruiters and companies during a job search.
+	For every email decide its category:
+	- "rejection": the application was declined or the position was closed
+	- "interview_invite": an invitation to an interview or a request to schedule a call
+	- "take_home": a take-home assignment or online coding test
+	- "offer": a job offer
+	- "generic": anything else, e.g. an application confirmation or a newsletter
+
+	For every email also provide:
+	- confidence: a number between 0 and 1 telling how sure you are of the category
+	- interview_stage: for interview invitations, "hr" for recruiter or culture screens and "technical" for technical interviews, otherwise ""
+	- summary: one sentence summarizing what the email asks for or announces
+	- company_name: the company the email is about, or "" if it is not clear
+	- job_title: the position the email is about, or "" if it is not mentioned
+
+	Return the result as a JSON array with the following structure:
+	[
+		{"email_id": 1, "category": "interview_invite", "confidence": 0.9, "interview_stage": "technical", "summary": "Invites to a technical interview next week.", "company_name": "Acme", "job_title": "Backend Engineer"}
+	]
+
+	Emails:
+`
+
+// EmailClassification is the category and details the model found for a single email
+type EmailClassification struct {
+	EmailID        int     `json:"email_id"`
+	Category       string  `json:"category"`
+	Confidence     float64 `json:"confidence"`
+	InterviewStage string  `json:"interview_stage"`
+	Summary        string  `json:"summary"`
+	CompanyName    string  `json:"company_name"`
+	JobTitle       string  `json:"job_title"`
+}
+
+type ClassifyEmailsResult struct {
+	Prompt string
+	Result string
+	// Classifications are keyed by the position of the email in the input, starting at 1
+	Classifications map[int]EmailClassification
+}
+
+type ClassifyEmailsWorkflow struct {
+	client   *agent.Client
+	messages []emails.Message
+}
+
+func NewClassifyEmailsWorkflow(client *agent.Client, messages []emails.Message) *ClassifyEmailsWorkflow {
+	return &ClassifyEmailsWorkflow{
+		client:   client,
+		messages: messages,
+	}
+}
+
+func (w *ClassifyEmailsWorkflow) Execute(ctx context.Context) (ClassifyEmailsResult, error) {
+	items := make([]BatchItem, len(w.messages))
+	for i, message := range w.messages {
+		sanitized := agent.SanitizeText(message.Body)
+		items[i] = BatchItem{JobID: i + 1, Text: fmt.Sprintf("\n--- EMAIL ID: %d ---\nFrom: %s\nSubject: %s\n\n%s\n", i+1, message.From(), message.Subject, sanitized)}
+	}
+
+	chunks, skipped, err := NewBatchPlanner(w.client).Plan(ctx, CLASSIFY_EMAILS_PROMPT, items)
+	if err != nil {
+		return ClassifyEmailsResult{}, fmt.Errorf("failed to plan batches: %w", err)
+	}
+	for emailID, err := range skipped {
+		log.Printf("Skipping email %d: %v", emailID, err)
+	}
+	outcomes := RunBatches(ctx, w.client, chunks, w.executeChunk)
+
+	classifications := make(map[int]EmailClassification, len(w.messages))
+	all := make([]EmailClassification, 0, len(w.messages))
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			return ClassifyEmailsResult{}, outcome.Err
+		}
+		for _, classification := range outcome.Results {
+			classification.Category = strings.ToLower(strings.TrimSpace(classification.Category))
+			if !slices.Contains(models.EmailCategories, classification.Category) {
+				classification.Category = models.EmailCategoryGeneric
+			}
+			classification.Confidence = min(max(classification.Confidence, 0), 1)
+			classification.InterviewStage = strings.ToLower(strings.TrimSpace(classification.InterviewStage))
+			classifications[classification.EmailID] = classification
+			all = append(all, classification)
+		}
+	}
+
+	resultJSON, err := json.Marshal(all)
+	if err != nil {
+		return ClassifyEmailsResult{}, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return ClassifyEmailsResult{
+		Prompt:          CLASSIFY_EMAILS_PROMPT,
+		Result:          string(resultJSON),
+		Classifications: classifications,
+	}, nil
+}
+
+// executeChunk classifies a single chunk of emails
+func (w *ClassifyEmailsWorkflow) executeChunk(ctx context.Context, chunk []BatchItem) ([]EmailClassification, error) {
+	prompt := fmt.Sprintf(`%s %s`, CLASSIFY_EMAILS_PROMPT, joinBatchItems(chunk))
+
+	resp, err := w.client.GenerateContent(ctx, prompt, 0.1, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("no response from Gemini")
+	}
+
+	resultText := agent.SanitizeAgentJSONResponse(resp.Text())
+
+	var result []EmailClassification
+	if err := json.Unmarshal([]byte(resultText), &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,261 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"data-analyzer/agent"
+	"data-analyzer/models"
+)
+
+const EXTRACT_JOB_METADATA_PROMPT = `
+	You are a job description analyzer for software engineer positions.
+	For each job description extract the following metadata. Only use what the job description states, never guess:
+	- seniority: one of "intern", "junior", "mid", "senior", "staff", "principal", "lead", "manager", or "unknown"
+	- remote_policy: one of "remote", "hybrid", "onsite", or "unknown"
+	- locations: the office locations or the countries/regions the role is open to, as "City, Country" or "Country"
+	- timezone: the timezone or working hours overlap required, e.g. "CET +/- 2h", or an empty string if none is stated
+	- visa_sponsorship: "available" if visa sponsorship or relocation support is offered, "not_available" if the posting says it is not offered or requires existing work authorization, otherwise "unknown"
+	- contract_type: one of "full_time", "part_time", "contract", "freelance", "internship", or "unknown"
+	- years_of_experience_min and years_of_experience_max: the stated years of experience as integers, 0 if not stated; for "5+ years" use min 5 and max 0
+
+	Return the result as a JSON array with the following structure:
+	[
+		{
+			"job_id": 1,
+			"seniority": "senior",
+			"remote_policy": "hybrid",
+			"locations": ["Berlin, Germany"],
+			"timezone": "CET +/- 2h",
+			"visa_sponsorship": "available",
+			"contract_type": "full_time",
+			"years_of_experience_min": 5,
+			"years_of_experience_max": 0
+		}
+	]
+
+	Job Descriptions:
+`
+
+// Value used for every enumerated metadata field the job description does not state
+const MetadataUnknown = "unknown"
+
+// Seniority levels of a job
+const (
+	SeniorityIntern    = "intern"
+	SeniorityJunior    = "junior"
+	SeniorityMid       = "mid"
+	SenioritySenior    = "senior"
+	SeniorityStaff     = "staff"
+	SeniorityPrincipal = "principal"
+	SeniorityLead      = "lead"
+	SeniorityManager   = "manager"
+)
+
+// Remote work policies of a job
+const (
+	RemotePolicyRemote = "remote"
+	RemotePolicyHybrid = "hybrid"
+	RemotePolicyOnsite = "onsite"
+)
+
+// Visa sponsorship availability of a job
+const (
+	VisaSponsorshipAvailable    = "available"
+	VisaSponsorshipNotAvailable = "not_available"
+)
+
+// Contract types of a job
+const (
+	ContractTypeFullTime   = "full_time"
+	ContractTypePartTime   = "part_time"
+	ContractTypeContract   = "contract"
+	ContractTypeFreelance  = "freelance"
+	ContractTypeInternship = "internship"
+)
+
+var (
+	SeniorityChoices       = []string{SeniorityIntern, SeniorityJunior, SeniorityMid, SenioritySenior, SeniorityStaff, SeniorityPrincipal, SeniorityLead, SeniorityManager}
+	RemotePolicyChoices    = []string{RemotePolicyRemote, RemotePolicyHybrid, RemotePolicyOnsite}
+	VisaSponsorshipChoices = []string{VisaSponsorshipAvailable, VisaSponsorshipNotAvailable}
+	ContractTypeChoices    = []string{ContractTypeFullTime, ContractTypePartTime, ContractTypeContract, ContractTypeFreelance, ContractTypeInternship}
+)
+
+// metadataAliases maps common spellings returned by the model to the canonical values
+var metadataAliases = map[string]string{
+	"entry":        SeniorityJunior,
+	"entry level":  SeniorityJunior,
+	"middle":       SeniorityMid,
+	"mid level":    SeniorityMid,
+	"intermediate": SeniorityMid,
+	"sr":           SenioritySenior,
+	"on site":      RemotePolicyOnsite,
+	"office":       RemotePolicyOnsite,
+	"in office":    RemotePolicyOnsite,
+	"fully remote": RemotePolicyRemote,
+	"yes":          VisaSponsorshipAvailable,
+	"no":           VisaSponsorshipNotAvailable,
+	"full time":    ContractTypeFullTime,
+	"permanent":    ContractTypeFullTime,
+	"part time":    ContractTypePartTime,
+	"contractor":   ContractTypeContract,
+	"intern":       ContractTypeInternship,
+}
+
+// JobMetadata is the structured metadata of a single job.
+// Enumerated fields are "unknown" and years of experience are 0 when the job description does not state them.
+type JobMetadata struct {
+	Seniority            string   `json:"seniority"`
+	RemotePolicy         string   `json:"remote_policy"`
+	Locations            []string `json:"locations"`
+	Timezone             string   `json:"timezone"`
+	VisaSponsorship      string   `json:"visa_sponsorship"`
+	ContractType         string   `json:"contract_type"`
+	YearsOfExperienceMin int      `json:"years_of_experience_min"`
+	YearsOfExperienceMax int      `json:"years_of_experience_max"`
+}
+
+// JobMetadataEntry is the metadata extracted for a single job
+type JobMetadataEntry struct {
+	JobId    int         `json:"job_id"`
+	Metadata JobMetadata `json:"metadata"`
+}
+
+// rawJobMetadata is the per-job shape returned by the model before normalization
+type rawJobMetadata struct {
+	JobId int `json:"job_id"`
+	JobMetadata
+}
+
+type JobMetadataResult struct {
+	Prompt   string
+	Result   string
+	Metadata []JobMetadataEntry
+}
+
+type ExtractJobMetadataWorkflow struct {
+	client *agent.Client
+	jobs   []models.JobApplication
+}
+
+func NewExtractJobMetadataWorkflow(client *agent.Client, jobs []models.JobApplication) *ExtractJobMetadataWorkflow {
+	return &ExtractJobMetadataWorkflow{
+		client: client,
+		jobs:   jobs,
+	}
+}
+
+func (w *ExtractJobMetadataWorkflow) Execute(ctx context.Context) (JobMetadataResult, error) {
+	items := make([]BatchItem, len(w.jobs))
+	for i, job := range w.jobs {
+		sanitized := agent.SanitizeText(job.JobDescription)
+		items[i] = BatchItem{JobID: job.ID, Text: fmt.Sprintf("JOB ID %d (%s): %s\n", job.ID, job.JobTitle, sanitized)}
+	}
+
+	chunks, skipped, err := NewBatchPlanner(w.client).Plan(ctx, EXTRACT_JOB_METADATA_PROMPT, items)
+	if err != nil {
+		return JobMetadataResult{}, fmt.Errorf("failed to plan batches: %w", err)
+	}
+	for jobID, err := range skipped {
+		log.Printf("Skipping job %d: %v", jobID, err)
+	}
+
+	metadata := make([]JobMetadataEntry, 0, len(w.jobs))
+	for _, outcome := range RunBatches(ctx, w.client, chunks, w.executeChunk) {
+		if outcome.Err != nil {
+			return JobMetadataResult{}, outcome.Err
+		}
+		for _, raw := range outcome.Results {
+			metadata = append(metadata, JobMetadataEntry{
+				JobId:    raw.JobId,
+				Metadata: raw.JobMetadata.normalize(),
+			})
+		}
+	}
+
+	resultJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return JobMetadataResult{}, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return JobMetadataResult{
+		Prompt:   EXTRACT_JOB_METADATA_PROMPT,
+		Result:   string(resultJSON),
+		Metadata: metadata,
+	}, nil
+}
+
+// executeChunk extracts the raw metadata for a single chunk of jobs
+func (w *ExtractJobMetadataWorkflow) executeChunk(ctx context.Context, chunk []BatchItem) ([]rawJobMetadata, error) {
+	prompt := fmt.Sprintf(`%s %s`, EXTRACT_JOB_METADATA_PROMPT, joinBatchItems(chunk))
+
+	resp, err := w.client.GenerateContent(ctx, prompt, 0.1, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("no response from Gemini")
+	}
+
+	resultText := agent.SanitizeAgentJSONResponse(resp.Text())
+
+	var result []rawJobMetadata
+	if err := json.Unmarshal([]byte(resultText), &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return result, nil
+}
+
+// normalize maps the enumerated fields to their canonical values and drops invalid years of experience
+func (m JobMetadata) normalize() JobMetadata {
+	m.Seniority = NormalizeMetadataValue(m.Seniority, SeniorityChoices)
+	m.RemotePolicy = NormalizeMetadataValue(m.RemotePolicy, RemotePolicyChoices)
+	m.VisaSponsorship = NormalizeMetadataValue(m.VisaSponsorship, VisaSponsorshipChoices)
+	m.ContractType = NormalizeMetadataValue(m.ContractType, ContractTypeChoices)
+	m.Timezone = strings.TrimSpace(m.Timezone)
+
+	locations := []string{}
+	for _, location := range m.Locations {
+		location = strings.TrimSpace(location)
+		if location != "" && !slices.ContainsFunc(locations, func(l string) bool { return strings.EqualFold(l, location) }) {
+			locations = append(locations, location)
+		}
+	}
+	m.Locations = locations
+
+	m.YearsOfExperienceMin = max(0, m.YearsOfExperienceMin)
+	m.YearsOfExperienceMax = max(0, m.YearsOfExperienceMax)
+	if m.YearsOfExperienceMax > 0 && m.YearsOfExperienceMax < m.YearsOfExperienceMin {
+		m.YearsOfExperienceMin, m.YearsOfExperienceMax = m.YearsOfExperienceMax, m.YearsOfExperienceMin
+	}
+	return m
+}
+
+// NormalizeMetadataValue maps a value to one of the choices, ignoring case, dashes and aliases.
+// Values that match none of the choices become "unknown".
+func NormalizeMetadataValue(value string, choices []string) string {
+	key := strings.ToLower(strings.TrimSpace(value))
+	key = strings.NewReplacer("-", " ", "_", " ").Replace(key)
+	if alias, ok := metadataAliases[key]; ok && slices.Contains(choices, alias) {
+		return alias
+	}
+	key = strings.ReplaceAll(key, " ", "_")
+	if slices.Contains(choices, key) {
+		return key
+	}
+	return MetadataUnknown
+}
+
+// HasLocation reports whether any of the locations contains the query, ignoring case
+func (m JobMetadata) HasLocation(query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	return slices.ContainsFunc(m.Locations, func(location string) bool {
+		return strings.Contains(strings.ToLower(location), query)
+	})
+}
@@ -0,0 +1,140 @@
+package workflows
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeMetadataValue(t *testing.T) {
+	tests := []struct {
+		value   string
+		choices []string
+		want    string
+	}{
+		{"senior", SeniorityChoices, SenioritySenior},
+		{"  Senior ", SeniorityChoices, SenioritySenior},
+		{"SR", SeniorityChoices, SenioritySenior},
+		{"Entry-Level", SeniorityChoices, SeniorityJunior},
+		{"mid_level", SeniorityChoices, SeniorityMid},
+		{"On-site", RemotePolicyChoices, RemotePolicyOnsite},
+		{"in office", RemotePolicyChoices, RemotePolicyOnsite},
+		{"Fully Remote", RemotePolicyChoices, RemotePolicyRemote},
+		{"Not Available", VisaSponsorshipChoices, VisaSponsorshipNotAvailable},
+		{"yes", VisaSponsorshipChoices, VisaSponsorshipAvailable},
+		{"Full-Time", ContractTypeChoices, ContractTypeFullTime},
+		{"permanent", ContractTypeChoices, ContractTypeFullTime},
+		{"intern", ContractTypeChoices, ContractTypeInternship},
+		// "intern" is a canonical seniority, not the internship alias
+		{"intern", SeniorityChoices, SeniorityIntern},
+		// an alias only applies to the field it belongs to
+		{"yes", ContractTypeChoices, MetadataUnknown},
+		{"office", SeniorityChoices, MetadataUnknown},
+		{"", SeniorityChoices, MetadataUnknown},
+		{"unknown", SeniorityChoices, MetadataUnknown},
+		{"vice president", SeniorityChoices, MetadataUnknown},
+	}
+	for _, tt := range tests {
+		if got := NormalizeMetadataValue(tt.value, tt.choices); got != tt.want {
+			t.Errorf("NormalizeMetadataValue(%q, %q) = %q, want %q", tt.value, tt.choices, got, tt.want)
+		}
+	}
+}
+
+func TestMetadataAliasesAreCanonical(t *testing.T) {
+	var choices []string
+	for _, fieldChoices := range [][]string{SeniorityChoices, RemotePolicyChoices, VisaSponsorshipChoices, ContractTypeChoices} {
+		choices = append(choices, fieldChoices...)
+	}
+
+	for alias, value := range metadataAliases {
+		if !slices.Contains(choices, value) {
+			t.Errorf("metadataAliases[%q] = %q, which is not a choice of any field", alias, value)
+		}
+		// the lookup happens after lowercasing and replacing dashes and underscores with spaces
+		if got := NormalizeMetadataValue(alias, choices); got != value {
+			t.Errorf("NormalizeMetadataValue(%q) = %q, want %q", alias, got, value)
+		}
+	}
+}
+
+func TestJobMetadataNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata JobMetadata
+		want     JobMetadata
+	}{
+		{
+			name: "enumerated fields",
+			metadata: JobMetadata{
+				Seniority:       "Sr",
+				RemotePolicy:    "Hybrid",
+				VisaSponsorship: "no",
+				ContractType:    "Contractor",
+				Timezone:        " CET ",
+			},
+			want: JobMetadata{
+				Seniority:       SenioritySenior,
+				RemotePolicy:    RemotePolicyHybrid,
+				VisaSponsorship: VisaSponsorshipNotAvailable,
+				ContractType:    ContractTypeContract,
+				Timezone:        "CET",
+				Locations:       []string{},
+			},
+		},
+		{
+			name: "locations are trimmed and deduplicated ignoring case",
+			metadata: JobMetadata{
+				Locations: []string{" Berlin ", "berlin", "", "Lisbon", "  "},
+			},
+			want: JobMetadata{
+				Seniority:       MetadataUnknown,
+				RemotePolicy:    MetadataUnknown,
+				VisaSponsorship: MetadataUnknown,
+				ContractType:    MetadataUnknown,
+				Locations:       []string{"Berlin", "Lisbon"},
+			},
+		},
+		{
+			name:     "negative years of experience are dropped",
+			metadata: JobMetadata{YearsOfExperienceMin: -1, YearsOfExperienceMax: -3},
+			want: JobMetadata{
+				Seniority:       MetadataUnknown,
+				RemotePolicy:    MetadataUnknown,
+				VisaSponsorship: MetadataUnknown,
+				ContractType:    MetadataUnknown,
+				Locations:       []string{},
+			},
+		},
+		{
+			name:     "a reversed range is swapped",
+			metadata: JobMetadata{YearsOfExperienceMin: 7, YearsOfExperienceMax: 3},
+			want: JobMetadata{
+				Seniority:            MetadataUnknown,
+				RemotePolicy:         MetadataUnknown,
+				VisaSponsorship:      MetadataUnknown,
+				ContractType:         MetadataUnknown,
+				Locations:            []string{},
+				YearsOfExperienceMin: 3,
+				YearsOfExperienceMax: 7,
+			},
+		},
+		{
+			name:     "a minimum without a maximum is kept",
+			metadata: JobMetadata{YearsOfExperienceMin: 5},
+			want: JobMetadata{
+				Seniority:            MetadataUnknown,
+				RemotePolicy:         MetadataUnknown,
+				VisaSponsorship:      MetadataUnknown,
+				ContractType:         MetadataUnknown,
+				Locations:            []string{},
+				YearsOfExperienceMin: 5,
+			},
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.metadata.normalize(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: normalize() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"data-analyzer/agent"
+	"data-analyzer/jobposting"
+)
+
+const EXTRACT_JOB_POSTING_PROMPT = `
+	You are a job posting parser. Below is the text of a web page containing a job posting.
+	Extract the following fields, only using what the page states:
+	- job_title: the title of the position, without the company name or location
+	- company_name: the name of the hiring company, not the job board hosting the posting
+	- salary: the pay exactly as stated (e.g. "€70,000 - €90,000 per year"), or an empty string if not stated
+	- job_description: the full description of the role, responsibilities, requirements and benefits, copied verbatim as plain text with one paragraph or bullet per line; leave out navigation, cookie notices, application forms and links to other jobs
+
+	Return the result as a JSON object with the following structure:
+	{
+		"job_title": "Senior Backend Engineer",
+		"company_name": "Acme",
+		"salary": "€70,000 - €90,000 per year",
+		"job_description": "About the role\nWe are looking for..."
+	}
+
+	Page Title: %s
+	Site Name: %s
+	Page Text:
+	%s
+`
+
+// maxPostingPageLength is the number of characters of page text sent to the model
+const maxPostingPageLength = 30000
+
+type JobPostingResult struct {
+	Prompt  string
+	Result  string
+	Posting jobposting.Posting
+}
+
+type ExtractJobPostingWorkflow struct {
+	client   *agent.Client
+	readable jobposting.Readable
+}
+
+func NewExtractJobPostingWorkflow(client *agent.Client, readable jobposting.Readable) *ExtractJobPostingWorkflow {
+	return &ExtractJobPostingWorkflow{
+		client:   client,
+		readable: readable,
+	}
+}
+
+func (w *ExtractJobPostingWorkflow) Execute(ctx context.Context) (JobPostingResult, error) {
+	prompt := fmt.Sprintf(EXTRACT_JOB_POSTING_PROMPT, w.readable.Title, w.readable.SiteName, agent.TruncateText(w.readable.Text, maxPostingPageLength))
+
+	resp, err := w.client.GenerateContent(ctx, prompt, 0.1, false)
+	if err != nil {
+		return JobPostingResult{}, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return JobPostingResult{}, fmt.Errorf("no response from Gemini")
+	}
+
+	resultText := agent.SanitizeAgentJSONResponse(resp.Text())
+
+	var posting jobposting.Posting
+	if err := json.Unmarshal([]byte(resultText), &posting); err != nil {
+		return JobPostingResult{}, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return JobPostingResult{
+		Prompt:  EXTRACT_JOB_POSTING_PROMPT,
+		Result:  resultText,
+		Posting: posting,
+	}, nil
+}
@@ -6,7 +6,7 @@ import (
 	"data-analyzer/models"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"log"
 )
 
 const PROMPT = `
@@ -67,22 +67,54 @@ func NewExtractRoleDetailsWorkflow(client *agent.Client, jobs []models.JobApplic
 }
 
 func (w *ExtractRoleDetailsWorkflow) Execute(ctx context.Context) (Result, error) {
-	// Build batch prompt with all job descriptions
-	var jobsBuilder strings.Builder
-	for _, job := range w.jobs {
+	// Render every job description as a batch item
+	items := make([]BatchItem, len(w.jobs))
+	for i, job := range w.jobs {
 		sanitized := agent.SanitizeText(job.JobDescription)
-		jobsBuilder.WriteString(fmt.Sprintf("JOB ID %d: %s\n", job.ID, sanitized))
+		items[i] = BatchItem{JobID: job.ID, Text: fmt.Sprintf("JOB ID %d: %s\n", job.ID, sanitized)}
 	}
 
-	prompt := fmt.Sprintf(`%s %s`, PROMPT, jobsBuilder.String())
+	// Split the jobs into chunks that fit the token budget and run them in parallel
+	chunks, skipped, err := NewBatchPlanner(w.client).Plan(ctx, PROMPT, items)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to plan batches: %w", err)
+	}
+	for jobID, err := range skipped {
+		log.Printf("Skipping job %d: %v", jobID, err)
+	}
+	outcomes := RunBatches(ctx, w.client, chunks, w.executeChunk)
+
+	roleDetails := make([]RoleDetails, 0, len(w.jobs))
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			return Result{}, outcome.Err
+		}
+		roleDetails = append(roleDetails, outcome.Results...)
+	}
+
+	resultJSON, err := json.Marshal(roleDetails)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return Result{
+		Prompt:      PROMPT,
+		Result:      string(resultJSON),
+		RoleDetails: roleDetails,
+	}, nil
+}
+
+// executeChunk extracts the role details for a single chunk of jobs
+func (w *ExtractRoleDetailsWorkflow) executeChunk(ctx context.Context, chunk []BatchItem) ([]RoleDetails, error) {
+	prompt := fmt.Sprintf(`%s %s`, PROMPT, joinBatchItems(chunk))
 
 	resp, err := w.client.GenerateContent(ctx, prompt, 0.1, false)
 	if err != nil {
-		return Result{}, fmt.Errorf("failed to generate content: %w", err)
+		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
 
 	if len(resp.Candidates) == 0 {
-		return Result{}, fmt.Errorf("no response from Gemini")
+		return nil, fmt.Errorf("no response from Gemini")
 	}
 
 	resultText := resp.Text()
@@ -90,12 +122,8 @@ func (w *ExtractRoleDetailsWorkflow) Execute(ctx context.Context) (Result, error
 
 	var result []RoleDetails
 	if err := json.Unmarshal([]byte(resultText), &result); err != nil {
-		return Result{}, fmt.Errorf("failed to unmarshal result: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
 	}
 
-	return Result{
-		Prompt:      PROMPT,
-		Result:      resultText,
-		RoleDetails: result,
-	}, nil
+	return result, nil
 }
@@ -0,0 +1,199 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"data-analyzer/agent"
+	"data-analyzer/compensation"
+	"data-analyzer/models"
+)
+
+const EXTRACT_SALARY_PROMPT = `
+	You are a job description analyzer focused on compensation.
+	For each job description find every sentence that describes pay: salary, hourly or daily rates, salary ranges, bonuses, equity or stock options.
+	Copy those sentences verbatim into compensation_text, joined by a space. If there is no such sentence, use an empty string.
+	Also fill in the structured fields when the amounts are stated:
+	- min and max: the lowest and highest amount as plain numbers (70k becomes 70000), or 0 if no amount is stated
+	- currency: the ISO 4217 currency code, or an empty string if unknown
+	- period: one of "hourly", "monthly", "yearly", or an empty string if unknown
+	- equity and bonus: true if equity/stock options or a bonus are mentioned
+
+	Return the result as a JSON array with the following structure:
+	[
+		{
+			"job_id": 1,
+			"compensation_text": "The salary range for this role is €70,000 - €90,000 per year plus stock options.",
+			"min": 70000,
+			"max": 90000,
+			"currency": "EUR",
+			"period": "yearly",
+			"equity": true,
+			"bonus": false
+		}
+	]
+
+	Job Descriptions:
+`
+
+// Where the amounts of a job's compensation came from
+const (
+	CompensationSourceSalaryField    = "salary_field"
+	CompensationSourceJobDescription = "job_description"
+	CompensationSourceModel          = "model"
+	CompensationSourceNone           = "none"
+)
+
+// JobCompensation is the structured compensation extracted for a single job
+type JobCompensation struct {
+	JobId        int                       `json:"job_id"`
+	Source       string                    `json:"source"`
+	Compensation compensation.Compensation `json:"compensation"`
+}
+
+// rawJobCompensation is the per-job shape returned by the model
+type rawJobCompensation struct {
+	JobId            int     `json:"job_id"`
+	CompensationText string  `json:"compensation_text"`
+	Min              float64 `json:"min"`
+	Max              float64 `json:"max"`
+	Currency         string  `json:"currency"`
+	Period           string  `json:"period"`
+	Equity           bool    `json:"equity"`
+	Bonus            bool    `json:"bonus"`
+}
+
+type SalaryResult struct {
+	Prompt        string
+	Result        string
+	Compensations []JobCompensation
+}
+
+type ExtractSalaryWorkflow struct {
+	client *agent.Client
+	jobs   []models.JobApplication
+}
+
+func NewExtractSalaryWorkflow(client *agent.Client, jobs []models.JobApplication) *ExtractSalaryWorkflow {
+	return &ExtractSalaryWorkflow{
+		client: client,
+		jobs:   jobs,
+	}
+}
+
+// Execute extracts the compensation of every job. The salary field is parsed first; only jobs
+// without an amount there are sent to the model, whose quoted text is then run through the parser.
+func (w *ExtractSalaryWorkflow) Execute(ctx context.Context) (SalaryResult, error) {
+	items := make([]BatchItem, 0, len(w.jobs))
+	for _, job := range w.jobs {
+		if compensation.ParseField(job.Salary).HasAmount() {
+			continue
+		}
+		sanitized := agent.SanitizeText(job.JobDescription)
+		items = append(items, BatchItem{JobID: job.ID, Text: fmt.Sprintf("JOB ID %d: %s\n", job.ID, sanitized)})
+	}
+
+	rawCompensations := make(map[int]rawJobCompensation)
+	if len(items) > 0 {
+		chunks, skipped, err := NewBatchPlanner(w.client).Plan(ctx, EXTRACT_SALARY_PROMPT, items)
+		if err != nil {
+			return SalaryResult{}, fmt.Errorf("failed to plan batches: %w", err)
+		}
+		// a skipped job falls back to parsing its salary field
+		for jobID, err := range skipped {
+			log.Printf("Skipping job %d: %v", jobID, err)
+		}
+		for _, outcome := range RunBatches(ctx, w.client, chunks, w.executeChunk) {
+			if outcome.Err != nil {
+				return SalaryResult{}, outcome.Err
+			}
+			for _, raw := range outcome.Results {
+				rawCompensations[raw.JobId] = raw
+			}
+		}
+	}
+
+	compensations := make([]JobCompensation, len(w.jobs))
+	for i, job := range w.jobs {
+		compensations[i] = resolveCompensation(job, rawCompensations[job.ID])
+	}
+
+	resultJSON, err := json.Marshal(compensations)
+	if err != nil {
+		return SalaryResult{}, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return SalaryResult{
+		Prompt:        EXTRACT_SALARY_PROMPT,
+		Result:        string(resultJSON),
+		Compensations: compensations,
+	}, nil
+}
+
+// executeChunk asks the model for the compensation of a single chunk of jobs
+func (w *ExtractSalaryWorkflow) executeChunk(ctx context.Context, chunk []BatchItem) ([]rawJobCompensation, error) {
+	prompt := fmt.Sprintf(`%s %s`, EXTRACT_SALARY_PROMPT, joinBatchItems(chunk))
+
+	resp, err := w.client.GenerateContent(ctx, prompt, 0.1, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("no response from Gemini")
+	}
+
+	resultText := agent.SanitizeAgentJSONResponse(resp.Text())
+
+	var result []rawJobCompensation
+	if err := json.Unmarshal([]byte(resultText), &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return result, nil
+}
+
+// resolveCompensation picks the most reliable compensation for a job: the salary field, then the
+// parsed text quoted by the model, then the model's own structured values, then the raw description.
+// Only the salary field accepts bare numbers; the quoted text is free text like the description.
+func resolveCompensation(job models.JobApplication, raw rawJobCompensation) JobCompensation {
+	fromDescription := compensation.Parse(job.JobDescription)
+
+	result := JobCompensation{JobId: job.ID, Source: CompensationSourceNone, Compensation: fromDescription}
+	if fromField := compensation.ParseField(job.Salary); fromField.HasAmount() {
+		result.Source = CompensationSourceSalaryField
+		result.Compensation = fromField
+	} else if fromText := compensation.Parse(raw.CompensationText); fromText.HasAmount() {
+		result.Source = CompensationSourceJobDescription
+		result.Compensation = fromText
+	} else if raw.Max > 0 || raw.Min > 0 {
+		result.Source = CompensationSourceModel
+		result.Compensation = compensation.Compensation{
+			Min:      raw.Min,
+			Max:      max(raw.Min, raw.Max),
+			Currency: strings.ToUpper(raw.Currency),
+			Period:   raw.Period,
+			RawText:  raw.CompensationText,
+		}
+		if result.Compensation.Min == 0 {
+			result.Compensation.Min = result.Compensation.Max
+		}
+	} else if fromDescription.HasAmount() {
+		result.Source = CompensationSourceJobDescription
+	}
+
+	// equity, bonus and vague mentions can appear anywhere in the posting
+	result.Compensation.Equity = result.Compensation.Equity || fromDescription.Equity || raw.Equity
+	result.Compensation.Bonus = result.Compensation.Bonus || fromDescription.Bonus || raw.Bonus
+	result.Compensation.VagueMention = result.Compensation.VagueMention || fromDescription.VagueMention
+
+	return result
+}
+
+// DeterministicCompensation resolves the compensation of a job with the parser only, without calling the model
+func DeterministicCompensation(job models.JobApplication) JobCompensation {
+	return resolveCompensation(job, rawJobCompensation{})
+}
@@ -0,0 +1,61 @@
+package workflows
+
+import (
+	"testing"
+
+	"data-analyzer/compensation"
+	"data-analyzer/models"
+)
+
+func TestResolveCompensation(t *testing.T) {
+	tests := []struct {
+		name       string
+		job        models.JobApplication
+		raw        rawJobCompensation
+		wantSource string
+		wantMin    float64
+		wantMax    float64
+		wantPeriod string
+	}{
+		{
+			name:       "salary field with bare numbers",
+			job:        models.JobApplication{Salary: "60000-70000", JobDescription: "Salary €5000/month"},
+			wantSource: CompensationSourceSalaryField,
+			wantMin:    60000, wantMax: 70000, wantPeriod: compensation.PeriodYearly,
+		},
+		{
+			name:       "quoted text is free text",
+			job:        models.JobApplication{JobDescription: "Join us."},
+			raw:        rawJobCompensation{CompensationText: "We have 2 offices and 10 000 customers, salary €5000/month"},
+			wantSource: CompensationSourceJobDescription,
+			wantMin:    5000, wantMax: 5000, wantPeriod: compensation.PeriodMonthly,
+		},
+		{
+			name:       "structured values when the quote has no currency",
+			job:        models.JobApplication{JobDescription: "Join us."},
+			raw:        rawJobCompensation{CompensationText: "2 offices", Min: 80000, Max: 90000, Currency: "usd", Period: compensation.PeriodYearly},
+			wantSource: CompensationSourceModel,
+			wantMin:    80000, wantMax: 90000, wantPeriod: compensation.PeriodYearly,
+		},
+		{
+			name:       "description without the model",
+			job:        models.JobApplication{JobDescription: "5+ years of Go. We pay £50k - £60k a year."},
+			wantSource: CompensationSourceJobDescription,
+			wantMin:    50000, wantMax: 60000, wantPeriod: compensation.PeriodYearly,
+		},
+		{
+			name:       "nothing",
+			job:        models.JobApplication{JobDescription: "Competitive salary."},
+			wantSource: CompensationSourceNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveCompensation(tt.job, tt.raw)
+			if got.Source != tt.wantSource || got.Compensation.Min != tt.wantMin || got.Compensation.Max != tt.wantMax || got.Compensation.Period != tt.wantPeriod {
+				t.Errorf("resolveCompensation() = %s %v-%v %s, want %s %v-%v %s", got.Source, got.Compensation.Min, got.Compensation.Max,
+					got.Compensation.Period, tt.wantSource, tt.wantMin, tt.wantMax, tt.wantPeriod)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"data-analyzer/agent"
+	"data-analyzer/models"
+)
+
+const EXTRACT_TECH_STACK_PROMPT = `
+	You are a job description analyzer for software engineer positions.
+	Your task is to extract every technology mentioned in each job description: programming languages, frameworks and libraries, databases and data stores, cloud platforms and infrastructure, and other tools.
+	Only include concrete technologies, not general concepts such as "microservices" or "distributed systems".
+	Use the name of the technology as it appears in the job description.
+	Categorize every technology as one of: "language", "framework", "database", "cloud", "tool".
+
+	Return the result as a JSON array with the following structure:
+	[
+		{
+			"job_id": 1,
+			"technologies": [
+				{"name": "Golang", "category": "language"},
+				{"name": "Postgres", "category": "database"}
+			]
+		}
+	]
+
+	Job Descriptions:
+`
+
+// TechStack holds the normalized technologies of a job grouped by category
+type TechStack struct {
+	Languages  []string `json:"language"`
+	Frameworks []string `json:"framework"`
+	Databases  []string `json:"database"`
+	Cloud      []string `json:"cloud"`
+	Tools      []string `json:"tool"`
+}
+
+// JobTechStack is the tech stack extracted for a single job
+type JobTechStack struct {
+	JobId     int       `json:"job_id"`
+	TechStack TechStack `json:"tech_stack"`
+}
+
+// rawJobTechStack is the per-job shape returned by the model before normalization
+type rawJobTechStack struct {
+	JobId        int          `json:"job_id"`
+	Technologies []Technology `json:"technologies"`
+}
+
+type TechStackResult struct {
+	Prompt     string
+	Result     string
+	TechStacks []JobTechStack
+}
+
+type ExtractTechStackWorkflow struct {
+	client   *agent.Client
+	jobs     []models.JobApplication
+	taxonomy *TechTaxonomy
+}
+
+func NewExtractTechStackWorkflow(client *agent.Client, jobs []models.JobApplication, taxonomy *TechTaxonomy) *ExtractTechStackWorkflow {
+	return &ExtractTechStackWorkflow{
+		client:   client,
+		jobs:     jobs,
+		taxonomy: taxonomy,
+	}
+}
+
+func (w *ExtractTechStackWorkflow) Execute(ctx context.Context) (TechStackResult, error) {
+	items := make([]BatchItem, len(w.jobs))
+	for i, job := range w.jobs {
+		sanitized := agent.SanitizeText(job.JobDescription)
+		items[i] = BatchItem{JobID: job.ID, Text: fmt.Sprintf("JOB ID %d: %s\n", job.ID, sanitized)}
+	}
+
+	chunks, skipped, err := NewBatchPlanner(w.client).Plan(ctx, EXTRACT_TECH_STACK_PROMPT, items)
+	if err != nil {
+		return TechStackResult{}, fmt.Errorf("failed to plan batches: %w", err)
+	}
+	for jobID, err := range skipped {
+		log.Printf("Skipping job %d: %v", jobID, err)
+	}
+	outcomes := RunBatches(ctx, w.client, chunks, w.executeChunk)
+
+	techStacks := make([]JobTechStack, 0, len(w.jobs))
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			return TechStackResult{}, outcome.Err
+		}
+		for _, raw := range outcome.Results {
+			techStacks = append(techStacks, JobTechStack{
+				JobId:     raw.JobId,
+				TechStack: w.groupTechnologies(raw.Technologies),
+			})
+		}
+	}
+
+	resultJSON, err := json.Marshal(techStacks)
+	if err != nil {
+		return TechStackResult{}, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return TechStackResult{
+		Prompt:     EXTRACT_TECH_STACK_PROMPT,
+		Result:     string(resultJSON),
+		TechStacks: techStacks,
+	}, nil
+}
+
+// executeChunk extracts the raw technologies for a single chunk of jobs
+func (w *ExtractTechStackWorkflow) executeChunk(ctx context.Context, chunk []BatchItem) ([]rawJobTechStack, error) {
+	prompt := fmt.Sprintf(`%s %s`, EXTRACT_TECH_STACK_PROMPT, joinBatchItems(chunk))
+
+	resp, err := w.client.GenerateContent(ctx, prompt, 0.1, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return nil, fmt.Errorf("no response from Gemini")
+	}
+
+	resultText := agent.SanitizeAgentJSONResponse(resp.Text())
+
+	var result []rawJobTechStack
+	if err := json.Unmarshal([]byte(resultText), &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	return result, nil
+}
+
+// groupTechnologies normalizes the technologies through the taxonomy and groups them by category
+func (w *ExtractTechStackWorkflow) groupTechnologies(technologies []Technology) TechStack {
+	var techStack TechStack
+	for _, raw := range technologies {
+		if raw.Name == "" {
+			continue
+		}
+		technology := w.taxonomy.Normalize(raw.Name, raw.Category)
+		group := techStack.group(technology.Category)
+		if !slices.Contains(*group, technology.Name) {
+			*group = append(*group, technology.Name)
+		}
+	}
+	return techStack
+}
+
+// Contains reports whether the tech stack includes the technology name, ignoring case
+func (t TechStack) Contains(name string) bool {
+	matches := func(technology string) bool { return strings.EqualFold(technology, name) }
+	return slices.ContainsFunc(t.Languages, matches) ||
+		slices.ContainsFunc(t.Frameworks, matches) ||
+		slices.ContainsFunc(t.Databases, matches) ||
+		slices.ContainsFunc(t.Cloud, matches) ||
+		slices.ContainsFunc(t.Tools, matches)
+}
+
+func (t *TechStack) group(category string) *[]string {
+	switch category {
+	case TechCategoryLanguage:
+		return &t.Languages
+	case TechCategoryFramework:
+		return &t.Frameworks
+	case TechCategoryDatabase:
+		return &t.Databases
+	case TechCategoryCloud:
+		return &t.Cloud
+	default:
+		return &t.Tools
+	}
+}
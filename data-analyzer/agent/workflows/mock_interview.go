@@ -0,0 +1,254 @@
+package workflows
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const MOCK_INTERVIEW_QUESTION_PROMPT = `
+	%s
+
+	You are running a mock interview of %d questions. Ask exactly one question at a time.
+	Build on the candidate's previous answers: follow up on vague or weak answers, otherwise move on
+	to a requirement or topic that was not covered yet. Mix technical and behavioral questions, in
+	the proportion that fits the interview stage. Do not repeat a question and do not answer it yourself.
+
+	Return the result as a JSON object with the following structure:
+	{
+		"question": "Tell me about a time you had to debug a production outage under pressure.",
+		"focus": "Incident handling"
+	}
+
+	Transcript so far:
+	%s
+
+	This is question %d of %d.
+`
+
+const MOCK_INTERVIEW_GRADE_PROMPT = `
+	%s
+
+	The mock interview is over. Grade every answer of the candidate against the rubric below.
+	Score each criterion from 1 (poor) to 5 (excellent), judging only what the candidate said.
+	An empty or evasive answer scores 1 on every criterion.
+	For every answer give short feedback on what worked and one concrete suggestion to improve it.
+
+	Rubric:
+	%s
+
+	Return the result as a JSON array with one object per answer, using the index of the question:
+	[
+		{
+			"index": 0,
+			"scores": {"relevance": 4, "technical_depth": 3, "structure": 5, "evidence": 2, "communication": 4},
+			"feedback": "Clear STAR structure and a relevant example",
+			"suggestion": "Quantify the impact of the fix"
+		}
+	]
+
+	Transcript:
+	%s
+`
+
+// MockInterviewRubric are the criteria every answer is graded on, from 1 to 5
+var MockInterviewRubric = []RubricCriterion{
+	{Name: "relevance", Description: "The answer addresses the question that was asked"},
+	{Name: "technical_depth", Description: "The answer shows a sound and deep understanding of the technologies and trade-offs involved"},
+	{Name: "structure", Description: "The answer is structured, e.g. situation, task, action and result for behavioral questions"},
+	{Name: "evidence", Description: "The answer is backed by concrete examples, the candidate's own contribution and measurable results"},
+	{Name: "communication", Description: "The answer is concise, clear and confident"},
+}
+
+// maxRubricScore is the highest score of a rubric criterion
+const maxRubricScore = 5
+
+// Mock interview states
+const (
+	MockInterviewInProgress = "in_progress"
+	MockInterviewCompleted  = "completed"
+)
+
+// RubricCriterion is a criterion answers are graded on
+type RubricCriterion struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AnswerGrade is the grade of a single answer
+type AnswerGrade struct {
+	// Scores is the score of every rubric criterion, from 1 to 5
+	Scores map[string]int `json:"scores"`
+	// Score is the average of the criteria as a percentage
+	Score      float64 `json:"score"`
+	Feedback   string  `json:"feedback"`
+	Suggestion string  `json:"suggestion"`
+}
+
+// MockInterviewTurn is a question of the interviewer and the candidate's answer
+type MockInterviewTurn struct {
+	Question string `json:"question"`
+	Focus    string `json:"focus"`
+	// Answer is empty while the question waits for an answer
+	Answer     string       `json:"answer"`
+	AnsweredAt *time.Time   `json:"answered_at,omitempty"`
+	Grade      *AnswerGrade `json:"grade,omitempty"`
+}
+
+// MockInterviewSession is a mock interview with its transcript, and its grades once completed
+type MockInterviewSession struct {
+	// SessionID is the ID of the workflow that started the session
+	SessionID int    `json:"session_id"`
+	JobId     int    `json:"job_id"`
+	Status    string `json:"status"`
+	State     string `json:"state"`
+	// Persona is the description of the interviewer, fixed when the session starts
+	Persona       string              `json:"persona"`
+	QuestionCount int                 `json:"question_count"`
+	Turns         []MockInterviewTurn `json:"turns"`
+	// Score is the average score of the graded answers as a percentage, set once completed
+	Score       float64    `json:"score"`
+	StartedAt   time.Time  `json:"started_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// rawAnswerGrade is the shape returned by the model, referencing questions by index
+type rawAnswerGrade struct {
+	Index      int            `json:"index"`
+	Scores     map[string]int `json:"scores"`
+	Feedback   string         `json:"feedback"`
+	Suggestion string         `json:"suggestion"`
+}
+
+type MockInterviewWorkflow struct {
+	client *agent.Client
+}
+
+func NewMockInterviewWorkflow(client *agent.Client) *MockInterviewWorkflow {
+	return &MockInterviewWorkflow{
+		client: client,
+	}
+}
+
+// NextQuestion asks the interviewer for the next question of the session. It returns the prompt so the
+// caller can store it with the turn.
+func (w *MockInterviewWorkflow) NextQuestion(ctx context.Context, session MockInterviewSession) (MockInterviewTurn, string, error) {
+	prompt := fmt.Sprintf(MOCK_INTERVIEW_QUESTION_PROMPT, session.Persona, session.QuestionCount,
+		formatTranscript(session.Turns, "No questions were asked yet."), len(session.Turns)+1, session.QuestionCount)
+
+	resp, err := w.client.GenerateContent(ctx, prompt, 0.7, false)
+	if err != nil {
+		return MockInterviewTurn{}, prompt, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return MockInterviewTurn{}, prompt, fmt.Errorf("no response from Gemini")
+	}
+
+	var turn MockInterviewTurn
+	if err := json.Unmarshal([]byte(agent.SanitizeAgentJSONResponse(resp.Text())), &turn); err != nil {
+		return MockInterviewTurn{}, prompt, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+	turn.Question = strings.TrimSpace(turn.Question)
+	if turn.Question == "" {
+		return MockInterviewTurn{}, prompt, fmt.Errorf("the interviewer did not ask a question")
+	}
+	turn.Answer = ""
+	turn.Grade = nil
+	return turn, prompt, nil
+}
+
+// Grade grades the answered turns of the session against the rubric and computes the session score.
+// It returns the prompt so the caller can store it with the grades.
+func (w *MockInterviewWorkflow) Grade(ctx context.Context, session MockInterviewSession) (MockInterviewSession, string, error) {
+	rubricString := ""
+	for _, criterion := range MockInterviewRubric {
+		rubricString += fmt.Sprintf("- %s: %s\n", criterion.Name, criterion.Description)
+	}
+
+	prompt := fmt.Sprintf(MOCK_INTERVIEW_GRADE_PROMPT, session.Persona, rubricString, formatTranscript(session.Turns, ""))
+
+	resp, err := w.client.GenerateContent(ctx, prompt, 0.1, false)
+	if err != nil {
+		return session, prompt, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return session, prompt, fmt.Errorf("no response from Gemini")
+	}
+
+	var rawGrades []rawAnswerGrade
+	if err := json.Unmarshal([]byte(agent.SanitizeAgentJSONResponse(resp.Text())), &rawGrades); err != nil {
+		return session, prompt, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	// Every answer starts at the lowest score, so answers the model skipped are not counted as good ones
+	turns := make([]MockInterviewTurn, len(session.Turns))
+	copy(turns, session.Turns)
+	for i := range turns {
+		scores := make(map[string]int, len(MockInterviewRubric))
+		for _, criterion := range MockInterviewRubric {
+			scores[criterion.Name] = 1
+		}
+		turns[i].Grade = &AnswerGrade{Scores: scores, Feedback: "Not graded"}
+	}
+	for _, raw := range rawGrades {
+		if raw.Index < 0 || raw.Index >= len(turns) {
+			continue
+		}
+		grade := turns[raw.Index].Grade
+		// criteria outside the rubric are dropped and scores are kept in range
+		for _, criterion := range MockInterviewRubric {
+			if score, ok := raw.Scores[criterion.Name]; ok {
+				grade.Scores[criterion.Name] = min(max(score, 1), maxRubricScore)
+			}
+		}
+		grade.Feedback = raw.Feedback
+		grade.Suggestion = raw.Suggestion
+	}
+
+	total := 0.0
+	for _, turn := range turns {
+		turn.Grade.Score = rubricScore(turn.Grade.Scores)
+		total += turn.Grade.Score
+	}
+	session.Turns = turns
+	if len(turns) > 0 {
+		session.Score = math.Round(total/float64(len(turns))*10) / 10
+	}
+	return session, prompt, nil
+}
+
+// rubricScore is the average of the criteria scores as a percentage
+func rubricScore(scores map[string]int) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	total := 0
+	for _, score := range scores {
+		total += score
+	}
+	return math.Round(float64(total)/float64(len(scores)*maxRubricScore)*1000) / 10
+}
+
+// formatTranscript writes the questions and answers of the session, numbered from 0
+func formatTranscript(turns []MockInterviewTurn, empty string) string {
+	if len(turns) == 0 {
+		return empty
+	}
+	transcript := ""
+	for i, turn := range turns {
+		transcript += fmt.Sprintf("%d. Interviewer: %s\n", i, turn.Question)
+		answer := turn.Answer
+		if answer == "" {
+			answer = "(no answer)"
+		}
+		transcript += fmt.Sprintf("   Candidate: %s\n", answer)
+	}
+	return transcript
+}
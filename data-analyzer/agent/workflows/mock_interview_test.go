@@ -0,0 +1,43 @@
+package workflows
+
+import "testing"
+
+func TestRubricScore(t *testing.T) {
+	tests := []struct {
+		scores map[string]int
+		want   float64
+	}{
+		{nil, 0},
+		{map[string]int{"structure": 5, "relevance": 5}, 100},
+		{map[string]int{"structure": 3, "relevance": 4, "impact": 2}, 60},
+		{map[string]int{"structure": 1, "relevance": 2, "impact": 2}, 33.3},
+		{map[string]int{"structure": 0}, 0},
+	}
+	for _, tt := range tests {
+		if got := rubricScore(tt.scores); got != tt.want {
+			t.Errorf("rubricScore(%v) = %v, want %v", tt.scores, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTranscript(t *testing.T) {
+	tests := []struct {
+		turns []MockInterviewTurn
+		want  string
+	}{
+		{nil, "No questions yet."},
+		{
+			[]MockInterviewTurn{
+				{Question: "Tell me about yourself", Answer: "I build payment systems"},
+				{Question: "Why Acme?"},
+			},
+			"0. Interviewer: Tell me about yourself\n   Candidate: I build payment systems\n" +
+				"1. Interviewer: Why Acme?\n   Candidate: (no answer)\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := formatTranscript(tt.turns, "No questions yet."); got != tt.want {
+			t.Errorf("formatTranscript(%v) = %q, want %q", tt.turns, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package workflows
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+const PREPARE_INTERVIEW_PROMPT = `
+	You are an expert interview coach preparing a Software Engineer for an interview.
+	You are given the job, the interview stage, the requirements of the job, research about the company
+	and the achievements of the candidate, each achievement with its ID.
+	Predict the questions the candidate is most likely to be asked at this stage:
+	- "technical_questions": questions about the technologies, system design and engineering practices in the requirements
+	- "behavioral_questions": questions about teamwork, ownership, conflicts, failures and the company's values from the research
+	Ask more technical questions for a technical interview and more behavioral questions for an HR interview.
+	For every question explain why it is likely to be asked and suggest an answer in the STAR format
+	(situation, task, action, result) built from the candidate's achievements.
+	Only use the achievements listed below, do not invent experience, numbers or results the achievements do not state.
+	List the IDs of the achievements every answer is drawn from. When no achievement fits, leave the IDs empty
+	and describe how the candidate should approach the question instead.
+
+	Return the result as a JSON object with the following structure:
+	{
+		"technical_questions": [
+			{
+				"question": "How would you design a rate limiter for a public API?",
+				"reason": "The role requires experience with high traffic APIs",
+				"answer": {
+					"situation": "The payments API was overloaded by a single client",
+					"task": "Protect the API without blocking legitimate traffic",
+					"action": "Designed a token bucket limiter backed by Redis",
+					"result": "Cut error rates during peaks to zero",
+					"achievement_ids": [3]
+				}
+			}
+		],
+		"behavioral_questions": []
+	}
+
+	Job Title:
+	%s
+
+	Company:
+	%s
+
+	Interview Stage:
+	%s
+
+	Job Requirements:
+	%s
+
+	Company Research:
+	%s
+
+	Candidate Achievements:
+	%s
+`
+
+// StarAnswer is a suggested answer in the situation, task, action, result format
+type StarAnswer struct {
+	Situation string `json:"situation"`
+	Task      string `json:"task"`
+	Action    string `json:"action"`
+	Result    string `json:"result"`
+	// AchievementIDs are the work achievements the answer is drawn from
+	AchievementIDs []int `json:"achievement_ids"`
+}
+
+// InterviewQuestion is a likely interview question with a suggested answer
+type InterviewQuestion struct {
+	Question string     `json:"question"`
+	Reason   string     `json:"reason"`
+	Answer   StarAnswer `json:"answer"`
+}
+
+// InterviewPreparation is the interview preparation of a single job
+type InterviewPreparation struct {
+	JobId int `json:"job_id"`
+	// Status is the status of the job application the preparation was made for
+	Status              string              `json:"status"`
+	PreparedAt          time.Time           `json:"prepared_at"`
+	TechnicalQuestions  []InterviewQuestion `json:"technical_questions"`
+	BehavioralQuestions []InterviewQuestion `json:"behavioral_questions"`
+}
+
+// InterviewPreparationInput is what is known about the job and the candidate
+type InterviewPreparationInput struct {
+	Requirements    []string
+	CompanyResearch []string
+	Achievements    []models.WorkAchievement
+}
+
+type PrepareInterviewWorkflow struct {
+	client *agent.Client
+	db     *db.DB
+}
+
+func NewPrepareInterviewWorkflow(client *agent.Client, db *db.DB) *PrepareInterviewWorkflow {
+	return &PrepareInterviewWorkflow{
+		client: client,
+		db:     db,
+	}
+}
+
+func (w *PrepareInterviewWorkflow) Execute(ctx context.Context, jobApplication models.JobApplication, input InterviewPreparationInput) (InterviewPreparation, error) {
+	requirementsString := ""
+	for _, requirement := range input.Requirements {
+		requirementsString += fmt.Sprintf("- %s\n", requirement)
+	}
+	if requirementsString == "" {
+		requirementsString = "No extracted requirements, use the job description:\n" + jobApplication.JobDescription
+	}
+
+	researchString := ""
+	for _, research := range input.CompanyResearch {
+		researchString += fmt.Sprintf("- %s\n", research)
+	}
+	if researchString == "" {
+		researchString = "No company research available"
+	}
+
+	achievementsString := ""
+	achievementIDs := make([]int, len(input.Achievements))
+	for i, achievement := range input.Achievements {
+		achievementIDs[i] = achievement.ID
+		achievementsString += fmt.Sprintf("- ID %d (%s at %s): %s\n", achievement.ID, achievement.JobTitle, achievement.CompanyName, achievement.Description)
+	}
+
+	prompt := fmt.Sprintf(PREPARE_INTERVIEW_PROMPT, jobApplication.JobTitle, jobApplication.CompanyName, jobApplication.Status,
+		requirementsString, researchString, achievementsString)
+
+	resp, err := w.client.GenerateContent(ctx, prompt, 0.1, false)
+	if err != nil {
+		return InterviewPreparation{}, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return InterviewPreparation{}, fmt.Errorf("no response from Gemini")
+	}
+
+	resultText := agent.SanitizeAgentJSONResponse(resp.Text())
+
+	result := InterviewPreparation{
+		JobId:      jobApplication.ID,
+		Status:     jobApplication.Status,
+		PreparedAt: time.Now(),
+	}
+	if err := json.Unmarshal([]byte(resultText), &result); err != nil {
+		return InterviewPreparation{}, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+	// the model does not get to change which job and status the preparation is for
+	result.JobId = jobApplication.ID
+	result.Status = jobApplication.Status
+	result.TechnicalQuestions = cleanInterviewQuestions(result.TechnicalQuestions, achievementIDs)
+	result.BehavioralQuestions = cleanInterviewQuestions(result.BehavioralQuestions, achievementIDs)
+	if len(result.TechnicalQuestions)+len(result.BehavioralQuestions) == 0 {
+		return InterviewPreparation{}, fmt.Errorf("no interview questions were generated")
+	}
+
+	outputJSON, err := json.Marshal(result)
+	if err != nil {
+		return InterviewPreparation{}, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	parametersJSON, err := json.Marshal(map[string]interface{}{
+		"job_ids": []int{jobApplication.ID},
+		"fields":  []string{"status", "requirements", "company_research", "work_achievements"},
+	})
+	if err != nil {
+		log.Printf("Failed to marshal parameters: %v", err)
+	}
+
+	// store the result in database
+	workflowRecord := models.Workflow{
+		WorkflowName: "prepare_interview",
+		Prompt:       prompt,
+		AgentModel:   w.client.ModelName,
+		Output:       string(outputJSON),
+		Parameters:   string(parametersJSON),
+	}
+
+	workflowID, err := w.db.InsertWorkflow(workflowRecord)
+	if err != nil {
+		log.Printf("Failed to store workflow: %v", err)
+	} else {
+		fmt.Printf("📝 Workflow stored with ID: %d\n", workflowID)
+	}
+	err = w.db.InsertJobApplicationsWorkflow([]int{jobApplication.ID}, workflowID)
+	if err != nil {
+		log.Printf("Failed to store job application workflow: %v", err)
+	}
+
+	err = w.db.AddStepToJobApplication(jobApplication.ID, models.StepInput{
+		Title: "Prepare Interview",
+		Description: fmt.Sprintf("Interview preparation for %s with %d technical and %d behavioral questions via workflow %d",
+			jobApplication.Status, len(result.TechnicalQuestions), len(result.BehavioralQuestions), workflowID),
+	})
+	if err != nil {
+		log.Printf("Failed to store job application step: %v", err)
+	}
+
+	return result, nil
+}
+
+// cleanInterviewQuestions drops empty questions and the achievement IDs the model made up
+func cleanInterviewQuestions(questions []InterviewQuestion, achievementIDs []int) []InterviewQuestion {
+	cleaned := []InterviewQuestion{}
+	for _, question := range questions {
+		question.Question = strings.TrimSpace(question.Question)
+		if question.Question == "" {
+			continue
+		}
+		supporting := []int{}
+		for _, id := range question.Answer.AchievementIDs {
+			if slices.Contains(achievementIDs, id) && !slices.Contains(supporting, id) {
+				supporting = append(supporting, id)
+			}
+		}
+		question.Answer.AchievementIDs = supporting
+		cleaned = append(cleaned, question)
+	}
+	return cleaned
+}
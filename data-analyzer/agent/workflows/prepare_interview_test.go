@@ -0,0 +1,26 @@
+package workflows
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCleanInterviewQuestions(t *testing.T) {
+	questions := []InterviewQuestion{
+		{Question: "  How would you design a rate limiter?  ", Answer: StarAnswer{AchievementIDs: []int{3, 99, 3, 5}}},
+		{Question: "   ", Answer: StarAnswer{AchievementIDs: []int{3}}},
+		{Question: "Tell me about a conflict", Answer: StarAnswer{Action: "Talked it through"}},
+	}
+
+	want := []InterviewQuestion{
+		// made up and repeated achievement IDs are dropped
+		{Question: "How would you design a rate limiter?", Answer: StarAnswer{AchievementIDs: []int{3, 5}}},
+		{Question: "Tell me about a conflict", Answer: StarAnswer{Action: "Talked it through", AchievementIDs: []int{}}},
+	}
+	if got := cleanInterviewQuestions(questions, []int{3, 5, 8}); !reflect.DeepEqual(got, want) {
+		t.Errorf("cleanInterviewQuestions() = %+v, want %+v", got, want)
+	}
+	if got := cleanInterviewQuestions(nil, nil); got == nil || len(got) != 0 {
+		t.Errorf("cleanInterviewQuestions(nil) = %#v, want an empty list", got)
+	}
+}
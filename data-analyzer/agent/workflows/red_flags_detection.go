@@ -4,16 +4,40 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"data-analyzer/agent"
+	"data-analyzer/config"
 	"data-analyzer/models"
 )
 
-// RedFlag represents a single red flag identified in a job description
+// Severity levels a red flag can have
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// severityWeights is the probability-like weight each severity adds to the job risk score
+var severityWeights = map[string]float64{
+	SeverityLow:    0.15,
+	SeverityMedium: 0.35,
+	SeverityHigh:   0.6,
+}
+
+// RedFlag represents a single red flag identified in a job description.
+// EvidenceStart and EvidenceEnd are character offsets of the quoted evidence in the original
+// job description, or -1 when the quote could not be located.
 type RedFlag struct {
-	Category    string `json:"category"`
-	Description string `json:"description"`
+	Category      string `json:"category"`
+	Severity      string `json:"severity"`
+	Description   string `json:"description"`
+	Evidence      string `json:"evidence"`
+	EvidenceStart int    `json:"evidence_start"`
+	EvidenceEnd   int    `json:"evidence_end"`
 }
 
 // JobRedFlags holds the red flags for a single job in the batch response
@@ -24,70 +48,124 @@ type JobRedFlags struct {
 
 // RedFlagsResult holds the result of red flags detection for a single job
 type RedFlagsResult struct {
-	JobID    int
-	JobTitle string
-	RedFlags []RedFlag
-	Error    error
+	JobID        int       `json:"job_id"`
+	JobTitle     string    `json:"job_title"`
+	RedFlags     []RedFlag `json:"red_flags"`
+	RiskScore    float64   `json:"risk_score"`
+	Error        error     `json:"-"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+}
+
+// UnmarshalJSON also reads the results stored before the fields had snake_case names
+func (r *RedFlagsResult) UnmarshalJSON(data []byte) error {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	type result RedFlagsResult
+	if _, legacy := keys["JobID"]; !legacy {
+		return json.Unmarshal(data, (*result)(r))
+	}
+
+	var legacy struct {
+		JobID        int
+		JobTitle     string
+		RedFlags     []RedFlag
+		RiskScore    float64
+		ErrorMessage string
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	*r = RedFlagsResult{
+		JobID:        legacy.JobID,
+		JobTitle:     legacy.JobTitle,
+		RedFlags:     legacy.RedFlags,
+		RiskScore:    legacy.RiskScore,
+		ErrorMessage: legacy.ErrorMessage,
+	}
+	return nil
 }
 
 // RedFlagsDetectionResult is the result of the red flags detection workflow
 type RedFlagsDetectionResult struct {
-	Results []RedFlagsResult
+	Results []RedFlagsResult `json:"results"`
 }
 
 // RedFlagsDetectionWorkflow detects red flags in job descriptions
 type RedFlagsDetectionWorkflow struct {
-	client *agent.Client
-	jobs   []models.JobApplication
+	client     *agent.Client
+	jobs       []models.JobApplication
+	categories []config.RedFlagCategory
 }
 
-// NewRedFlagsDetectionWorkflow creates a new red flags detection workflow
-func NewRedFlagsDetectionWorkflow(client *agent.Client, jobs []models.JobApplication) *RedFlagsDetectionWorkflow {
+// NewRedFlagsDetectionWorkflow creates a new red flags detection workflow looking for the given categories
+func NewRedFlagsDetectionWorkflow(client *agent.Client, jobs []models.JobApplication, categories []config.RedFlagCategory) *RedFlagsDetectionWorkflow {
 	return &RedFlagsDetectionWorkflow{
-		client: client,
-		jobs:   jobs,
+		client:     client,
+		jobs:       jobs,
+		categories: categories,
 	}
 }
 
-// Execute runs the red flags detection workflow - sends all jobs in a single batch request
+// Execute runs the red flags detection workflow - packs the jobs into token-bounded batches that run concurrently
 func (w *RedFlagsDetectionWorkflow) Execute(ctx context.Context) (RedFlagsDetectionResult, error) {
 	if len(w.jobs) == 0 {
 		return RedFlagsDetectionResult{Results: []RedFlagsResult{}}, nil
 	}
 
-	// Build batch prompt with all job descriptions
-	var jobsBuilder strings.Builder
-	for _, job := range w.jobs {
+	// Render every job description as a batch item
+	items := make([]BatchItem, len(w.jobs))
+	for i, job := range w.jobs {
 		sanitized := agent.SanitizeText(job.JobDescription)
-		// Truncate very long descriptions to avoid token limits
-		if len(sanitized) > 3000 {
-			sanitized = sanitized[:3000] + "..."
+		items[i] = BatchItem{JobID: job.ID, Text: fmt.Sprintf("\n--- JOB ID: %d ---\nTitle: %s\n\n%s\n", job.ID, job.JobTitle, sanitized)}
+	}
+
+	chunks, skipped, err := NewBatchPlanner(w.client).Plan(ctx, w.PROMT(), items)
+	if err != nil {
+		return w.errorResult(fmt.Errorf("failed to plan batches: %w", err)), nil
+	}
+	outcomes := RunBatches(ctx, w.client, chunks, w.executeChunk)
+
+	// Merge the per-job results, keeping the error of a failed chunk or of a skipped job on each job
+	flagsMap := make(map[int][]RedFlag)
+	errorsMap := make(map[int]error)
+	for jobID, err := range skipped {
+		errorsMap[jobID] = err
+	}
+	for _, outcome := range outcomes {
+		for _, item := range outcome.Items {
+			errorsMap[item.JobID] = outcome.Err
+		}
+		for _, jrf := range outcome.Results {
+			flagsMap[jrf.JobID] = jrf.RedFlags
 		}
-		jobsBuilder.WriteString(fmt.Sprintf("\n--- JOB ID: %d ---\nTitle: %s\n\n%s\n", job.ID, job.JobTitle, sanitized))
 	}
 
-	prompt := fmt.Sprintf(`%s %s`, w.PROMT(), jobsBuilder.String())
+	// Map results back to jobs
+	return w.mapResults(flagsMap, errorsMap), nil
+}
+
+// executeChunk detects the red flags for a single chunk of jobs
+func (w *RedFlagsDetectionWorkflow) executeChunk(ctx context.Context, chunk []BatchItem) ([]JobRedFlags, error) {
+	prompt := fmt.Sprintf(`%s %s`, w.PROMT(), joinBatchItems(chunk))
 
 	resp, err := w.client.GenerateContent(ctx, prompt, 0.1, false)
 	if err != nil {
-		return w.errorResult(fmt.Errorf("failed to generate content: %w", err)), nil
+		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
 
 	if len(resp.Candidates) == 0 {
-		return w.errorResult(fmt.Errorf("no response from Gemini")), nil
+		return nil, fmt.Errorf("no response from Gemini")
 	}
 
-	// Extract text from the response
-	resultText := resp.Text()
-
 	// Parse the JSON response
-	jobRedFlags, err := parseBatchRedFlags(resultText)
+	jobRedFlags, err := parseBatchRedFlags(resp.Text())
 	if err != nil {
-		return w.errorResult(fmt.Errorf("failed to parse red flags: %w", err)), nil
+		return nil, fmt.Errorf("failed to parse red flags: %w", err)
 	}
 
-	// Map results back to jobs
-	return w.mapResults(jobRedFlags), nil
+	return jobRedFlags, nil
 }
 
 // errorResult creates a result with the same error for all jobs
@@ -95,56 +173,64 @@ func (w *RedFlagsDetectionWorkflow) errorResult(err error) RedFlagsDetectionResu
 	results := make([]RedFlagsResult, len(w.jobs))
 	for i, job := range w.jobs {
 		results[i] = RedFlagsResult{
-			JobID:    job.ID,
-			JobTitle: job.JobTitle,
-			RedFlags: nil,
-			Error:    err,
+			JobID:        job.ID,
+			JobTitle:     job.JobTitle,
+			RedFlags:     nil,
+			Error:        err,
+			ErrorMessage: err.Error(),
 		}
 	}
 	return RedFlagsDetectionResult{Results: results}
 }
 
-// mapResults maps the parsed response back to job results
-func (w *RedFlagsDetectionWorkflow) mapResults(jobRedFlags []JobRedFlags) RedFlagsDetectionResult {
-	// Create a map for quick lookup
-	flagsMap := make(map[int][]RedFlag)
-	for _, jrf := range jobRedFlags {
-		flagsMap[jrf.JobID] = jrf.RedFlags
-	}
-
+// mapResults maps the merged batch responses back to job results
+func (w *RedFlagsDetectionWorkflow) mapResults(flagsMap map[int][]RedFlag, errorsMap map[int]error) RedFlagsDetectionResult {
 	results := make([]RedFlagsResult, len(w.jobs))
 	for i, job := range w.jobs {
+		redFlags := flagsMap[job.ID]
+		for j := range redFlags {
+			redFlags[j].Category = strings.ToUpper(strings.TrimSpace(redFlags[j].Category))
+			redFlags[j].Severity = normalizeSeverity(redFlags[j].Severity)
+			redFlags[j].EvidenceStart, redFlags[j].EvidenceEnd = locateEvidence(job.JobDescription, redFlags[j].Evidence)
+		}
 		results[i] = RedFlagsResult{
-			JobID:    job.ID,
-			JobTitle: job.JobTitle,
-			RedFlags: flagsMap[job.ID],
-			Error:    nil,
+			JobID:     job.ID,
+			JobTitle:  job.JobTitle,
+			RedFlags:  redFlags,
+			RiskScore: RiskScore(redFlags),
+			Error:     errorsMap[job.ID],
+		}
+		if results[i].Error != nil {
+			results[i].ErrorMessage = results[i].Error.Error()
 		}
 	}
 	return RedFlagsDetectionResult{Results: results}
 }
 
 func (w *RedFlagsDetectionWorkflow) PROMT() string {
-	return `Analyze the following job descriptions and identify any red flags that might indicate potential issues with each position or company.
+	var categoriesBuilder strings.Builder
+	for _, category := range w.categories {
+		categoriesBuilder.WriteString(fmt.Sprintf("\t- %s: %s\n", category.Name, category.Description))
+	}
+
+	return fmt.Sprintf(`Analyze the following job descriptions and identify any red flags that might indicate potential issues with each position or company.
 
 	Look for red flags in these categories:
-	- UNREALISTIC_EXPECTATIONS: Requiring excessive years of experience for entry/mid-level roles, expecting expertise in too many technologies
-	- POOR_WORK_LIFE_BALANCE: Phrases like "fast-paced environment", "wear many hats", "startup mentality", "flexible hours" (often meaning long hours)
-	- COMPENSATION_ISSUES: Vague or missing salary information, "competitive salary" without details, unpaid overtime expectations
-	- HIGH_TURNOVER: Frequently hiring for same role, "immediate start" urgency
-	- TOXIC_CULTURE: Emphasis on "family" culture, "drama-free", "thick skin required"
-	- UNREASONABLE_REQUIREMENTS: Expecting senior skills at junior pay, requiring unpaid trial work
+%s
+	For every red flag also provide:
+	- severity: one of "low", "medium" or "high", based on how much the issue would affect a candidate
+	- evidence: the exact sentence or phrase from the job description that shows the red flag, copied verbatim without any changes
 
 	Return your response as a JSON array where each element contains the job_id and its red_flags.
 	If a job has no red flags, include an empty red_flags array for that job.
 
 	Example response format:
 	[
-		{"job_id": 1, "red_flags": [{"category": "UNREALISTIC_EXPECTATIONS", "description": "Requires 10+ years experience"}]},
+		{"job_id": 1, "red_flags": [{"category": "UNREALISTIC_EXPECTATIONS", "severity": "high", "description": "Requires 10+ years experience", "evidence": "10+ years of experience with Kubernetes"}]},
 		{"job_id": 2, "red_flags": []}
 	]
 
-	Job Descriptions:`
+	Job Descriptions:`, categoriesBuilder.String())
 }
 
 // parseBatchRedFlags parses the batch JSON response
@@ -164,3 +250,72 @@ func parseBatchRedFlags(text string) ([]JobRedFlags, error) {
 
 	return jobRedFlags, nil
 }
+
+// RiskScore combines the severities of the red flags into a 0-100 score.
+// Each flag adds its severity weight to the remaining headroom, so the score never exceeds 100.
+func RiskScore(redFlags []RedFlag) float64 {
+	safe := 1.0
+	for _, redFlag := range redFlags {
+		safe *= 1 - severityWeights[normalizeSeverity(redFlag.Severity)]
+	}
+	return math.Round((1-safe)*1000) / 10
+}
+
+// normalizeSeverity maps the severity returned by the model onto a known level, defaulting to medium
+func normalizeSeverity(severity string) string {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	if _, ok := severityWeights[severity]; ok {
+		return severity
+	}
+	return SeverityMedium
+}
+
+// locateEvidence returns the character offsets of the quote in the text, or -1, -1 if it is not found.
+// An exact match is tried first, then a case insensitive match that ignores differences in whitespace.
+func locateEvidence(text string, quote string) (int, int) {
+	quote = strings.Trim(strings.TrimSpace(quote), `"'“”`)
+	if quote == "" {
+		return -1, -1
+	}
+
+	if i := strings.Index(text, quote); i >= 0 {
+		start := utf8.RuneCountInString(text[:i])
+		return start, start + utf8.RuneCountInString(quote)
+	}
+
+	normalizedText, positions := normalizeForMatch(text)
+	normalizedQuote, _ := normalizeForMatch(quote)
+	if len(normalizedQuote) == 0 {
+		return -1, -1
+	}
+	i := strings.Index(string(normalizedText), string(normalizedQuote))
+	if i < 0 {
+		return -1, -1
+	}
+	first := utf8.RuneCountInString(string(normalizedText)[:i])
+	last := first + len(normalizedQuote) - 1
+	return positions[first], positions[last] + 1
+}
+
+// normalizeForMatch lowercases the text and collapses whitespace runs into a single space.
+// It also returns, for every normalized rune, its position in the original text.
+func normalizeForMatch(text string) ([]rune, []int) {
+	var normalized []rune
+	var positions []int
+	inSpace := false
+	for position, r := range []rune(text) {
+		if unicode.IsSpace(r) {
+			if inSpace || len(normalized) == 0 {
+				continue
+			}
+			inSpace = true
+			r = ' '
+		} else {
+			inSpace = false
+			r = unicode.ToLower(r)
+		}
+		normalized = append(normalized, r)
+		positions = append(positions, position)
+	}
+	return normalized, positions
+}
@@ -0,0 +1,137 @@
+package workflows
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRedFlagsResultJSON(t *testing.T) {
+	result := RedFlagsDetectionResult{Results: []RedFlagsResult{
+		{JobID: 4, JobTitle: "SRE", RedFlags: []RedFlag{{Category: "ON_CALL", Severity: SeverityHigh}}, RiskScore: 60},
+		{JobID: 5, JobTitle: "QA", Error: errors.New("chunk failed"), ErrorMessage: "chunk failed"},
+	}}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"results"`, `"job_id":4`, `"job_title"`, `"red_flags"`, `"risk_score":60`, `"error_message":"chunk failed"`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("json.Marshal() = %s, missing %s", data, key)
+		}
+	}
+	if strings.Count(string(data), "error_message") != 1 {
+		t.Errorf("json.Marshal() = %s, want error_message only when set", data)
+	}
+
+	var decoded RedFlagsDetectionResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.Results[0]; got.JobID != 4 || got.RiskScore != 60 || len(got.RedFlags) != 1 {
+		t.Errorf("round trip = %+v, want job 4 with one flag", got)
+	}
+}
+
+func TestRedFlagsResultLegacyJSON(t *testing.T) {
+	stored := `{"Results":[{"JobID":7,"JobTitle":"Backend","RedFlags":[{"category":"TOXIC_CULTURE","severity":"medium"}],"RiskScore":35,"ErrorMessage":""},
+		{"JobID":8,"JobTitle":"Frontend","RedFlags":null,"RiskScore":0,"ErrorMessage":"no response"}]}`
+
+	var decoded RedFlagsDetectionResult
+	if err := json.Unmarshal([]byte(stored), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Results) != 2 {
+		t.Fatalf("decoded %d results, want 2", len(decoded.Results))
+	}
+	if got := decoded.Results[0]; got.JobID != 7 || got.JobTitle != "Backend" || got.RiskScore != 35 || len(got.RedFlags) != 1 || got.RedFlags[0].Category != "TOXIC_CULTURE" {
+		t.Errorf("legacy result = %+v", got)
+	}
+	if got := decoded.Results[1]; got.JobID != 8 || got.ErrorMessage != "no response" {
+		t.Errorf("legacy failed result = %+v", got)
+	}
+}
+
+func TestRedFlagsResultJSONMentioningLegacyKey(t *testing.T) {
+	stored := `{"job_id":9,"job_title":"Data","red_flags":[{"category":"VAGUE_ROLE","severity":"low","evidence":"send your \"JobID\" with the form"}],"risk_score":15}`
+
+	var decoded RedFlagsResult
+	if err := json.Unmarshal([]byte(stored), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.JobID != 9 || decoded.RiskScore != 15 || len(decoded.RedFlags) != 1 {
+		t.Errorf("result = %+v, want job 9 with one flag", decoded)
+	}
+}
+
+func TestRiskScore(t *testing.T) {
+	tests := []struct {
+		severities []string
+		want       float64
+	}{
+		{nil, 0},
+		{[]string{SeverityLow}, 15},
+		{[]string{SeverityHigh}, 60},
+		{[]string{SeverityHigh, SeverityHigh}, 84},
+		{[]string{SeverityHigh, SeverityMedium}, 74},
+		{[]string{" HIGH "}, 60},
+		// unknown severities count as medium
+		{[]string{"critical"}, 35},
+		{[]string{SeverityHigh, SeverityHigh, SeverityHigh, SeverityHigh, SeverityHigh, SeverityHigh, SeverityHigh, SeverityHigh, SeverityHigh, SeverityHigh}, 100},
+	}
+	for _, tt := range tests {
+		redFlags := []RedFlag{}
+		for _, severity := range tt.severities {
+			redFlags = append(redFlags, RedFlag{Severity: severity})
+		}
+		if got := RiskScore(redFlags); got != tt.want {
+			t.Errorf("RiskScore(%v) = %v, want %v", tt.severities, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeSeverity(t *testing.T) {
+	tests := []struct {
+		severity string
+		want     string
+	}{
+		{"low", SeverityLow},
+		{"Medium", SeverityMedium},
+		{"  HIGH\n", SeverityHigh},
+		{"severe", SeverityMedium},
+		{"", SeverityMedium},
+	}
+	for _, tt := range tests {
+		if got := normalizeSeverity(tt.severity); got != tt.want {
+			t.Errorf("normalizeSeverity(%q) = %q, want %q", tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestLocateEvidence(t *testing.T) {
+	text := "Café startup.\nYou must be on call 24/7,\n  including   Weekends."
+
+	tests := []struct {
+		quote     string
+		wantStart int
+		wantEnd   int
+	}{
+		{"on call 24/7", 26, 38},
+		{`"on call 24/7"`, 26, 38},
+		{"“must be on call”", 18, 33},
+		// offsets count characters, not bytes
+		{"Café", 0, 4},
+		{"24/7, including weekends", 34, 62},
+		{"ON CALL", 26, 33},
+		{"unlimited PTO", -1, -1},
+		{"  ", -1, -1},
+		{"", -1, -1},
+	}
+	for _, tt := range tests {
+		start, end := locateEvidence(text, tt.quote)
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("locateEvidence(%q) = %d, %d, want %d, %d", tt.quote, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
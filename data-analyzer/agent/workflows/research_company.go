@@ -56,22 +56,35 @@ const RESEACH_COMPANY_PROMPT = `
 	Company Website: %s
 `
 
+// ResearchItem is a single finding of the company research
+type ResearchItem struct {
+	Value   string `json:"value"`
+	Example string `json:"example"`
+	// Source is the source the model claims, which should be the title of a grounding chunk
+	Source string `json:"source"`
+	// ChunkIndices are the grounding chunks whose supports cover the item in the response
+	ChunkIndices []int `json:"chunk_indices"`
+	// Verified is true when the claimed source matches one of the grounding chunks supporting the item,
+	// or any grounding chunk when no support covers it, or for research from documents when the item
+	// cites at least one excerpt
+	Verified bool `json:"verified"`
+	// Citations locate the item in the documents it was researched from, only set for research from documents
+	Citations []DocumentCitation `json:"citations,omitempty"`
+}
+
 type ResearchCompany struct {
-	SoftwareEngineering []struct {
-		Value   string `json:"value"`
-		Example string `json:"example"`
-		Source  string `json:"source"`
-	} `json:"software_engineering"`
-	Business []struct {
-		Value   string `json:"value"`
-		Example string `json:"example"`
-		Source  string `json:"source"`
-	} `json:"business"`
-	CompanyOverview []struct {
-		Value   string `json:"value"`
-		Example string `json:"example"`
-		Source  string `json:"source"`
-	} `json:"company_overview"`
+	// Mode is how the research was done, ResearchModeWeb or ResearchModeDocuments. Research stored
+	// before documents were supported has no mode and was done on the web.
+	Mode                string         `json:"mode,omitempty"`
+	SoftwareEngineering []ResearchItem `json:"software_engineering"`
+	Business            []ResearchItem `json:"business"`
+	CompanyOverview     []ResearchItem `json:"company_overview"`
+	// Grounding is the grounding metadata of the search behind the research, missing for research
+	// stored before it was captured
+	Grounding *agent.Grounding `json:"grounding,omitempty"`
+	// UnverifiedItems is the number of items whose claimed source matches no grounding chunk, or for
+	// research from documents that cite no excerpt
+	UnverifiedItems int `json:"unverified_items"`
 }
 
 type ResearchCompanyWorkflow struct {
@@ -86,26 +99,38 @@ func NewResearchCompanyWorkflow(client *agent.Client, db *db.DB) *ResearchCompan
 	}
 }
 
-func (w *ResearchCompanyWorkflow) Execute(ctx context.Context, jobApplication models.JobApplication) (ResearchCompany, error) {
+// Execute researches the company of the job applications, which all belong to the same company, and
+// links the research to each of them. It returns the ID of the stored workflow.
+func (w *ResearchCompanyWorkflow) Execute(ctx context.Context, jobApplications []models.JobApplication) (ResearchCompany, int64, error) {
 	var result ResearchCompany
-	companyName := jobApplication.CompanyName
-	companyWebsite := jobApplication.CompanyURL
+	if len(jobApplications) == 0 {
+		return result, 0, fmt.Errorf("no job applications to research the company of")
+	}
+	companyName := jobApplications[0].CompanyName
+	companyWebsite := jobApplications[0].CompanyURL
+	jobIDs := make([]int, len(jobApplications))
+	for i, jobApplication := range jobApplications {
+		jobIDs[i] = jobApplication.ID
+		if companyWebsite == "" {
+			companyWebsite = jobApplication.CompanyURL
+		}
+	}
 
 	prompt := fmt.Sprintf(RESEACH_COMPANY_PROMPT, companyName, companyWebsite)
 
 	resp, err := w.client.GenerateContent(ctx, prompt, 1.5, true)
 	if err != nil {
-		return result, fmt.Errorf("failed to generate content: %w", err)
+		return result, 0, fmt.Errorf("failed to generate content: %w", err)
 	}
 
 	if len(resp.Candidates) == 0 {
-		return result, fmt.Errorf("no response from Gemini")
+		return result, 0, fmt.Errorf("no response from Gemini")
 	}
 
 	resultText := resp.Text()
 
 	parametersJSON, err := json.Marshal(map[string]interface{}{
-		"job_ids": []int{jobApplication.ID},
+		"job_ids": jobIDs,
 		"fields":  []string{"company_name, company_url"},
 	})
 	if err != nil {
@@ -124,7 +149,24 @@ func (w *ResearchCompanyWorkflow) Execute(ctx context.Context, jobApplication mo
 	}
 
 	if err := json.Unmarshal([]byte(resultText), &result); err != nil {
-		return result, fmt.Errorf("failed to unmarshal JSON: %w", err)
+		return result, 0, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+
+	result.Mode = ResearchModeWeb
+	// keep the grounding with the research and check the items against it
+	grounding := agent.GroundingFromResponse(resp)
+	result.Grounding = &grounding
+	partTexts := []string{}
+	if resp.Candidates[0].Content != nil {
+		for _, part := range resp.Candidates[0].Content.Parts {
+			partTexts = append(partTexts, part.Text)
+		}
+	}
+	verifyResearch(&result, partTexts)
+	if outputJSON, err := json.Marshal(result); err != nil {
+		log.Printf("Failed to marshal research with grounding: %v", err)
+	} else {
+		workflowRecord.Output = string(outputJSON)
 	}
 
 	// store the result in database
@@ -134,18 +176,20 @@ func (w *ResearchCompanyWorkflow) Execute(ctx context.Context, jobApplication mo
 	} else {
 		fmt.Printf("📝 Workflow stored with ID: %d\n", workflowID)
 	}
-	err = w.db.InsertJobApplicationsWorkflow([]int{jobApplication.ID}, workflowID)
+	err = w.db.InsertJobApplicationsWorkflow(jobIDs, workflowID)
 	if err != nil {
 		log.Printf("Failed to store job application workflow: %v", err)
 	}
 
-	err = w.db.AddStepToJobApplication(jobApplication.ID, models.StepInput{
-		Title:       "Research Company",
-		Description: fmt.Sprintf("Company research generated successfully via workflow %d", workflowID),
-	})
-	if err != nil {
-		log.Printf("Failed to store job application step: %v", err)
+	for _, jobID := range jobIDs {
+		err = w.db.AddStepToJobApplication(jobID, models.StepInput{
+			Title:       "Research Company",
+			Description: fmt.Sprintf("Company research generated successfully via workflow %d", workflowID),
+		})
+		if err != nil {
+			log.Printf("Failed to store job application step: %v", err)
+		}
 	}
 
-	return result, nil
+	return result, workflowID, nil
 }
@@ -0,0 +1,256 @@
+package workflows
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+const RESEARCH_COMPANY_DOCUMENTS_PROMPT = `
+	You are an expert at researching companies and their values and needs.
+	The first priority of the research is the software engineering aspect.
+	The second priority of the research is the business aspect.
+	The last one is the company overview.
+
+	The research must only use the numbered document excerpts below, which were collected by the user.
+	Do not use anything you know about the company from elsewhere. When the excerpts say nothing about an
+	aspect, leave its array empty.
+	Every finding must cite the numbers of the excerpts it comes from.
+	The facts that appear in more excerpts should be first in the output and the ones that are less frequent should be the last.
+	The results of this research must be summarised in these categories with examples.
+	The ouput should be a JSON object with the following structure:
+	{
+		"software_engineering": [
+			{
+				"value": "Value 1",
+				"example": "Example 1",
+				"excerpts": [1, 3]
+			}
+		],
+		"business": [
+			{
+				"value": "Value 1",
+				"example": "Example 1",
+				"excerpts": [2]
+			}
+		],
+		"company_overview": [
+			{
+				"value": "Value 1",
+				"example": "Example 1",
+				"excerpts": [4]
+			}
+		]
+	}
+
+	Company Name: %s
+	Company Website: %s
+
+	Document excerpts:
+	%s
+`
+
+// Research modes
+const (
+	ResearchModeWeb       = "web"
+	ResearchModeDocuments = "documents"
+)
+
+// DocumentExcerpt is a retrieved chunk of a research document given to the model as a numbered excerpt
+type DocumentExcerpt struct {
+	Number      int
+	DocumentID  int
+	Document    string
+	StartOffset int
+	EndOffset   int
+	Text        string
+}
+
+// DocumentCitation locates the text behind a finding in a research document, with byte offsets into the
+// document's extracted text
+type DocumentCitation struct {
+	DocumentID  int    `json:"document_id"`
+	Document    string `json:"document"`
+	StartOffset int    `json:"start_offset"`
+	EndOffset   int    `json:"end_offset"`
+}
+
+// documentResearchItem is a finding as the model answers it, citing excerpt numbers
+type documentResearchItem struct {
+	Value    string `json:"value"`
+	Example  string `json:"example"`
+	Excerpts []int  `json:"excerpts"`
+}
+
+type documentResearchAnswer struct {
+	SoftwareEngineering []documentResearchItem `json:"software_engineering"`
+	Business            []documentResearchItem `json:"business"`
+	CompanyOverview     []documentResearchItem `json:"company_overview"`
+}
+
+type ResearchDocumentsWorkflow struct {
+	client *agent.Client
+	db     *db.DB
+}
+
+func NewResearchDocumentsWorkflow(client *agent.Client, db *db.DB) *ResearchDocumentsWorkflow {
+	return &ResearchDocumentsWorkflow{
+		client: client,
+		db:     db,
+	}
+}
+
+// Execute researches the company of the job applications, which all belong to the same company, from
+// the excerpts of its documents instead of a web search, and links the research to each of them. It
+// returns the ID of the stored workflow.
+func (w *ResearchDocumentsWorkflow) Execute(ctx context.Context, jobApplications []models.JobApplication, excerpts []DocumentExcerpt) (ResearchCompany, int64, error) {
+	var result ResearchCompany
+	if len(jobApplications) == 0 {
+		return result, 0, fmt.Errorf("no job applications to research the company of")
+	}
+	if len(excerpts) == 0 {
+		return result, 0, fmt.Errorf("no document excerpts to research %s from", jobApplications[0].CompanyName)
+	}
+	companyName := jobApplications[0].CompanyName
+	companyWebsite := jobApplications[0].CompanyURL
+	jobIDs := make([]int, len(jobApplications))
+	for i, jobApplication := range jobApplications {
+		jobIDs[i] = jobApplication.ID
+		if companyWebsite == "" {
+			companyWebsite = jobApplication.CompanyURL
+		}
+	}
+
+	documentIDs := []int{}
+	var excerptsText strings.Builder
+	for _, excerpt := range excerpts {
+		fmt.Fprintf(&excerptsText, "[%d] %s (bytes %d-%d):\n%s\n\n", excerpt.Number, excerpt.Document, excerpt.StartOffset, excerpt.EndOffset, excerpt.Text)
+		if !slices.Contains(documentIDs, excerpt.DocumentID) {
+			documentIDs = append(documentIDs, excerpt.DocumentID)
+		}
+	}
+
+	prompt := fmt.Sprintf(RESEARCH_COMPANY_DOCUMENTS_PROMPT, companyName, companyWebsite, excerptsText.String())
+
+	resp, err := w.client.GenerateContent(ctx, prompt, 0.2, false)
+	if err != nil {
+		return result, 0, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return result, 0, fmt.Errorf("no response from Gemini")
+	}
+
+	resultText := agent.SanitizeAgentJSONResponse(resp.Text())
+
+	var answer documentResearchAnswer
+	if err := json.Unmarshal([]byte(resultText), &answer); err != nil {
+		return result, 0, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+	result = citeDocuments(answer, excerpts)
+
+	parametersJSON, err := json.Marshal(map[string]interface{}{
+		"job_ids":      jobIDs,
+		"fields":       []string{"company_name, company_url"},
+		"document_ids": documentIDs,
+	})
+	if err != nil {
+		log.Printf("Failed to marshal parameters: %v", err)
+	}
+
+	outputJSON, err := json.Marshal(result)
+	if err != nil {
+		return result, 0, fmt.Errorf("failed to marshal research: %w", err)
+	}
+
+	// store the result in database
+	workflowRecord := models.Workflow{
+		WorkflowName: "research_company_documents",
+		Prompt:       prompt,
+		AgentModel:   w.client.ModelName,
+		Output:       string(outputJSON),
+		Parameters:   string(parametersJSON),
+	}
+
+	workflowID, err := w.db.InsertWorkflow(workflowRecord)
+	if err != nil {
+		log.Printf("Failed to store workflow: %v", err)
+	} else {
+		fmt.Printf("📝 Workflow stored with ID: %d\n", workflowID)
+	}
+	err = w.db.InsertJobApplicationsWorkflow(jobIDs, workflowID)
+	if err != nil {
+		log.Printf("Failed to store job application workflow: %v", err)
+	}
+
+	for _, jobID := range jobIDs {
+		err = w.db.AddStepToJobApplication(jobID, models.StepInput{
+			Title:       "Research Company",
+			Description: fmt.Sprintf("Company research generated from %d documents via workflow %d", len(documentIDs), workflowID),
+		})
+		if err != nil {
+			log.Printf("Failed to store job application step: %v", err)
+		}
+	}
+
+	return result, workflowID, nil
+}
+
+// citeDocuments turns the excerpt numbers of every finding into citations of the documents. Findings
+// citing no existing excerpt are unverified.
+func citeDocuments(answer documentResearchAnswer, excerpts []DocumentExcerpt) ResearchCompany {
+	byNumber := make(map[int]DocumentExcerpt, len(excerpts))
+	for _, excerpt := range excerpts {
+		byNumber[excerpt.Number] = excerpt
+	}
+
+	research := ResearchCompany{Mode: ResearchModeDocuments}
+	convert := func(items []documentResearchItem) []ResearchItem {
+		converted := []ResearchItem{}
+		for _, item := range items {
+			researchItem := ResearchItem{
+				Value:        item.Value,
+				Example:      item.Example,
+				ChunkIndices: []int{},
+				Citations:    []DocumentCitation{},
+			}
+			sources := []string{}
+			for _, number := range item.Excerpts {
+				excerpt, ok := byNumber[number]
+				if !ok {
+					continue
+				}
+				citation := DocumentCitation{
+					DocumentID:  excerpt.DocumentID,
+					Document:    excerpt.Document,
+					StartOffset: excerpt.StartOffset,
+					EndOffset:   excerpt.EndOffset,
+				}
+				if slices.Contains(researchItem.Citations, citation) {
+					continue
+				}
+				researchItem.Citations = append(researchItem.Citations, citation)
+				if !slices.Contains(sources, excerpt.Document) {
+					sources = append(sources, excerpt.Document)
+				}
+			}
+			researchItem.Source = strings.Join(sources, ", ")
+			researchItem.Verified = len(researchItem.Citations) > 0
+			if !researchItem.Verified {
+				research.UnverifiedItems++
+			}
+			converted = append(converted, researchItem)
+		}
+		return converted
+	}
+	research.SoftwareEngineering = convert(answer.SoftwareEngineering)
+	research.Business = convert(answer.Business)
+	research.CompanyOverview = convert(answer.CompanyOverview)
+	return research
+}
@@ -0,0 +1,60 @@
+package workflows
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCiteDocuments(t *testing.T) {
+	excerpts := []DocumentExcerpt{
+		{Number: 1, DocumentID: 10, Document: "engineering-blog.html", StartOffset: 0, EndOffset: 120},
+		{Number: 2, DocumentID: 10, Document: "engineering-blog.html", StartOffset: 100, EndOffset: 240},
+		{Number: 3, DocumentID: 11, Document: "annual-report.pdf", StartOffset: 5000, EndOffset: 5300},
+	}
+	answer := documentResearchAnswer{
+		SoftwareEngineering: []documentResearchItem{
+			{Value: "Go microservices", Example: "Payments run on Go", Excerpts: []int{2, 1, 2}},
+		},
+		Business: []documentResearchItem{
+			{Value: "Profitable since 2023", Excerpts: []int{3, 1}},
+			// the model cited an excerpt it was not given
+			{Value: "Raised a Series C", Excerpts: []int{9}},
+		},
+		CompanyOverview: nil,
+	}
+
+	research := citeDocuments(answer, excerpts)
+
+	want := ResearchCompany{
+		Mode: ResearchModeDocuments,
+		SoftwareEngineering: []ResearchItem{{
+			Value:        "Go microservices",
+			Example:      "Payments run on Go",
+			Source:       "engineering-blog.html",
+			Verified:     true,
+			ChunkIndices: []int{},
+			Citations: []DocumentCitation{
+				{DocumentID: 10, Document: "engineering-blog.html", StartOffset: 100, EndOffset: 240},
+				{DocumentID: 10, Document: "engineering-blog.html", StartOffset: 0, EndOffset: 120},
+			},
+		}},
+		Business: []ResearchItem{
+			{
+				Value:        "Profitable since 2023",
+				Source:       "annual-report.pdf, engineering-blog.html",
+				Verified:     true,
+				ChunkIndices: []int{},
+				Citations: []DocumentCitation{
+					{DocumentID: 11, Document: "annual-report.pdf", StartOffset: 5000, EndOffset: 5300},
+					{DocumentID: 10, Document: "engineering-blog.html", StartOffset: 0, EndOffset: 120},
+				},
+			},
+			{Value: "Raised a Series C", ChunkIndices: []int{}, Citations: []DocumentCitation{}},
+		},
+		CompanyOverview: []ResearchItem{},
+		UnverifiedItems: 1,
+	}
+	if !reflect.DeepEqual(research, want) {
+		t.Errorf("citeDocuments() = %+v\nwant %+v", research, want)
+	}
+}
@@ -0,0 +1,164 @@
+package workflows
+
+import (
+	"bytes"
+	"data-analyzer/agent"
+	"data-analyzer/domains"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// minSupportTextLength is the shortest item text matched against support segments by content when
+// the item cannot be found in the response. Shorter texts match too many segments.
+const minSupportTextLength = 12
+
+// verifyResearch links every research item to the grounding chunks supporting it and flags the items
+// whose claimed source matches none of those chunks. Items no support covers are checked against all
+// the chunks of the search. The part texts are the raw texts of the response, which the support
+// segments point into.
+func verifyResearch(research *ResearchCompany, partTexts []string) {
+	grounding := research.Grounding
+	research.UnverifiedItems = 0
+
+	// items are searched for in order, so repeated values are matched to their own occurrence
+	cursors := make([]int, len(partTexts))
+	for _, items := range [][]ResearchItem{research.SoftwareEngineering, research.Business, research.CompanyOverview} {
+		for i := range items {
+			item := &items[i]
+			item.ChunkIndices = supportingChunks(*item, grounding, partTexts, cursors)
+			item.Verified = false
+			candidates := grounding.Chunks
+			if len(item.ChunkIndices) > 0 {
+				candidates = []agent.GroundingChunk{}
+				for _, index := range item.ChunkIndices {
+					if index >= 0 && index < len(grounding.Chunks) {
+						candidates = append(candidates, grounding.Chunks[index])
+					}
+				}
+			}
+			for _, chunk := range candidates {
+				if sourceMatchesChunk(item.Source, chunk) {
+					item.Verified = true
+					break
+				}
+			}
+			if !item.Verified {
+				research.UnverifiedItems++
+			}
+		}
+	}
+}
+
+// supportingChunks returns the chunks of the supports whose segment overlaps the item in the response,
+// or, when the item cannot be located, whose segment text contains the item's value
+func supportingChunks(item ResearchItem, grounding *agent.Grounding, partTexts []string, cursors []int) []int {
+	chunks := []int{}
+	add := func(support agent.GroundingSupport) {
+		for _, index := range support.ChunkIndices {
+			if !slices.Contains(chunks, index) {
+				chunks = append(chunks, index)
+			}
+		}
+	}
+
+	part, start, end, found := locateItem(item, partTexts, cursors)
+	for _, support := range grounding.Supports {
+		switch {
+		case found:
+			if support.PartIndex == part && support.StartIndex < end && support.EndIndex > start {
+				add(support)
+			}
+		case len(item.Value) >= minSupportTextLength && strings.Contains(strings.ToLower(support.Text), strings.ToLower(item.Value)):
+			add(support)
+		}
+	}
+	sort.Ints(chunks)
+	return chunks
+}
+
+// locateItem finds the byte range of the item's value and example in the raw response
+func locateItem(item ResearchItem, partTexts []string, cursors []int) (part int, start int, end int, ok bool) {
+	value := jsonStringContent(item.Value)
+	if value == "" {
+		return 0, 0, 0, false
+	}
+	for part, text := range partTexts {
+		offset := cursors[part]
+		index := strings.Index(text[offset:], value)
+		if index < 0 {
+			offset = 0
+			if index = strings.Index(text, value); index < 0 {
+				continue
+			}
+		}
+		start = offset + index
+		end = start + len(value)
+		if example := jsonStringContent(item.Example); example != "" {
+			// the example follows the value within the same object
+			next := strings.Index(text[end:], example)
+			closing := strings.Index(text[end:], "}")
+			if next >= 0 && (closing < 0 || next < closing) {
+				end += next + len(example)
+			}
+		}
+		cursors[part] = end
+		return part, start, end, true
+	}
+	return 0, 0, 0, false
+}
+
+// jsonStringContent is the text as it appears between the quotes of a JSON string
+func jsonStringContent(text string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(text); err != nil {
+		return ""
+	}
+	encoded := strings.TrimSpace(buffer.String())
+	return strings.TrimSuffix(strings.TrimPrefix(encoded, `"`), `"`)
+}
+
+// sourceMatchesChunk reports whether the claimed source names the chunk: its title, its domain or the
+// host of its URI, compared without case, scheme or "www."
+func sourceMatchesChunk(source string, chunk agent.GroundingChunk) bool {
+	claimed := normalizeSource(source)
+	if claimed == "" {
+		return false
+	}
+	candidates := []string{chunk.Title, chunk.Domain}
+	if parsed, err := url.Parse(chunk.URI); err == nil {
+		candidates = append(candidates, parsed.Hostname())
+	}
+	claimedDomain := domains.Normalize(claimed)
+	for _, candidate := range candidates {
+		candidate = normalizeSource(candidate)
+		if candidate == "" {
+			continue
+		}
+		if candidate == claimed {
+			return true
+		}
+		// "acme.com" claimed for the chunk titled "blog.acme.com"
+		if claimedDomain != "" && strings.Contains(candidate, ".") && domains.Normalize(candidate) == claimedDomain {
+			return true
+		}
+		// titles of web pages may be longer than the claimed source, e.g. "Acme Engineering Blog"
+		if len(claimed) >= 4 && strings.Contains(candidate, claimed) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeSource(source string) string {
+	source = strings.ToLower(strings.TrimSpace(source))
+	if index := strings.Index(source, "://"); index >= 0 {
+		source = source[index+3:]
+	}
+	source = strings.TrimPrefix(source, "www.")
+	return strings.TrimSuffix(source, "/")
+}
@@ -0,0 +1,94 @@
+package workflows
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"data-analyzer/agent"
+)
+
+func TestVerifyResearch(t *testing.T) {
+	research := ResearchCompany{
+		SoftwareEngineering: []ResearchItem{
+			{Value: "Microservices in Kotlin", Example: "Payments run on Kotlin services", Source: "blog.acme.io"},
+			{Value: "Weekly releases", Example: "Ships every Monday", Source: "acme.io"},
+		},
+		Business: []ResearchItem{
+			{Value: "Raised a Series B", Example: "EUR 40M in 2025", Source: "reuters.com"},
+		},
+		CompanyOverview: []ResearchItem{
+			{Value: "Founded in 2020", Example: "Berlin", Source: "acme.io"},
+			{Value: "Offices in Lisbon", Example: "Opened 2024", Source: "wikipedia.org"},
+		},
+	}
+	raw, err := json.Marshal(research)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(raw)
+	segment := func(value string, chunks ...int) agent.GroundingSupport {
+		start := strings.Index(text, value)
+		return agent.GroundingSupport{StartIndex: start, EndIndex: start + len(value), Text: value, ChunkIndices: chunks}
+	}
+
+	research.Grounding = &agent.Grounding{
+		Chunks: []agent.GroundingChunk{
+			{Index: 0, URI: "https://vertexaisearch.cloud.google.com/grounding-api-redirect/a", Title: "acme.io", Domain: "acme.io"},
+			{Index: 1, URI: "https://vertexaisearch.cloud.google.com/grounding-api-redirect/b", Title: "reuters.com", Domain: "reuters.com"},
+		},
+		Supports: []agent.GroundingSupport{
+			segment("Microservices in Kotlin", 0),
+			// the claimed source is in the search, but this segment is backed by another chunk
+			segment("Weekly releases", 1),
+			segment("Raised a Series B", 1, 0),
+		},
+	}
+
+	verifyResearch(&research, []string{text})
+
+	tests := []struct {
+		item         ResearchItem
+		wantChunks   []int
+		wantVerified bool
+	}{
+		{research.SoftwareEngineering[0], []int{0}, true},
+		{research.SoftwareEngineering[1], []int{1}, false},
+		{research.Business[0], []int{0, 1}, true},
+		// no segment covers these, so every chunk of the search is a candidate
+		{research.CompanyOverview[0], []int{}, true},
+		{research.CompanyOverview[1], []int{}, false},
+	}
+	for _, tt := range tests {
+		if !slices.Equal(tt.item.ChunkIndices, tt.wantChunks) || tt.item.Verified != tt.wantVerified {
+			t.Errorf("%q = chunks %v verified %v, want %v %v", tt.item.Value, tt.item.ChunkIndices, tt.item.Verified, tt.wantChunks, tt.wantVerified)
+		}
+	}
+	if research.UnverifiedItems != 2 {
+		t.Errorf("UnverifiedItems = %d, want 2", research.UnverifiedItems)
+	}
+}
+
+func TestSourceMatchesChunk(t *testing.T) {
+	chunk := agent.GroundingChunk{URI: "https://www.acme.io/blog/post", Title: "Acme Engineering Blog", Domain: "acme.io"}
+
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"acme.io", true},
+		{"https://www.acme.io/", true},
+		{"engineering.acme.io", true},
+		{"Engineering Blog", true},
+		{"ACME", true},
+		{"acme.com", false},
+		{"io", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := sourceMatchesChunk(tt.source, chunk); got != tt.want {
+			t.Errorf("sourceMatchesChunk(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"time"
 
 	"data-analyzer/agent"
+	"data-analyzer/config"
 	"data-analyzer/db"
 	"data-analyzer/models"
 )
@@ -14,13 +17,15 @@ import (
 type WorkflowRunner struct {
 	db     *db.DB
 	client *agent.Client
+	cfg    *config.Config
 }
 
 // NewWorkflowRunner creates a new workflow runner with database connection
-func NewWorkflowRunner(database *db.DB, client *agent.Client) *WorkflowRunner {
+func NewWorkflowRunner(database *db.DB, client *agent.Client, cfg *config.Config) *WorkflowRunner {
 	return &WorkflowRunner{
 		db:     database,
 		client: client,
+		cfg:    cfg,
 	}
 }
 
@@ -46,10 +51,11 @@ func (r *WorkflowRunner) RunRedFlagsDetection(ctx context.Context, jobs []models
 	}
 
 	// Execute the workflow
-	workflow := NewRedFlagsDetectionWorkflow(r.client, jobs)
+	workflow := NewRedFlagsDetectionWorkflow(r.client, jobs, r.cfg.RedFlagCategories)
 	prompt := workflow.PROMT()
 	result, err := workflow.Execute(ctx)
 	if err != nil {
+		RecordWorkflowFailure(r.db, "red_flags_detection", jobIDs, err)
 		return RedFlagsRunResult{}, fmt.Errorf("failed to execute workflow: %w", err)
 	}
 
@@ -72,9 +78,44 @@ func (r *WorkflowRunner) RunRedFlagsDetection(ctx context.Context, jobs []models
 	if err != nil {
 		return RedFlagsRunResult{}, fmt.Errorf("failed to store workflow: %w", err)
 	}
+	if err := r.db.InsertJobApplicationsWorkflow(jobIDs, workflowID); err != nil {
+		return RedFlagsRunResult{}, fmt.Errorf("failed to store job application workflow: %w", err)
+	}
 
 	return RedFlagsRunResult{
 		Result:     result,
 		WorkflowID: workflowID,
 	}, nil
 }
+
+// WorkflowFailure is the data of a workflow.failed webhook event
+type WorkflowFailure struct {
+	WorkflowName      string `json:"workflow_name"`
+	JobApplicationIDs []int  `json:"job_application_ids"`
+	Error             string `json:"error"`
+}
+
+// RecordWorkflowFailure records a workflow.failed webhook event for a workflow that failed before its
+// result was stored. Failing to record it does not change the outcome of the workflow, so the error
+// is only logged.
+func RecordWorkflowFailure(database *db.DB, workflowName string, jobApplicationIDs []int, err error) {
+	if jobApplicationIDs == nil {
+		jobApplicationIDs = []int{}
+	}
+	data, marshalErr := json.Marshal(WorkflowFailure{WorkflowName: workflowName, JobApplicationIDs: jobApplicationIDs, Error: err.Error()})
+	if marshalErr != nil {
+		log.Printf("Failed to marshal workflow failure: %v", marshalErr)
+		return
+	}
+	event := models.WebhookEvent{
+		Type: models.WebhookEventWorkflowFailed,
+		Key:  fmt.Sprintf("workflow_failed:%s:%d", workflowName, time.Now().UnixNano()),
+		Data: string(data),
+	}
+	if len(jobApplicationIDs) == 1 {
+		event.JobApplicationID = jobApplicationIDs[0]
+	}
+	if _, err := database.RecordWebhookEvent(event); err != nil {
+		log.Printf("Failed to record workflow failure: %v", err)
+	}
+}
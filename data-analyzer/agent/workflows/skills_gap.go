@@ -0,0 +1,202 @@
+package workflows
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/models"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+const SKILLS_GAP_PROMPT = `
+	You are an expert technical recruiter assessing how well a Software Engineer matches a job.
+	You are given the requirements of a job and the achievements of the candidate, each achievement with its ID.
+	For every requirement decide whether the candidate's achievements support it:
+	- "covered": at least one achievement clearly demonstrates the requirement
+	- "partial": achievements show related or weaker experience, or only part of the requirement
+	- "missing": no achievement supports the requirement
+	Only use the achievements listed below, do not assume any other experience.
+	For every requirement list the IDs of the supporting achievements and give a short justification.
+	Keep the requirements in the same order and use their index.
+
+	Return the result as a JSON array with the following structure:
+	[
+		{
+			"index": 0,
+			"status": "covered",
+			"achievement_ids": [3, 7],
+			"justification": "Led the migration of the payments service to Go (achievement 3)"
+		}
+	]
+
+	Job Title:
+	%s
+
+	Job Requirements:
+	%s
+
+	Candidate Achievements:
+	%s
+`
+
+// Coverage levels of a job requirement
+const (
+	CoverageCovered = "covered"
+	CoveragePartial = "partial"
+	CoverageMissing = "missing"
+)
+
+// coverageWeights is how much each coverage level contributes to the fit percentage
+var coverageWeights = map[string]float64{
+	CoverageCovered: 1,
+	CoveragePartial: 0.5,
+	CoverageMissing: 0,
+}
+
+// RequirementCoverage maps a single job requirement to the achievements supporting it
+type RequirementCoverage struct {
+	Requirement    string `json:"requirement"`
+	Status         string `json:"status"`
+	AchievementIDs []int  `json:"achievement_ids"`
+	Justification  string `json:"justification"`
+}
+
+// SkillsGap is the skills gap analysis of a single job
+type SkillsGap struct {
+	JobId         int                   `json:"job_id"`
+	FitPercentage float64               `json:"fit_percentage"`
+	Requirements  []RequirementCoverage `json:"requirements"`
+}
+
+// rawRequirementCoverage is the shape returned by the model, referencing requirements by index
+type rawRequirementCoverage struct {
+	Index          int    `json:"index"`
+	Status         string `json:"status"`
+	AchievementIDs []int  `json:"achievement_ids"`
+	Justification  string `json:"justification"`
+}
+
+type SkillsGapResult struct {
+	Prompt    string
+	Result    string
+	SkillsGap SkillsGap
+}
+
+type SkillsGapWorkflow struct {
+	client         *agent.Client
+	jobApplication models.JobApplication
+	requirements   []string
+	achievements   []models.WorkAchievement
+}
+
+func NewSkillsGapWorkflow(client *agent.Client, jobApplication models.JobApplication, requirements []string, achievements []models.WorkAchievement) *SkillsGapWorkflow {
+	return &SkillsGapWorkflow{
+		client:         client,
+		jobApplication: jobApplication,
+		requirements:   requirements,
+		achievements:   achievements,
+	}
+}
+
+func (w *SkillsGapWorkflow) Execute(ctx context.Context) (SkillsGapResult, error) {
+	if len(w.requirements) == 0 {
+		return SkillsGapResult{}, fmt.Errorf("job application %d has no extracted requirements", w.jobApplication.ID)
+	}
+
+	requirementsString := ""
+	for i, requirement := range w.requirements {
+		requirementsString += fmt.Sprintf("%d. %s\n", i, requirement)
+	}
+
+	achievementsString := ""
+	achievementIDs := make([]int, len(w.achievements))
+	for i, achievement := range w.achievements {
+		achievementIDs[i] = achievement.ID
+		achievementsString += fmt.Sprintf("- ID %d (%s at %s): %s\n", achievement.ID, achievement.JobTitle, achievement.CompanyName, achievement.Description)
+	}
+
+	prompt := fmt.Sprintf(SKILLS_GAP_PROMPT, w.jobApplication.JobTitle, requirementsString, achievementsString)
+
+	resp, err := w.client.GenerateContent(ctx, prompt, 0.1, false)
+	if err != nil {
+		return SkillsGapResult{}, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 {
+		return SkillsGapResult{}, fmt.Errorf("no response from Gemini")
+	}
+
+	resultText := agent.SanitizeAgentJSONResponse(resp.Text())
+
+	var rawCoverage []rawRequirementCoverage
+	if err := json.Unmarshal([]byte(resultText), &rawCoverage); err != nil {
+		return SkillsGapResult{}, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	coverage := resolveCoverage(w.requirements, achievementIDs, rawCoverage)
+	skillsGap := SkillsGap{
+		JobId:         w.jobApplication.ID,
+		FitPercentage: FitPercentage(coverage),
+		Requirements:  coverage,
+	}
+
+	outputJSON, err := json.Marshal(skillsGap)
+	if err != nil {
+		return SkillsGapResult{}, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	return SkillsGapResult{
+		Prompt:    prompt,
+		Result:    string(outputJSON),
+		SkillsGap: skillsGap,
+	}, nil
+}
+
+// resolveCoverage maps the model's answer onto the requirements. Every requirement starts as missing,
+// so requirements the model skipped are not counted as covered, and achievement IDs the model made
+// up are dropped.
+func resolveCoverage(requirements []string, achievementIDs []int, rawCoverage []rawRequirementCoverage) []RequirementCoverage {
+	coverage := make([]RequirementCoverage, len(requirements))
+	for i, requirement := range requirements {
+		coverage[i] = RequirementCoverage{
+			Requirement:    requirement,
+			Status:         CoverageMissing,
+			AchievementIDs: []int{},
+			Justification:  "Not assessed",
+		}
+	}
+	for _, raw := range rawCoverage {
+		if raw.Index < 0 || raw.Index >= len(requirements) {
+			continue
+		}
+		status := strings.ToLower(strings.TrimSpace(raw.Status))
+		if _, ok := coverageWeights[status]; !ok {
+			status = CoverageMissing
+		}
+		supporting := []int{}
+		for _, id := range raw.AchievementIDs {
+			if slices.Contains(achievementIDs, id) && !slices.Contains(supporting, id) {
+				supporting = append(supporting, id)
+			}
+		}
+		coverage[raw.Index].Status = status
+		coverage[raw.Index].AchievementIDs = supporting
+		coverage[raw.Index].Justification = raw.Justification
+	}
+	return coverage
+}
+
+// FitPercentage is the share of requirements covered by the candidate, counting partial coverage as half
+func FitPercentage(coverage []RequirementCoverage) float64 {
+	if len(coverage) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, requirement := range coverage {
+		total += coverageWeights[requirement.Status]
+	}
+	return math.Round(total/float64(len(coverage))*1000) / 10
+}
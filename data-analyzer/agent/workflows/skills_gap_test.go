@@ -0,0 +1,37 @@
+package workflows
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolveCoverage(t *testing.T) {
+	requirements := []string{"Go", "Kubernetes", "PostgreSQL", "Team lead"}
+	raw := []rawRequirementCoverage{
+		{Index: 0, Status: "Covered", AchievementIDs: []int{3, 3, 99}, Justification: "Payments service in Go"},
+		{Index: 1, Status: "partial", AchievementIDs: []int{7}, Justification: "Ran Docker Compose"},
+		{Index: 2, Status: "probably", AchievementIDs: []int{3}},
+		{Index: 9, Status: "covered"},
+	}
+
+	coverage := resolveCoverage(requirements, []int{3, 7}, raw)
+
+	wantStatuses := []string{CoverageCovered, CoveragePartial, CoverageMissing, CoverageMissing}
+	for i, want := range wantStatuses {
+		if coverage[i].Requirement != requirements[i] || coverage[i].Status != want {
+			t.Errorf("requirement %d = %q %s, want %q %s", i, coverage[i].Requirement, coverage[i].Status, requirements[i], want)
+		}
+	}
+	if !slices.Equal(coverage[0].AchievementIDs, []int{3}) {
+		t.Errorf("achievement IDs = %v, want the known IDs once", coverage[0].AchievementIDs)
+	}
+	if coverage[3].Justification != "Not assessed" || coverage[3].AchievementIDs == nil {
+		t.Errorf("skipped requirement = %+v, want it not assessed with no achievements", coverage[3])
+	}
+	if got := FitPercentage(coverage); got != 37.5 {
+		t.Errorf("FitPercentage() = %v, want 37.5", got)
+	}
+	if got := FitPercentage(nil); got != 0 {
+		t.Errorf("FitPercentage(nil) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,97 @@
+package workflows
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Technology categories used to group a tech stack
+const (
+	TechCategoryLanguage  = "language"
+	TechCategoryFramework = "framework"
+	TechCategoryDatabase  = "database"
+	TechCategoryCloud     = "cloud"
+	TechCategoryTool      = "tool"
+)
+
+//go:embed tech_taxonomy.json
+var techTaxonomyData []byte
+
+// TechTaxonomyEntry describes a canonical technology and the names it is known by
+type TechTaxonomyEntry struct {
+	Category string   `json:"category"`
+	Aliases  []string `json:"aliases"`
+}
+
+// Technology is a normalized technology name with its category
+type Technology struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// TechTaxonomy normalizes technology names through the alias table kept in tech_taxonomy.json
+type TechTaxonomy struct {
+	byAlias map[string]Technology
+}
+
+// LoadTechTaxonomy parses the embedded taxonomy data file
+func LoadTechTaxonomy() (*TechTaxonomy, error) {
+	var entries map[string]TechTaxonomyEntry
+	if err := json.Unmarshal(techTaxonomyData, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse tech taxonomy: %w", err)
+	}
+
+	taxonomy := &TechTaxonomy{byAlias: make(map[string]Technology)}
+	for name, entry := range entries {
+		if !isTechCategory(entry.Category) {
+			return nil, fmt.Errorf("technology %s has unknown category %q", name, entry.Category)
+		}
+		technology := Technology{Name: name, Category: entry.Category}
+		for _, alias := range append([]string{name}, entry.Aliases...) {
+			key := techKey(alias)
+			if existing, ok := taxonomy.byAlias[key]; ok && existing.Name != name {
+				return nil, fmt.Errorf("alias %q is used by both %s and %s", alias, existing.Name, name)
+			}
+			taxonomy.byAlias[key] = technology
+		}
+	}
+
+	return taxonomy, nil
+}
+
+// Normalize maps a technology name onto its canonical form.
+// Unknown technologies keep their name and the fallback category (or "tool" if that is not valid).
+func (t *TechTaxonomy) Normalize(name string, fallbackCategory string) Technology {
+	if technology, ok := t.byAlias[techKey(name)]; ok {
+		return technology
+	}
+
+	category := strings.ToLower(strings.TrimSpace(fallbackCategory))
+	if !isTechCategory(category) {
+		category = TechCategoryTool
+	}
+	return Technology{Name: strings.TrimSpace(name), Category: category}
+}
+
+// Lookup returns the canonical technology for a name, if the taxonomy knows it
+func (t *TechTaxonomy) Lookup(name string) (Technology, bool) {
+	technology, ok := t.byAlias[techKey(name)]
+	return technology, ok
+}
+
+func techKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+func isTechCategory(category string) bool {
+	return slices.Contains([]string{
+		TechCategoryLanguage,
+		TechCategoryFramework,
+		TechCategoryDatabase,
+		TechCategoryCloud,
+		TechCategoryTool,
+	}, category)
+}
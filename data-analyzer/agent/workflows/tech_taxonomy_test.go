@@ -0,0 +1,60 @@
+package workflows
+
+import "testing"
+
+func TestTechTaxonomyNormalize(t *testing.T) {
+	taxonomy, err := LoadTechTaxonomy()
+	if err != nil {
+		t.Fatalf("LoadTechTaxonomy() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		fallback string
+		want     Technology
+	}{
+		{"Golang", "", Technology{"Go", TechCategoryLanguage}},
+		{"  K8S ", "", Technology{"Kubernetes", TechCategoryCloud}},
+		{"postgres", "", Technology{"PostgreSQL", TechCategoryDatabase}},
+		{"GitHub", "", Technology{"GitHub", TechCategoryTool}},
+		{"GitLab", "", Technology{"GitLab", TechCategoryTool}},
+		{"git", "", Technology{"Git", TechCategoryTool}},
+		{"OpenSearch", "", Technology{"OpenSearch", TechCategoryDatabase}},
+		{"Elastic Search", "", Technology{"Elasticsearch", TechCategoryDatabase}},
+		{"lambda", "", Technology{"AWS Lambda", TechCategoryCloud}},
+		{"Serverless", "", Technology{"Serverless", TechCategoryCloud}},
+		{"nextjs", "", Technology{"Next.js", TechCategoryFramework}},
+		{"Spring", "", Technology{"Spring", TechCategoryFramework}},
+		{"springboot", "", Technology{"Spring Boot", TechCategoryFramework}},
+		{"shell scripting", "", Technology{"Shell", TechCategoryLanguage}},
+		{"Bash", "", Technology{"Bash", TechCategoryLanguage}},
+		// managed services and frameworks built on a technology are kept apart from it
+		{"EKS", "", Technology{"Amazon EKS", TechCategoryCloud}},
+		{"gke", "", Technology{"Google Kubernetes Engine", TechCategoryCloud}},
+		{"AKS", "", Technology{"Azure Kubernetes Service", TechCategoryCloud}},
+		{"Kubernetes", "", Technology{"Kubernetes", TechCategoryCloud}},
+		{"DRF", "", Technology{"Django REST Framework", TechCategoryFramework}},
+		{"django rest framework", "", Technology{"Django REST Framework", TechCategoryFramework}},
+		{"Django", "", Technology{"Django", TechCategoryFramework}},
+		{"asp.net core", "", Technology{"ASP.NET", TechCategoryFramework}},
+		{"dotnet", "", Technology{".NET", TechCategoryFramework}},
+		// ordinary words are not technologies
+		{"next", "framework", Technology{"next", TechCategoryFramework}},
+		{"containers", "", Technology{"containers", TechCategoryTool}},
+		{"nest", "", Technology{"nest", TechCategoryTool}},
+		{"rabbit", "", Technology{"rabbit", TechCategoryTool}},
+		{"Oracle", "", Technology{"Oracle", TechCategoryTool}},
+		{"mongo", "", Technology{"mongo", TechCategoryTool}},
+		{"Dynamo", "", Technology{"Dynamo", TechCategoryTool}},
+		{"py", "", Technology{"py", TechCategoryTool}},
+		{"TS", "", Technology{"TS", TechCategoryTool}},
+		{"kube", "", Technology{"kube", TechCategoryTool}},
+		{"Temporal", "Framework", Technology{"Temporal", TechCategoryFramework}},
+		{"Temporal", "platform", Technology{"Temporal", TechCategoryTool}},
+	}
+	for _, tt := range tests {
+		if got := taxonomy.Normalize(tt.name, tt.fallback); got != tt.want {
+			t.Errorf("Normalize(%q, %q) = %+v, want %+v", tt.name, tt.fallback, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvHeader are the columns of the CSV report, matching the JSON field names
+var csvHeader = []string{
+	"dimension", "value", "applications", "applied", "interviews", "offers", "interview_rate",
+	"offer_rate", "interview_to_offer_rate", "responses", "median_hours_to_first_response",
+}
+
+// WriteCSV writes the report as CSV, one row per group with the overall funnel first. The median is
+// empty for groups without a response.
+func WriteCSV(w io.Writer, report Report) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	rows := append([]Stats{report.Overall}, report.Breakdowns...)
+	for _, stats := range rows {
+		median := ""
+		if stats.MedianHoursToFirstResponse != nil {
+			median = strconv.FormatFloat(*stats.MedianHoursToFirstResponse, 'f', -1, 64)
+		}
+		record := []string{
+			stats.Dimension,
+			stats.Value,
+			strconv.Itoa(stats.Applications),
+			strconv.Itoa(stats.Applied),
+			strconv.Itoa(stats.Interviews),
+			strconv.Itoa(stats.Offers),
+			strconv.FormatFloat(stats.InterviewRate, 'f', -1, 64),
+			strconv.FormatFloat(stats.OfferRate, 'f', -1, 64),
+			strconv.FormatFloat(stats.InterviewToOfferRate, 'f', -1, 64),
+			strconv.Itoa(stats.Responses),
+			median,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package analytics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	median := 36.5
+	report := Report{
+		Overall: Stats{Dimension: "all", Value: "all", Applications: 5, Applied: 4, Interviews: 3, Offers: 1,
+			InterviewRate: 0.75, OfferRate: 0.25, InterviewToOfferRate: 0.333, Responses: 2, MedianHoursToFirstResponse: &median},
+		Breakdowns: []Stats{
+			{Dimension: DimensionSource, Value: "LinkedIn, Inc", Applications: 1},
+		},
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteCSV(&buffer, report); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	want := "dimension,value,applications,applied,interviews,offers,interview_rate,offer_rate,interview_to_offer_rate,responses,median_hours_to_first_response\n" +
+		"all,all,5,4,3,1,0.75,0.25,0.333,2,36.5\n" +
+		"source,\"LinkedIn, Inc\",1,0,0,0,0,0,0,0,\n"
+	if got := buffer.String(); got != want {
+		t.Errorf("WriteCSV() = %q, want %q", got, want)
+	}
+}
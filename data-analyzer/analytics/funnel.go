@@ -0,0 +1,283 @@
+package analytics
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"data-analyzer/models"
+)
+
+// Dimensions the funnel is broken down by
+const (
+	DimensionSource        = "source"
+	DimensionResumeVersion = "resume_version"
+	DimensionRoleFamily    = "role_family"
+	DimensionMonth         = "month"
+)
+
+// Dimensions lists every dimension the funnel can be broken down by
+var Dimensions = []string{DimensionSource, DimensionResumeVersion, DimensionRoleFamily, DimensionMonth}
+
+// appliedStepTitle is the title of the step Django adds when a job application moves from Preparing
+// Application to Applied
+const appliedStepTitle = "Applied"
+
+// unknownValue is the group of job applications without a value for a dimension
+const unknownValue = "unknown"
+
+var (
+	// interviewStepRe finds the steps showing that a job application reached an interview, such as
+	// the "Interview Invitation" steps of ingested emails or interviews added by hand
+	interviewStepRe = regexp.MustCompile(`(?i)\b(interview|interviews|screening|phone screen|onsite|on-site|take-home)\b`)
+	// offerStepRe finds the steps showing that a job application got an offer
+	offerStepRe = regexp.MustCompile(`(?i)\b(offer|offered)\b`)
+)
+
+// Options controls how the steps of a job application are read
+type Options struct {
+	// IgnoredSteps are the titles of steps that are not activity of the company, such as the steps
+	// the analyzer adds on its own. They never count as a response or a stage.
+	IgnoredSteps []string
+}
+
+// Progress is how far a job application got through the funnel
+type Progress struct {
+	JobApplicationID int `json:"job_application_id"`
+	// AppliedAt is when the Applied step was added, or when the job application was created without one
+	AppliedAt   time.Time `json:"applied_at"`
+	Applied     bool      `json:"applied"`
+	Interviewed bool      `json:"interviewed"`
+	Offered     bool      `json:"offered"`
+	// Responded is true when the company answered, with a step after applying, a rejection or an interview
+	Responded bool `json:"responded"`
+	// FirstResponseAt is when the first step from the company after applying was added, zero without one
+	FirstResponseAt time.Time `json:"first_response_at"`
+}
+
+// Stats is the funnel of a group of job applications. Rates are fractions of the applied job
+// applications, except InterviewToOfferRate which is a fraction of the interviewed ones.
+type Stats struct {
+	Dimension string `json:"dimension"`
+	Value     string `json:"value"`
+	// Applications counts every job application in the group, including those not applied to yet
+	Applications         int     `json:"applications"`
+	Applied              int     `json:"applied"`
+	Interviews           int     `json:"interviews"`
+	Offers               int     `json:"offers"`
+	InterviewRate        float64 `json:"interview_rate"`
+	OfferRate            float64 `json:"offer_rate"`
+	InterviewToOfferRate float64 `json:"interview_to_offer_rate"`
+	// Responses counts the applied job applications with a step from the company after applying
+	Responses int `json:"responses"`
+	// MedianHoursToFirstResponse is nil when no job application in the group got a response
+	MedianHoursToFirstResponse *float64 `json:"median_hours_to_first_response"`
+
+	responseHours []float64
+}
+
+// Report is the funnel of every job application and its breakdowns
+type Report struct {
+	Overall Stats `json:"overall"`
+	// Breakdowns are grouped by dimension in the requested order, with the values of each dimension sorted
+	Breakdowns []Stats `json:"breakdowns"`
+}
+
+// Funnel computes the conversion from Applied to an interview to an offer and the median time to the
+// first response, overall and broken down by each of the dimensions. Steps must be sorted oldest first.
+func Funnel(jobs []models.JobApplication, steps []models.Step, dimensions []string, options Options) Report {
+	stepsByJob := make(map[int][]models.Step)
+	for _, step := range steps {
+		if slices.Contains(options.IgnoredSteps, step.Title) {
+			continue
+		}
+		stepsByJob[step.JobApplicationID] = append(stepsByJob[step.JobApplicationID], step)
+	}
+
+	report := Report{
+		Overall:    Stats{Dimension: "all", Value: "all"},
+		Breakdowns: []Stats{},
+	}
+	groups := make(map[string]map[string]*Stats)
+	for _, dimension := range dimensions {
+		groups[dimension] = make(map[string]*Stats)
+	}
+
+	for _, job := range jobs {
+		progress := Track(job, stepsByJob[job.ID])
+		report.Overall.add(progress)
+		for _, dimension := range dimensions {
+			value := dimensionValue(job, progress, dimension)
+			group, ok := groups[dimension][value]
+			if !ok {
+				group = &Stats{Dimension: dimension, Value: value}
+				groups[dimension][value] = group
+			}
+			group.add(progress)
+		}
+	}
+
+	report.Overall.finish()
+	for _, dimension := range dimensions {
+		values := make([]string, 0, len(groups[dimension]))
+		for value := range groups[dimension] {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			group := groups[dimension][value]
+			group.finish()
+			report.Breakdowns = append(report.Breakdowns, *group)
+		}
+	}
+	return report
+}
+
+// Track reads how far a job application got from its current status and its steps, so a job
+// application rejected after an interview still counts as interviewed. Steps must be sorted oldest
+// first and not include ignored steps.
+func Track(job models.JobApplication, steps []models.Step) Progress {
+	progress := Progress{
+		JobApplicationID: job.ID,
+		AppliedAt:        job.CreatedAt,
+	}
+	switch job.Status {
+	case models.StatusOffer:
+		progress.Offered = true
+		progress.Interviewed = true
+		progress.Applied = true
+	case models.StatusTechnicalInterview, models.StatusHRInterview:
+		progress.Interviewed = true
+		progress.Applied = true
+	case models.StatusApplied, models.StatusGhosted, models.StatusRejected:
+		progress.Applied = true
+	}
+
+	// the first Applied step is when the job application was sent, none of them is a response
+	for _, step := range steps {
+		if isAppliedStep(step) {
+			progress.AppliedAt = step.CreatedAt
+			progress.Applied = true
+			break
+		}
+	}
+
+	for _, step := range steps {
+		if isAppliedStep(step) {
+			continue
+		}
+		if interviewStepRe.MatchString(step.Title) {
+			progress.Interviewed = true
+		}
+		if offerStepRe.MatchString(step.Title) {
+			progress.Offered = true
+		}
+		if progress.FirstResponseAt.IsZero() && step.CreatedAt.After(progress.AppliedAt) {
+			progress.FirstResponseAt = step.CreatedAt
+		}
+	}
+	// a step can show an interview or offer for a job application whose status was never updated
+	if progress.Offered || progress.Interviewed {
+		progress.Applied = true
+	}
+	if !progress.Applied {
+		progress.FirstResponseAt = time.Time{}
+	}
+	progress.Responded = progress.Applied && (!progress.FirstResponseAt.IsZero() || progress.Interviewed ||
+		job.Status == models.StatusRejected)
+	return progress
+}
+
+func isAppliedStep(step models.Step) bool {
+	return strings.EqualFold(strings.TrimSpace(step.Title), appliedStepTitle)
+}
+
+// ParseDimensions splits a comma separated list of dimensions
+func ParseDimensions(value string) []string {
+	dimensions := []string{}
+	for _, dimension := range strings.Split(value, ",") {
+		if dimension = strings.TrimSpace(dimension); dimension != "" {
+			dimensions = append(dimensions, dimension)
+		}
+	}
+	return dimensions
+}
+
+// dimensionValue is the group of the job application in a dimension
+func dimensionValue(job models.JobApplication, progress Progress, dimension string) string {
+	var value string
+	switch dimension {
+	case DimensionSource:
+		value = job.Source
+	case DimensionResumeVersion:
+		value = job.ResumeVersion
+	case DimensionRoleFamily:
+		value = RoleFamily(job.JobTitle)
+	case DimensionMonth:
+		if !progress.AppliedAt.IsZero() {
+			value = progress.AppliedAt.UTC().Format("2006-01")
+		}
+	}
+	if value = strings.TrimSpace(value); value == "" {
+		return unknownValue
+	}
+	return value
+}
+
+func (s *Stats) add(progress Progress) {
+	s.Applications++
+	if !progress.Applied {
+		return
+	}
+	s.Applied++
+	if progress.Interviewed {
+		s.Interviews++
+	}
+	if progress.Offered {
+		s.Offers++
+	}
+	if !progress.FirstResponseAt.IsZero() {
+		s.Responses++
+		s.responseHours = append(s.responseHours, progress.FirstResponseAt.Sub(progress.AppliedAt).Hours())
+	}
+}
+
+// finish computes the rates and the median from the counts
+func (s *Stats) finish() {
+	s.InterviewRate = rate(s.Interviews, s.Applied)
+	s.OfferRate = rate(s.Offers, s.Applied)
+	s.InterviewToOfferRate = rate(s.Offers, s.Interviews)
+	if len(s.responseHours) > 0 {
+		median := round(Median(s.responseHours), 1)
+		s.MedianHoursToFirstResponse = &median
+	}
+}
+
+// Median returns the middle of the values, or the mean of the two middle ones for an even count
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
+
+func rate(count int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return round(float64(count)/float64(total), 3)
+}
+
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
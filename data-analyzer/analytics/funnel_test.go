@@ -0,0 +1,173 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"data-analyzer/models"
+)
+
+func TestTrack(t *testing.T) {
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return created.Add(time.Duration(hours) * time.Hour) }
+
+	tests := []struct {
+		name  string
+		job   models.JobApplication
+		steps []models.Step
+		want  Progress
+	}{
+		{
+			"not applied yet",
+			models.JobApplication{ID: 1, Status: models.StatusPreparingApplication, CreatedAt: created},
+			[]models.Step{{Title: "Research notes", CreatedAt: at(1)}},
+			Progress{JobApplicationID: 1, AppliedAt: created},
+		},
+		{
+			"applied step and a response",
+			models.JobApplication{ID: 2, Status: models.StatusApplied, CreatedAt: created},
+			[]models.Step{{Title: "Notes", CreatedAt: at(1)}, {Title: "applied", CreatedAt: at(2)}, {Title: "Recruiter call", CreatedAt: at(26)}},
+			Progress{JobApplicationID: 2, AppliedAt: at(2), Applied: true, Responded: true, FirstResponseAt: at(26)},
+		},
+		{
+			"interview status without steps",
+			models.JobApplication{ID: 3, Status: models.StatusHRInterview, CreatedAt: created},
+			nil,
+			Progress{JobApplicationID: 3, AppliedAt: created, Applied: true, Interviewed: true, Responded: true},
+		},
+		{
+			"rejected after an interview",
+			models.JobApplication{ID: 4, Status: models.StatusRejected, CreatedAt: created},
+			[]models.Step{{Title: "Applied", CreatedAt: at(0)}, {Title: "Technical Interview", CreatedAt: at(72)}, {Title: "Rejected", CreatedAt: at(96)}},
+			Progress{JobApplicationID: 4, AppliedAt: at(0), Applied: true, Interviewed: true, Responded: true, FirstResponseAt: at(72)},
+		},
+		{
+			"rejected without steps",
+			models.JobApplication{ID: 5, Status: models.StatusRejected, CreatedAt: created},
+			nil,
+			Progress{JobApplicationID: 5, AppliedAt: created, Applied: true, Responded: true},
+		},
+		{
+			"offer step on a stale status",
+			models.JobApplication{ID: 6, Status: models.StatusPreparingApplication, CreatedAt: created},
+			[]models.Step{{Title: "Offer received", CreatedAt: at(200)}},
+			Progress{JobApplicationID: 6, AppliedAt: created, Applied: true, Offered: true, Responded: true, FirstResponseAt: at(200)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Track(tt.job, tt.steps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Track() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFunnel(t *testing.T) {
+	march := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	april := time.Date(2025, 4, 2, 10, 0, 0, 0, time.UTC)
+	jobs := []models.JobApplication{
+		{ID: 1, Status: models.StatusRejected, Source: "linkedin", ResumeVersion: "v1", JobTitle: "Senior Backend Engineer", CreatedAt: march},
+		{ID: 2, Status: models.StatusOffer, Source: "referral", ResumeVersion: "v2", JobTitle: "Frontend Developer", CreatedAt: march.Add(24 * time.Hour)},
+		{ID: 3, Status: models.StatusPreparingApplication, Source: "linkedin", JobTitle: "Data Analyst", CreatedAt: march},
+		{ID: 4, Status: models.StatusApplied, ResumeVersion: "v1", JobTitle: "Go Developer", CreatedAt: april},
+		{ID: 5, Status: models.StatusGhosted, Source: "referral", ResumeVersion: "v2", JobTitle: "Marketing Lead", CreatedAt: march.AddDate(0, 0, -2)},
+	}
+	steps := []models.Step{
+		{JobApplicationID: 5, Title: "Applied", CreatedAt: march.Add(48 * time.Hour)},
+		{JobApplicationID: 1, Title: "Applied", CreatedAt: march.Add(time.Hour)},
+		{JobApplicationID: 1, Title: "HR Interview", CreatedAt: march.Add(49 * time.Hour)},
+		{JobApplicationID: 1, Title: "Rejected", CreatedAt: march.Add(100 * time.Hour)},
+		{JobApplicationID: 4, Title: "Applied", CreatedAt: april},
+		// steps the analyzer adds are not a response from the company
+		{JobApplicationID: 4, Title: "Follow-up reminder", CreatedAt: april.Add(2 * time.Hour)},
+		{JobApplicationID: 4, Title: "Coding challenge (take-home)", CreatedAt: april.Add(24 * time.Hour)},
+	}
+
+	report := Funnel(jobs, steps, []string{DimensionSource, DimensionMonth}, Options{IgnoredSteps: []string{"Follow-up reminder"}})
+
+	type counts struct {
+		applications, applied, interviews, offers, responses int
+		median                                               float64
+	}
+	summarize := func(stats Stats) counts {
+		median := -1.0
+		if stats.MedianHoursToFirstResponse != nil {
+			median = *stats.MedianHoursToFirstResponse
+		}
+		return counts{stats.Applications, stats.Applied, stats.Interviews, stats.Offers, stats.Responses, median}
+	}
+
+	if got, want := summarize(report.Overall), (counts{5, 4, 3, 1, 2, 36}); got != want {
+		t.Errorf("Funnel() overall = %+v, want %+v", got, want)
+	}
+	if report.Overall.InterviewRate != 0.75 || report.Overall.OfferRate != 0.25 || report.Overall.InterviewToOfferRate != 0.333 {
+		t.Errorf("Funnel() overall rates = %v, %v, %v, want 0.75, 0.25, 0.333", report.Overall.InterviewRate, report.Overall.OfferRate, report.Overall.InterviewToOfferRate)
+	}
+
+	tests := []struct {
+		dimension string
+		value     string
+		want      counts
+	}{
+		{DimensionSource, "linkedin", counts{2, 1, 1, 0, 1, 48}},
+		{DimensionSource, "referral", counts{2, 2, 1, 1, 0, -1}},
+		{DimensionSource, "unknown", counts{1, 1, 1, 0, 1, 24}},
+		{DimensionMonth, "2025-03", counts{4, 3, 2, 1, 1, 48}},
+		{DimensionMonth, "2025-04", counts{1, 1, 1, 0, 1, 24}},
+	}
+	if len(report.Breakdowns) != len(tests) {
+		t.Fatalf("Funnel() has %d breakdowns, want %d", len(report.Breakdowns), len(tests))
+	}
+	for i, tt := range tests {
+		breakdown := report.Breakdowns[i]
+		if breakdown.Dimension != tt.dimension || breakdown.Value != tt.value {
+			t.Errorf("breakdown %d = %s %s, want %s %s", i, breakdown.Dimension, breakdown.Value, tt.dimension, tt.value)
+			continue
+		}
+		if got := summarize(breakdown); got != tt.want {
+			t.Errorf("breakdown %s %s = %+v, want %+v", tt.dimension, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseDimensions(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", []string{}},
+		{"source", []string{"source"}},
+		{" source , month,,role_family ", []string{"source", "month", "role_family"}},
+	}
+	for _, tt := range tests {
+		if got := ParseDimensions(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseDimensions(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{nil, 0},
+		{[]float64{5}, 5},
+		{[]float64{9, 1, 5}, 5},
+		{[]float64{48, 24}, 36},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+	for _, tt := range tests {
+		if got := Median(tt.values); got != tt.want {
+			t.Errorf("Median(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+
+	values := []float64{3, 1, 2}
+	Median(values)
+	if !reflect.DeepEqual(values, []float64{3, 1, 2}) {
+		t.Errorf("Median() sorted its argument to %v", values)
+	}
+}
@@ -0,0 +1,299 @@
+package analytics
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"data-analyzer/models"
+)
+
+// Metrics compared between resume versions
+const (
+	MetricResponse  = "response"
+	MetricInterview = "interview"
+)
+
+// SignificanceLevel is the p-value under which two resume versions are considered to perform differently
+const SignificanceLevel = 0.05
+
+// Variables the comparison of resume versions controls for
+const (
+	ControlSeniority = "seniority"
+	ControlSource    = "source"
+)
+
+// minKnownShare is the share of applied job applications that must have a known value for a variable
+// to be controlled for; below it, the strata would mostly be "unknown" and only hide the data
+const minKnownShare = 0.5
+
+// smallSample is the number of applied job applications under which a version's intervals are too wide
+// to tell much
+const smallSample = 10
+
+// ResumeVersionStats is how the job applications sent with one resume version did
+type ResumeVersionStats struct {
+	ResumeVersion string     `json:"resume_version"`
+	Applications  int        `json:"applications"`
+	Applied       int        `json:"applied"`
+	ResponseRate  Proportion `json:"response_rate"`
+	InterviewRate Proportion `json:"interview_rate"`
+	// Sources and Seniorities count the applied job applications, to see how comparable versions are
+	Sources     map[string]int `json:"sources"`
+	Seniorities map[string]int `json:"seniorities"`
+}
+
+// ResumeVersionComparison tests whether two resume versions differ on a metric
+type ResumeVersionComparison struct {
+	A      string  `json:"a"`
+	B      string  `json:"b"`
+	Metric string  `json:"metric"`
+	RateA  float64 `json:"rate_a"`
+	RateB  float64 `json:"rate_b"`
+	// Difference is the rate of A minus the rate of B
+	Difference float64 `json:"difference"`
+	// PValue is the p-value of the two-proportion z-test, without controlling for anything
+	PValue float64 `json:"p_value"`
+	// AdjustedPValue and AdjustedOddsRatio come from the Cochran-Mantel-Haenszel test within the strata
+	// of the controlled variables; they are nil when nothing is controlled for or no stratum has both versions
+	AdjustedPValue    *float64 `json:"adjusted_p_value"`
+	AdjustedOddsRatio *float64 `json:"adjusted_odds_ratio"`
+	// Strata counts the strata where both versions were sent
+	Strata int `json:"strata"`
+	// Significant uses the adjusted p-value when there is one
+	Significant bool `json:"significant"`
+}
+
+// ResumeVersionReport compares the response and interview rates of the resume versions
+type ResumeVersionReport struct {
+	SignificanceLevel float64 `json:"significance_level"`
+	// ControlledFor lists the variables the adjusted tests hold constant
+	ControlledFor []string                  `json:"controlled_for"`
+	Versions      []ResumeVersionStats      `json:"versions"`
+	Comparisons   []ResumeVersionComparison `json:"comparisons"`
+	// Notes explain what the data did not allow
+	Notes []string `json:"notes"`
+}
+
+// resumeVersionApplication is an applied job application with the groups it belongs to
+type resumeVersionApplication struct {
+	progress  Progress
+	version   string
+	source    string
+	seniority string
+}
+
+// CompareResumeVersions computes the response and interview rates of every resume version with 95%
+// confidence intervals, and tests every pair of versions. The tests control for seniority and source
+// when enough job applications have them: seniority comes from the extracted job metadata, keyed by job
+// application ID, or from the job title without it. Job applications without a resume version are
+// reported but not compared. Steps must be sorted oldest first.
+func CompareResumeVersions(jobs []models.JobApplication, steps []models.Step, seniorities map[int]string, options Options) ResumeVersionReport {
+	stepsByJob := make(map[int][]models.Step)
+	for _, step := range steps {
+		if slices.Contains(options.IgnoredSteps, step.Title) {
+			continue
+		}
+		stepsByJob[step.JobApplicationID] = append(stepsByJob[step.JobApplicationID], step)
+	}
+
+	report := ResumeVersionReport{
+		SignificanceLevel: SignificanceLevel,
+		ControlledFor:     []string{},
+		Versions:          []ResumeVersionStats{},
+		Comparisons:       []ResumeVersionComparison{},
+		Notes:             []string{},
+	}
+
+	applications := []resumeVersionApplication{}
+	versions := make(map[string]*ResumeVersionStats)
+	counts := make(map[string][2]int)
+	for _, job := range jobs {
+		progress := Track(job, stepsByJob[job.ID])
+		version := dimensionValue(job, progress, DimensionResumeVersion)
+		stats, ok := versions[version]
+		if !ok {
+			stats = &ResumeVersionStats{ResumeVersion: version, Sources: map[string]int{}, Seniorities: map[string]int{}}
+			versions[version] = stats
+		}
+		stats.Applications++
+		if !progress.Applied {
+			continue
+		}
+
+		application := resumeVersionApplication{
+			progress:  progress,
+			version:   version,
+			source:    dimensionValue(job, progress, DimensionSource),
+			seniority: seniorityBand(seniorities[job.ID], job.JobTitle),
+		}
+		applications = append(applications, application)
+		stats.Applied++
+		stats.Sources[application.source]++
+		stats.Seniorities[application.seniority]++
+		count := counts[version]
+		if progress.Responded {
+			count[0]++
+		}
+		if progress.Interviewed {
+			count[1]++
+		}
+		counts[version] = count
+	}
+
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		stats := versions[name]
+		stats.ResponseRate = NewProportion(counts[name][0], stats.Applied)
+		stats.InterviewRate = NewProportion(counts[name][1], stats.Applied)
+		report.Versions = append(report.Versions, *stats)
+		if stats.Applied > 0 && stats.Applied < smallSample && name != unknownValue {
+			report.Notes = append(report.Notes, fmt.Sprintf("%s has only %d applied job applications, its confidence intervals are wide", name, stats.Applied))
+		}
+	}
+
+	report.ControlledFor, report.Notes = controls(applications, report.Notes)
+	if len(report.ControlledFor) == 0 {
+		report.Notes = append(report.Notes, "nothing is controlled for, the p-values compare the versions as they are")
+	}
+
+	compared := slices.DeleteFunc(slices.Clone(names), func(name string) bool {
+		return name == unknownValue || versions[name].Applied == 0
+	})
+	if len(compared) < 2 {
+		report.Notes = append(report.Notes, "at least two resume versions with applied job applications are needed to compare them")
+	}
+	for i := 0; i < len(compared); i++ {
+		for j := i + 1; j < len(compared); j++ {
+			a, b := versions[compared[i]], versions[compared[j]]
+			report.Comparisons = append(report.Comparisons,
+				compareVersions(a.ResumeVersion, b.ResumeVersion, MetricResponse, a.ResponseRate, b.ResponseRate, applications, report.ControlledFor),
+				compareVersions(a.ResumeVersion, b.ResumeVersion, MetricInterview, a.InterviewRate, b.InterviewRate, applications, report.ControlledFor),
+			)
+		}
+	}
+	return report
+}
+
+// controls picks the variables with enough known values to control for, noting why the others are not
+func controls(applications []resumeVersionApplication, notes []string) ([]string, []string) {
+	controlled := []string{}
+	for _, variable := range []string{ControlSeniority, ControlSource} {
+		values := make(map[string]bool)
+		known := 0
+		for _, application := range applications {
+			value := application.seniority
+			if variable == ControlSource {
+				value = application.source
+			}
+			if value != unknownValue {
+				values[value] = true
+				known++
+			}
+		}
+		switch {
+		case len(applications) == 0:
+		case float64(known) < minKnownShare*float64(len(applications)):
+			notes = append(notes, fmt.Sprintf("%s is not controlled for, only %d of %d applied job applications have one", variable, known, len(applications)))
+		case len(values) < 2:
+			notes = append(notes, fmt.Sprintf("%s is not controlled for, every applied job application has the same", variable))
+		default:
+			controlled = append(controlled, variable)
+		}
+	}
+	return controlled, notes
+}
+
+// compareVersions tests two versions on a metric, overall and within the strata of the controlled variables
+func compareVersions(a string, b string, metric string, rateA Proportion, rateB Proportion, applications []resumeVersionApplication, controlled []string) ResumeVersionComparison {
+	comparison := ResumeVersionComparison{
+		A:          a,
+		B:          b,
+		Metric:     metric,
+		RateA:      rateA.Rate,
+		RateB:      rateB.Rate,
+		Difference: round(rateA.Rate-rateB.Rate, 3),
+		PValue:     round(TwoProportionTest(rateA, rateB), 4),
+	}
+	pValue := comparison.PValue
+
+	if len(controlled) > 0 {
+		keys := []string{}
+		strata := make(map[string]*Stratum)
+		for _, application := range applications {
+			if application.version != a && application.version != b {
+				continue
+			}
+			levels := []string{}
+			for _, variable := range controlled {
+				if variable == ControlSeniority {
+					levels = append(levels, application.seniority)
+				} else {
+					levels = append(levels, application.source)
+				}
+			}
+			key := strings.Join(levels, "|")
+			stratum, ok := strata[key]
+			if !ok {
+				stratum = &Stratum{}
+				strata[key] = stratum
+				keys = append(keys, key)
+			}
+			success := application.progress.Responded
+			if metric == MetricInterview {
+				success = application.progress.Interviewed
+			}
+			switch {
+			case application.version == a && success:
+				stratum.SuccessA++
+			case application.version == a:
+				stratum.FailureA++
+			case success:
+				stratum.SuccessB++
+			default:
+				stratum.FailureB++
+			}
+		}
+		tables := make([]Stratum, len(keys))
+		for i, key := range keys {
+			tables[i] = *strata[key]
+		}
+		if result, ok := MantelHaenszelTest(tables); ok {
+			adjusted := round(result.PValue, 4)
+			comparison.AdjustedPValue = &adjusted
+			comparison.Strata = result.Strata
+			if result.OddsRatio > 0 {
+				comparison.AdjustedOddsRatio = &result.OddsRatio
+			}
+			pValue = adjusted
+		}
+	}
+
+	comparison.Significant = pValue < SignificanceLevel
+	return comparison
+}
+
+// seniorityBand groups the seniority of a job into junior, mid, senior or manager so that strata keep
+// enough job applications. The extracted seniority wins over the one read from the title.
+func seniorityBand(extracted string, jobTitle string) string {
+	seniority := strings.TrimSpace(extracted)
+	if seniority == "" || seniority == unknownValue {
+		seniority = TitleSeniority(jobTitle)
+	}
+	switch seniority {
+	case "intern", "junior":
+		return "junior"
+	case "mid":
+		return "mid"
+	case "senior", "staff", "principal", "lead":
+		return "senior"
+	case "manager":
+		return "manager"
+	}
+	return unknownValue
+}
@@ -0,0 +1,89 @@
+package analytics
+
+import (
+	"strings"
+
+	"data-analyzer/domains"
+)
+
+// Role families job titles are grouped into
+const (
+	RoleFamilyProduct         = "product"
+	RoleFamilyManagement      = "management"
+	RoleFamilyMachineLearning = "machine_learning"
+	RoleFamilyData            = "data"
+	RoleFamilySecurity        = "security"
+	RoleFamilyDevOps          = "devops"
+	RoleFamilyMobile          = "mobile"
+	RoleFamilyFullstack       = "fullstack"
+	RoleFamilyFrontend        = "frontend"
+	RoleFamilyBackend         = "backend"
+	RoleFamilyQA              = "qa"
+	RoleFamilyDesign          = "design"
+	RoleFamilySoftware        = "software"
+	RoleFamilyOther           = "other"
+)
+
+// roleFamilyKeywords are the words of a job title that place it in a family, checked in order so that
+// an "Engineering Manager, Backend" is management and a "Machine Learning Data Engineer" is machine learning
+var roleFamilyKeywords = []struct {
+	family   string
+	keywords []string
+}{
+	{RoleFamilyProduct, []string{"product manager", "product owner", "product lead"}},
+	{RoleFamilyManagement, []string{"manager", "head of", "director", "vp", "vice president", "cto"}},
+	{RoleFamilyMachineLearning, []string{"machine learning", "ml", "mlops", "ai", "deep learning", "nlp", "llm", "computer vision"}},
+	{RoleFamilyData, []string{"data", "analytics", "analyst", "bi", "etl"}},
+	{RoleFamilySecurity, []string{"security", "appsec", "devsecops"}},
+	{RoleFamilyDevOps, []string{"devops", "sre", "site reliability", "platform", "infrastructure", "cloud"}},
+	{RoleFamilyMobile, []string{"mobile", "ios", "android", "flutter", "react native"}},
+	{RoleFamilyFullstack, []string{"full stack", "fullstack"}},
+	{RoleFamilyFrontend, []string{"frontend", "front end", "ui", "react", "angular", "vue", "javascript", "typescript"}},
+	{RoleFamilyBackend, []string{"backend", "back end", "api", "go", "golang", "java", "python", "ruby", "rails", "node", "php", "c#", "scala", "elixir", "rust"}},
+	{RoleFamilyQA, []string{"qa", "quality", "test", "tester", "sdet"}},
+	{RoleFamilyDesign, []string{"designer", "ux", "design"}},
+	{RoleFamilySoftware, []string{"software", "engineer", "developer", "programmer"}},
+}
+
+// RoleFamily groups a job title into a role family by its keywords, e.g. "Senior Back-End Engineer
+// (Go)" is backend. Titles without a known keyword are other.
+func RoleFamily(jobTitle string) string {
+	words := " " + strings.Join(domains.Words(jobTitle), " ") + " "
+	for _, family := range roleFamilyKeywords {
+		for _, keyword := range family.keywords {
+			if strings.Contains(words, " "+keyword+" ") {
+				return family.family
+			}
+		}
+	}
+	return RoleFamilyOther
+}
+
+// titleSeniorityKeywords are the words of a job title that give its seniority, using the seniority
+// levels of the job metadata workflow, checked in order so a "Senior Engineering Manager" is a manager
+var titleSeniorityKeywords = []struct {
+	seniority string
+	keywords  []string
+}{
+	{"manager", []string{"manager", "head of", "director", "vp", "vice president", "cto"}},
+	{"principal", []string{"principal", "distinguished"}},
+	{"staff", []string{"staff"}},
+	{"lead", []string{"lead"}},
+	{"senior", []string{"senior", "sr"}},
+	{"mid", []string{"mid", "mid level", "intermediate"}},
+	{"junior", []string{"junior", "jr", "entry level", "graduate", "associate"}},
+	{"intern", []string{"intern", "internship", "trainee"}},
+}
+
+// TitleSeniority reads the seniority from the keywords of a job title, empty when the title has none
+func TitleSeniority(jobTitle string) string {
+	words := " " + strings.Join(domains.Words(jobTitle), " ") + " "
+	for _, level := range titleSeniorityKeywords {
+		for _, keyword := range level.keywords {
+			if strings.Contains(words, " "+keyword+" ") {
+				return level.seniority
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package analytics
+
+import "testing"
+
+func TestRoleFamily(t *testing.T) {
+	tests := []struct {
+		jobTitle string
+		want     string
+	}{
+		{"Senior Back-End Engineer (Go)", RoleFamilyBackend},
+		{"C# Developer", RoleFamilyBackend},
+		{"Engineering Manager, Backend", RoleFamilyManagement},
+		{"Product Manager", RoleFamilyProduct},
+		{"Machine Learning Data Engineer", RoleFamilyMachineLearning},
+		{"Senior Data Analyst", RoleFamilyData},
+		{"AppSec Engineer", RoleFamilySecurity},
+		{"SRE", RoleFamilyDevOps},
+		{"iOS Developer", RoleFamilyMobile},
+		{"Full Stack Developer", RoleFamilyFullstack},
+		{"Frontend Engineer (React)", RoleFamilyFrontend},
+		{"QA Automation Engineer", RoleFamilyQA},
+		{"Product Designer", RoleFamilyDesign},
+		{"Software Engineer", RoleFamilySoftware},
+		// keywords match whole words only
+		{"Mailroom Clerk", RoleFamilyOther},
+		{"", RoleFamilyOther},
+	}
+	for _, tt := range tests {
+		if got := RoleFamily(tt.jobTitle); got != tt.want {
+			t.Errorf("RoleFamily(%q) = %q, want %q", tt.jobTitle, got, tt.want)
+		}
+	}
+}
+
+func TestTitleSeniority(t *testing.T) {
+	tests := []struct {
+		jobTitle string
+		want     string
+	}{
+		{"Senior Engineering Manager", "manager"},
+		{"Principal Engineer", "principal"},
+		{"Staff Software Engineer", "staff"},
+		{"Tech Lead, Payments", "lead"},
+		{"Sr. Backend Engineer", "senior"},
+		{"Mid-Level Developer", "mid"},
+		{"Jr Frontend Developer", "junior"},
+		{"Software Engineering Intern", "intern"},
+		{"Internal Tools Engineer", ""},
+		{"Software Engineer", ""},
+	}
+	for _, tt := range tests {
+		if got := TitleSeniority(tt.jobTitle); got != tt.want {
+			t.Errorf("TitleSeniority(%q) = %q, want %q", tt.jobTitle, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package analytics
+
+import "math"
+
+// z95 is the standard normal quantile of a two-sided 95% confidence interval
+const z95 = 1.959963984540054
+
+// Proportion is a count out of a total with its 95% Wilson score confidence interval
+type Proportion struct {
+	Count int     `json:"count"`
+	Total int     `json:"total"`
+	Rate  float64 `json:"rate"`
+	Low   float64 `json:"ci_low"`
+	High  float64 `json:"ci_high"`
+}
+
+// NewProportion computes the rate and its Wilson score interval, which unlike the normal approximation
+// stays within 0 and 1 and is usable for the small counts of a job search
+func NewProportion(count int, total int) Proportion {
+	proportion := Proportion{Count: count, Total: total}
+	if total == 0 {
+		return proportion
+	}
+	n := float64(total)
+	p := float64(count) / n
+	z2 := z95 * z95
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	half := z95 * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / (1 + z2/n)
+	proportion.Rate = round(p, 3)
+	proportion.Low = round(max(0, center-half), 3)
+	proportion.High = round(min(1, center+half), 3)
+	return proportion
+}
+
+// TwoProportionTest is the two-sided p-value of the pooled z-test that two proportions are equal.
+// It is 1 when either group is empty or nobody or everybody succeeded in both.
+func TwoProportionTest(a Proportion, b Proportion) float64 {
+	if a.Total == 0 || b.Total == 0 {
+		return 1
+	}
+	pooled := float64(a.Count+b.Count) / float64(a.Total+b.Total)
+	variance := pooled * (1 - pooled) * (1/float64(a.Total) + 1/float64(b.Total))
+	if variance == 0 {
+		return 1
+	}
+	z := (float64(a.Count)/float64(a.Total) - float64(b.Count)/float64(b.Total)) / math.Sqrt(variance)
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// Stratum is the 2x2 table of two groups within one level of the controlled variables
+type Stratum struct {
+	SuccessA int
+	FailureA int
+	SuccessB int
+	FailureB int
+}
+
+// MantelHaenszel is the Cochran-Mantel-Haenszel test of two groups across strata
+type MantelHaenszel struct {
+	// PValue is the two-sided p-value with continuity correction that the groups do not differ
+	// within strata
+	PValue float64
+	// OddsRatio is the Mantel-Haenszel common odds ratio of A over B, 0 when it is undefined
+	OddsRatio float64
+	// Strata counts the strata where both groups were present
+	Strata int
+}
+
+// MantelHaenszelTest compares two groups while holding the variables that define the strata constant.
+// Strata where only one group is present carry no information and are skipped; ok is false when none is left.
+func MantelHaenszelTest(strata []Stratum) (MantelHaenszel, bool) {
+	var result MantelHaenszel
+	var observed, expected, variance, numerator, denominator float64
+	for _, stratum := range strata {
+		a, b := float64(stratum.SuccessA), float64(stratum.FailureA)
+		c, d := float64(stratum.SuccessB), float64(stratum.FailureB)
+		n := a + b + c + d
+		if a+b == 0 || c+d == 0 || n < 2 {
+			continue
+		}
+		result.Strata++
+		observed += a
+		expected += (a + b) * (a + c) / n
+		variance += (a + b) * (c + d) * (a + c) * (b + d) / (n * n * (n - 1))
+		numerator += a * d / n
+		denominator += b * c / n
+	}
+	if result.Strata == 0 {
+		return result, false
+	}
+
+	result.PValue = 1
+	if variance > 0 {
+		statistic := math.Pow(max(0, math.Abs(observed-expected)-0.5), 2) / variance
+		// a chi-square with one degree of freedom is a squared standard normal
+		result.PValue = math.Erfc(math.Sqrt(statistic / 2))
+	}
+	if denominator > 0 {
+		result.OddsRatio = round(numerator/denominator, 3)
+	}
+	return result, true
+}
@@ -0,0 +1,115 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewProportion(t *testing.T) {
+	tests := []struct {
+		count int
+		total int
+		want  Proportion
+	}{
+		{0, 0, Proportion{}},
+		{5, 10, Proportion{Count: 5, Total: 10, Rate: 0.5, Low: 0.237, High: 0.763}},
+		{1, 4, Proportion{Count: 1, Total: 4, Rate: 0.25, Low: 0.046, High: 0.699}},
+		// the interval stays within 0 and 1 at the edges
+		{0, 10, Proportion{Count: 0, Total: 10, Rate: 0, Low: 0, High: 0.278}},
+		{10, 10, Proportion{Count: 10, Total: 10, Rate: 1, Low: 0.722, High: 1}},
+	}
+	for _, tt := range tests {
+		if got := NewProportion(tt.count, tt.total); got != tt.want {
+			t.Errorf("NewProportion(%d, %d) = %+v, want %+v", tt.count, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestTwoProportionTest(t *testing.T) {
+	tests := []struct {
+		a    Proportion
+		b    Proportion
+		want float64
+	}{
+		{NewProportion(8, 10), NewProportion(2, 10), 0.0073},
+		{NewProportion(30, 100), NewProportion(20, 100), 0.1025},
+		{NewProportion(5, 10), NewProportion(5, 10), 1},
+		{NewProportion(0, 10), NewProportion(0, 12), 1},
+		{NewProportion(10, 10), NewProportion(12, 12), 1},
+		{NewProportion(3, 10), NewProportion(0, 0), 1},
+	}
+	for _, tt := range tests {
+		if got := TwoProportionTest(tt.a, tt.b); math.Abs(got-tt.want) > 0.0001 {
+			t.Errorf("TwoProportionTest(%d/%d, %d/%d) = %.4f, want %.4f", tt.a.Count, tt.a.Total, tt.b.Count, tt.b.Total, got, tt.want)
+		}
+	}
+}
+
+func TestMantelHaenszelTest(t *testing.T) {
+	tests := []struct {
+		name          string
+		strata        []Stratum
+		wantOK        bool
+		wantPValue    float64
+		wantOddsRatio float64
+		wantStrata    int
+	}{
+		{"no strata", nil, false, 0, 0, 0},
+		{"only one group in each stratum", []Stratum{{SuccessA: 3, FailureA: 2}, {SuccessB: 1, FailureB: 4}}, false, 0, 0, 0},
+		{"single stratum", []Stratum{{SuccessA: 8, FailureA: 2, SuccessB: 2, FailureB: 8}}, true, 0.0293, 16, 1},
+		{"no difference", []Stratum{{4, 6, 4, 6}, {2, 3, 2, 3}}, true, 1, 1, 2},
+		{"strata without both groups are skipped", []Stratum{{8, 2, 2, 8}, {SuccessA: 5}}, true, 0.0293, 16, 1},
+		{"no failures in B", []Stratum{{SuccessA: 3, FailureA: 2, SuccessB: 5}}, true, 0.4533, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MantelHaenszelTest(tt.strata)
+			if ok != tt.wantOK || got.Strata != tt.wantStrata {
+				t.Fatalf("MantelHaenszelTest() = %+v, %v, want %d strata, %v", got, ok, tt.wantStrata, tt.wantOK)
+			}
+			if math.Abs(got.PValue-tt.wantPValue) > 0.0001 || got.OddsRatio != tt.wantOddsRatio {
+				t.Errorf("MantelHaenszelTest() = p %.4f odds ratio %v, want %.4f %v", got.PValue, got.OddsRatio, tt.wantPValue, tt.wantOddsRatio)
+			}
+		})
+	}
+}
+
+func TestMantelHaenszelTestSimpsonsParadox(t *testing.T) {
+	// A does better at every seniority but was mostly sent to senior roles, which respond less
+	strata := []Stratum{
+		{SuccessA: 9, FailureA: 1, SuccessB: 80, FailureB: 20},
+		{SuccessA: 20, FailureA: 80, SuccessB: 1, FailureB: 9},
+	}
+	a, b := NewProportion(29, 110), NewProportion(81, 110)
+	if a.Rate >= b.Rate || TwoProportionTest(a, b) >= SignificanceLevel {
+		t.Fatalf("A = %v, B = %v, want A significantly worse overall", a.Rate, b.Rate)
+	}
+	result, ok := MantelHaenszelTest(strata)
+	if !ok || result.OddsRatio <= 1 {
+		t.Errorf("MantelHaenszelTest() = %+v, want an odds ratio favoring A", result)
+	}
+}
+
+func TestSeniorityBand(t *testing.T) {
+	tests := []struct {
+		extracted string
+		jobTitle  string
+		want      string
+	}{
+		{"staff", "Software Engineer", "senior"},
+		{"intern", "", "junior"},
+		{"mid", "Senior Engineer", "mid"},
+		{"manager", "", "manager"},
+		// the title is only read without an extracted seniority
+		{"", "Principal Engineer", "senior"},
+		{"unknown", "Junior Developer", "junior"},
+		{" ", "Head of Engineering", "manager"},
+		{"", "Software Engineer", unknownValue},
+		{"c-level", "", unknownValue},
+	}
+	for _, tt := range tests {
+		if got := seniorityBand(tt.extracted, tt.jobTitle); got != tt.want {
+			t.Errorf("seniorityBand(%q, %q) = %q, want %q", tt.extracted, tt.jobTitle, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"data-analyzer/scenarios"
+)
+
+// AddCompanyAliasRequest represents the request body for adding a name variant to a company
+type AddCompanyAliasRequest struct {
+	Alias string `json:"alias"`
+}
+
+// CompaniesResponse represents the response body for listing companies
+type CompaniesResponse struct {
+	Message   string           `json:"message"`
+	Companies []models.Company `json:"companies"`
+}
+
+// CompanyResponse represents the response body for the full history with a company
+type CompanyResponse struct {
+	Message string                `json:"message"`
+	Company scenarios.CompanyView `json:"company"`
+}
+
+type CompaniesHandler struct {
+	db *db.DB
+}
+
+func NewCompaniesHandler(db *db.DB) *CompaniesHandler {
+	return &CompaniesHandler{
+		db: db,
+	}
+}
+
+// HandleCompanies handles GET requests listing the companies deduplicated from the job applications
+func (h *CompaniesHandler) HandleCompanies(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	companies, err := scenarios.NewCompaniesScenario(h.db).Sync()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get companies: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(CompaniesResponse{Message: "Success", Companies: companies})
+}
+
+// HandleCompany handles GET requests for the applications, steps, red flags, salaries and research of a company
+func (h *CompaniesHandler) HandleCompany(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	companyID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "company id must be a number"})
+		return
+	}
+
+	view, err := scenarios.NewCompaniesScenario(h.db).Get(companyID)
+	if err != nil {
+		writeCompanyError(w, "Failed to get company: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(CompanyResponse{Message: "Success", Company: view})
+}
+
+// HandleAliases handles POST requests adding a name variant to a company, which job applications without
+// a URL are matched by
+func (h *CompaniesHandler) HandleAliases(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	companyID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "company id must be a number"})
+		return
+	}
+
+	// Parse the JSON request body
+	var req AddCompanyAliasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	view, err := scenarios.NewCompaniesScenario(h.db).AddAlias(companyID, req.Alias)
+	if err != nil {
+		writeCompanyError(w, "Failed to add company alias: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(CompanyResponse{Message: "Alias added", Company: view})
+}
+
+func writeCompanyError(w http.ResponseWriter, prefix string, err error) {
+	switch {
+	case errors.Is(err, db.ErrCompanyNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, scenarios.ErrInvalidCompanyAlias):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(ErrorResponse{Error: prefix + err.Error()})
+}
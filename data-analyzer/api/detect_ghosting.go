@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/scenarios"
+)
+
+// MoveToGhostedRequest represents the request body for the move to Ghosted endpoint.
+// Without IDs every job application reported as ghosted is moved.
+type MoveToGhostedRequest struct {
+	JobApplicationIDs []int `json:"job_application_ids"`
+}
+
+type DetectGhostingHandler struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewDetectGhostingHandler(cfg *config.Config, db *db.DB) *DetectGhostingHandler {
+	return &DetectGhostingHandler{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// HandleGetGhosting handles GET requests listing the ghosted job applications and the follow-ups that are due
+func (h *DetectGhostingHandler) HandleGetGhosting(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	report, err := scenarios.NewDetectGhostingScenario(h.cfg, h.db).Execute(time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to detect ghosting: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// HandleMoveToGhosted handles POST requests moving ghosted job applications to the Ghosted status
+func (h *DetectGhostingHandler) HandleMoveToGhosted(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req MoveToGhostedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	scenario := scenarios.NewDetectGhostingScenario(h.cfg, h.db)
+	report, err := scenario.Execute(time.Now())
+	if err == nil {
+		// job applications that are not ghosted are never moved, even when their ID is given
+		report, err = scenario.MoveToGhosted(report, req.JobApplicationIDs)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to move job applications to Ghosted: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/config"
+	"data-analyzer/db"
+)
+
+// DetectRedFlagsRequest represents the request body for the detect red flags endpoint
+type DetectRedFlagsRequest struct {
+	JobApplicationIDs []int `json:"job_application_ids"`
+}
+
+// DetectRedFlagsResponse represents the response body for the detect red flags endpoint
+type DetectRedFlagsResponse struct {
+	Message    string                     `json:"message"`
+	WorkflowID int64                      `json:"workflow_id"`
+	Results    []workflows.RedFlagsResult `json:"results"`
+}
+
+type DetectRedFlagsHandler struct {
+	cfg          *config.Config
+	db           *db.DB
+	geminiClient *agent.Client
+}
+
+func NewDetectRedFlagsHandler(cfg *config.Config, db *db.DB, geminiClient *agent.Client) *DetectRedFlagsHandler {
+	return &DetectRedFlagsHandler{
+		cfg:          cfg,
+		db:           db,
+		geminiClient: geminiClient,
+	}
+}
+
+// HandleDetectRedFlags handles POST requests to detect red flags in job applications
+func (h *DetectRedFlagsHandler) HandleDetectRedFlags(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req DetectRedFlagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	// Validate that job_application_ids is not empty
+	if len(req.JobApplicationIDs) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_ids cannot be empty"})
+		return
+	}
+
+	jobApplications, err := h.db.GetJobApplicationsById(req.JobApplicationIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+
+	runner := workflows.NewWorkflowRunner(h.db, h.geminiClient, h.cfg)
+	runResult, err := runner.RunRedFlagsDetection(context.TODO(), jobApplications)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to detect red flags: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(DetectRedFlagsResponse{
+		Message:    "Red flags detection completed",
+		WorkflowID: runResult.WorkflowID,
+		Results:    runResult.Result.Results,
+	})
+}
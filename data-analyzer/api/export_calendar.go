@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"data-analyzer/calendar"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/scenarios"
+)
+
+type ExportCalendarHandler struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewExportCalendarHandler(cfg *config.Config, db *db.DB) *ExportCalendarHandler {
+	return &ExportCalendarHandler{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// HandleCalendar handles GET requests for the iCalendar feed of interviews and follow-up reminders
+func (h *ExportCalendarHandler) HandleCalendar(w http.ResponseWriter, r *http.Request) {
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	events, err := scenarios.NewExportCalendarScenario(h.cfg, h.db).Execute(time.Now())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to export calendar: " + err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="job-applications.ics"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(calendar.Render("Job Applications", events)))
+}
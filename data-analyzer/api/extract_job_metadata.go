@@ -0,0 +1,269 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"data-analyzer/scenarios"
+)
+
+// ExtractJobMetadataRequest represents the request body for the extract job metadata endpoint
+type ExtractJobMetadataRequest struct {
+	JobApplicationIDs []int `json:"job_application_ids"`
+	// Refresh re-runs the extraction even for job applications that already have metadata
+	Refresh bool `json:"refresh"`
+}
+
+// ExtractJobMetadataResponse represents the response body for the extract job metadata endpoint
+type ExtractJobMetadataResponse struct {
+	Message  string                       `json:"message"`
+	Metadata []workflows.JobMetadataEntry `json:"metadata"`
+}
+
+// MetadataJobApplication is a job application together with its stored metadata
+type MetadataJobApplication struct {
+	JobApplicationID int                   `json:"job_application_id"`
+	JobTitle         string                `json:"job_title"`
+	CompanyName      string                `json:"company_name"`
+	Status           string                `json:"status"`
+	Metadata         workflows.JobMetadata `json:"metadata"`
+}
+
+// GetJobMetadataResponse represents the response body for the job metadata query endpoint
+type GetJobMetadataResponse struct {
+	JobApplications []MetadataJobApplication `json:"job_applications"`
+}
+
+// jobMetadataFilter holds the query parameters of the job metadata query endpoint; empty fields match everything
+type jobMetadataFilter struct {
+	seniority         string
+	remotePolicy      string
+	visaSponsorship   string
+	contractType      string
+	location          string
+	maxYearsRequired  int
+	hasMaxYearsFilter bool
+}
+
+type ExtractJobMetadataHandler struct {
+	db           *db.DB
+	geminiClient *agent.Client
+}
+
+func NewExtractJobMetadataHandler(db *db.DB, geminiClient *agent.Client) *ExtractJobMetadataHandler {
+	return &ExtractJobMetadataHandler{
+		db:           db,
+		geminiClient: geminiClient,
+	}
+}
+
+// HandleExtractJobMetadata handles POST requests to extract the metadata of job applications
+func (h *ExtractJobMetadataHandler) HandleExtractJobMetadata(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req ExtractJobMetadataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	// Validate that job_application_ids is not empty
+	if len(req.JobApplicationIDs) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_ids cannot be empty"})
+		return
+	}
+
+	jobApplications, err := h.db.GetJobApplicationsById(req.JobApplicationIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+
+	storedMetadata, err := scenarios.GetStoredJobMetadata(h.db)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get workflows: " + err.Error()})
+		return
+	}
+
+	// Only extract job applications without stored metadata, unless a refresh was requested
+	jobApplicationsToExtract := make([]models.JobApplication, 0)
+	metadata := make([]workflows.JobMetadataEntry, 0)
+	for _, jobApplication := range jobApplications {
+		if jobMetadata, ok := storedMetadata[jobApplication.ID]; ok && !req.Refresh {
+			metadata = append(metadata, workflows.JobMetadataEntry{JobId: jobApplication.ID, Metadata: jobMetadata})
+			continue
+		}
+		jobApplicationsToExtract = append(jobApplicationsToExtract, jobApplication)
+	}
+
+	response := ExtractJobMetadataResponse{
+		Message:  "No new workflows to execute",
+		Metadata: metadata,
+	}
+
+	if len(jobApplicationsToExtract) > 0 {
+		extractJobMetadataScenario := scenarios.NewExtractJobMetadataScenario(h.geminiClient, h.db, jobApplicationsToExtract)
+		newMetadata, err := extractJobMetadataScenario.Execute(context.TODO())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to extract job metadata: " + err.Error()})
+			return
+		}
+		response = ExtractJobMetadataResponse{
+			Message:  "Job metadata extraction completed",
+			Metadata: append(metadata, newMetadata...),
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetJobMetadata handles GET requests listing the stored job metadata. Supported filters are
+// ?seniority=, ?remote_policy=, ?visa_sponsorship=, ?contract_type=, ?location= (substring match)
+// and ?max_years_experience= (jobs asking for at most that many years).
+func (h *ExtractJobMetadataHandler) HandleGetJobMetadata(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	filter, err := parseJobMetadataFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	storedMetadata, err := scenarios.GetStoredJobMetadata(h.db)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job metadata: " + err.Error()})
+		return
+	}
+
+	for jobID, jobMetadata := range storedMetadata {
+		if !filter.matches(jobMetadata) {
+			delete(storedMetadata, jobID)
+		}
+	}
+
+	response := GetJobMetadataResponse{JobApplications: []MetadataJobApplication{}}
+	if len(storedMetadata) == 0 {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	jobIDs := make([]int, 0, len(storedMetadata))
+	for jobID := range storedMetadata {
+		jobIDs = append(jobIDs, jobID)
+	}
+	jobApplications, err := h.db.GetJobApplicationsById(jobIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+
+	for _, jobApplication := range jobApplications {
+		response.JobApplications = append(response.JobApplications, MetadataJobApplication{
+			JobApplicationID: jobApplication.ID,
+			JobTitle:         jobApplication.JobTitle,
+			CompanyName:      jobApplication.CompanyName,
+			Status:           jobApplication.Status,
+			Metadata:         storedMetadata[jobApplication.ID],
+		})
+	}
+	sort.Slice(response.JobApplications, func(i, j int) bool {
+		return response.JobApplications[i].JobApplicationID > response.JobApplications[j].JobApplicationID
+	})
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseJobMetadataFilter reads the filters from the query string, normalizing enumerated values the same way as the workflow
+func parseJobMetadataFilter(r *http.Request) (jobMetadataFilter, error) {
+	query := r.URL.Query()
+	filter := jobMetadataFilter{location: strings.TrimSpace(query.Get("location"))}
+
+	enumerated := []struct {
+		param   string
+		choices []string
+		target  *string
+	}{
+		{"seniority", workflows.SeniorityChoices, &filter.seniority},
+		{"remote_policy", workflows.RemotePolicyChoices, &filter.remotePolicy},
+		{"visa_sponsorship", workflows.VisaSponsorshipChoices, &filter.visaSponsorship},
+		{"contract_type", workflows.ContractTypeChoices, &filter.contractType},
+	}
+	for _, field := range enumerated {
+		value := query.Get(field.param)
+		if value == "" {
+			continue
+		}
+		normalized := workflows.NormalizeMetadataValue(value, field.choices)
+		if normalized == workflows.MetadataUnknown && !strings.EqualFold(value, workflows.MetadataUnknown) {
+			return filter, fmt.Errorf("%s must be one of: %s", field.param, strings.Join(append(slices.Clone(field.choices), workflows.MetadataUnknown), ", "))
+		}
+		*field.target = normalized
+	}
+
+	if value := query.Get("max_years_experience"); value != "" {
+		years, err := strconv.Atoi(value)
+		if err != nil || years < 0 {
+			return filter, fmt.Errorf("max_years_experience must be a non-negative number")
+		}
+		filter.maxYearsRequired = years
+		filter.hasMaxYearsFilter = true
+	}
+
+	return filter, nil
+}
+
+func (f jobMetadataFilter) matches(metadata workflows.JobMetadata) bool {
+	switch {
+	case f.seniority != "" && metadata.Seniority != f.seniority:
+		return false
+	case f.remotePolicy != "" && metadata.RemotePolicy != f.remotePolicy:
+		return false
+	case f.visaSponsorship != "" && metadata.VisaSponsorship != f.visaSponsorship:
+		return false
+	case f.contractType != "" && metadata.ContractType != f.contractType:
+		return false
+	case f.location != "" && !metadata.HasLocation(f.location):
+		return false
+	case f.hasMaxYearsFilter && metadata.YearsOfExperienceMin > f.maxYearsRequired:
+		return false
+	}
+	return true
+}
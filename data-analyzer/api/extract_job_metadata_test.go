@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"data-analyzer/agent/workflows"
+)
+
+func TestParseJobMetadataFilter(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    jobMetadataFilter
+		wantErr bool
+	}{
+		{"", jobMetadataFilter{}, false},
+		{"seniority=Senior&remote_policy=on-site", jobMetadataFilter{seniority: workflows.SenioritySenior, remotePolicy: workflows.RemotePolicyOnsite}, false},
+		{"visa_sponsorship=yes&contract_type=Full-Time", jobMetadataFilter{visaSponsorship: workflows.VisaSponsorshipAvailable, contractType: workflows.ContractTypeFullTime}, false},
+		{"seniority=unknown", jobMetadataFilter{seniority: workflows.MetadataUnknown}, false},
+		{"location=%20Berlin%20", jobMetadataFilter{location: "Berlin"}, false},
+		{"max_years_experience=0", jobMetadataFilter{maxYearsRequired: 0, hasMaxYearsFilter: true}, false},
+		{"max_years_experience=5", jobMetadataFilter{maxYearsRequired: 5, hasMaxYearsFilter: true}, false},
+		{"seniority=wizard", jobMetadataFilter{}, true},
+		{"contract_type=yes", jobMetadataFilter{}, true},
+		{"max_years_experience=-1", jobMetadataFilter{}, true},
+		{"max_years_experience=five", jobMetadataFilter{}, true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/job_applications/metadata?"+tt.query, nil)
+		got, err := parseJobMetadataFilter(r)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseJobMetadataFilter(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseJobMetadataFilter(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestJobMetadataFilterMatches(t *testing.T) {
+	metadata := workflows.JobMetadata{
+		Seniority:            workflows.SenioritySenior,
+		RemotePolicy:         workflows.RemotePolicyHybrid,
+		Locations:            []string{"Berlin, Germany", "Lisbon"},
+		VisaSponsorship:      workflows.MetadataUnknown,
+		ContractType:         workflows.ContractTypeFullTime,
+		YearsOfExperienceMin: 5,
+		YearsOfExperienceMax: 8,
+	}
+
+	tests := []struct {
+		name   string
+		filter jobMetadataFilter
+		want   bool
+	}{
+		{"no filter", jobMetadataFilter{}, true},
+		{"same seniority", jobMetadataFilter{seniority: workflows.SenioritySenior}, true},
+		{"other seniority", jobMetadataFilter{seniority: workflows.SeniorityJunior}, false},
+		{"other remote policy", jobMetadataFilter{remotePolicy: workflows.RemotePolicyRemote}, false},
+		{"unknown visa sponsorship", jobMetadataFilter{visaSponsorship: workflows.MetadataUnknown}, true},
+		{"other contract type", jobMetadataFilter{contractType: workflows.ContractTypeContract}, false},
+		{"location substring ignoring case", jobMetadataFilter{location: "germany"}, true},
+		{"other location", jobMetadataFilter{location: "Paris"}, false},
+		{"minimum equal to the maximum years", jobMetadataFilter{maxYearsRequired: 5, hasMaxYearsFilter: true}, true},
+		{"minimum above the maximum years", jobMetadataFilter{maxYearsRequired: 4, hasMaxYearsFilter: true}, false},
+		{"zero maximum years", jobMetadataFilter{maxYearsRequired: 0, hasMaxYearsFilter: true}, false},
+		{"every filter matching", jobMetadataFilter{seniority: workflows.SenioritySenior, remotePolicy: workflows.RemotePolicyHybrid, location: "lisbon", maxYearsRequired: 10, hasMaxYearsFilter: true}, true},
+		{"one filter not matching", jobMetadataFilter{seniority: workflows.SenioritySenior, location: "Paris"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.matches(metadata); got != tt.want {
+			t.Errorf("%s: matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/compensation"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/scenarios"
+)
+
+// ExtractSalaryRequest represents the request body for the extract salary endpoint
+type ExtractSalaryRequest struct {
+	JobApplicationIDs []int `json:"job_application_ids"`
+}
+
+// ExtractSalaryResponse represents the response body for the extract salary endpoint
+type ExtractSalaryResponse struct {
+	Message       string                      `json:"message"`
+	Compensations []workflows.JobCompensation `json:"compensations"`
+}
+
+// SalaryComparison is the compensation of a job application normalized to a yearly amount in the base currency
+type SalaryComparison struct {
+	JobApplicationID int                       `json:"job_application_id"`
+	JobTitle         string                    `json:"job_title"`
+	CompanyName      string                    `json:"company_name"`
+	Status           string                    `json:"status"`
+	Source           string                    `json:"source"`
+	Compensation     compensation.Compensation `json:"compensation"`
+	YearlyMin        float64                   `json:"yearly_min"`
+	YearlyMax        float64                   `json:"yearly_max"`
+	Normalized       bool                      `json:"normalized"`
+	NoNumbers        bool                      `json:"no_numbers"`
+}
+
+// GetSalariesResponse represents the response body for the salaries endpoint
+type GetSalariesResponse struct {
+	BaseCurrency    string             `json:"base_currency"`
+	JobApplications []SalaryComparison `json:"job_applications"`
+}
+
+type ExtractSalaryHandler struct {
+	cfg          *config.Config
+	db           *db.DB
+	geminiClient *agent.Client
+}
+
+func NewExtractSalaryHandler(cfg *config.Config, db *db.DB, geminiClient *agent.Client) *ExtractSalaryHandler {
+	return &ExtractSalaryHandler{
+		cfg:          cfg,
+		db:           db,
+		geminiClient: geminiClient,
+	}
+}
+
+// HandleExtractSalary handles POST requests to extract the compensation of job applications
+func (h *ExtractSalaryHandler) HandleExtractSalary(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req ExtractSalaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	// Validate that job_application_ids is not empty
+	if len(req.JobApplicationIDs) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_ids cannot be empty"})
+		return
+	}
+
+	jobApplications, err := h.db.GetJobApplicationsById(req.JobApplicationIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+
+	extractSalaryScenario := scenarios.NewExtractSalaryScenario(h.geminiClient, h.db, jobApplications)
+	compensations, err := extractSalaryScenario.Execute(context.TODO())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to extract salary: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ExtractSalaryResponse{
+		Message:       "Salary extraction completed",
+		Compensations: compensations,
+	})
+}
+
+// HandleGetSalaries handles GET requests comparing the compensation of all job applications.
+// With ?no_numbers=true only postings that mention pay without any amount are returned.
+func (h *ExtractSalaryHandler) HandleGetSalaries(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	jobApplications, err := h.db.GetAllJobApplications()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+
+	compensations, err := scenarios.ResolveCompensations(h.db, jobApplications)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get salaries: " + err.Error()})
+		return
+	}
+
+	onlyNoNumbers := r.URL.Query().Get("no_numbers") == "true"
+	rates := compensation.RateTable{BaseCurrency: h.cfg.BaseCurrency, Rates: h.cfg.CurrencyRates}
+	response := GetSalariesResponse{
+		BaseCurrency:    h.cfg.BaseCurrency,
+		JobApplications: []SalaryComparison{},
+	}
+	for _, jobApplication := range jobApplications {
+		jobCompensation := compensations[jobApplication.ID]
+		comparison := SalaryComparison{
+			JobApplicationID: jobApplication.ID,
+			JobTitle:         jobApplication.JobTitle,
+			CompanyName:      jobApplication.CompanyName,
+			Status:           jobApplication.Status,
+			Source:           jobCompensation.Source,
+			Compensation:     jobCompensation.Compensation,
+			NoNumbers:        jobCompensation.Compensation.NoNumbers(),
+		}
+		if onlyNoNumbers && !comparison.NoNumbers {
+			continue
+		}
+		comparison.YearlyMin, comparison.YearlyMax, comparison.Normalized = rates.Yearly(jobCompensation.Compensation)
+		response.JobApplications = append(response.JobApplications, comparison)
+	}
+
+	// highest paying first, postings without amounts last
+	sort.SliceStable(response.JobApplications, func(i, j int) bool {
+		return response.JobApplications[i].YearlyMax > response.JobApplications[j].YearlyMax
+	})
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"data-analyzer/scenarios"
+)
+
+// ExtractTechStackRequest represents the request body for the extract tech stack endpoint
+type ExtractTechStackRequest struct {
+	JobApplicationIDs []int `json:"job_application_ids"`
+}
+
+// ExtractTechStackResponse represents the response body for the extract tech stack endpoint
+type ExtractTechStackResponse struct {
+	Message    string                   `json:"message"`
+	TechStacks []workflows.JobTechStack `json:"tech_stacks"`
+}
+
+// TechStackJobApplication is a job application together with its stored tech stack
+type TechStackJobApplication struct {
+	JobApplicationID int                 `json:"job_application_id"`
+	JobTitle         string              `json:"job_title"`
+	CompanyName      string              `json:"company_name"`
+	TechStack        workflows.TechStack `json:"tech_stack"`
+}
+
+// GetTechStackResponse represents the response body for the tech stack query endpoint
+type GetTechStackResponse struct {
+	Technology      string                    `json:"technology,omitempty"`
+	Category        string                    `json:"category,omitempty"`
+	JobApplications []TechStackJobApplication `json:"job_applications"`
+}
+
+type ExtractTechStackHandler struct {
+	db           *db.DB
+	geminiClient *agent.Client
+	taxonomy     *workflows.TechTaxonomy
+}
+
+func NewExtractTechStackHandler(db *db.DB, geminiClient *agent.Client, taxonomy *workflows.TechTaxonomy) *ExtractTechStackHandler {
+	return &ExtractTechStackHandler{
+		db:           db,
+		geminiClient: geminiClient,
+		taxonomy:     taxonomy,
+	}
+}
+
+// HandleExtractTechStack handles POST requests to extract the tech stack of job applications
+func (h *ExtractTechStackHandler) HandleExtractTechStack(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req ExtractTechStackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	// Validate that job_application_ids is not empty
+	if len(req.JobApplicationIDs) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_ids cannot be empty"})
+		return
+	}
+
+	jobApplications, err := h.db.GetJobApplicationsById(req.JobApplicationIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+
+	existingWorkflows, err := h.db.GetWorkflowsByName("extract_tech_stack")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get workflows: " + err.Error()})
+		return
+	}
+
+	jobApplicationsWithoutExistingWorkflows := make([]models.JobApplication, 0)
+
+	// Check if any of the job applications already have a workflow of type extract_tech_stack
+	for _, jobApplication := range jobApplications {
+		workflowExists := false
+		for _, workflow := range existingWorkflows {
+			var workflowParameters models.WorkflowParameters
+			if err := json.Unmarshal([]byte(workflow.Parameters), &workflowParameters); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to unmarshal job_ids: " + err.Error()})
+				return
+			}
+			if slices.Contains(workflowParameters.JobIds, jobApplication.ID) {
+				workflowExists = true
+			}
+		}
+		if !workflowExists {
+			jobApplicationsWithoutExistingWorkflows = append(jobApplicationsWithoutExistingWorkflows, jobApplication)
+		}
+	}
+
+	response := ExtractTechStackResponse{
+		Message:    "No new workflows to execute",
+		TechStacks: nil,
+	}
+
+	if len(jobApplicationsWithoutExistingWorkflows) > 0 {
+		extractTechStackScenario := scenarios.NewExtractTechStackScenario(h.geminiClient, h.db, h.taxonomy, jobApplicationsWithoutExistingWorkflows)
+		techStacks, err := extractTechStackScenario.Execute(context.TODO())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to extract tech stack: " + err.Error()})
+			return
+		}
+		response = ExtractTechStackResponse{
+			Message:    "Success",
+			TechStacks: techStacks,
+		}
+	} else {
+		fmt.Println("No new workflows to execute")
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetTechStack handles GET requests listing the stored tech stacks, optionally filtered by ?technology=
+func (h *ExtractTechStackHandler) HandleGetTechStack(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	techStacks, err := scenarios.GetStoredTechStacks(h.db)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get tech stacks: " + err.Error()})
+		return
+	}
+
+	response := GetTechStackResponse{JobApplications: []TechStackJobApplication{}}
+
+	// Normalize the queried technology so that aliases such as k8s or golang match
+	technology := strings.TrimSpace(r.URL.Query().Get("technology"))
+	if technology != "" {
+		normalized := h.taxonomy.Normalize(technology, "")
+		response.Technology = normalized.Name
+		if _, ok := h.taxonomy.Lookup(technology); ok {
+			response.Category = normalized.Category
+		}
+		for jobID, techStack := range techStacks {
+			if !techStack.Contains(normalized.Name) {
+				delete(techStacks, jobID)
+			}
+		}
+	}
+
+	if len(techStacks) == 0 {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	jobIDs := make([]int, 0, len(techStacks))
+	for jobID := range techStacks {
+		jobIDs = append(jobIDs, jobID)
+	}
+	jobApplications, err := h.db.GetJobApplicationsById(jobIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+
+	for _, jobApplication := range jobApplications {
+		response.JobApplications = append(response.JobApplications, TechStackJobApplication{
+			JobApplicationID: jobApplication.ID,
+			JobTitle:         jobApplication.JobTitle,
+			CompanyName:      jobApplication.CompanyName,
+			TechStack:        techStacks[jobApplication.ID],
+		})
+	}
+	sort.Slice(response.JobApplications, func(i, j int) bool {
+		return response.JobApplications[i].JobApplicationID > response.JobApplications[j].JobApplicationID
+	})
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"data-analyzer/scenarios"
+)
+
+// GetDuplicatesResponse represents the response body for the duplicates endpoint
+type GetDuplicatesResponse struct {
+	Clusters []scenarios.DuplicateCluster `json:"clusters"`
+}
+
+// CheckDuplicatesRequest represents the request body for the duplicate check endpoint.
+// Either an existing job_application_id or the posting itself must be given.
+type CheckDuplicatesRequest struct {
+	JobApplicationID int    `json:"job_application_id"`
+	JobTitle         string `json:"job_title"`
+	JobDescription   string `json:"job_description"`
+	CompanyURL       string `json:"company_url"`
+}
+
+// CheckDuplicatesResponse represents the response body for the duplicate check endpoint
+type CheckDuplicatesResponse struct {
+	Duplicates []scenarios.DuplicateMatch `json:"duplicates"`
+	Warning    string                     `json:"warning,omitempty"`
+}
+
+type FindDuplicatesHandler struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewFindDuplicatesHandler(cfg *config.Config, db *db.DB) *FindDuplicatesHandler {
+	return &FindDuplicatesHandler{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// HandleGetDuplicates handles GET requests listing the clusters of near-duplicate job applications
+func (h *FindDuplicatesHandler) HandleGetDuplicates(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	clusters, err := scenarios.NewFindDuplicatesScenario(h.cfg, h.db).Execute()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to find duplicates: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(GetDuplicatesResponse{Clusters: clusters})
+}
+
+// HandleCheckDuplicates handles POST requests checking whether a job closely matches an existing one
+func (h *FindDuplicatesHandler) HandleCheckDuplicates(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req CheckDuplicatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	jobApplication := models.JobApplication{
+		JobTitle:       req.JobTitle,
+		JobDescription: req.JobDescription,
+		CompanyURL:     req.CompanyURL,
+	}
+	if req.JobApplicationID > 0 {
+		jobApplications, err := h.db.GetJobApplicationsById([]int{req.JobApplicationID})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+			return
+		}
+		if len(jobApplications) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Job application not found"})
+			return
+		}
+		jobApplication = jobApplications[0]
+	}
+
+	if strings.TrimSpace(jobApplication.JobDescription) == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_id or job_description is required"})
+		return
+	}
+
+	matches, err := scenarios.NewFindDuplicatesScenario(h.cfg, h.db).Check(jobApplication)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to check duplicates: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(CheckDuplicatesResponse{
+		Duplicates: matches,
+		Warning:    scenarios.DuplicateWarning(matches),
+	})
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"data-analyzer/analytics"
+	"data-analyzer/db"
+	"data-analyzer/scenarios"
+)
+
+// FunnelAnalyticsResponse represents the response body for the application funnel
+type FunnelAnalyticsResponse struct {
+	Message string           `json:"message"`
+	Report  analytics.Report `json:"report"`
+}
+
+type FunnelAnalyticsHandler struct {
+	db *db.DB
+}
+
+func NewFunnelAnalyticsHandler(db *db.DB) *FunnelAnalyticsHandler {
+	return &FunnelAnalyticsHandler{
+		db: db,
+	}
+}
+
+// HandleFunnel handles GET requests for the conversion from Applied to an interview to an offer and the
+// median time to the first response. ?by= takes a comma separated list of source, resume_version,
+// role_family and month, all of them by default; ?format=csv returns CSV instead of JSON.
+func (h *FunnelAnalyticsHandler) HandleFunnel(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "format must be json or csv"})
+		return
+	}
+
+	dimensions := analytics.ParseDimensions(r.URL.Query().Get("by"))
+	report, err := scenarios.NewFunnelAnalyticsScenario(h.db).Execute(dimensions)
+	if err != nil {
+		if errors.Is(err, scenarios.ErrInvalidFunnelDimension) {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to compute funnel: " + err.Error()})
+		return
+	}
+
+	if format == "csv" {
+		var buffer bytes.Buffer
+		if err := analytics.WriteCSV(&buffer, report); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to write CSV: " + err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="funnel.csv"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write(buffer.Bytes())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(FunnelAnalyticsResponse{Message: "Success", Report: report})
+}
@@ -117,6 +117,7 @@ func (h *GenerateCoverLetterHandler) HandleGenerateCoverLetter(w http.ResponseWr
 			generateCoverLetterWorkflow := agentWorkflows.NewGenerateCoverLetterWorkflow(h.geminiClient, h.db)
 			coverLetter, err := generateCoverLetterWorkflow.Execute(context.TODO(), jobApplication, req.CoverLetterInputs[i])
 			if err != nil {
+				agentWorkflows.RecordWorkflowFailure(h.db, "generate_cover_letter", []int{jobApplication.ID}, err)
 				w.WriteHeader(http.StatusInternalServerError)
 				json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to generate cover letter: " + err.Error()})
 				return
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"data-analyzer/db"
+	"data-analyzer/importer"
+	"data-analyzer/models"
+)
+
+// ImportJobApplicationsRequest represents the request body for the bulk import endpoint.
+// Content is the CSV or JSON file itself; the format is detected when it is not given.
+type ImportJobApplicationsRequest struct {
+	Format      string           `json:"format"`
+	Content     string           `json:"content"`
+	Mapping     importer.Mapping `json:"mapping"`
+	DryRun      bool             `json:"dry_run"`
+	SkipInvalid bool             `json:"skip_invalid"`
+}
+
+// ImportJobApplicationsResponse represents the response body for the bulk import endpoint
+type ImportJobApplicationsResponse struct {
+	models.ImportSummary
+	Error string `json:"error,omitempty"`
+}
+
+type ImportJobApplicationsHandler struct {
+	db *db.DB
+}
+
+func NewImportJobApplicationsHandler(db *db.DB) *ImportJobApplicationsHandler {
+	return &ImportJobApplicationsHandler{
+		db: db,
+	}
+}
+
+// HandleImportJobApplications handles POST requests importing job applications from a CSV or JSON file
+func (h *ImportJobApplicationsHandler) HandleImportJobApplications(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req ImportJobApplicationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	if strings.TrimSpace(req.Content) == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "content is required"})
+		return
+	}
+
+	content := []byte(req.Content)
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = importer.DetectFormat("", content)
+	}
+
+	rows, err := importer.Parse(format, content, req.Mapping)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to read import: " + err.Error()})
+		return
+	}
+
+	summary, err := h.db.ImportJobApplications(rows, models.ImportOptions{
+		DryRun:      req.DryRun,
+		SkipInvalid: req.SkipInvalid,
+		Origin:      format + " import",
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidImportRows) {
+			// the summary lists the errors of every invalid row
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(ImportJobApplicationsResponse{ImportSummary: summary, Error: err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to import job applications: " + err.Error()})
+		return
+	}
+
+	if req.DryRun {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(ImportJobApplicationsResponse{ImportSummary: summary})
+}
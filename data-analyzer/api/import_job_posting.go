@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"data-analyzer/agent"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/jobposting"
+	"data-analyzer/scenarios"
+)
+
+// ImportJobPostingRequest represents the request body for the import URL endpoint
+type ImportJobPostingRequest struct {
+	URL           string `json:"url"`
+	Status        string `json:"status"`
+	Source        string `json:"source"`
+	ResumeVersion string `json:"resume_version"`
+	DryRun        bool   `json:"dry_run"`
+}
+
+type ImportJobPostingHandler struct {
+	cfg          *config.Config
+	db           *db.DB
+	geminiClient *agent.Client
+	httpClient   *http.Client
+}
+
+func NewImportJobPostingHandler(cfg *config.Config, db *db.DB, geminiClient *agent.Client, httpClient *http.Client) *ImportJobPostingHandler {
+	return &ImportJobPostingHandler{
+		cfg:          cfg,
+		db:           db,
+		geminiClient: geminiClient,
+		httpClient:   httpClient,
+	}
+}
+
+// HandleImportJobPosting handles POST requests creating a job application from a posting URL
+func (h *ImportJobPostingHandler) HandleImportJobPosting(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req ImportJobPostingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	if strings.TrimSpace(req.URL) == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "url is required"})
+		return
+	}
+
+	scenario := scenarios.NewImportJobPostingScenario(h.cfg, h.geminiClient, h.db, h.httpClient)
+	result, err := scenario.Execute(r.Context(), req.URL, scenarios.ImportJobPostingOptions{
+		Status:        req.Status,
+		Source:        req.Source,
+		ResumeVersion: req.ResumeVersion,
+		DryRun:        req.DryRun,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, jobposting.ErrInvalidURL), errors.Is(err, scenarios.ErrInvalidImportOptions):
+			status = http.StatusBadRequest
+		case errors.Is(err, jobposting.ErrFetch):
+			status = http.StatusBadGateway
+		case errors.Is(err, scenarios.ErrIncompletePosting):
+			status = http.StatusUnprocessableEntity
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to import job posting: " + err.Error()})
+		return
+	}
+
+	if req.DryRun {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"data-analyzer/agent"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/emails"
+	"data-analyzer/models"
+	"data-analyzer/scenarios"
+)
+
+// IngestEmailsRequest represents the request body for the email ingestion endpoint.
+// Content is a single .eml message or an mbox; the file name only helps to tell them apart.
+type IngestEmailsRequest struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	DryRun  bool   `json:"dry_run"`
+}
+
+// GetEmailsResponse represents the response body for the email list endpoint
+type GetEmailsResponse struct {
+	Emails []models.Email `json:"emails"`
+}
+
+// ReviewEmailRequest represents the request body for the email review endpoint.
+// The job application and status are optional and override the match and the proposal.
+type ReviewEmailRequest struct {
+	ID               int    `json:"id"`
+	Action           string `json:"action"`
+	JobApplicationID int    `json:"job_application_id"`
+	Status           string `json:"status"`
+}
+
+// ReviewEmailResponse represents the response body for the email review endpoint
+type ReviewEmailResponse struct {
+	Email models.Email `json:"email"`
+}
+
+type IngestEmailsHandler struct {
+	cfg          *config.Config
+	db           *db.DB
+	geminiClient *agent.Client
+}
+
+func NewIngestEmailsHandler(cfg *config.Config, db *db.DB, geminiClient *agent.Client) *IngestEmailsHandler {
+	return &IngestEmailsHandler{
+		cfg:          cfg,
+		db:           db,
+		geminiClient: geminiClient,
+	}
+}
+
+// HandleIngestEmails handles POST requests ingesting recruiter emails
+func (h *IngestEmailsHandler) HandleIngestEmails(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req IngestEmailsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	if strings.TrimSpace(req.Content) == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "content is required"})
+		return
+	}
+
+	messages, err := emails.Parse(req.Name, []byte(req.Content))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to read emails: " + err.Error()})
+		return
+	}
+
+	result, err := scenarios.NewIngestEmailsScenario(h.cfg, h.geminiClient, h.db).Execute(r.Context(), messages, req.DryRun)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to ingest emails: " + err.Error()})
+		return
+	}
+
+	if req.DryRun {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// HandleGetEmails handles GET requests listing ingested emails. The comma separated state parameter
+// filters them and defaults to the review queue: emails to review and proposed status updates.
+// Use state=all to list every email.
+func (h *IngestEmailsHandler) HandleGetEmails(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	states := []string{models.EmailStateReview, models.EmailStateProposed}
+	if state := r.URL.Query().Get("state"); state == "all" {
+		states = nil
+	} else if state != "" {
+		states = strings.Split(state, ",")
+		for _, state := range states {
+			if !slices.Contains(models.EmailStates, state) {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "state must be all or one of " + strings.Join(models.EmailStates, ", ")})
+				return
+			}
+		}
+	}
+
+	list, err := h.db.GetEmails(states)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get emails: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(GetEmailsResponse{Emails: list})
+}
+
+// HandleReviewEmail handles POST requests approving or dismissing an email of the review queue
+func (h *IngestEmailsHandler) HandleReviewEmail(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req ReviewEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	email, err := scenarios.NewIngestEmailsScenario(h.cfg, h.geminiClient, h.db).Review(req.ID, scenarios.ReviewEmailOptions{
+		Action:           req.Action,
+		JobApplicationID: req.JobApplicationID,
+		Status:           req.Status,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrEmailNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, scenarios.ErrInvalidEmailReview):
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to review email: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ReviewEmailResponse{Email: email})
+}
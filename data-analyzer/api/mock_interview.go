@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/scenarios"
+)
+
+// StartMockInterviewRequest represents the request body for starting a mock interview
+type StartMockInterviewRequest struct {
+	JobApplicationID int `json:"job_application_id"`
+	// Questions is the number of questions, MOCK_INTERVIEW_QUESTIONS when not set
+	Questions int `json:"questions"`
+}
+
+// AnswerMockInterviewRequest represents the request body for answering the pending question of a mock interview
+type AnswerMockInterviewRequest struct {
+	SessionID int    `json:"session_id"`
+	Answer    string `json:"answer"`
+}
+
+// FinishMockInterviewRequest represents the request body for ending a mock interview early
+type FinishMockInterviewRequest struct {
+	SessionID int `json:"session_id"`
+}
+
+// MockInterviewResponse represents the response body for the endpoints that move a mock interview forward
+type MockInterviewResponse struct {
+	Message string                         `json:"message"`
+	Session workflows.MockInterviewSession `json:"session"`
+}
+
+// MockInterviewsResponse represents the response body for listing mock interviews
+type MockInterviewsResponse struct {
+	Message  string                           `json:"message"`
+	Rubric   []workflows.RubricCriterion      `json:"rubric"`
+	Sessions []workflows.MockInterviewSession `json:"sessions"`
+}
+
+type MockInterviewHandler struct {
+	cfg          *config.Config
+	db           *db.DB
+	geminiClient *agent.Client
+}
+
+func NewMockInterviewHandler(cfg *config.Config, db *db.DB, geminiClient *agent.Client) *MockInterviewHandler {
+	return &MockInterviewHandler{
+		cfg:          cfg,
+		db:           db,
+		geminiClient: geminiClient,
+	}
+}
+
+// HandleMockInterview handles POST requests to start a mock interview and GET requests to list the sessions
+func (h *MockInterviewHandler) HandleMockInterview(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodPost:
+		h.startMockInterview(w, r)
+	case http.MethodGet:
+		h.getMockInterviews(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET or POST."})
+	}
+}
+
+func (h *MockInterviewHandler) startMockInterview(w http.ResponseWriter, r *http.Request) {
+	// Parse the JSON request body
+	var req StartMockInterviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	jobApplications, err := h.db.GetJobApplicationsById([]int{req.JobApplicationID})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+	if len(jobApplications) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Job application not found"})
+		return
+	}
+
+	session, err := scenarios.NewMockInterviewScenario(h.cfg, h.geminiClient, h.db).Start(context.TODO(), jobApplications[0], req.Questions)
+	if err != nil {
+		writeMockInterviewError(w, "Failed to start mock interview: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(MockInterviewResponse{Message: "Mock interview started", Session: session})
+}
+
+func (h *MockInterviewHandler) getMockInterviews(w http.ResponseWriter, r *http.Request) {
+	response := MockInterviewsResponse{
+		Message:  "Success",
+		Rubric:   workflows.MockInterviewRubric,
+		Sessions: []workflows.MockInterviewSession{},
+	}
+
+	// Return a single session when ?session_id= is set, otherwise the sessions of ?job_application_id= or of all job applications
+	if value := r.URL.Query().Get("session_id"); value != "" {
+		sessionID, err := strconv.Atoi(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "session_id must be a number"})
+			return
+		}
+		sessions, err := scenarios.GetStoredMockInterviews(h.db)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get workflows: " + err.Error()})
+			return
+		}
+		session, ok := sessions[sessionID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Mock interview not found"})
+			return
+		}
+		response.Sessions = append(response.Sessions, session)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	jobApplicationID := 0
+	if value := r.URL.Query().Get("job_application_id"); value != "" {
+		var err error
+		if jobApplicationID, err = strconv.Atoi(value); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_id must be a number"})
+			return
+		}
+	}
+	history, err := scenarios.GetMockInterviewHistory(h.db, jobApplicationID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get workflows: " + err.Error()})
+		return
+	}
+	response.Sessions = history
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleAnswer handles POST requests answering the pending question of a mock interview
+func (h *MockInterviewHandler) HandleAnswer(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req AnswerMockInterviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	session, err := scenarios.NewMockInterviewScenario(h.cfg, h.geminiClient, h.db).Answer(context.TODO(), req.SessionID, req.Answer)
+	if err != nil {
+		writeMockInterviewError(w, "Failed to answer mock interview: ", err)
+		return
+	}
+
+	message := "Answer recorded"
+	if session.State == workflows.MockInterviewCompleted {
+		message = "Mock interview completed"
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(MockInterviewResponse{Message: message, Session: session})
+}
+
+// HandleFinish handles POST requests ending a mock interview before the last question and grading it
+func (h *MockInterviewHandler) HandleFinish(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	// Parse the JSON request body
+	var req FinishMockInterviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	session, err := scenarios.NewMockInterviewScenario(h.cfg, h.geminiClient, h.db).Finish(context.TODO(), req.SessionID)
+	if err != nil {
+		writeMockInterviewError(w, "Failed to finish mock interview: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(MockInterviewResponse{Message: "Mock interview completed", Session: session})
+}
+
+func writeMockInterviewError(w http.ResponseWriter, prefix string, err error) {
+	switch {
+	case errors.Is(err, scenarios.ErrMockInterviewNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, scenarios.ErrMockInterviewCompleted):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, scenarios.ErrInvalidMockInterview):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(ErrorResponse{Error: prefix + err.Error()})
+}
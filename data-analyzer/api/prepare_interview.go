@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"data-analyzer/scenarios"
+)
+
+// PrepareInterviewRequest represents the request body for the prepare interview endpoint
+type PrepareInterviewRequest struct {
+	JobApplicationIDs []int `json:"job_application_ids"`
+	// Refresh prepares again even the job applications already prepared for their current status
+	Refresh bool `json:"refresh"`
+}
+
+// PrepareInterviewResponse represents the response body for the prepare interview endpoints
+type PrepareInterviewResponse struct {
+	Message      string                           `json:"message"`
+	Preparations []workflows.InterviewPreparation `json:"preparations"`
+}
+
+type PrepareInterviewHandler struct {
+	cfg          *config.Config
+	db           *db.DB
+	geminiClient *agent.Client
+}
+
+func NewPrepareInterviewHandler(cfg *config.Config, db *db.DB, geminiClient *agent.Client) *PrepareInterviewHandler {
+	return &PrepareInterviewHandler{
+		cfg:          cfg,
+		db:           db,
+		geminiClient: geminiClient,
+	}
+}
+
+// HandlePrepareInterview handles POST requests to prepare interviews and GET requests to read or export the stored preparations
+func (h *PrepareInterviewHandler) HandlePrepareInterview(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodPost:
+		h.runPrepareInterview(w, r)
+	case http.MethodGet:
+		h.getPrepareInterview(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET or POST."})
+	}
+}
+
+func (h *PrepareInterviewHandler) runPrepareInterview(w http.ResponseWriter, r *http.Request) {
+	// Parse the JSON request body
+	var req PrepareInterviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	// Validate that job_application_ids is not empty
+	if len(req.JobApplicationIDs) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_ids cannot be empty"})
+		return
+	}
+
+	jobApplications, err := h.db.GetJobApplicationsById(req.JobApplicationIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+
+	storedPreparations, err := scenarios.GetStoredInterviewPreparations(h.db)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get workflows: " + err.Error()})
+		return
+	}
+
+	// Only prepare job applications without a preparation for their current status, unless a refresh was requested
+	jobApplicationsToPrepare := make([]models.JobApplication, 0)
+	preparations := make([]workflows.InterviewPreparation, 0)
+	for _, jobApplication := range jobApplications {
+		if preparation, ok := storedPreparations[jobApplication.ID]; ok && preparation.Status == jobApplication.Status && !req.Refresh {
+			preparations = append(preparations, preparation)
+			continue
+		}
+		jobApplicationsToPrepare = append(jobApplicationsToPrepare, jobApplication)
+	}
+
+	response := PrepareInterviewResponse{
+		Message:      "No new workflows to execute",
+		Preparations: preparations,
+	}
+
+	if len(jobApplicationsToPrepare) > 0 {
+		prepareInterviewScenario := scenarios.NewPrepareInterviewScenario(h.cfg, h.geminiClient, h.db)
+		newPreparations, err := prepareInterviewScenario.Execute(context.TODO(), jobApplicationsToPrepare)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to prepare interview: " + err.Error()})
+			return
+		}
+		response = PrepareInterviewResponse{
+			Message:      "Interview preparation completed",
+			Preparations: append(preparations, newPreparations...),
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *PrepareInterviewHandler) getPrepareInterview(w http.ResponseWriter, r *http.Request) {
+	storedPreparations, err := scenarios.GetStoredInterviewPreparations(h.db)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get workflows: " + err.Error()})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "markdown" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "format must be json or markdown"})
+		return
+	}
+
+	response := PrepareInterviewResponse{
+		Message:      "Success",
+		Preparations: []workflows.InterviewPreparation{},
+	}
+
+	// Return a single job application when ?job_application_id= is set, otherwise all of them, most recent first
+	value := r.URL.Query().Get("job_application_id")
+	if value == "" {
+		if format == "markdown" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_id is required to export as markdown"})
+			return
+		}
+		for _, preparation := range storedPreparations {
+			response.Preparations = append(response.Preparations, preparation)
+		}
+		sort.Slice(response.Preparations, func(i, j int) bool {
+			return response.Preparations[i].PreparedAt.After(response.Preparations[j].PreparedAt)
+		})
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	jobApplicationID, err := strconv.Atoi(value)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_id must be a number"})
+		return
+	}
+	preparation, ok := storedPreparations[jobApplicationID]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "No interview preparation found for this job application"})
+		return
+	}
+
+	if format == "markdown" {
+		jobApplications, err := h.db.GetJobApplicationsById([]int{jobApplicationID})
+		if err != nil || len(jobApplications) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Failed to get job application %d: %v", jobApplicationID, err)})
+			return
+		}
+		achievements, err := h.db.GetAllWorkAchievements()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get work achievements: " + err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="interview-preparation-%d.md"`, jobApplicationID))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(scenarios.InterviewPreparationMarkdown(jobApplications[0], preparation, achievements)))
+		return
+	}
+
+	response.Preparations = append(response.Preparations, preparation)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
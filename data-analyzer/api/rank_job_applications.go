@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/scenarios"
+)
+
+// RankJobApplicationsResponse represents the response body for the ranked job applications endpoint
+type RankJobApplicationsResponse struct {
+	GeneratedAt     time.Time                        `json:"generated_at"`
+	Weights         config.RankingWeights            `json:"weights"`
+	JobApplications []scenarios.RankedJobApplication `json:"job_applications"`
+}
+
+type RankJobApplicationsHandler struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewRankJobApplicationsHandler(cfg *config.Config, db *db.DB) *RankJobApplicationsHandler {
+	return &RankJobApplicationsHandler{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// HandleRankJobApplications handles GET requests to rank the job applications that are being prepared
+func (h *RankJobApplicationsHandler) HandleRankJobApplications(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	now := time.Now()
+	rankScenario := scenarios.NewRankJobApplicationsScenario(h.cfg, h.db)
+	ranked, err := rankScenario.Execute(now)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to rank job applications: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RankJobApplicationsResponse{
+		GeneratedAt:     now,
+		Weights:         h.cfg.RankingWeights,
+		JobApplications: ranked,
+	})
+}
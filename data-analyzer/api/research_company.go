@@ -3,39 +3,50 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"slices"
 
 	"data-analyzer/agent"
-	agentWorkflows "data-analyzer/agent/workflows"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/config"
 	"data-analyzer/db"
-	"data-analyzer/models"
+	"data-analyzer/scenarios"
 )
 
 // ResearchCompanyRequest represents the request body for the research company endpoint
 type ResearchCompanyRequest struct {
 	JobApplicationIDs []int `json:"job_application_ids"`
+	// Refresh researches the companies again even when their cached research has not expired
+	Refresh bool `json:"refresh"`
+	// Mode is "web" to research with Google Search, the default, or "documents" to research from the
+	// documents added for the companies
+	Mode string `json:"mode"`
 }
 
 // ResearchCompanyResponse represents the response body for the research company endpoint
 type ResearchCompanyResponse struct {
-	Message         string                           `json:"message"`
-	CompanyResearch []agentWorkflows.ResearchCompany `json:"company_research"`
+	Message         string                            `json:"message"`
+	CompanyResearch []scenarios.CompanyResearchResult `json:"company_research"`
 }
 
 type ResearchCompanyHandler struct {
+	cfg          *config.Config
 	db           *db.DB
 	geminiClient *agent.Client
+	embedder     agent.Embedder
 }
 
-func NewResearchCompanyHandler(db *db.DB, geminiClient *agent.Client) *ResearchCompanyHandler {
+func NewResearchCompanyHandler(cfg *config.Config, db *db.DB, geminiClient *agent.Client, embedder agent.Embedder) *ResearchCompanyHandler {
 	return &ResearchCompanyHandler{
+		cfg:          cfg,
 		db:           db,
 		geminiClient: geminiClient,
+		embedder:     embedder,
 	}
 }
 
-// HandleResearchCompany handles POST requests to research companies
+// HandleResearchCompany handles POST requests to research companies, on the web reusing the cached research of
+// each company, or from the documents added for them
 func (h *ResearchCompanyHandler) HandleResearchCompany(w http.ResponseWriter, r *http.Request) {
 	// Set JSON content type for all responses
 	w.Header().Set("Content-Type", "application/json")
@@ -69,56 +80,35 @@ func (h *ResearchCompanyHandler) HandleResearchCompany(w http.ResponseWriter, r
 		return
 	}
 
-	workflows, err := h.db.GetAllWorkflows()
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get workflows: " + err.Error()})
+	var results []scenarios.CompanyResearchResult
+	switch req.Mode {
+	case "", workflows.ResearchModeWeb:
+		results, err = scenarios.NewResearchCompanyScenario(h.cfg, h.geminiClient, h.db).Execute(context.TODO(), jobApplications, req.Refresh)
+	case workflows.ResearchModeDocuments:
+		results, err = scenarios.NewResearchDocumentsScenario(h.cfg, h.geminiClient, h.db, h.embedder).Execute(context.TODO(), jobApplications)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "mode must be \"web\" or \"documents\""})
 		return
 	}
-
-	jobApplicationsWithoutExistingWorkflows := make([]models.JobApplication, 0)
-
-	// Check if any of the job applications already have a workflow of type research_company
-	for _, jobApplication := range jobApplications {
-		workflowExists := false
-		for _, workflow := range workflows {
-			if workflow.WorkflowName == "research_company" {
-				var workflowParameters models.WorkflowParameters
-				if err := json.Unmarshal([]byte(workflow.Parameters), &workflowParameters); err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
-					json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to unmarshal job_ids: " + err.Error()})
-					return
-				}
-				if slices.Contains(workflowParameters.JobIds, jobApplication.ID) {
-					workflowExists = true
-				}
-			}
-		}
-		if !workflowExists {
-			jobApplicationsWithoutExistingWorkflows = append(jobApplicationsWithoutExistingWorkflows, jobApplication)
+	if err != nil {
+		if errors.Is(err, scenarios.ErrNoResearchDocuments) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
 		}
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to research company: " + err.Error()})
+		return
 	}
 
 	response := ResearchCompanyResponse{
 		Message:         "No new workflows to execute",
-		CompanyResearch: nil,
+		CompanyResearch: results,
 	}
-
-	if len(jobApplicationsWithoutExistingWorkflows) > 0 {
-		companyResearch := make([]agentWorkflows.ResearchCompany, 0)
-		for _, jobApplication := range jobApplicationsWithoutExistingWorkflows {
-			researchCompanyWorkflow := agentWorkflows.NewResearchCompanyWorkflow(h.geminiClient, h.db)
-			research, err := researchCompanyWorkflow.Execute(context.TODO(), jobApplication)
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to research company: " + err.Error()})
-				return
-			}
-			companyResearch = append(companyResearch, research)
-		}
-		response = ResearchCompanyResponse{
-			Message:         "Company research completed",
-			CompanyResearch: companyResearch,
+	for _, result := range results {
+		if !result.Cached {
+			response.Message = "Company research completed"
+			break
 		}
 	}
 
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"data-analyzer/agent"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"data-analyzer/scenarios"
+)
+
+// AddResearchDocumentRequest represents the request body for adding a document about the company of a job application.
+// Content is the text of an HTML page or note; binary files such as PDFs are sent in ContentBase64 instead.
+type AddResearchDocumentRequest struct {
+	JobApplicationID int    `json:"job_application_id"`
+	Name             string `json:"name"`
+	// Format is "html", "pdf" or "text", detected from the name and content when not given
+	Format        string `json:"format"`
+	Content       string `json:"content"`
+	ContentBase64 string `json:"content_base64"`
+}
+
+// ResearchDocumentResponse represents the response body for adding or getting a single research document
+type ResearchDocumentResponse struct {
+	Message  string                  `json:"message"`
+	Document models.ResearchDocument `json:"document"`
+}
+
+// ResearchDocumentsResponse represents the response body for listing research documents
+type ResearchDocumentsResponse struct {
+	Message   string                    `json:"message"`
+	Documents []models.ResearchDocument `json:"documents"`
+}
+
+type ResearchDocumentsHandler struct {
+	cfg          *config.Config
+	db           *db.DB
+	geminiClient *agent.Client
+	embedder     agent.Embedder
+}
+
+func NewResearchDocumentsHandler(cfg *config.Config, db *db.DB, geminiClient *agent.Client, embedder agent.Embedder) *ResearchDocumentsHandler {
+	return &ResearchDocumentsHandler{
+		cfg:          cfg,
+		db:           db,
+		geminiClient: geminiClient,
+		embedder:     embedder,
+	}
+}
+
+// HandleResearchDocuments handles POST requests adding a research document, GET requests listing them
+// and DELETE requests removing one
+func (h *ResearchDocumentsHandler) HandleResearchDocuments(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodPost:
+		h.addDocument(w, r)
+	case http.MethodGet:
+		h.getDocuments(w, r)
+	case http.MethodDelete:
+		h.deleteDocument(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET, POST or DELETE."})
+	}
+}
+
+func (h *ResearchDocumentsHandler) addDocument(w http.ResponseWriter, r *http.Request) {
+	// Parse the JSON request body
+	var req AddResearchDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	content := []byte(req.Content)
+	if req.ContentBase64 != "" {
+		decoded, err := base64.StdEncoding.DecodeString(req.ContentBase64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "content_base64 is not valid base64: " + err.Error()})
+			return
+		}
+		content = decoded
+	}
+	if req.Name == "" || len(content) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "name and content or content_base64 are required"})
+		return
+	}
+
+	jobApplications, err := h.db.GetJobApplicationsById([]int{req.JobApplicationID})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+	if len(jobApplications) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Job application not found"})
+		return
+	}
+
+	scenario := scenarios.NewResearchDocumentsScenario(h.cfg, h.geminiClient, h.db, h.embedder)
+	document, created, err := scenario.AddDocument(context.TODO(), jobApplications[0], req.Name, req.Format, content)
+	if err != nil {
+		if errors.Is(err, scenarios.ErrInvalidResearchDocument) {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to add research document: " + err.Error()})
+		return
+	}
+
+	response := ResearchDocumentResponse{Message: "Document added", Document: document}
+	status := http.StatusCreated
+	if !created {
+		response.Message = "Document already added"
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *ResearchDocumentsHandler) getDocuments(w http.ResponseWriter, r *http.Request) {
+	// Return a single document with its text when ?document_id= is set
+	if value := r.URL.Query().Get("document_id"); value != "" {
+		documentID, err := strconv.Atoi(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "document_id must be a number"})
+			return
+		}
+		document, err := h.db.GetResearchDocument(documentID)
+		if err != nil {
+			writeResearchDocumentError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ResearchDocumentResponse{Message: "Success", Document: document})
+		return
+	}
+
+	// Otherwise list the documents of the company of ?job_application_id=, or of every company
+	documents := []models.ResearchDocument{}
+	var err error
+	if value := r.URL.Query().Get("job_application_id"); value != "" {
+		var jobApplicationID int
+		if jobApplicationID, err = strconv.Atoi(value); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_id must be a number"})
+			return
+		}
+		var jobApplications []models.JobApplication
+		if jobApplications, err = h.db.GetJobApplicationsById([]int{jobApplicationID}); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+			return
+		}
+		if len(jobApplications) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Job application not found"})
+			return
+		}
+		documents, err = scenarios.NewResearchDocumentsScenario(h.cfg, h.geminiClient, h.db, h.embedder).Documents(jobApplications[0])
+	} else {
+		documents, err = h.db.GetResearchDocuments("")
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get research documents: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResearchDocumentsResponse{Message: "Success", Documents: documents})
+}
+
+func (h *ResearchDocumentsHandler) deleteDocument(w http.ResponseWriter, r *http.Request) {
+	documentID, err := strconv.Atoi(r.URL.Query().Get("document_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "document_id must be a number"})
+		return
+	}
+	if err := h.db.DeleteResearchDocument(documentID); err != nil {
+		writeResearchDocumentError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResearchDocumentsResponse{Message: "Document deleted", Documents: []models.ResearchDocument{}})
+}
+
+func writeResearchDocumentError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrResearchDocumentNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+	} else {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get research document: " + err.Error()})
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"data-analyzer/analytics"
+	"data-analyzer/db"
+	"data-analyzer/scenarios"
+)
+
+// ResumeVersionsResponse represents the response body for the resume version comparison
+type ResumeVersionsResponse struct {
+	Message string                        `json:"message"`
+	Report  analytics.ResumeVersionReport `json:"report"`
+}
+
+type ResumeVersionsHandler struct {
+	db *db.DB
+}
+
+func NewResumeVersionsHandler(db *db.DB) *ResumeVersionsHandler {
+	return &ResumeVersionsHandler{
+		db: db,
+	}
+}
+
+// HandleResumeVersions handles GET requests comparing the response and interview rates of the resume versions
+func (h *ResumeVersionsHandler) HandleResumeVersions(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	report, err := scenarios.NewResumeVersionsScenario(h.db).Execute()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to compare resume versions: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ResumeVersionsResponse{Message: "Success", Report: report})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"data-analyzer/agent"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"data-analyzer/scenarios"
+)
+
+// defaultSearchLimit is the number of results returned when ?limit= is not set
+const defaultSearchLimit = 10
+
+// SearchResponse represents the response body for the semantic search endpoints
+type SearchResponse struct {
+	Query   string                   `json:"query,omitempty"`
+	Model   string                   `json:"model"`
+	Results []scenarios.SearchResult `json:"results"`
+}
+
+type SemanticSearchHandler struct {
+	db       *db.DB
+	embedder agent.Embedder
+}
+
+func NewSemanticSearchHandler(db *db.DB, embedder agent.Embedder) *SemanticSearchHandler {
+	return &SemanticSearchHandler{
+		db:       db,
+		embedder: embedder,
+	}
+}
+
+// HandleSearch handles GET requests for items semantically similar to ?q=
+func (h *SemanticSearchHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "q is required"})
+		return
+	}
+
+	itemTypes, limit, ok := parseSearchOptions(w, r)
+	if !ok {
+		return
+	}
+
+	results, err := scenarios.NewSemanticSearchScenario(h.db, h.embedder).Search(context.TODO(), query, itemTypes, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to search: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SearchResponse{Query: query, Model: h.embedder.Name(), Results: results})
+}
+
+// HandleSimilar handles GET requests for items similar to the job application in ?job_application_id=
+func (h *SemanticSearchHandler) HandleSimilar(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	jobApplicationID, err := strconv.Atoi(r.URL.Query().Get("job_application_id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_id must be a number"})
+		return
+	}
+
+	itemTypes, limit, ok := parseSearchOptions(w, r)
+	if !ok {
+		return
+	}
+	// more like this compares jobs with jobs unless other types are asked for
+	if len(itemTypes) == 0 {
+		itemTypes = []string{models.EmbeddingItemJob}
+	}
+
+	results, err := scenarios.NewSemanticSearchScenario(h.db, h.embedder).Similar(context.TODO(), jobApplicationID, itemTypes, limit)
+	if errors.Is(err, scenarios.ErrNoEmbedding) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to find similar items: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SearchResponse{Model: h.embedder.Name(), Results: results})
+}
+
+// HandleRefresh handles POST requests embedding the items whose text changed
+func (h *SemanticSearchHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	result, err := scenarios.NewSemanticSearchScenario(h.db, h.embedder).Refresh(context.TODO())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to refresh embeddings: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// parseSearchOptions reads ?type= (comma separated item types) and ?limit=, writing a 400 response when they are invalid
+func parseSearchOptions(w http.ResponseWriter, r *http.Request) ([]string, int, bool) {
+	itemTypes := []string{}
+	if value := r.URL.Query().Get("type"); value != "" {
+		for _, itemType := range strings.Split(value, ",") {
+			itemType = strings.TrimSpace(itemType)
+			if !slices.Contains(models.EmbeddingItemTypes, itemType) {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "type must be one of: " + strings.Join(models.EmbeddingItemTypes, ", ")})
+				return nil, 0, false
+			}
+			itemTypes = append(itemTypes, itemType)
+		}
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "limit must be a positive number"})
+			return nil, 0, false
+		}
+		limit = parsed
+	}
+
+	return itemTypes, limit, true
+}
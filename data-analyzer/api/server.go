@@ -1,12 +1,16 @@
 package api
 
 import (
+	"context"
 	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
 	"data-analyzer/config"
 	"data-analyzer/db"
+	"data-analyzer/scenarios"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 )
 
 type Server struct {
@@ -26,11 +30,100 @@ func NewServer(cfg *config.Config, db *db.DB, geminiClient *agent.Client) *Serve
 func (s *Server) Run() {
 	coverLetterHandler := NewGenerateCoverLetterHandler(s.db, s.geminiClient)
 	insightHandler := NewGenerateInsightHandler(s.db, s.geminiClient)
-	researchCompanyHandler := NewResearchCompanyHandler(s.db, s.geminiClient)
+	redFlagsHandler := NewDetectRedFlagsHandler(s.cfg, s.db, s.geminiClient)
+
+	techTaxonomy, err := workflows.LoadTechTaxonomy()
+	if err != nil {
+		log.Fatalf("Failed to load tech taxonomy: %v", err)
+	}
+	techStackHandler := NewExtractTechStackHandler(s.db, s.geminiClient, techTaxonomy)
+	skillsGapHandler := NewSkillsGapHandler(s.db, s.geminiClient)
+	rankHandler := NewRankJobApplicationsHandler(s.cfg, s.db)
+	salaryHandler := NewExtractSalaryHandler(s.cfg, s.db, s.geminiClient)
+	jobMetadataHandler := NewExtractJobMetadataHandler(s.db, s.geminiClient)
+	duplicatesHandler := NewFindDuplicatesHandler(s.cfg, s.db)
+	importJobApplicationsHandler := NewImportJobApplicationsHandler(s.db)
+	importJobPostingHandler := NewImportJobPostingHandler(s.cfg, s.db, s.geminiClient, scenarios.NewPostingHTTPClient(s.cfg))
+	ingestEmailsHandler := NewIngestEmailsHandler(s.cfg, s.db, s.geminiClient)
+	ghostingHandler := NewDetectGhostingHandler(s.cfg, s.db)
+	calendarHandler := NewExportCalendarHandler(s.cfg, s.db)
+	prepareInterviewHandler := NewPrepareInterviewHandler(s.cfg, s.db, s.geminiClient)
+	mockInterviewHandler := NewMockInterviewHandler(s.cfg, s.db, s.geminiClient)
+	companiesHandler := NewCompaniesHandler(s.db)
+	funnelAnalyticsHandler := NewFunnelAnalyticsHandler(s.db)
+	resumeVersionsHandler := NewResumeVersionsHandler(s.db)
+	digestHandler := NewWeeklyDigestHandler(s.cfg, s.db)
+	webhooksHandler := NewWebhooksHandler(s.cfg, s.db)
+
+	embedder, err := agent.NewEmbedder(s.cfg, s.geminiClient)
+	if err != nil {
+		log.Fatalf("Failed to create embedder: %v", err)
+	}
+	researchCompanyHandler := NewResearchCompanyHandler(s.cfg, s.db, s.geminiClient, embedder)
+	researchDocumentsHandler := NewResearchDocumentsHandler(s.cfg, s.db, s.geminiClient, embedder)
+	semanticSearchHandler := NewSemanticSearchHandler(s.db, embedder)
+	textSearchHandler := NewTextSearchHandler(s.db)
 
 	http.HandleFunc("/job_application/generate_cover_letter", coverLetterHandler.HandleGenerateCoverLetter)
 	http.HandleFunc("/job_application/generate_insight", insightHandler.HandleGenerateInsight)
 	http.HandleFunc("/job_application/research_company", researchCompanyHandler.HandleResearchCompany)
+	http.HandleFunc("/job_application/research_documents", researchDocumentsHandler.HandleResearchDocuments)
+	http.HandleFunc("/job_application/detect_red_flags", redFlagsHandler.HandleDetectRedFlags)
+	http.HandleFunc("/job_application/extract_tech_stack", techStackHandler.HandleExtractTechStack)
+	http.HandleFunc("/job_applications/tech_stack", techStackHandler.HandleGetTechStack)
+	http.HandleFunc("/job_application/skills_gap", skillsGapHandler.HandleSkillsGap)
+	http.HandleFunc("/job_applications/ranked", rankHandler.HandleRankJobApplications)
+	http.HandleFunc("/job_application/extract_salary", salaryHandler.HandleExtractSalary)
+	http.HandleFunc("/job_applications/salaries", salaryHandler.HandleGetSalaries)
+	http.HandleFunc("/job_application/extract_job_metadata", jobMetadataHandler.HandleExtractJobMetadata)
+	http.HandleFunc("/job_applications/metadata", jobMetadataHandler.HandleGetJobMetadata)
+	http.HandleFunc("/job_applications/duplicates", duplicatesHandler.HandleGetDuplicates)
+	http.HandleFunc("/job_applications/duplicates/check", duplicatesHandler.HandleCheckDuplicates)
+	http.HandleFunc("/search", semanticSearchHandler.HandleSearch)
+	http.HandleFunc("/search/similar", semanticSearchHandler.HandleSimilar)
+	http.HandleFunc("/search/refresh", semanticSearchHandler.HandleRefresh)
+	http.HandleFunc("/search/text", textSearchHandler.HandleTextSearch)
+	http.HandleFunc("/job_application/import_url", importJobPostingHandler.HandleImportJobPosting)
+	http.HandleFunc("/job_applications/import", importJobApplicationsHandler.HandleImportJobApplications)
+	http.HandleFunc("/emails", ingestEmailsHandler.HandleGetEmails)
+	http.HandleFunc("/emails/ingest", ingestEmailsHandler.HandleIngestEmails)
+	http.HandleFunc("/emails/review", ingestEmailsHandler.HandleReviewEmail)
+	http.HandleFunc("/job_applications/ghosting", ghostingHandler.HandleGetGhosting)
+	http.HandleFunc("/job_applications/ghosting/move", ghostingHandler.HandleMoveToGhosted)
+	http.HandleFunc("/calendar.ics", calendarHandler.HandleCalendar)
+	http.HandleFunc("/job_application/prepare_interview", prepareInterviewHandler.HandlePrepareInterview)
+	http.HandleFunc("/job_application/mock_interview", mockInterviewHandler.HandleMockInterview)
+	http.HandleFunc("/job_application/mock_interview/answer", mockInterviewHandler.HandleAnswer)
+	http.HandleFunc("/job_application/mock_interview/finish", mockInterviewHandler.HandleFinish)
+	http.HandleFunc("/companies", companiesHandler.HandleCompanies)
+	http.HandleFunc("/companies/{id}", companiesHandler.HandleCompany)
+	http.HandleFunc("/companies/{id}/aliases", companiesHandler.HandleAliases)
+	http.HandleFunc("/analytics/funnel", funnelAnalyticsHandler.HandleFunnel)
+	http.HandleFunc("/analytics/resume_versions", resumeVersionsHandler.HandleResumeVersions)
+	http.HandleFunc("/digest", digestHandler.HandleDigest)
+	http.HandleFunc("/digest/deliver", digestHandler.HandleDeliver)
+	http.HandleFunc("/webhooks", webhooksHandler.HandleWebhooks)
+	http.HandleFunc("/webhooks/dispatch", webhooksHandler.HandleDispatch)
+	http.HandleFunc("/webhooks/{id}", webhooksHandler.HandleWebhook)
+	http.HandleFunc("/webhooks/{id}/deliveries", webhooksHandler.HandleDeliveries)
+
+	if s.cfg.Ghosting.IntervalHours > 0 {
+		go scenarios.NewDetectGhostingScenario(s.cfg, s.db).RunPeriodically(context.Background())
+	}
+	// preparing interviews needs the agent
+	if s.cfg.InterviewPrep.IntervalHours > 0 && s.geminiClient.Enabled() {
+		go scenarios.NewPrepareInterviewScenario(s.cfg, s.geminiClient, s.db).RunPeriodically(context.Background())
+	}
+	if s.cfg.Digest.IntervalHours > 0 {
+		go scenarios.NewWeeklyDigestScenario(s.cfg, s.db).RunPeriodically(context.Background())
+	}
+	if s.cfg.Webhooks.PollSeconds > 0 {
+		go scenarios.NewWebhooksScenario(s.cfg, s.db).RunPeriodically(context.Background())
+	}
+
+	if s.cfg.EmbeddingRefreshMinutes > 0 {
+		go scenarios.NewSemanticSearchScenario(s.db, embedder).RunPeriodically(context.Background(), time.Duration(s.cfg.EmbeddingRefreshMinutes)*time.Minute)
+	}
 
 	fmt.Printf("🚀 Starting HTTP server on port %s\n", s.cfg.ServerPort)
 	log.Fatal(http.ListenAndServe(s.cfg.ServerPort, nil))
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"data-analyzer/scenarios"
+)
+
+// SkillsGapRequest represents the request body for the skills gap endpoint
+type SkillsGapRequest struct {
+	JobApplicationIDs []int `json:"job_application_ids"`
+	// Refresh re-runs the analysis even for job applications that already have one
+	Refresh bool `json:"refresh"`
+}
+
+// SkillsGapResponse represents the response body for the skills gap endpoints
+type SkillsGapResponse struct {
+	Message    string                `json:"message"`
+	SkillsGaps []workflows.SkillsGap `json:"skills_gaps"`
+}
+
+type SkillsGapHandler struct {
+	db           *db.DB
+	geminiClient *agent.Client
+}
+
+func NewSkillsGapHandler(db *db.DB, geminiClient *agent.Client) *SkillsGapHandler {
+	return &SkillsGapHandler{
+		db:           db,
+		geminiClient: geminiClient,
+	}
+}
+
+// HandleSkillsGap handles POST requests to run the skills gap analysis and GET requests to read the stored results
+func (h *SkillsGapHandler) HandleSkillsGap(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodPost:
+		h.runSkillsGap(w, r)
+	case http.MethodGet:
+		h.getSkillsGap(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET or POST."})
+	}
+}
+
+func (h *SkillsGapHandler) runSkillsGap(w http.ResponseWriter, r *http.Request) {
+	// Parse the JSON request body
+	var req SkillsGapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+		return
+	}
+
+	// Validate that job_application_ids is not empty
+	if len(req.JobApplicationIDs) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_ids cannot be empty"})
+		return
+	}
+
+	jobApplications, err := h.db.GetJobApplicationsById(req.JobApplicationIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get job applications: " + err.Error()})
+		return
+	}
+
+	storedSkillsGaps, err := scenarios.GetStoredSkillsGaps(h.db)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get workflows: " + err.Error()})
+		return
+	}
+
+	// Only analyze job applications without a stored analysis, unless a refresh was requested
+	jobApplicationsToAnalyze := make([]models.JobApplication, 0)
+	skillsGaps := make([]workflows.SkillsGap, 0)
+	for _, jobApplication := range jobApplications {
+		if skillsGap, ok := storedSkillsGaps[jobApplication.ID]; ok && !req.Refresh {
+			skillsGaps = append(skillsGaps, skillsGap)
+			continue
+		}
+		jobApplicationsToAnalyze = append(jobApplicationsToAnalyze, jobApplication)
+	}
+
+	response := SkillsGapResponse{
+		Message:    "No new workflows to execute",
+		SkillsGaps: skillsGaps,
+	}
+
+	if len(jobApplicationsToAnalyze) > 0 {
+		skillsGapScenario := scenarios.NewSkillsGapScenario(h.geminiClient, h.db, jobApplicationsToAnalyze)
+		newSkillsGaps, err := skillsGapScenario.Execute(context.TODO())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to analyze skills gap: " + err.Error()})
+			return
+		}
+		response = SkillsGapResponse{
+			Message:    "Skills gap analysis completed",
+			SkillsGaps: append(skillsGaps, newSkillsGaps...),
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *SkillsGapHandler) getSkillsGap(w http.ResponseWriter, r *http.Request) {
+	storedSkillsGaps, err := scenarios.GetStoredSkillsGaps(h.db)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get workflows: " + err.Error()})
+		return
+	}
+
+	response := SkillsGapResponse{
+		Message:    "Success",
+		SkillsGaps: []workflows.SkillsGap{},
+	}
+
+	// Return a single job application when ?job_application_id= is set, otherwise all of them by fit
+	if value := r.URL.Query().Get("job_application_id"); value != "" {
+		jobApplicationID, err := strconv.Atoi(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "job_application_id must be a number"})
+			return
+		}
+		skillsGap, ok := storedSkillsGaps[jobApplicationID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "No skills gap analysis found for this job application"})
+			return
+		}
+		response.SkillsGaps = append(response.SkillsGaps, skillsGap)
+	} else {
+		for _, skillsGap := range storedSkillsGaps {
+			response.SkillsGaps = append(response.SkillsGaps, skillsGap)
+		}
+		sort.Slice(response.SkillsGaps, func(i, j int) bool {
+			return response.SkillsGaps[i].FitPercentage > response.SkillsGaps[j].FitPercentage
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"data-analyzer/db"
+	"data-analyzer/models"
+)
+
+// TextSearchResponse represents the response body for the full-text search endpoint
+type TextSearchResponse struct {
+	Query   string                    `json:"query"`
+	Results []models.TextSearchResult `json:"results"`
+}
+
+type TextSearchHandler struct {
+	db *db.DB
+}
+
+func NewTextSearchHandler(db *db.DB) *TextSearchHandler {
+	return &TextSearchHandler{
+		db: db,
+	}
+}
+
+// HandleTextSearch handles GET requests for a full-text query in ?q=. Supported filters are
+// ?status= and ?type= (both comma separated) and ?limit=.
+func (h *TextSearchHandler) HandleTextSearch(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	if !h.db.TextSearchAvailable() {
+		w.WriteHeader(http.StatusNotImplemented)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: db.ErrFullTextSearchUnavailable.Error()})
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "q is required"})
+		return
+	}
+
+	itemTypes, ok := parseListParameter(w, r, "type", models.TextSearchItemTypes)
+	if !ok {
+		return
+	}
+	statuses, ok := parseListParameter(w, r, "status", models.StatusChoices)
+	if !ok {
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "limit must be a positive number"})
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.db.SearchText(query, itemTypes, statuses, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to search: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(TextSearchResponse{Query: query, Results: results})
+}
+
+// parseListParameter reads a comma separated query parameter whose values must be among the choices,
+// writing a 400 response when one is not
+func parseListParameter(w http.ResponseWriter, r *http.Request, name string, choices []string) ([]string, bool) {
+	values := []string{}
+	parameter := r.URL.Query().Get(name)
+	if parameter == "" {
+		return values, true
+	}
+	for _, value := range strings.Split(parameter, ",") {
+		value = strings.TrimSpace(value)
+		if !slices.Contains(choices, value) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: name + " must be one of: " + strings.Join(choices, ", ")})
+			return nil, false
+		}
+		values = append(values, value)
+	}
+	return values, true
+}
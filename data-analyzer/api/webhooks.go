@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"data-analyzer/scenarios"
+)
+
+// WebhooksResponse represents the response body for listing webhook subscriptions
+type WebhooksResponse struct {
+	Message       string                       `json:"message"`
+	Subscriptions []models.WebhookSubscription `json:"subscriptions"`
+}
+
+// WebhookResponse represents the response body for a webhook subscription, with its secret when it was just created
+type WebhookResponse struct {
+	Message      string                     `json:"message"`
+	Subscription models.WebhookSubscription `json:"subscription"`
+}
+
+// WebhookDeliveriesResponse represents the response body for the delivery log of a webhook subscription
+type WebhookDeliveriesResponse struct {
+	Message    string                   `json:"message"`
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+// WebhookDispatchResponse represents the response body for dispatching the webhook events
+type WebhookDispatchResponse struct {
+	Message string                          `json:"message"`
+	Result  scenarios.WebhookDispatchResult `json:"result"`
+}
+
+type WebhooksHandler struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewWebhooksHandler(cfg *config.Config, db *db.DB) *WebhooksHandler {
+	return &WebhooksHandler{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// HandleWebhooks handles GET requests listing the webhook subscriptions and POST requests creating one
+func (h *WebhooksHandler) HandleWebhooks(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	scenario := scenarios.NewWebhooksScenario(h.cfg, h.db)
+	switch r.Method {
+	case http.MethodGet:
+		subscriptions, err := scenario.List()
+		if err != nil {
+			writeWebhookError(w, "Failed to get webhook subscriptions: ", err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(WebhooksResponse{Message: "Success", Subscriptions: subscriptions})
+	case http.MethodPost:
+		// Parse the JSON request body
+		var req scenarios.WebhookSubscriptionInput
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+			return
+		}
+		subscription, err := scenario.Subscribe(req)
+		if err != nil {
+			writeWebhookError(w, "Failed to create webhook subscription: ", err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Subscription created, keep the secret to verify the signatures", Subscription: subscription})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET or POST."})
+	}
+}
+
+// HandleWebhook handles GET requests for a webhook subscription, PATCH requests changing its event
+// types, description or state and DELETE requests removing it with its delivery log, which answer with
+// the remaining subscriptions
+func (h *WebhooksHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "webhook subscription id must be a number"})
+		return
+	}
+
+	scenario := scenarios.NewWebhooksScenario(h.cfg, h.db)
+	switch r.Method {
+	case http.MethodGet:
+		subscription, err := scenario.Get(id)
+		if err != nil {
+			writeWebhookError(w, "Failed to get webhook subscription: ", err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Success", Subscription: subscription})
+	case http.MethodPatch:
+		// Parse the JSON request body
+		var req scenarios.WebhookSubscriptionInput
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON payload: " + err.Error()})
+			return
+		}
+		subscription, err := scenario.Update(id, req)
+		if err != nil {
+			writeWebhookError(w, "Failed to update webhook subscription: ", err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(WebhookResponse{Message: "Subscription updated", Subscription: subscription})
+	case http.MethodDelete:
+		if err := scenario.Unsubscribe(id); err != nil {
+			writeWebhookError(w, "Failed to delete webhook subscription: ", err)
+			return
+		}
+		subscriptions, err := scenario.List()
+		if err != nil {
+			writeWebhookError(w, "Failed to get webhook subscriptions: ", err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(WebhooksResponse{Message: "Subscription deleted", Subscriptions: subscriptions})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET, PATCH or DELETE."})
+	}
+}
+
+// HandleDeliveries handles GET requests for the latest deliveries of a webhook subscription
+func (h *WebhooksHandler) HandleDeliveries(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "webhook subscription id must be a number"})
+		return
+	}
+
+	deliveries, err := scenarios.NewWebhooksScenario(h.cfg, h.db).Deliveries(id)
+	if err != nil {
+		writeWebhookError(w, "Failed to get webhook deliveries: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(WebhookDeliveriesResponse{Message: "Success", Deliveries: deliveries})
+}
+
+// HandleDispatch handles POST requests sending the new events and the due retries now instead of at
+// the next WEBHOOK_POLL_SECONDS tick
+func (h *WebhooksHandler) HandleDispatch(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	result, err := scenarios.NewWebhooksScenario(h.cfg, h.db).Dispatch(context.Background(), time.Now())
+	if err != nil {
+		writeWebhookError(w, "Failed to dispatch webhooks: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(WebhookDispatchResponse{Message: "Success", Result: result})
+}
+
+func writeWebhookError(w http.ResponseWriter, prefix string, err error) {
+	switch {
+	case errors.Is(err, db.ErrWebhookSubscriptionNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, scenarios.ErrInvalidWebhookSubscription):
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(ErrorResponse{Error: prefix + err.Error()})
+}
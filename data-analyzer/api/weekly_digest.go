@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/digest"
+	"data-analyzer/scenarios"
+)
+
+// DigestResponse represents the response body for the digest as JSON
+type DigestResponse struct {
+	Message string        `json:"message"`
+	Digest  digest.Digest `json:"digest"`
+}
+
+// DeliverDigestResponse represents the response body for delivering the digest
+type DeliverDigestResponse struct {
+	Message  string                   `json:"message"`
+	Delivery scenarios.DigestDelivery `json:"delivery"`
+}
+
+type WeeklyDigestHandler struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewWeeklyDigestHandler(cfg *config.Config, db *db.DB) *WeeklyDigestHandler {
+	return &WeeklyDigestHandler{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// HandleDigest handles GET requests for the digest of the last DIGEST_DAYS days, as JSON or rendered
+// with ?format=markdown or ?format=html
+func (h *WeeklyDigestHandler) HandleDigest(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use GET."})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "markdown" && format != "html" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "format must be json, markdown or html"})
+		return
+	}
+
+	result, err := scenarios.NewWeeklyDigestScenario(h.cfg, h.db).Build(time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to build digest: " + err.Error()})
+		return
+	}
+
+	var rendered, contentType string
+	switch format {
+	case "markdown":
+		rendered, err = digest.RenderMarkdown(result)
+		contentType = "text/markdown; charset=utf-8"
+	case "html":
+		rendered, err = digest.RenderHTML(result)
+		contentType = "text/html; charset=utf-8"
+	default:
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(DigestResponse{Message: "Success", Digest: result})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to render digest: " + err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(rendered))
+}
+
+// HandleDeliver handles POST requests delivering the digest now, to DIGEST_DIR and by email when
+// DIGEST_SMTP_HOST is set
+func (h *WeeklyDigestHandler) HandleDeliver(w http.ResponseWriter, r *http.Request) {
+	// Set JSON content type for all responses
+	w.Header().Set("Content-Type", "application/json")
+
+	// Only allow POST requests
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Method not allowed. Use POST."})
+		return
+	}
+
+	scenario := scenarios.NewWeeklyDigestScenario(h.cfg, h.db)
+	result, err := scenario.Build(time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to build digest: " + err.Error()})
+		return
+	}
+	delivery, err := scenario.Deliver(result, h.cfg.Digest.Dir, h.cfg.Digest.SMTPHost != "")
+	if err != nil {
+		if errors.Is(err, scenarios.ErrDigestNotConfigured) {
+			w.WriteHeader(http.StatusConflict)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to deliver digest: " + err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(DeliverDigestResponse{Message: "Digest delivered", Delivery: delivery})
+}
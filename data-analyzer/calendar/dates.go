@@ -0,0 +1,142 @@
+package calendar
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// monthPattern matches full and abbreviated month names only, so words such as "marketing" or
+// "decision" are not read as months
+const monthPattern = `(jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sep(?:t(?:ember)?)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)\b\.?`
+
+var (
+	// 2026-10-25, optionally followed by a time as in 2026-10-25 14:00 or 2026-10-25T14:00
+	isoDateRe = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})(?:\b|T)`)
+	// 25.10.2026 and 25/10/2026 are read day first, 10/25/2026 month first when the day cannot be a month
+	numericDateRe = regexp.MustCompile(`\b(\d{1,2})[./](\d{1,2})[./](\d{4})\b`)
+	// October 25, Oct 25th 2026
+	monthDayRe = regexp.MustCompile(`(?i)\b` + monthPattern + `\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s+(\d{4})\b)?`)
+	// 25 October, 25th of Oct 2026
+	dayMonthRe = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?` + monthPattern + `(?:,?\s+(\d{4})\b)?`)
+	// 14:00, 2:30 pm, 2pm
+	timeRe = regexp.MustCompile(`(?i)\b(\d{1,2})(?::(\d{2}))?\s*(am|pm)\b|\b(\d{1,2}):(\d{2})\b`)
+)
+
+var months = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// FindDateTime finds the first date in the text and the time following it, e.g. "Interview on
+// 2026-10-25 at 14:00" or "call Oct 25, 2pm". Dates without a year are placed on or after the
+// reference date. The time is read in loc; hasTime is false when the text only has a date.
+func FindDateTime(text string, reference time.Time, loc *time.Location) (result time.Time, hasTime bool, ok bool) {
+	year, month, day, end, found := findDate(text, reference.In(loc))
+	if !found {
+		return time.Time{}, false, false
+	}
+
+	hour, minute, hasTime := findTime(text[end:])
+	if !hasTime {
+		// a time written before the date, as in "14:00 on 25 October"
+		hour, minute, hasTime = findTime(text)
+	}
+	result = time.Date(year, month, day, hour, minute, 0, 0, loc)
+	if result.Day() != day {
+		// February 30th and the like
+		return time.Time{}, false, false
+	}
+	return result, hasTime, true
+}
+
+// findDate returns the earliest date in the text and the offset where it ends
+func findDate(text string, reference time.Time) (year int, month time.Month, day int, end int, ok bool) {
+	start := len(text) + 1
+
+	if m := isoDateRe.FindStringSubmatchIndex(text); m != nil && m[0] < start {
+		y, _ := strconv.Atoi(text[m[2]:m[3]])
+		mo, _ := strconv.Atoi(text[m[4]:m[5]])
+		d, _ := strconv.Atoi(text[m[6]:m[7]])
+		if validDate(mo, d) {
+			year, month, day, start, end, ok = y, time.Month(mo), d, m[0], m[1], true
+		}
+	}
+	if m := numericDateRe.FindStringSubmatchIndex(text); m != nil && m[0] < start {
+		first, _ := strconv.Atoi(text[m[2]:m[3]])
+		second, _ := strconv.Atoi(text[m[4]:m[5]])
+		y, _ := strconv.Atoi(text[m[6]:m[7]])
+		d, mo := first, second
+		if second > 12 {
+			d, mo = second, first
+		}
+		if validDate(mo, d) {
+			year, month, day, start, end, ok = y, time.Month(mo), d, m[0], m[1], true
+		}
+	}
+	for _, named := range []struct {
+		re         *regexp.Regexp
+		monthGroup int
+		dayGroup   int
+	}{{monthDayRe, 1, 2}, {dayMonthRe, 2, 1}} {
+		m := named.re.FindStringSubmatchIndex(text)
+		if m == nil || m[0] >= start {
+			continue
+		}
+		mo := months[strings.ToLower(text[m[2*named.monthGroup]:m[2*named.monthGroup]+3])]
+		d, _ := strconv.Atoi(text[m[2*named.dayGroup]:m[2*named.dayGroup+1]])
+		if !validDate(int(mo), d) {
+			continue
+		}
+		y := reference.Year()
+		if m[6] >= 0 {
+			y, _ = strconv.Atoi(text[m[6]:m[7]])
+		} else if time.Date(y, mo, d, 23, 59, 0, 0, reference.Location()).Before(reference) {
+			// the next occurrence of a date without a year
+			y++
+		}
+		year, month, day, start, end, ok = y, mo, d, m[0], m[1], true
+	}
+
+	return year, month, day, end, ok
+}
+
+// findTime returns the first time of day in the text
+func findTime(text string) (hour int, minute int, ok bool) {
+	m := timeRe.FindStringSubmatch(text)
+	if m == nil {
+		return 0, 0, false
+	}
+	if m[4] != "" {
+		hour, _ = strconv.Atoi(m[4])
+		minute, _ = strconv.Atoi(m[5])
+	} else {
+		hour, _ = strconv.Atoi(m[1])
+		if m[2] != "" {
+			minute, _ = strconv.Atoi(m[2])
+		}
+		if hour > 12 {
+			return 0, 0, false
+		}
+		switch strings.ToLower(m[3]) {
+		case "pm":
+			if hour < 12 {
+				hour += 12
+			}
+		case "am":
+			if hour == 12 {
+				hour = 0
+			}
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+func validDate(month int, day int) bool {
+	return month >= 1 && month <= 12 && day >= 1 && day <= 31
+}
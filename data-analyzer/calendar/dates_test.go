@@ -0,0 +1,39 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindDateTime(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	reference := time.Date(2026, 10, 18, 12, 0, 0, 0, loc)
+	tests := []struct {
+		text     string
+		want     time.Time
+		wantTime bool
+		wantOK   bool
+	}{
+		{"Interview on 2026-10-25 at 14:00", time.Date(2026, 10, 25, 14, 0, 0, 0, loc), true, true},
+		{"Onsite 2026-11-02T09:30", time.Date(2026, 11, 2, 9, 30, 0, 0, loc), true, true},
+		{"call Oct 25, 2pm", time.Date(2026, 10, 25, 14, 0, 0, 0, loc), true, true},
+		{"Technical interview on 25th of October 2027", time.Date(2027, 10, 25, 0, 0, 0, 0, loc), false, true},
+		{"HR call Sept. 3 at 10:15 am", time.Date(2027, 9, 3, 10, 15, 0, 0, loc), true, true},
+		{"Final round December 1st", time.Date(2026, 12, 1, 0, 0, 0, 0, loc), false, true},
+		{"14:00 on 25.10.2026", time.Date(2026, 10, 25, 14, 0, 0, 0, loc), true, true},
+		{"due 10/25/2026", time.Date(2026, 10, 25, 0, 0, 0, 0, loc), false, true},
+		{"February 30", time.Time{}, false, false},
+		// words starting like a month are not months
+		{"HR call with the marketing 2 person team", time.Time{}, false, false},
+		{"Technical interview for junior 3 positions", time.Time{}, false, false},
+		{"Interview decision 5 days after", time.Time{}, false, false},
+		{"Octopus 4 deploy", time.Time{}, false, false},
+		{"No date here", time.Time{}, false, false},
+	}
+	for _, tt := range tests {
+		got, hasTime, ok := FindDateTime(tt.text, reference, loc)
+		if ok != tt.wantOK || hasTime != tt.wantTime || !got.Equal(tt.want) {
+			t.Errorf("FindDateTime(%q) = %v, %v, %v, want %v, %v, %v", tt.text, got, hasTime, ok, tt.want, tt.wantTime, tt.wantOK)
+		}
+	}
+}
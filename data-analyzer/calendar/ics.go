@@ -0,0 +1,83 @@
+package calendar
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineLength is the number of octets after which iCalendar content lines are folded
+const maxLineLength = 75
+
+// Event is a calendar event. All-day events only use the date of Start and last one day.
+type Event struct {
+	// UID identifies the event across exports, so subscribed calendars update it instead of adding a copy
+	UID         string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Summary     string
+	Description string
+	// Updated is when the data behind the event last changed
+	Updated time.Time
+}
+
+// Render writes the events as an iCalendar (RFC 5545) document
+func Render(name string, events []Event) string {
+	var builder strings.Builder
+	writeLine(&builder, "BEGIN:VCALENDAR")
+	writeLine(&builder, "VERSION:2.0")
+	writeLine(&builder, "PRODID:-//job-tracker//data-analyzer//EN")
+	writeLine(&builder, "CALSCALE:GREGORIAN")
+	writeLine(&builder, "METHOD:PUBLISH")
+	writeLine(&builder, "X-WR-CALNAME:"+escapeText(name))
+
+	for _, event := range events {
+		writeLine(&builder, "BEGIN:VEVENT")
+		writeLine(&builder, "UID:"+event.UID)
+		writeLine(&builder, "DTSTAMP:"+formatUTC(event.Updated))
+		writeLine(&builder, "LAST-MODIFIED:"+formatUTC(event.Updated))
+		if event.AllDay {
+			writeLine(&builder, "DTSTART;VALUE=DATE:"+event.Start.Format("20060102"))
+			writeLine(&builder, "DTEND;VALUE=DATE:"+event.Start.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			writeLine(&builder, "DTSTART:"+formatUTC(event.Start))
+			writeLine(&builder, "DTEND:"+formatUTC(event.End))
+		}
+		writeLine(&builder, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(&builder, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		writeLine(&builder, "END:VEVENT")
+	}
+
+	writeLine(&builder, "END:VCALENDAR")
+	return builder.String()
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText escapes the characters that have a meaning in iCalendar text values
+func escapeText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
+
+// writeLine writes a content line, folding it into continuation lines of at most 75 octets
+// without splitting UTF-8 characters
+func writeLine(builder *strings.Builder, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		builder.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space, which counts towards their length
+		limit = maxLineLength - 1
+	}
+	builder.WriteString(line + "\r\n")
+}
@@ -0,0 +1,58 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	updated := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 10, 25, 14, 0, 0, 0, time.FixedZone("CET", 3600))
+	ics := Render("Job Applications", []Event{
+		{UID: "step-1@job-tracker", Start: start, End: start.Add(time.Hour), Summary: "Interview, Acme; round 1", Description: "line one\nline two", Updated: updated},
+		{UID: "follow-up-2@job-tracker", Start: start, AllDay: true, Summary: "Follow up", Updated: updated},
+	})
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Job Applications\r\n",
+		"DTSTART:20261025T130000Z\r\n",
+		"DTEND:20261025T140000Z\r\n",
+		`SUMMARY:Interview\, Acme\; round 1` + "\r\n",
+		`DESCRIPTION:line one\nline two` + "\r\n",
+		"DTSTART;VALUE=DATE:20261025\r\n",
+		"DTEND;VALUE=DATE:20261026\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("Render() is missing %q", want)
+		}
+	}
+}
+
+func TestWriteLineFolds(t *testing.T) {
+	var builder strings.Builder
+	line := "DESCRIPTION:" + strings.Repeat("ä", 100)
+	writeLine(&builder, line)
+
+	lines := strings.Split(strings.TrimSuffix(builder.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("writeLine() did not fold a %d octet line", len(line))
+	}
+	unfolded := lines[0]
+	for i, folded := range lines {
+		if len(folded) > maxLineLength {
+			t.Errorf("line %d has %d octets, want at most %d", i, len(folded), maxLineLength)
+		}
+		if i > 0 {
+			if !strings.HasPrefix(folded, " ") {
+				t.Errorf("continuation line %d does not start with a space", i)
+			}
+			unfolded += folded[1:]
+		}
+	}
+	if unfolded != line {
+		t.Errorf("unfolded line = %q, want %q", unfolded, line)
+	}
+}
@@ -0,0 +1,1059 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/analytics"
+	"data-analyzer/calendar"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/digest"
+	"data-analyzer/emails"
+	"data-analyzer/importer"
+	"data-analyzer/models"
+
+	"data-analyzer/scenarios"
+)
+
+// command is a CLI subcommand, run as `data-analyzer <name> [flags]` instead of starting the server
+type command struct {
+	description string
+	run         func(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error
+}
+
+var commands = map[string]command{
+	"duplicates": {
+		description: "Report clusters of near-duplicate job applications",
+		run:         runDuplicatesCommand,
+	},
+	"import": {
+		description: "Import job applications from a CSV or JSON file",
+		run:         runImportCommand,
+	},
+	"import-url": {
+		description: "Create a job application from a job posting URL",
+		run:         runImportURLCommand,
+	},
+	"ingest-emails": {
+		description: "Match recruiter emails (.eml or mbox) to job applications and classify them",
+		run:         runIngestEmailsCommand,
+	},
+	"ghosting": {
+		description: "Report ghosted job applications and due follow-ups, optionally moving them to Ghosted",
+		run:         runGhostingCommand,
+	},
+	"calendar": {
+		description: "Export interviews and follow-up reminders as an iCalendar file",
+		run:         runCalendarCommand,
+	},
+	"prepare-interview": {
+		description: "Prepare likely interview questions with STAR answers, for given or newly interviewing job applications",
+		run:         runPrepareInterviewCommand,
+	},
+	"mock-interview": {
+		description: "Run an interactive mock interview for a job application and grade the answers",
+		run:         runMockInterviewCommand,
+	},
+	"research-documents": {
+		description: "Add HTML pages, PDFs or notes about the company of a job application and research it from them",
+		run:         runResearchDocumentsCommand,
+	},
+	"companies": {
+		description: "List the companies of the job applications, or the full history with one company",
+		run:         runCompaniesCommand,
+	},
+	"funnel": {
+		description: "Report the conversion from Applied to interviews and offers and the time to the first response",
+		run:         runFunnelCommand,
+	},
+	"resume-versions": {
+		description: "Compare the response and interview rates of the resume versions with confidence intervals",
+		run:         runResumeVersionsCommand,
+	},
+	"digest": {
+		description: "Build the digest of the last week and print, write or email it",
+		run:         runDigestCommand,
+	},
+	"webhooks": {
+		description: "List, add and delete webhook subscriptions, show their deliveries or send the pending events",
+		run:         runWebhooksCommand,
+	},
+	"embeddings": {
+		description: "Embed the jobs, requirements, research and achievements whose text changed",
+		run:         runEmbeddingsCommand,
+	},
+	"search": {
+		description: "Rebuild the full-text search index with `search rebuild`",
+		run:         runSearchCommand,
+	},
+}
+
+// runCommand runs the subcommand named by the first argument
+func runCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		printUsage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd.run(ctx, cfg, database, geminiClient, args[1:])
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: data-analyzer [command] [flags]")
+	fmt.Fprintln(os.Stderr, "Without a command the analyzer runs the HTTP server when SHOULD_RUN_SERVER=true.")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", name, commands[name].description)
+	}
+}
+
+// printJSON writes the value as indented JSON to stdout
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func runDuplicatesCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("duplicates", flag.ContinueOnError)
+	jobID := flags.Int("job-id", 0, "only check this job application against the others")
+	threshold := flags.Float64("threshold", cfg.Dedup.Threshold, "similarity above which descriptions are duplicates")
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	cfg.Dedup.Threshold = *threshold
+
+	scenario := scenarios.NewFindDuplicatesScenario(cfg, database)
+
+	if *jobID > 0 {
+		jobApplications, err := database.GetJobApplicationsById([]int{*jobID})
+		if err != nil {
+			return err
+		}
+		if len(jobApplications) == 0 {
+			return fmt.Errorf("job application %d not found", *jobID)
+		}
+		matches, err := scenario.Check(jobApplications[0])
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(matches)
+		}
+		if len(matches) == 0 {
+			fmt.Printf("No duplicates found for job application %d\n", *jobID)
+			return nil
+		}
+		fmt.Printf("⚠️  %s\n", scenarios.DuplicateWarning(matches))
+		return nil
+	}
+
+	clusters, err := scenario.Execute()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(clusters)
+	}
+	if len(clusters) == 0 {
+		fmt.Println("No duplicates found")
+		return nil
+	}
+
+	for i, cluster := range clusters {
+		fmt.Printf("Cluster %d (%d job applications):\n", i+1, len(cluster.JobApplications))
+		for _, jobApplication := range cluster.JobApplications {
+			fmt.Printf("   - #%d %s at %s [%s] (%s, %s)\n", jobApplication.JobApplicationID, jobApplication.JobTitle, jobApplication.CompanyName,
+				jobApplication.Domain, jobApplication.Status, jobApplication.CreatedAt.Format(time.DateOnly))
+		}
+		for _, pair := range cluster.Pairs {
+			sameDomain := ""
+			if pair.SameDomain {
+				sameDomain = ", same domain"
+			}
+			fmt.Printf("     #%d ↔ #%d: %.0f%% similar%s\n", pair.A, pair.B, pair.Similarity*100, sameDomain)
+		}
+	}
+	return nil
+}
+
+func runEmbeddingsCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("embeddings", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	embedder, err := agent.NewEmbedder(cfg, geminiClient)
+	if err != nil {
+		return err
+	}
+
+	result, err := scenarios.NewSemanticSearchScenario(database, embedder).Refresh(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🔎 %s: %d embedded, %d unchanged, %d deleted\n", result.Model, result.Embedded, result.Unchanged, result.Deleted)
+	return nil
+}
+
+func runSearchCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.Arg(0) != "rebuild" {
+		return fmt.Errorf("usage: data-analyzer search rebuild")
+	}
+
+	if err := database.RebuildTextSearchIndex(); err != nil {
+		return err
+	}
+	fmt.Println("🔎 Full-text index rebuilt")
+	return nil
+}
+
+func runImportURLCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("import-url", flag.ContinueOnError)
+	status := flags.String("status", models.StatusPreparingApplication, "status of the new job application")
+	source := flags.String("source", "", "source of the new job application (default: guessed from the URL)")
+	resumeVersion := flags.String("resume-version", "", "resume version sent with the application")
+	dryRun := flags.Bool("dry-run", false, "print the extracted posting without storing it")
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: data-analyzer import-url [flags] <url>")
+	}
+
+	scenario := scenarios.NewImportJobPostingScenario(cfg, geminiClient, database, scenarios.NewPostingHTTPClient(cfg))
+	result, err := scenario.Execute(ctx, flags.Arg(0), scenarios.ImportJobPostingOptions{
+		Status:        *status,
+		Source:        *source,
+		ResumeVersion: *resumeVersion,
+		DryRun:        *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(result)
+	}
+
+	if result.JobApplicationID == 0 {
+		fmt.Println("Dry run, nothing was stored")
+	}
+	fmt.Printf("%s at %s (%s)\n", result.Posting.JobTitle, result.Posting.CompanyName, result.Method)
+	fmt.Printf("   Company URL: %s\n", result.Posting.CompanyURL)
+	if result.Posting.Salary != "" {
+		fmt.Printf("   Salary: %s\n", result.Posting.Salary)
+	}
+	fmt.Printf("   Status: %s, Source: %s\n", result.Status, result.Source)
+	fmt.Printf("   Description: %s\n", agent.TruncateText(strings.ReplaceAll(result.Posting.JobDescription, "\n", " "), 200))
+	if result.Warning != "" {
+		fmt.Printf("⚠️  %s\n", result.Warning)
+	}
+	return nil
+}
+
+func runImportCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "csv or json (default: detected from the file)")
+	mappingPath := flags.String("mapping", "", "JSON file mapping columns, statuses and sources")
+	dryRun := flags.Bool("dry-run", false, "preview the import without storing anything")
+	skipInvalid := flags.Bool("skip-invalid", false, "import the valid rows even when some rows are invalid")
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: data-analyzer import [flags] <file>, use - to read from stdin")
+	}
+
+	path := flags.Arg(0)
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	mapping, err := importer.LoadMapping(*mappingPath)
+	if err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = importer.DetectFormat(path, content)
+	}
+	rows, err := importer.Parse(*format, content, mapping)
+	if err != nil {
+		return err
+	}
+
+	summary, importErr := database.ImportJobApplications(rows, models.ImportOptions{
+		DryRun:      *dryRun,
+		SkipInvalid: *skipInvalid,
+		Origin:      filepath.Base(path),
+	})
+	if importErr != nil && !errors.Is(importErr, db.ErrInvalidImportRows) {
+		return importErr
+	}
+	if *asJSON {
+		if err := printJSON(summary); err != nil {
+			return err
+		}
+		return importErr
+	}
+
+	for _, row := range summary.Rows {
+		switch row.Action {
+		case models.ImportActionCreate:
+			fmt.Printf("   line %d: ✅ %s at %s (%s, %s)\n", row.Line, row.JobTitle, row.CompanyName, row.Status, row.Source)
+		case models.ImportActionDuplicate:
+			duplicateOf := fmt.Sprintf("job application #%d", row.DuplicateOf)
+			if row.DuplicateOf == 0 {
+				duplicateOf = fmt.Sprintf("line %d", row.DuplicateOfLine)
+			}
+			fmt.Printf("   line %d: ⏭️  %s at %s duplicates %s\n", row.Line, row.JobTitle, row.CompanyName, duplicateOf)
+		case models.ImportActionInvalid:
+			fmt.Printf("   line %d: ❌ %s\n", row.Line, strings.Join(row.Errors, "; "))
+		}
+	}
+
+	verb := "Imported"
+	if *dryRun || importErr != nil {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d job applications, %d duplicates, %d invalid rows\n", verb, summary.Created, summary.Duplicates, summary.Invalid)
+	return importErr
+}
+
+func runIngestEmailsCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("ingest-emails", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "match and classify the emails without storing anything")
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: data-analyzer ingest-emails [flags] <.eml, .mbox or directory>...")
+	}
+
+	var messages []emails.Message
+	for _, arg := range flags.Args() {
+		paths := []string{arg}
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			paths = nil
+			err := filepath.WalkDir(arg, func(path string, entry os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if ext := strings.ToLower(filepath.Ext(path)); !entry.IsDir() && (ext == ".eml" || ext == ".mbox") {
+					paths = append(paths, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list emails in %s: %w", arg, err)
+			}
+		}
+		for _, path := range paths {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read email file: %w", err)
+			}
+			parsed, err := emails.Parse(path, content)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			messages = append(messages, parsed...)
+		}
+	}
+
+	result, err := scenarios.NewIngestEmailsScenario(cfg, geminiClient, database).Execute(ctx, messages, *dryRun)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(result)
+	}
+
+	for _, email := range result.Emails {
+		fmt.Printf("   %s: %q from %s\n", email.State, email.Subject, email.From)
+		fmt.Printf("      %s (%.2f)", email.Category, email.CategoryConfidence)
+		if email.JobApplicationID != 0 {
+			fmt.Printf(", job application #%d %s at %s, %s (%.2f)", email.JobApplicationID, email.JobTitle, email.CompanyName, email.MatchReason, email.MatchConfidence)
+		} else {
+			fmt.Printf(", %s", email.MatchReason)
+		}
+		if email.ProposedStatus != "" {
+			fmt.Printf(", status -> %s", email.ProposedStatus)
+		}
+		fmt.Println()
+	}
+
+	if *dryRun {
+		fmt.Println("Dry run, nothing was stored")
+	}
+	fmt.Printf("📬 %d applied, %d proposed, %d recorded, %d to review, %d already ingested\n",
+		result.Applied, result.Proposed, result.Recorded, result.Review, result.AlreadyIngested)
+	return nil
+}
+
+func runGhostingCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("ghosting", flag.ContinueOnError)
+	move := flags.Bool("move", false, "move the ghosted job applications to Ghosted and record why")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	scenario := scenarios.NewDetectGhostingScenario(cfg, database)
+	report, err := scenario.Execute(time.Now())
+	if err != nil {
+		return err
+	}
+	if *move {
+		if report, err = scenario.MoveToGhosted(report, nil); err != nil {
+			return err
+		}
+	}
+	if *asJSON {
+		return printJSON(report)
+	}
+
+	fmt.Printf("👻 %d ghosted job applications\n", len(report.Ghosted))
+	for _, suggestion := range report.Ghosted {
+		fmt.Printf("   #%d %s at %s: %s\n", suggestion.JobApplicationID, suggestion.JobTitle, suggestion.CompanyName, suggestion.Reason)
+	}
+	fmt.Printf("📨 %d follow-ups due\n", len(report.FollowUps))
+	for _, suggestion := range report.FollowUps {
+		fmt.Printf("   #%d %s at %s: %s\n", suggestion.JobApplicationID, suggestion.JobTitle, suggestion.CompanyName, suggestion.Reason)
+	}
+	if len(report.Ghosted) > 0 && !*move {
+		fmt.Println("Run with -move to move the ghosted job applications to Ghosted")
+	}
+	return nil
+}
+
+func runCalendarCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("calendar", flag.ContinueOnError)
+	output := flags.String("o", "", "file to write the calendar to (default: stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	events, err := scenarios.NewExportCalendarScenario(cfg, database).Execute(time.Now())
+	if err != nil {
+		return err
+	}
+	ics := calendar.Render("Job Applications", events)
+	if *output == "" {
+		fmt.Print(ics)
+		return nil
+	}
+	if err := os.WriteFile(*output, []byte(ics), 0o644); err != nil {
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+	fmt.Printf("📅 Wrote %d events to %s\n", len(events), *output)
+	return nil
+}
+
+func runPrepareInterviewCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("prepare-interview", flag.ContinueOnError)
+	pending := flags.Bool("pending", false, "prepare the job applications that reached one of INTERVIEW_PREP_STATUSES since their last preparation")
+	refresh := flags.Bool("refresh", false, "prepare again even when a preparation for the current status exists")
+	output := flags.String("o", "", "file to write the Markdown export to (default: stdout)")
+	asJSON := flags.Bool("json", false, "print the preparations as JSON instead of Markdown")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	scenario := scenarios.NewPrepareInterviewScenario(cfg, geminiClient, database)
+	var jobs []models.JobApplication
+	if *pending {
+		var err error
+		if jobs, err = scenario.Pending(); err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			fmt.Println("🎤 No job applications waiting for an interview preparation")
+			return nil
+		}
+	} else {
+		if flags.NArg() == 0 {
+			return fmt.Errorf("usage: data-analyzer prepare-interview [flags] <job application id>... or -pending")
+		}
+		ids := make([]int, 0, flags.NArg())
+		for _, arg := range flags.Args() {
+			id, err := strconv.Atoi(arg)
+			if err != nil {
+				return fmt.Errorf("invalid job application id %q", arg)
+			}
+			ids = append(ids, id)
+		}
+		var err error
+		if jobs, err = database.GetJobApplicationsById(ids); err != nil {
+			return err
+		}
+		if len(jobs) != len(ids) {
+			return fmt.Errorf("some of the job applications %v do not exist", ids)
+		}
+	}
+
+	stored, err := scenarios.GetStoredInterviewPreparations(database)
+	if err != nil {
+		return err
+	}
+	toPrepare := []models.JobApplication{}
+	for _, job := range jobs {
+		if preparation, ok := stored[job.ID]; !ok || preparation.Status != job.Status || *refresh {
+			toPrepare = append(toPrepare, job)
+		}
+	}
+	if len(toPrepare) > 0 {
+		prepared, err := scenario.Execute(ctx, toPrepare)
+		if err != nil {
+			return err
+		}
+		for _, preparation := range prepared {
+			stored[preparation.JobId] = preparation
+		}
+	}
+
+	preparations := make([]workflows.InterviewPreparation, 0, len(jobs))
+	for _, job := range jobs {
+		preparations = append(preparations, stored[job.ID])
+	}
+	if *asJSON {
+		return printJSON(preparations)
+	}
+
+	achievements, err := database.GetAllWorkAchievements()
+	if err != nil {
+		return err
+	}
+	exports := make([]string, 0, len(jobs))
+	for i, job := range jobs {
+		exports = append(exports, scenarios.InterviewPreparationMarkdown(job, preparations[i], achievements))
+	}
+	markdown := strings.Join(exports, "\n---\n\n")
+	if *output == "" {
+		fmt.Print(markdown)
+		return nil
+	}
+	if err := os.WriteFile(*output, []byte(markdown), 0o644); err != nil {
+		return fmt.Errorf("failed to write interview preparation: %w", err)
+	}
+	fmt.Printf("🎤 Wrote the interview preparation of %d job applications to %s\n", len(jobs), *output)
+	return nil
+}
+
+func runMockInterviewCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("mock-interview", flag.ContinueOnError)
+	questions := flags.Int("questions", 0, "number of questions (default: MOCK_INTERVIEW_QUESTIONS)")
+	sessionID := flags.Int("session", 0, "resume this in progress session instead of starting a new one")
+	history := flags.Bool("history", false, "list the past sessions and their scores instead of interviewing")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	jobID := 0
+	if flags.NArg() > 0 {
+		var err error
+		if jobID, err = strconv.Atoi(flags.Arg(0)); err != nil {
+			return fmt.Errorf("invalid job application id %q", flags.Arg(0))
+		}
+	}
+
+	if *history {
+		sessions, err := scenarios.GetMockInterviewHistory(database, jobID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🎙️ %d mock interviews\n", len(sessions))
+		for _, session := range sessions {
+			score := "in progress"
+			if session.State == workflows.MockInterviewCompleted {
+				score = fmt.Sprintf("%.0f%%", session.Score)
+			}
+			fmt.Printf("   session %d, job application #%d (%s), %s: %d questions, %s\n",
+				session.SessionID, session.JobId, session.Status, session.StartedAt.Format("2006-01-02"), len(session.Turns), score)
+		}
+		return nil
+	}
+
+	scenario := scenarios.NewMockInterviewScenario(cfg, geminiClient, database)
+	var session workflows.MockInterviewSession
+	if *sessionID != 0 {
+		sessions, err := scenarios.GetStoredMockInterviews(database)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if session, ok = sessions[*sessionID]; !ok {
+			return scenarios.ErrMockInterviewNotFound
+		}
+		if session.State == workflows.MockInterviewCompleted {
+			return scenarios.ErrMockInterviewCompleted
+		}
+	} else {
+		if jobID == 0 {
+			return fmt.Errorf("usage: data-analyzer mock-interview [flags] <job application id>")
+		}
+		jobs, err := database.GetJobApplicationsById([]int{jobID})
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return fmt.Errorf("job application %d not found", jobID)
+		}
+		if session, err = scenario.Start(ctx, jobs[0], *questions); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("🎙️ Mock interview session %d, %d questions. End an answer with an empty line, type /finish to stop and get graded, /quit to resume later.\n",
+		session.SessionID, session.QuestionCount)
+	input := bufio.NewScanner(os.Stdin)
+	for session.State != workflows.MockInterviewCompleted {
+		turn := session.Turns[len(session.Turns)-1]
+		fmt.Printf("\n[%d/%d] %s\n> ", len(session.Turns), session.QuestionCount, turn.Question)
+
+		var lines []string
+		command := ""
+		for input.Scan() {
+			line := input.Text()
+			if len(lines) == 0 && (line == "/finish" || line == "/quit") {
+				command = line
+				break
+			}
+			if strings.TrimSpace(line) == "" {
+				if len(lines) > 0 {
+					break
+				}
+				continue
+			}
+			lines = append(lines, line)
+		}
+		if err := input.Err(); err != nil {
+			return fmt.Errorf("failed to read answer: %w", err)
+		}
+
+		var err error
+		switch {
+		case command == "/quit" || (command == "" && len(lines) == 0):
+			fmt.Printf("\nResume with: data-analyzer mock-interview -session %d\n", session.SessionID)
+			return nil
+		case command == "/finish":
+			session, err = scenario.Finish(ctx, session.SessionID)
+		default:
+			session, err = scenario.Answer(ctx, session.SessionID, strings.Join(lines, "\n"))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\n🏁 Score: %.0f%%\n", session.Score)
+	for i, turn := range session.Turns {
+		if turn.Grade == nil {
+			continue
+		}
+		fmt.Printf("\n%d. %s (%.0f%%)\n", i+1, turn.Question, turn.Grade.Score)
+		for _, criterion := range workflows.MockInterviewRubric {
+			fmt.Printf("   %-16s %d/5\n", criterion.Name, turn.Grade.Scores[criterion.Name])
+		}
+		fmt.Printf("   %s\n", turn.Grade.Feedback)
+		if turn.Grade.Suggestion != "" {
+			fmt.Printf("   Try: %s\n", turn.Grade.Suggestion)
+		}
+	}
+	return nil
+}
+
+func runResearchDocumentsCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("research-documents", flag.ContinueOnError)
+	jobID := flags.Int("job-id", 0, "job application whose company the documents are about")
+	format := flags.String("format", "", "format of the files: html, pdf or text (default: detected)")
+	research := flags.Bool("research", false, "research the company from its documents after adding the files")
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *jobID <= 0 {
+		return fmt.Errorf("usage: data-analyzer research-documents -job-id <id> [-research] [file...]")
+	}
+
+	jobApplications, err := database.GetJobApplicationsById([]int{*jobID})
+	if err != nil {
+		return err
+	}
+	if len(jobApplications) == 0 {
+		return fmt.Errorf("job application %d not found", *jobID)
+	}
+
+	embedder, err := agent.NewEmbedder(cfg, geminiClient)
+	if err != nil {
+		return err
+	}
+	scenario := scenarios.NewResearchDocumentsScenario(cfg, geminiClient, database, embedder)
+	for _, path := range flags.Args() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		document, created, err := scenario.AddDocument(ctx, jobApplications[0], filepath.Base(path), *format, content)
+		if err != nil {
+			return err
+		}
+		if !created {
+			fmt.Printf("📄 %s was already added as document %d\n", path, document.ID)
+		}
+	}
+
+	if *research {
+		results, err := scenario.Execute(ctx, jobApplications)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(results[0])
+		}
+		printDocumentResearch(results[0].ResearchCompany)
+		return nil
+	}
+
+	documents, err := scenario.Documents(jobApplications[0])
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(documents)
+	}
+	if len(documents) == 0 {
+		fmt.Printf("No documents for %s yet\n", jobApplications[0].CompanyName)
+		return nil
+	}
+	for _, document := range documents {
+		fmt.Printf("%4d  %-5s %7d bytes %3d chunks  %s\n", document.ID, document.Format, document.Length, document.Chunks, document.Name)
+	}
+	return nil
+}
+
+// printDocumentResearch prints the findings of research from documents with their citations
+func printDocumentResearch(research workflows.ResearchCompany) {
+	sections := []struct {
+		title string
+		items []workflows.ResearchItem
+	}{
+		{"Software engineering", research.SoftwareEngineering},
+		{"Business", research.Business},
+		{"Company overview", research.CompanyOverview},
+	}
+	for _, section := range sections {
+		fmt.Printf("\n%s\n", section.title)
+		for _, item := range section.items {
+			fmt.Printf("  • %s\n", item.Value)
+			if item.Example != "" {
+				fmt.Printf("    %s\n", item.Example)
+			}
+			if !item.Verified {
+				fmt.Println("    ⚠️  cites no document")
+			}
+			for _, citation := range item.Citations {
+				fmt.Printf("    [%s, bytes %d-%d]\n", citation.Document, citation.StartOffset, citation.EndOffset)
+			}
+		}
+	}
+}
+
+func runCompaniesCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("companies", flag.ContinueOnError)
+	alias := flags.String("alias", "", "add a name variant to the company, matching job applications without a URL that use it")
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	scenario := scenarios.NewCompaniesScenario(database)
+	if flags.NArg() == 0 {
+		companies, err := scenario.Sync()
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(companies)
+		}
+		for _, company := range companies {
+			fmt.Printf("%4d  %-30s %-25s %2d applications  %s\n", company.ID, company.Name, company.Domain,
+				len(company.JobApplicationIDs), strings.Join(company.Aliases, ", "))
+		}
+		return nil
+	}
+
+	companyID, err := strconv.Atoi(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid company id %q", flags.Arg(0))
+	}
+	var view scenarios.CompanyView
+	if *alias != "" {
+		view, err = scenario.AddAlias(companyID, *alias)
+	} else {
+		view, err = scenario.Get(companyID)
+	}
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(view)
+	}
+
+	fmt.Printf("🏢 %s (%s), also known as %s\n", view.Name, view.Domain, strings.Join(view.Aliases, ", "))
+	fmt.Printf("First applied %s, last activity %s\n", view.FirstAppliedAt.Format("2006-01-02"), view.LastActivityAt.Format("2006-01-02"))
+	for _, application := range view.Applications {
+		fmt.Printf("\n#%d %s: %s (%s)\n", application.ID, application.JobTitle, application.Status, application.CreatedAt.Format("2006-01-02"))
+		if application.Salary != nil {
+			fmt.Printf("   salary: %s\n", application.Salary.Compensation.RawText)
+		}
+		if application.RedFlags != nil && len(application.RedFlags.RedFlags) > 0 {
+			fmt.Printf("   red flags: %d, risk %.0f\n", len(application.RedFlags.RedFlags), application.RedFlags.RiskScore)
+		}
+		for _, step := range application.Steps {
+			fmt.Printf("   %s  %s\n", step.CreatedAt.Format("2006-01-02"), step.Title)
+		}
+	}
+	if view.Research != nil {
+		fmt.Printf("\nResearch: %d engineering, %d business and %d overview findings\n",
+			len(view.Research.SoftwareEngineering), len(view.Research.Business), len(view.Research.CompanyOverview))
+	}
+	return nil
+}
+
+func runFunnelCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("funnel", flag.ContinueOnError)
+	by := flags.String("by", strings.Join(analytics.Dimensions, ","), "comma separated dimensions to break the funnel down by")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	asCSV := flags.Bool("csv", false, "print the report as CSV")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := scenarios.NewFunnelAnalyticsScenario(database).Execute(analytics.ParseDimensions(*by))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(report)
+	}
+	if *asCSV {
+		return analytics.WriteCSV(os.Stdout, report)
+	}
+
+	fmt.Printf("%-15s %-25s %7s %10s %7s %9s %7s %12s\n", "DIMENSION", "VALUE", "APPLIED", "INTERVIEWS", "OFFERS", "INTERVIEW", "OFFER", "MEDIAN REPLY")
+	for _, stats := range append([]analytics.Stats{report.Overall}, report.Breakdowns...) {
+		median := "-"
+		if stats.MedianHoursToFirstResponse != nil {
+			median = fmt.Sprintf("%.1fh", *stats.MedianHoursToFirstResponse)
+		}
+		fmt.Printf("%-15s %-25s %7d %10d %7d %8.0f%% %6.0f%% %12s\n", stats.Dimension, stats.Value, stats.Applied,
+			stats.Interviews, stats.Offers, stats.InterviewRate*100, stats.OfferRate*100, median)
+	}
+	return nil
+}
+
+func runResumeVersionsCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("resume-versions", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := scenarios.NewResumeVersionsScenario(database).Execute()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(report)
+	}
+
+	fmt.Printf("%-20s %7s  %-22s %-22s\n", "RESUME VERSION", "APPLIED", "RESPONSE RATE (95% CI)", "INTERVIEW RATE (95% CI)")
+	for _, version := range report.Versions {
+		fmt.Printf("%-20s %7d  %3.0f%% (%3.0f%%-%3.0f%%)      %3.0f%% (%3.0f%%-%3.0f%%)\n", version.ResumeVersion, version.Applied,
+			version.ResponseRate.Rate*100, version.ResponseRate.Low*100, version.ResponseRate.High*100,
+			version.InterviewRate.Rate*100, version.InterviewRate.Low*100, version.InterviewRate.High*100)
+	}
+
+	if len(report.Comparisons) > 0 {
+		controlled := "nothing"
+		if len(report.ControlledFor) > 0 {
+			controlled = strings.Join(report.ControlledFor, " and ")
+		}
+		fmt.Printf("\nComparisons, controlling for %s:\n", controlled)
+	}
+	for _, comparison := range report.Comparisons {
+		verdict := "no significant difference"
+		if comparison.Significant {
+			verdict = "significant"
+		}
+		adjusted := ""
+		if comparison.AdjustedPValue != nil {
+			adjusted = fmt.Sprintf(", adjusted p=%.3f over %d strata", *comparison.AdjustedPValue, comparison.Strata)
+		}
+		fmt.Printf("  %s vs %s %s rate: %+.0f points, p=%.3f%s: %s\n", comparison.A, comparison.B, comparison.Metric,
+			comparison.Difference*100, comparison.PValue, adjusted, verdict)
+	}
+	for _, note := range report.Notes {
+		fmt.Printf("ℹ️  %s\n", note)
+	}
+	return nil
+}
+
+func runDigestCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("digest", flag.ContinueOnError)
+	days := flags.Int("days", cfg.Digest.Days, "number of days the digest covers, ending today")
+	asHTML := flags.Bool("html", false, "print the digest as HTML instead of Markdown")
+	asJSON := flags.Bool("json", false, "print the digest as JSON")
+	dir := flags.String("o", "", "write the Markdown and HTML digests to this directory instead of printing")
+	send := flags.Bool("send", false, "email the digest to DIGEST_TO through DIGEST_SMTP_HOST")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *days <= 0 {
+		return fmt.Errorf("-days must be positive")
+	}
+	cfg.Digest.Days = *days
+
+	scenario := scenarios.NewWeeklyDigestScenario(cfg, database)
+	result, err := scenario.Build(time.Now())
+	if err != nil {
+		return err
+	}
+	if *dir != "" || *send {
+		_, err := scenario.Deliver(result, *dir, *send)
+		return err
+	}
+	if *asJSON {
+		return printJSON(result)
+	}
+
+	var rendered string
+	if *asHTML {
+		rendered, err = digest.RenderHTML(result)
+	} else {
+		rendered, err = digest.RenderMarkdown(result)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Print(rendered)
+	return nil
+}
+
+func runWebhooksCommand(ctx context.Context, cfg *config.Config, database *db.DB, geminiClient *agent.Client, args []string) error {
+	flags := flag.NewFlagSet("webhooks", flag.ContinueOnError)
+	add := flags.String("add", "", "subscribe this http or https URL to the events")
+	events := flags.String("events", "", "comma separated event types of the new subscription, all of them when empty: "+strings.Join(models.WebhookEventTypes, ", "))
+	description := flags.String("description", "", "description of the new subscription")
+	remove := flags.Int("delete", 0, "delete the subscription with this ID and its deliveries")
+	deliveries := flags.Int("deliveries", 0, "show the latest deliveries of the subscription with this ID")
+	dispatch := flags.Bool("dispatch", false, "send the pending events and the due retries now")
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	scenario := scenarios.NewWebhooksScenario(cfg, database)
+	switch {
+	case *add != "":
+		input := scenarios.WebhookSubscriptionInput{URL: *add, EventTypes: strings.Split(*events, ","), Description: *description}
+		if *events == "" {
+			input.EventTypes = nil
+		}
+		subscription, err := scenario.Subscribe(input)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(subscription)
+		}
+		fmt.Printf("📡 Subscription %d created for %s\n", subscription.ID, subscription.URL)
+		fmt.Printf("Secret, shown only once: %s\n", subscription.Secret)
+		return nil
+	case *remove != 0:
+		if err := scenario.Unsubscribe(*remove); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️ Subscription %d deleted\n", *remove)
+		return nil
+	case *deliveries != 0:
+		entries, err := scenario.Deliveries(*deliveries)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(entries)
+		}
+		for _, delivery := range entries {
+			fmt.Printf("%6d  %s  %-18s %-9s %d attempts  %3d  %s\n", delivery.ID, delivery.CreatedAt.Format("2006-01-02 15:04"),
+				delivery.EventType, delivery.Status, delivery.Attempts, delivery.LastStatusCode, delivery.LastError)
+		}
+		return nil
+	case *dispatch:
+		result, err := scenario.Dispatch(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(result)
+		}
+		fmt.Printf("📡 %d events queued %d deliveries, %d delivered, %d retrying, %d failed\n",
+			result.Events, result.Queued, result.Delivered, result.Retrying, result.Failed)
+		return nil
+	}
+
+	subscriptions, err := scenario.List()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(subscriptions)
+	}
+	for _, subscription := range subscriptions {
+		eventTypes := "all events"
+		if len(subscription.EventTypes) > 0 {
+			eventTypes = strings.Join(subscription.EventTypes, ", ")
+		}
+		state := "active"
+		if !subscription.Active {
+			state = "paused"
+		}
+		fmt.Printf("%4d  %-6s %-45s %s  %s\n", subscription.ID, state, subscription.URL, eventTypes, subscription.Description)
+	}
+	return nil
+}
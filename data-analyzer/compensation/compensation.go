@@ -0,0 +1,281 @@
+package compensation
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Pay periods a compensation can be expressed in
+const (
+	PeriodHourly  = "hourly"
+	PeriodMonthly = "monthly"
+	PeriodYearly  = "yearly"
+)
+
+const (
+	hoursPerYear  = 2080
+	monthsPerYear = 12
+)
+
+// Compensation is the structured pay information of a job posting.
+// Min and Max are 0 when no amount was found; Max equals Min for a single amount.
+type Compensation struct {
+	Min          float64 `json:"min"`
+	Max          float64 `json:"max"`
+	Currency     string  `json:"currency"`
+	Period       string  `json:"period"`
+	Equity       bool    `json:"equity"`
+	Bonus        bool    `json:"bonus"`
+	VagueMention bool    `json:"vague_mention"`
+	RawText      string  `json:"raw_text"`
+}
+
+// HasAmount reports whether an amount was found
+func (c Compensation) HasAmount() bool {
+	return c.Max > 0
+}
+
+// NoNumbers reports whether the posting talks about pay (e.g. "competitive salary") without giving any amount
+func (c Compensation) NoNumbers() bool {
+	return c.VagueMention && !c.HasAmount()
+}
+
+var (
+	// an amount with optional thousands separators, decimals and a k suffix
+	amountPattern = `(\d{1,3}(?:[,.\s]\d{3})+|\d+(?:\.\d+)?)\s*([kK])?`
+	// two amounts joined by a dash or "to", with an optional currency marker in front of the second one
+	rangeRe  = regexp.MustCompile(amountPattern + `\s*(?:-|–|—|to)\s*` + currencyMarkerPattern + `?\s*` + amountPattern)
+	singleRe = regexp.MustCompile(amountPattern)
+
+	currencyMarkerPattern = `(?:€|\$|£|(?i:eur|usd|gbp|ron|lei|chf|pln|cad|aud)\b)`
+	currencyBeforeRe      = regexp.MustCompile(currencyMarkerPattern + `\s*$`)
+	currencyAfterRe       = regexp.MustCompile(`^\s*` + currencyMarkerPattern)
+	currencyAnywhereRe    = regexp.MustCompile(`(?:€|\$|£|\b(?i:eur|usd|gbp|ron|lei|chf|pln|cad|aud)\b)`)
+
+	thousandsRe = regexp.MustCompile(`^\d{1,3}([,.\s]\d{3})+$`)
+
+	hourlyRe  = regexp.MustCompile(`(?i)(per hour|an hour|/\s*h(ou)?r?\b|hourly|\bph\b)`)
+	monthlyRe = regexp.MustCompile(`(?i)(per month|a month|/\s*mo(nth)?\b|monthly|\bpm\b|lunar)`)
+	yearlyRe  = regexp.MustCompile(`(?i)(per year|a year|/\s*y(ea)?r\b|yearly|annual|annually|per annum|p\.a\.|\bpa\b|\bgross/year\b)`)
+
+	equityRe = regexp.MustCompile(`(?i)\b(equity|stock options?|rsus?|esop|share options?|shares)\b`)
+	bonusRe  = regexp.MustCompile(`(?i)\b(bonus(es)?|commission|13th salary|profit sharing)\b`)
+	vagueRe  = regexp.MustCompile(`(?i)(competitive (salary|pay|compensation|package|rates?)|attractive (salary|compensation|package)|salary (is )?negotiable|depending on experience|\bDOE\b|market rate|excellent (salary|compensation))`)
+)
+
+// currencyCodes maps currency markers to ISO 4217 codes
+var currencyCodes = map[string]string{
+	"€":   "EUR",
+	"eur": "EUR",
+	"$":   "USD",
+	"usd": "USD",
+	"£":   "GBP",
+	"gbp": "GBP",
+	"ron": "RON",
+	"lei": "RON",
+	"chf": "CHF",
+	"pln": "PLN",
+	"cad": "CAD",
+	"aud": "AUD",
+}
+
+// Parse extracts compensation from free text such as a job description.
+// Only amounts next to a currency marker, or ranges with a k suffix, are considered,
+// so numbers like "5+ years" are not mistaken for pay.
+func Parse(text string) Compensation {
+	return parse(text, true)
+}
+
+// ParseField extracts compensation from a dedicated salary field, where bare numbers are accepted
+func ParseField(text string) Compensation {
+	return parse(text, false)
+}
+
+func parse(text string, requireCurrency bool) Compensation {
+	compensation := Compensation{
+		Equity: equityRe.MatchString(text),
+		Bonus:  bonusRe.MatchString(text),
+	}
+	if !requireCurrency {
+		compensation.RawText = strings.TrimSpace(text)
+	}
+
+	start, end, ok := findAmounts(text, requireCurrency, &compensation)
+	if !ok {
+		compensation.VagueMention = vagueRe.MatchString(text)
+		return compensation
+	}
+
+	// look for the currency and period around the amounts
+	window := text[max(0, start-20):min(len(text), end+40)]
+	if requireCurrency {
+		compensation.RawText = wholeWords(window, start > 20, end+40 < len(text))
+	}
+	if compensation.Currency == "" {
+		if match := currencyAnywhereRe.FindString(window); match != "" {
+			compensation.Currency = currencyCodes[strings.ToLower(match)]
+		}
+	}
+	compensation.Period = detectPeriod(window, compensation.Max)
+	compensation.VagueMention = vagueRe.MatchString(text)
+
+	return compensation
+}
+
+// findAmounts fills in Min/Max (and the currency when it is adjacent) from the first usable amount or range.
+// It returns the byte span of the match.
+func findAmounts(text string, requireCurrency bool, compensation *Compensation) (int, int, bool) {
+	for _, match := range rangeRe.FindAllStringSubmatchIndex(text, -1) {
+		low, lowK := text[match[2]:match[3]], match[4] >= 0
+		high, highK := text[match[6]:match[7]], match[8] >= 0
+		currency := adjacentCurrency(text, match[0], match[1])
+		if requireCurrency && currency == "" && !(lowK || highK) {
+			continue
+		}
+
+		minAmount, err1 := parseAmount(low)
+		maxAmount, err2 := parseAmount(high)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if highK {
+			maxAmount *= 1000
+			// "70-90k" means both ends are in thousands
+			if !lowK && minAmount < 1000 {
+				lowK = true
+			}
+		}
+		if lowK {
+			minAmount *= 1000
+		}
+		if minAmount > maxAmount {
+			minAmount, maxAmount = maxAmount, minAmount
+		}
+		if minAmount <= 0 {
+			continue
+		}
+
+		compensation.Min = minAmount
+		compensation.Max = maxAmount
+		compensation.Currency = currency
+		return match[0], match[1], true
+	}
+
+	for _, match := range singleRe.FindAllStringSubmatchIndex(text, -1) {
+		hasK := match[4] >= 0
+		currency := adjacentCurrency(text, match[0], match[1])
+		if requireCurrency && currency == "" {
+			continue
+		}
+
+		amount, err := parseAmount(text[match[2]:match[3]])
+		if err != nil || amount <= 0 {
+			continue
+		}
+		if hasK {
+			amount *= 1000
+		}
+
+		compensation.Min = amount
+		compensation.Max = amount
+		compensation.Currency = currency
+		return match[0], match[1], true
+	}
+
+	return 0, 0, false
+}
+
+// wholeWords drops the partial words a window was cut through on either side
+func wholeWords(window string, cutStart bool, cutEnd bool) string {
+	window = strings.ToValidUTF8(window, "")
+	if cutStart {
+		if i := strings.IndexAny(window, " \t\n"); i >= 0 {
+			window = window[i:]
+		}
+	}
+	if cutEnd {
+		if i := strings.LastIndexAny(window, " \t\n"); i >= 0 {
+			window = window[:i]
+		}
+	}
+	return strings.TrimSpace(window)
+}
+
+// adjacentCurrency returns the currency written right before or right after the span
+func adjacentCurrency(text string, start int, end int) string {
+	if match := currencyBeforeRe.FindString(text[:start]); match != "" {
+		return currencyCodes[strings.ToLower(strings.TrimSpace(match))]
+	}
+	if match := currencyAfterRe.FindString(text[end:]); match != "" {
+		return currencyCodes[strings.ToLower(strings.TrimSpace(match))]
+	}
+	return ""
+}
+
+// parseAmount parses a number that may contain thousands separators
+func parseAmount(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	// a separator followed by exactly three digits is a thousands separator
+	if thousandsRe.MatchString(value) {
+		value = strings.NewReplacer(",", "", ".", "", " ", "").Replace(value)
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// detectPeriod finds the pay period in the text, or infers it from the size of the amount
+func detectPeriod(text string, amount float64) string {
+	switch {
+	case hourlyRe.MatchString(text):
+		return PeriodHourly
+	case monthlyRe.MatchString(text):
+		return PeriodMonthly
+	case yearlyRe.MatchString(text):
+		return PeriodYearly
+	case amount < 500:
+		return PeriodHourly
+	case amount < 20000:
+		return PeriodMonthly
+	default:
+		return PeriodYearly
+	}
+}
+
+// RateTable converts amounts into a base currency using static rates
+type RateTable struct {
+	BaseCurrency string
+	// Rates holds how much one unit of each currency is worth in the base currency
+	Rates map[string]float64
+}
+
+// Yearly converts the compensation into a yearly range in the base currency.
+// It returns false when there is no amount or the currency has no rate.
+func (t RateTable) Yearly(c Compensation) (float64, float64, bool) {
+	if !c.HasAmount() {
+		return 0, 0, false
+	}
+
+	currency := c.Currency
+	if currency == "" {
+		currency = t.BaseCurrency
+	}
+	rate := 1.0
+	if currency != t.BaseCurrency {
+		var ok bool
+		rate, ok = t.Rates[currency]
+		if !ok {
+			return 0, 0, false
+		}
+	}
+
+	multiplier := 1.0
+	switch c.Period {
+	case PeriodHourly:
+		multiplier = hoursPerYear
+	case PeriodMonthly:
+		multiplier = monthsPerYear
+	}
+
+	return math.Round(c.Min * multiplier * rate), math.Round(c.Max * multiplier * rate), true
+}
@@ -0,0 +1,96 @@
+package compensation
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text     string
+		want     Compensation
+		wantText string
+	}{
+		{
+			text: "Salary: €70,000 - €85,000 per year plus equity",
+			want: Compensation{Min: 70000, Max: 85000, Currency: "EUR", Period: PeriodYearly, Equity: true},
+		},
+		{
+			text: "We have 2 offices and 10 000 customers, salary €5000/month",
+			want: Compensation{Min: 5000, Max: 5000, Currency: "EUR", Period: PeriodMonthly},
+		},
+		{
+			text: "Pay is 70-90k depending on experience",
+			want: Compensation{Min: 70000, Max: 90000, Period: PeriodYearly, VagueMention: true},
+		},
+		{
+			text: "$45 to $60 an hour, annual bonus",
+			want: Compensation{Min: 45, Max: 60, Currency: "USD", Period: PeriodHourly, Bonus: true},
+		},
+		{
+			text: "12.000 - 15.000 RON brut lunar",
+			want: Compensation{Min: 12000, Max: 15000, Currency: "RON", Period: PeriodMonthly},
+		},
+		{
+			text: "5+ years of experience with 3 cloud providers",
+			want: Compensation{},
+		},
+		{
+			text: "Competitive salary and stock options",
+			want: Compensation{Equity: true, VagueMention: true},
+		},
+	}
+	for _, tt := range tests {
+		got := Parse(tt.text)
+		got.RawText = ""
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		text string
+		want Compensation
+	}{
+		{"60000-70000", Compensation{Min: 60000, Max: 70000, Period: PeriodYearly, RawText: "60000-70000"}},
+		{" 4500 ", Compensation{Min: 4500, Max: 4500, Period: PeriodMonthly, RawText: "4500"}},
+		{"85k EUR", Compensation{Min: 85000, Max: 85000, Currency: "EUR", Period: PeriodYearly, RawText: "85k EUR"}},
+		{"", Compensation{}},
+	}
+	for _, tt := range tests {
+		if got := ParseField(tt.text); got != tt.want {
+			t.Errorf("ParseField(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNoNumbers(t *testing.T) {
+	if !Parse("Salary is negotiable").NoNumbers() {
+		t.Error("NoNumbers() = false for a vague mention without an amount")
+	}
+	if Parse("Salary is negotiable, around €60k").NoNumbers() {
+		t.Error("NoNumbers() = true for a vague mention with an amount")
+	}
+}
+
+func TestYearly(t *testing.T) {
+	table := RateTable{BaseCurrency: "EUR", Rates: map[string]float64{"USD": 0.9, "RON": 0.2}}
+	tests := []struct {
+		compensation Compensation
+		wantMin      float64
+		wantMax      float64
+		wantOK       bool
+	}{
+		{Compensation{Min: 60000, Max: 70000, Currency: "EUR", Period: PeriodYearly}, 60000, 70000, true},
+		{Compensation{Min: 5000, Max: 5000, Period: PeriodMonthly}, 60000, 60000, true},
+		{Compensation{Min: 50, Max: 60, Currency: "USD", Period: PeriodHourly}, 93600, 112320, true},
+		{Compensation{Min: 10000, Max: 12000, Currency: "RON", Period: PeriodMonthly}, 24000, 28800, true},
+		{Compensation{Min: 100, Max: 100, Currency: "JPY", Period: PeriodYearly}, 0, 0, false},
+		{Compensation{}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		gotMin, gotMax, ok := table.Yearly(tt.compensation)
+		if gotMin != tt.wantMin || gotMax != tt.wantMax || ok != tt.wantOK {
+			t.Errorf("Yearly(%+v) = %v, %v, %v, want %v, %v, %v", tt.compensation, gotMin, gotMax, ok, tt.wantMin, tt.wantMax, tt.wantOK)
+		}
+	}
+}
@@ -1,29 +1,245 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	// this will automatically load your .env file:
 	_ "github.com/joho/godotenv/autoload"
 )
 
 type Config struct {
-	GeminiAPIKey    string
-	GeminiModel     string
-	ShouldRunAgent  bool
-	ServerPort      string
-	ShouldRunServer bool
-	DBPath          string
+	GeminiAPIKey        string
+	GeminiModel         string
+	ShouldRunAgent      bool
+	ServerPort          string
+	ShouldRunServer     bool
+	DBPath              string
+	BatchTokenBudget    int
+	BatchConcurrency    int
+	RedFlagCategories   []RedFlagCategory
+	RankingWeights      RankingWeights
+	TargetSalary        float64
+	RecencyHalfLife     int
+	BaseCurrency        string
+	CurrencyRates       map[string]float64
+	Dedup               DedupConfig
+	EmbeddingBackend    string
+	EmbeddingModel      string
+	EmbeddingDimensions int
+	// EmbeddingRefreshMinutes is how often the server embeds the changed items, 0 disables the schedule
+	EmbeddingRefreshMinutes int
+	ImportFetchTimeout      int
+	Email                   EmailConfig
+	Ghosting                GhostingConfig
+	Calendar                CalendarConfig
+	InterviewPrep           InterviewPrepConfig
+	Research                ResearchConfig
+	Digest                  DigestConfig
+	Webhooks                WebhooksConfig
+}
+
+// DedupConfig controls near-duplicate detection of job descriptions
+type DedupConfig struct {
+	Threshold           float64
+	SameDomainThreshold float64
+	ShingleSize         int
+	WarnOnNew           bool
+}
+
+// EmailConfig controls how ingested recruiter emails update job applications
+type EmailConfig struct {
+	// ConfidenceThreshold is the minimum match and classification confidence for an email to be applied without review
+	ConfidenceThreshold float64
+	// AutoApplyStatus updates the status right away instead of proposing it
+	AutoApplyStatus bool
+}
+
+// GhostingConfig controls when job applications without activity are reported as ghosted
+type GhostingConfig struct {
+	// Thresholds is the number of days without a new step after which a job application in each status counts as ghosted
+	Thresholds map[string]int
+	// FollowUpThresholds is the number of days without a new step after which a follow-up is due
+	FollowUpThresholds map[string]int
+	// AutoMove moves ghosted job applications to the Ghosted status during the periodic runs
+	AutoMove bool
+	// IntervalHours is how often the server checks for ghosting, 0 disables the periodic check
+	IntervalHours int
+}
+
+// CalendarConfig controls the iCalendar feed
+type CalendarConfig struct {
+	// Location is the time zone of the dates and times written in steps
+	Location *time.Location
+	// InterviewMinutes is the length of interview events whose end is not known
+	InterviewMinutes int
+}
+
+// InterviewPrepConfig controls when interview preparations are generated on their own
+type InterviewPrepConfig struct {
+	// Statuses are the statuses that trigger an interview preparation when a job application reaches them
+	Statuses []string
+	// IntervalHours is how often the server looks for job applications that reached one of the statuses, 0 disables it
+	IntervalHours int
+	// MockQuestions is the number of questions of a mock interview when the request does not say
+	MockQuestions int
+}
+
+// ResearchConfig controls company research
+type ResearchConfig struct {
+	// CacheTTLDays is how long the research of a company is reused before it is researched again
+	CacheTTLDays int
+	// ChunkSize and ChunkOverlap are the size in bytes of the chunks research documents are indexed in,
+	// and how much consecutive chunks overlap
+	ChunkSize    int
+	ChunkOverlap int
+	// DocumentExcerpts is the number of chunks retrieved from the documents of a company for its research
+	DocumentExcerpts int
+}
+
+// DigestConfig controls the weekly digest and where it is delivered
+type DigestConfig struct {
+	// Days is the number of days the digest covers, ending today
+	Days int
+	// DailyGoal is the number of job applications to add every day, DAILY_GOAL in the Django jobs app
+	DailyGoal int
+	// IntervalHours is how often the server delivers the digest, 0 disables the schedule
+	IntervalHours int
+	// Dir is where the Markdown and HTML digests are written, empty to not write them
+	Dir string
+	// SMTPHost, SMTPPort, SMTPUsername and SMTPPassword are the server the digest is sent through,
+	// it is not sent without a host
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	To           []string
+}
+
+// WebhooksConfig controls how the events are delivered to the webhook subscriptions
+type WebhooksConfig struct {
+	// MaxAttempts is the number of times a delivery is attempted before it is given up
+	MaxAttempts int
+	// BackoffSeconds is the wait after the first failed attempt, doubled after every attempt
+	BackoffSeconds int
+	// TimeoutSeconds bounds every attempt
+	TimeoutSeconds int
+	// PollSeconds is how often the server sends the new events and retries the due deliveries
+	PollSeconds int
+}
+
+// RankingWeights controls how much each component contributes to the fit score of a job application
+type RankingWeights struct {
+	Coverage float64 `json:"coverage"`
+	RedFlags float64 `json:"red_flags"`
+	Salary   float64 `json:"salary"`
+	Recency  float64 `json:"recency"`
 }
 
 func LoadConfig() (*Config, error) {
 	cfg := &Config{
-		GeminiAPIKey:    os.Getenv("GEMINI_API_KEY"),
-		GeminiModel:     os.Getenv("GEMINI_MODEL"),
-		ShouldRunAgent:  os.Getenv("SHOULD_RUN_AGENT") == "true",
-		ServerPort:      getEnvOrDefault("SERVER_PORT", ":8081"),
-		ShouldRunServer: os.Getenv("SHOULD_RUN_SERVER") == "true",
-		DBPath:          getEnvOrDefault("DB_PATH", "../server/db.sqlite3"),
+		GeminiAPIKey:     os.Getenv("GEMINI_API_KEY"),
+		GeminiModel:      os.Getenv("GEMINI_MODEL"),
+		ShouldRunAgent:   os.Getenv("SHOULD_RUN_AGENT") == "true",
+		ServerPort:       getEnvOrDefault("SERVER_PORT", ":8081"),
+		ShouldRunServer:  os.Getenv("SHOULD_RUN_SERVER") == "true",
+		DBPath:           getEnvOrDefault("DB_PATH", "../server/db.sqlite3"),
+		BatchTokenBudget: getEnvIntOrDefault("BATCH_TOKEN_BUDGET", 20000),
+		BatchConcurrency: getEnvIntOrDefault("BATCH_CONCURRENCY", 4),
+		RankingWeights: RankingWeights{
+			Coverage: getEnvFloatOrDefault("RANK_WEIGHT_COVERAGE", 0.4),
+			RedFlags: getEnvFloatOrDefault("RANK_WEIGHT_RED_FLAGS", 0.2),
+			Salary:   getEnvFloatOrDefault("RANK_WEIGHT_SALARY", 0.25),
+			Recency:  getEnvFloatOrDefault("RANK_WEIGHT_RECENCY", 0.15),
+		},
+		TargetSalary:    getEnvFloatOrDefault("TARGET_SALARY", 0),
+		RecencyHalfLife: getEnvIntOrDefault("RANK_RECENCY_HALF_LIFE_DAYS", 14),
+		BaseCurrency:    strings.ToUpper(getEnvOrDefault("BASE_CURRENCY", "EUR")),
+		Dedup: DedupConfig{
+			Threshold:           getEnvFloatOrDefault("DEDUP_THRESHOLD", 0.7),
+			SameDomainThreshold: getEnvFloatOrDefault("DEDUP_SAME_DOMAIN_THRESHOLD", 0.5),
+			ShingleSize:         getEnvIntOrDefault("DEDUP_SHINGLE_SIZE", 3),
+			WarnOnNew:           os.Getenv("DEDUP_WARN_ON_NEW") == "true",
+		},
+		EmbeddingBackend:        os.Getenv("EMBEDDING_BACKEND"),
+		EmbeddingModel:          getEnvOrDefault("EMBEDDING_MODEL", "gemini-embedding-001"),
+		EmbeddingDimensions:     getEnvIntOrDefault("EMBEDDING_DIMENSIONS", 768),
+		EmbeddingRefreshMinutes: getEnvIntOrDefault("EMBEDDING_REFRESH_MINUTES", 60),
+		ImportFetchTimeout:      getEnvIntOrDefault("IMPORT_FETCH_TIMEOUT_SECONDS", 20),
+		Email: EmailConfig{
+			ConfidenceThreshold: getEnvFloatOrDefault("EMAIL_CONFIDENCE_THRESHOLD", 0.7),
+			AutoApplyStatus:     os.Getenv("EMAIL_AUTO_APPLY_STATUS") == "true",
+		},
+		Ghosting: GhostingConfig{
+			AutoMove:      os.Getenv("GHOSTING_AUTO_MOVE") == "true",
+			IntervalHours: getEnvIntOrDefault("GHOSTING_INTERVAL_HOURS", 0),
+		},
+		Calendar: CalendarConfig{
+			InterviewMinutes: getEnvIntOrDefault("CALENDAR_INTERVIEW_MINUTES", 60),
+		},
+		InterviewPrep: InterviewPrepConfig{
+			IntervalHours: getEnvIntOrDefault("INTERVIEW_PREP_INTERVAL_HOURS", 0),
+			MockQuestions: getEnvIntOrDefault("MOCK_INTERVIEW_QUESTIONS", 5),
+		},
+		Research: ResearchConfig{
+			CacheTTLDays:     getEnvIntOrDefault("RESEARCH_CACHE_TTL_DAYS", 30),
+			ChunkSize:        getEnvIntOrDefault("RESEARCH_CHUNK_SIZE", 1500),
+			ChunkOverlap:     getEnvIntOrDefault("RESEARCH_CHUNK_OVERLAP", 200),
+			DocumentExcerpts: getEnvIntOrDefault("RESEARCH_DOCUMENT_EXCERPTS", 12),
+		},
+		Digest: DigestConfig{
+			Days:          getEnvIntOrDefault("DIGEST_DAYS", 7),
+			DailyGoal:     getEnvIntOrDefault("DIGEST_DAILY_GOAL", 5),
+			IntervalHours: getEnvIntOrDefault("DIGEST_INTERVAL_HOURS", 0),
+			Dir:           os.Getenv("DIGEST_DIR"),
+			SMTPHost:      os.Getenv("DIGEST_SMTP_HOST"),
+			SMTPPort:      getEnvIntOrDefault("DIGEST_SMTP_PORT", 587),
+			SMTPUsername:  os.Getenv("DIGEST_SMTP_USERNAME"),
+			SMTPPassword:  os.Getenv("DIGEST_SMTP_PASSWORD"),
+			From:          getEnvOrDefault("DIGEST_FROM", os.Getenv("DIGEST_SMTP_USERNAME")),
+			To:            splitList(os.Getenv("DIGEST_TO")),
+		},
+		Webhooks: WebhooksConfig{
+			MaxAttempts:    getEnvIntOrDefault("WEBHOOK_MAX_ATTEMPTS", 8),
+			BackoffSeconds: getEnvIntOrDefault("WEBHOOK_BACKOFF_SECONDS", 60),
+			TimeoutSeconds: getEnvIntOrDefault("WEBHOOK_TIMEOUT_SECONDS", 10),
+			PollSeconds:    getEnvIntOrDefault("WEBHOOK_POLL_SECONDS", 5),
+		},
+	}
+
+	redFlagCategories, err := loadRedFlagCategories(os.Getenv("RED_FLAG_CATEGORIES_FILE"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.RedFlagCategories = redFlagCategories
+
+	currencyRates, err := parseCurrencyRates(getEnvOrDefault("CURRENCY_RATES", defaultCurrencyRates))
+	if err != nil {
+		return nil, err
+	}
+	cfg.CurrencyRates = currencyRates
+
+	cfg.Calendar.Location, err = time.LoadLocation(getEnvOrDefault("CALENDAR_TIMEZONE", "Local"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CALENDAR_TIMEZONE: %w", err)
+	}
+
+	cfg.Ghosting.Thresholds, err = parseStatusDays(getEnvOrDefault("GHOSTING_THRESHOLDS", defaultGhostingThresholds))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Ghosting.FollowUpThresholds, err = parseStatusDays(getEnvOrDefault("GHOSTING_FOLLOW_UP_THRESHOLDS", defaultFollowUpThresholds))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.InterviewPrep.Statuses, err = parseStatuses(getEnvOrDefault("INTERVIEW_PREP_STATUSES", defaultInterviewPrepStatuses))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INTERVIEW_PREP_STATUSES: %w", err)
 	}
 
 	return cfg, nil
@@ -35,3 +251,17 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvFloatOrDefault(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && value >= 0 {
+		return value
+	}
+	return defaultValue
+}
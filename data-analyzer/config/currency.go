@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultCurrencyRates is how much one unit of each currency is worth in EUR
+const defaultCurrencyRates = "USD=0.92,GBP=1.17,CHF=1.05,RON=0.20,PLN=0.23,CAD=0.68,AUD=0.61"
+
+// parseCurrencyRates parses a comma separated list of CODE=rate pairs
+func parseCurrencyRates(value string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		code, rateString, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid currency rate %q, expected CODE=rate", pair)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateString), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid currency rate %q: rate must be a positive number", pair)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	return rates, nil
+}
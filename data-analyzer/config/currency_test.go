@@ -0,0 +1,36 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCurrencyRates(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{"", map[string]float64{}, false},
+		{"USD=0.92", map[string]float64{"USD": 0.92}, false},
+		{" usd = 0.92 ,, gbp=1.17,", map[string]float64{"USD": 0.92, "GBP": 1.17}, false},
+		{"USD", nil, true},
+		{"USD=abc", nil, true},
+		{"USD=0", nil, true},
+		{"USD=-1", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCurrencyRates(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCurrencyRates(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCurrencyRates(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := parseCurrencyRates(defaultCurrencyRates); err != nil {
+		t.Errorf("default currency rates do not parse: %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package config
+
+import "strings"
+
+// splitList parses a comma separated list such as the digest recipients, dropping empty entries
+func splitList(value string) []string {
+	values := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			values = append(values, entry)
+		}
+	}
+	return values
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"data-analyzer/models"
+)
+
+// defaultGhostingThresholds is after how many days without a new step a job application in each status counts as ghosted
+const defaultGhostingThresholds = "Applied=21,HR Interview=10,Technical Interview=14"
+
+// defaultFollowUpThresholds is after how many days without a new step a follow-up is due
+const defaultFollowUpThresholds = "Applied=7,HR Interview=4,Technical Interview=5"
+
+// parseStatusDays parses a comma separated list of Status=days pairs
+func parseStatusDays(value string) (map[string]int, error) {
+	days := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		status, daysString, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid status threshold %q, expected Status=days", pair)
+		}
+		status = strings.TrimSpace(status)
+		if !slices.Contains(models.StatusChoices, status) {
+			return nil, fmt.Errorf("invalid status threshold %q: status must be one of %s", pair, strings.Join(models.StatusChoices, ", "))
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(daysString))
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("invalid status threshold %q: days must be a positive number", pair)
+		}
+		days[status] = count
+	}
+	return days, nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStatusDays(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string]int
+		wantErr bool
+	}{
+		{"", map[string]int{}, false},
+		{"Applied=21", map[string]int{"Applied": 21}, false},
+		{" Applied = 21 , HR Interview=10,", map[string]int{"Applied": 21, "HR Interview": 10}, false},
+		{"Applied", nil, true},
+		{"applied=21", nil, true},
+		{"Waiting=5", nil, true},
+		{"Applied=soon", nil, true},
+		{"Applied=0", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseStatusDays(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseStatusDays(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseStatusDays(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{defaultGhostingThresholds, defaultFollowUpThresholds} {
+		if _, err := parseStatusDays(value); err != nil {
+			t.Errorf("default thresholds %q do not parse: %v", value, err)
+		}
+	}
+}
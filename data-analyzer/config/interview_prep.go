@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"data-analyzer/models"
+)
+
+// defaultInterviewPrepStatuses are the statuses in which a job application gets an interview preparation
+const defaultInterviewPrepStatuses = "Technical Interview,HR Interview"
+
+// parseStatuses parses a comma separated list of job application statuses
+func parseStatuses(value string) ([]string, error) {
+	statuses := []string{}
+	for _, status := range strings.Split(value, ",") {
+		status = strings.TrimSpace(status)
+		if status == "" {
+			continue
+		}
+		if !slices.Contains(models.StatusChoices, status) {
+			return nil, fmt.Errorf("invalid status %q: status must be one of %s", status, strings.Join(models.StatusChoices, ", "))
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStatuses(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"", []string{}, false},
+		{defaultInterviewPrepStatuses, []string{"Technical Interview", "HR Interview"}, false},
+		{" Offer ,, Applied ", []string{"Offer", "Applied"}, false},
+		{"Technical Interview,Onsite", nil, true},
+		{"offer", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseStatuses(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseStatuses(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseStatuses(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
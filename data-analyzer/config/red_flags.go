@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// RedFlagCategory describes a category of red flags the analyzer looks for in job descriptions
+type RedFlagCategory struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var defaultRedFlagCategories = []RedFlagCategory{
+	{
+		Name:        "UNREALISTIC_EXPECTATIONS",
+		Description: "Requiring excessive years of experience for entry/mid-level roles, expecting expertise in too many technologies",
+	},
+	{
+		Name:        "POOR_WORK_LIFE_BALANCE",
+		Description: `Phrases like "fast-paced environment", "wear many hats", "startup mentality", "flexible hours" (often meaning long hours)`,
+	},
+	{
+		Name:        "COMPENSATION_ISSUES",
+		Description: `Vague or missing salary information, "competitive salary" without details, unpaid overtime expectations`,
+	},
+	{
+		Name:        "HIGH_TURNOVER",
+		Description: `Frequently hiring for same role, "immediate start" urgency`,
+	},
+	{
+		Name:        "TOXIC_CULTURE",
+		Description: `Emphasis on "family" culture, "drama-free", "thick skin required"`,
+	},
+	{
+		Name:        "UNREASONABLE_REQUIREMENTS",
+		Description: "Expecting senior skills at junior pay, requiring unpaid trial work",
+	},
+}
+
+// loadRedFlagCategories reads the red flag categories from a JSON file.
+// The built-in categories are used when no file is configured.
+func loadRedFlagCategories(path string) ([]RedFlagCategory, error) {
+	if path == "" {
+		return defaultRedFlagCategories, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read red flag categories: %w", err)
+	}
+
+	var categories []RedFlagCategory
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return nil, fmt.Errorf("failed to parse red flag categories: %w", err)
+	}
+	if len(categories) == 0 {
+		return nil, fmt.Errorf("red flag categories file %s is empty", path)
+	}
+
+	return categories, nil
+}
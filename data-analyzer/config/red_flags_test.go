@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadRedFlagCategories(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    []RedFlagCategory
+		wantErr bool
+	}{
+		{"built-in categories", "", defaultRedFlagCategories, false},
+		{
+			"custom categories",
+			write("custom.json", `[{"name": "ON_CALL", "description": "24/7 on call"}, {"name": "RELOCATION"}]`),
+			[]RedFlagCategory{{Name: "ON_CALL", Description: "24/7 on call"}, {Name: "RELOCATION"}},
+			false,
+		},
+		{"missing file", filepath.Join(dir, "missing.json"), nil, true},
+		{"invalid JSON", write("invalid.json", `{"name": "ON_CALL"}`), nil, true},
+		{"empty list", write("empty.json", `[]`), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadRedFlagCategories(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadRedFlagCategories() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadRedFlagCategories() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"data-analyzer/models"
+)
+
+// ErrCompanyNotFound is returned when no company has the given ID
+var ErrCompanyNotFound = errors.New("company not found")
+
+// GetCompanies retrieves every company with its aliases and job applications, oldest first
+func (db *DB) GetCompanies() ([]models.Company, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, name, domain, created_at, updated_at
+		FROM analyzer_company
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query companies: %w", err)
+	}
+	defer rows.Close()
+
+	companies := []models.Company{}
+	index := make(map[int]int)
+	for rows.Next() {
+		company := models.Company{Aliases: []string{}, JobApplicationIDs: []int{}}
+		if err := rows.Scan(&company.ID, &company.Name, &company.Domain, &company.CreatedAt, &company.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan company row: %w", err)
+		}
+		index[company.ID] = len(companies)
+		companies = append(companies, company)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read companies: %w", err)
+	}
+
+	aliasRows, err := db.conn.Query(`SELECT company_id, alias FROM analyzer_company_alias ORDER BY company_id, rowid`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query company aliases: %w", err)
+	}
+	defer aliasRows.Close()
+	for aliasRows.Next() {
+		var companyID int
+		var alias string
+		if err := aliasRows.Scan(&companyID, &alias); err != nil {
+			return nil, fmt.Errorf("failed to scan company alias row: %w", err)
+		}
+		if i, ok := index[companyID]; ok {
+			companies[i].Aliases = append(companies[i].Aliases, alias)
+		}
+	}
+
+	jobRows, err := db.conn.Query(`SELECT company_id, job_application_id FROM analyzer_company_job ORDER BY job_application_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query company job applications: %w", err)
+	}
+	defer jobRows.Close()
+	for jobRows.Next() {
+		var companyID, jobApplicationID int
+		if err := jobRows.Scan(&companyID, &jobApplicationID); err != nil {
+			return nil, fmt.Errorf("failed to scan company job application row: %w", err)
+		}
+		if i, ok := index[companyID]; ok {
+			companies[i].JobApplicationIDs = append(companies[i].JobApplicationIDs, jobApplicationID)
+		}
+	}
+
+	return companies, nil
+}
+
+// SaveCompanies stores the companies in a single transaction: companies without an ID are created,
+// the others updated, aliases are added to the stored ones and the job applications of every company
+// are replaced. Stored companies missing from the list are deleted. The companies are returned with
+// their IDs.
+func (db *DB) SaveCompanies(companies []models.Company) ([]models.Company, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	kept := []interface{}{}
+	for i, company := range companies {
+		if company.ID == 0 {
+			result, err := tx.Exec(`
+				INSERT INTO analyzer_company (name, domain, created_at, updated_at) VALUES (?, ?, ?, ?)
+			`, company.Name, company.Domain, now, now)
+			if err != nil {
+				return nil, fmt.Errorf("failed to insert company: %w", err)
+			}
+			id, err := result.LastInsertId()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get last insert id: %w", err)
+			}
+			companies[i].ID = int(id)
+		} else {
+			_, err := tx.Exec(`
+				UPDATE analyzer_company SET name = ?, domain = ?, updated_at = ?
+				WHERE id = ? AND (name != ? OR domain != ?)
+			`, company.Name, company.Domain, now, company.ID, company.Name, company.Domain)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update company: %w", err)
+			}
+		}
+		kept = append(kept, companies[i].ID)
+
+		for _, alias := range company.Aliases {
+			_, err := tx.Exec(`INSERT OR IGNORE INTO analyzer_company_alias (company_id, alias) VALUES (?, ?)`, companies[i].ID, alias)
+			if err != nil {
+				return nil, fmt.Errorf("failed to insert company alias: %w", err)
+			}
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM analyzer_company_job`); err != nil {
+		return nil, fmt.Errorf("failed to delete company job applications: %w", err)
+	}
+	for _, company := range companies {
+		for _, jobApplicationID := range company.JobApplicationIDs {
+			_, err := tx.Exec(`
+				INSERT INTO analyzer_company_job (job_application_id, company_id) VALUES (?, ?)
+			`, jobApplicationID, company.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to insert company job application: %w", err)
+			}
+		}
+	}
+
+	// companies that lost all their job applications
+	placeholders := "0"
+	for range kept {
+		placeholders += ", ?"
+	}
+	if _, err := tx.Exec(`DELETE FROM analyzer_company_alias WHERE company_id NOT IN (`+placeholders+`)`, kept...); err != nil {
+		return nil, fmt.Errorf("failed to delete company aliases: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM analyzer_company WHERE id NOT IN (`+placeholders+`)`, kept...); err != nil {
+		return nil, fmt.Errorf("failed to delete companies: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit companies: %w", err)
+	}
+	return companies, nil
+}
+
+// AddCompanyAlias adds a name the company is known by, so job applications without a URL using that
+// name are matched to it
+func (db *DB) AddCompanyAlias(companyID int, alias string) error {
+	var exists int
+	err := db.conn.QueryRow(`SELECT 1 FROM analyzer_company WHERE id = ?`, companyID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCompanyNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query company: %w", err)
+	}
+
+	_, err = db.conn.Exec(`INSERT OR IGNORE INTO analyzer_company_alias (company_id, alias) VALUES (?, ?)`, companyID, alias)
+	if err != nil {
+		return fmt.Errorf("failed to insert company alias: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"fmt"
+
+	"data-analyzer/models"
+)
+
+// UpsertCompanyResearch stores the research of a company, replacing the previous research with the same key
+func (db *DB) UpsertCompanyResearch(research models.CompanyResearch) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO analyzer_company_research (key, company_name, workflow_id, research, researched_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			company_name = excluded.company_name,
+			workflow_id = excluded.workflow_id,
+			research = excluded.research,
+			researched_at = excluded.researched_at
+	`, research.Key, research.CompanyName, research.WorkflowID, research.Research, research.ResearchedAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to upsert company research: %w", err)
+	}
+	return nil
+}
+
+// GetAllCompanyResearch retrieves the cached research of every company, keyed by company key
+func (db *DB) GetAllCompanyResearch() (map[string]models.CompanyResearch, error) {
+	rows, err := db.conn.Query(`
+		SELECT key, company_name, workflow_id, research, researched_at
+		FROM analyzer_company_research
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query company research: %w", err)
+	}
+	defer rows.Close()
+
+	research := make(map[string]models.CompanyResearch)
+	for rows.Next() {
+		var r models.CompanyResearch
+		if err := rows.Scan(&r.Key, &r.CompanyName, &r.WorkflowID, &r.Research, &r.ResearchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan company research row: %w", err)
+		}
+		research[r.Key] = r
+	}
+
+	return research, nil
+}
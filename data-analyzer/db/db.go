@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"data-analyzer/models"
@@ -13,6 +14,8 @@ import (
 // DB wraps the database connection
 type DB struct {
 	conn *sql.DB
+	// textSearch is set when SQLite supports FTS5 and the full-text index exists
+	textSearch bool
 }
 
 // New creates a new database connection
@@ -27,7 +30,12 @@ func New(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &DB{conn: conn}, nil
+	db := &DB{conn: conn}
+	if err := db.migrate(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
 }
 
 // Close closes the database connection
@@ -38,7 +46,8 @@ func (db *DB) Close() error {
 // GetAllJobApplications retrieves all job applications from the database
 func (db *DB) GetAllJobApplications() ([]models.JobApplication, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, job_title, job_description, company_name, company_url
+		SELECT id, job_title, job_description, company_name, company_url,
+			salary, resume_version, status, source, created_at, updated_at
 		FROM jobs_jobapplication
 		ORDER BY created_at DESC
 	`)
@@ -52,6 +61,7 @@ func (db *DB) GetAllJobApplications() ([]models.JobApplication, error) {
 		var app models.JobApplication
 		err := rows.Scan(
 			&app.ID, &app.JobTitle, &app.JobDescription, &app.CompanyName, &app.CompanyURL,
+			&app.Salary, &app.ResumeVersion, &app.Status, &app.Source, &app.CreatedAt, &app.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
@@ -70,7 +80,8 @@ func (db *DB) GetJobApplicationsById(jobapplicationIds []int) ([]models.JobAppli
 	}
 	jobapplicationIdsString = jobapplicationIdsString[:len(jobapplicationIdsString)-1]
 	queryString := fmt.Sprintf(`
-		SELECT id, job_title, job_description, company_name, company_url
+		SELECT id, job_title, job_description, company_name, company_url,
+			salary, resume_version, status, source, created_at, updated_at
 		FROM jobs_jobapplication
 		WHERE id IN (%s)
 	`, jobapplicationIdsString)
@@ -85,6 +96,7 @@ func (db *DB) GetJobApplicationsById(jobapplicationIds []int) ([]models.JobAppli
 		var app models.JobApplication
 		err := rows.Scan(
 			&app.ID, &app.JobTitle, &app.JobDescription, &app.CompanyName, &app.CompanyURL,
+			&app.Salary, &app.ResumeVersion, &app.Status, &app.Source, &app.CreatedAt, &app.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
@@ -95,6 +107,37 @@ func (db *DB) GetJobApplicationsById(jobapplicationIds []int) ([]models.JobAppli
 	return applications, nil
 }
 
+// execer is implemented by both the connection and transactions
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// InsertJobApplication inserts a new job application and returns its ID.
+// CreatedAt and UpdatedAt are set to the current time in UTC, as Django stores them.
+func (db *DB) InsertJobApplication(app models.JobApplication) (int, error) {
+	return insertJobApplication(db.conn, app, time.Now())
+}
+
+func insertJobApplication(conn execer, app models.JobApplication, createdAt time.Time) (int, error) {
+	result, err := conn.Exec(`
+		INSERT INTO jobs_jobapplication (job_title, job_description, company_name, company_url,
+			salary, resume_version, status, source, cover_letter, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, '', ?, ?)
+	`, app.JobTitle, app.JobDescription, app.CompanyName, app.CompanyURL,
+		app.Salary, app.ResumeVersion, app.Status, app.Source,
+		createdAt.UTC().Format("2006-01-02 15:04:05"), time.Now().UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert job application: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	return int(id), nil
+}
+
 // InsertWorkflow inserts a new workflow record into the database
 func (db *DB) InsertWorkflow(workflow models.Workflow) (int64, error) {
 	result, err := db.conn.Exec(`
@@ -126,6 +169,19 @@ func (db *DB) InsertJobApplicationsWorkflow(jobApplicationIDs []int, workflowID
 	return nil
 }
 
+// JobApplicationHasWorkflow reports whether the workflow is linked to the job application
+func (db *DB) JobApplicationHasWorkflow(jobApplicationID int, workflowID int) (bool, error) {
+	var count int
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*) FROM jobs_jobapplication_workflows
+		WHERE jobapplication_id = ? AND workflow_id = ?
+	`, jobApplicationID, workflowID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to query job application workflow: %w", err)
+	}
+	return count > 0, nil
+}
+
 // GetAllWorkflows retrieves all workflow records from the database
 func (db *DB) GetAllWorkflows() ([]models.Workflow, error) {
 	rows, err := db.conn.Query(`
@@ -153,13 +209,253 @@ func (db *DB) GetAllWorkflows() ([]models.Workflow, error) {
 	return workflows, nil
 }
 
+// GetWorkflow retrieves a workflow record with the IDs of the job applications linked to it
+func (db *DB) GetWorkflow(workflowID int) (models.Workflow, []int, error) {
+	var w models.Workflow
+	err := db.conn.QueryRow(`
+		SELECT workflow_id, workflow_name, created_at, prompt, agent_model, output, parameters
+		FROM jobs_workflow
+		WHERE workflow_id = ?
+	`, workflowID).Scan(&w.ID, &w.WorkflowName, &w.CreatedAt, &w.Prompt, &w.AgentModel, &w.Output, &w.Parameters)
+	if err != nil {
+		return w, nil, fmt.Errorf("failed to query workflow: %w", err)
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT jobapplication_id FROM jobs_jobapplication_workflows
+		WHERE workflow_id = ?
+		ORDER BY jobapplication_id
+	`, workflowID)
+	if err != nil {
+		return w, nil, fmt.Errorf("failed to query job application workflows: %w", err)
+	}
+	defer rows.Close()
+
+	jobApplicationIDs := []int{}
+	for rows.Next() {
+		var jobApplicationID int
+		if err := rows.Scan(&jobApplicationID); err != nil {
+			return w, nil, fmt.Errorf("failed to scan job application workflow row: %w", err)
+		}
+		jobApplicationIDs = append(jobApplicationIDs, jobApplicationID)
+	}
+	return w, jobApplicationIDs, nil
+}
+
 func (db *DB) AddStepToJobApplication(jobApplicationID int, step models.StepInput) error {
-	_, err := db.conn.Exec(`
+	return addStep(db.conn, jobApplicationID, step)
+}
+
+func addStep(conn execer, jobApplicationID int, step models.StepInput) error {
+	_, err := conn.Exec(`
 		INSERT INTO jobs_step (job_application_id, title, description, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?)
-	`, jobApplicationID, step.Title, step.Description, time.Now().Format("2006-01-02 15:04:05"), time.Now().Format("2006-01-02 15:04:05"))
+	`, jobApplicationID, step.Title, step.Description, time.Now().UTC().Format("2006-01-02 15:04:05"), time.Now().UTC().Format("2006-01-02 15:04:05"))
 	if err != nil {
 		return fmt.Errorf("failed to insert job application step: %w", err)
 	}
 	return nil
 }
+
+// UpdateJobApplicationStatus sets the status of a job application
+func (db *DB) UpdateJobApplicationStatus(jobApplicationID int, status string) error {
+	return updateStatus(db.conn, jobApplicationID, status)
+}
+
+// ChangeJobApplicationStatus sets the status of a job application and adds the step explaining the change in a single transaction
+func (db *DB) ChangeJobApplicationStatus(jobApplicationID int, status string, step models.StepInput) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := addStepAndStatus(tx, jobApplicationID, &step, status); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit status change: %w", err)
+	}
+	return nil
+}
+
+// addStepAndStatus adds the step when it is not nil and updates the status when it is not empty
+func addStepAndStatus(conn execer, jobApplicationID int, step *models.StepInput, status string) error {
+	if step != nil {
+		if err := addStep(conn, jobApplicationID, *step); err != nil {
+			return err
+		}
+	}
+	if status != "" {
+		if err := updateStatus(conn, jobApplicationID, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func updateStatus(conn execer, jobApplicationID int, status string) error {
+	_, err := conn.Exec(`
+		UPDATE jobs_jobapplication SET status = ?, updated_at = ? WHERE id = ?
+	`, status, time.Now().UTC().Format("2006-01-02 15:04:05"), jobApplicationID)
+	if err != nil {
+		return fmt.Errorf("failed to update job application status: %w", err)
+	}
+	return nil
+}
+
+// GetAllSteps retrieves the steps of every job application, oldest first
+func (db *DB) GetAllSteps() ([]models.Step, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, job_application_id, title, description, created_at, updated_at
+		FROM jobs_step
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query steps: %w", err)
+	}
+	defer rows.Close()
+
+	var steps []models.Step
+	for rows.Next() {
+		var step models.Step
+		if err := rows.Scan(&step.ID, &step.JobApplicationID, &step.Title, &step.Description, &step.CreatedAt, &step.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan step row: %w", err)
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// GetStep retrieves a step of a job application
+func (db *DB) GetStep(stepID int) (models.Step, error) {
+	var step models.Step
+	err := db.conn.QueryRow(`
+		SELECT id, job_application_id, title, description, created_at, updated_at
+		FROM jobs_step
+		WHERE id = ?
+	`, stepID).Scan(&step.ID, &step.JobApplicationID, &step.Title, &step.Description, &step.CreatedAt, &step.UpdatedAt)
+	if err != nil {
+		return step, fmt.Errorf("failed to query step: %w", err)
+	}
+	return step, nil
+}
+
+// GetLatestSteps retrieves the most recent step of every job application that has steps, keyed by
+// job application ID. Steps with one of the excluded titles are ignored.
+func (db *DB) GetLatestSteps(excludedTitles []string) (map[int]models.Step, error) {
+	excluded := "''"
+	args := make([]interface{}, len(excludedTitles))
+	if len(excludedTitles) > 0 {
+		excluded = strings.TrimSuffix(strings.Repeat("?,", len(excludedTitles)), ",")
+		for i, title := range excludedTitles {
+			args[i] = title
+		}
+	}
+	rows, err := db.conn.Query(fmt.Sprintf(`
+		SELECT s.id, s.job_application_id, s.title, s.description, s.created_at
+		FROM jobs_step s
+		WHERE s.id = (
+			SELECT latest.id FROM jobs_step latest
+			WHERE latest.job_application_id = s.job_application_id AND latest.title NOT IN (%s)
+			ORDER BY latest.created_at DESC, latest.id DESC
+			LIMIT 1
+		)
+	`, excluded), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query steps: %w", err)
+	}
+	defer rows.Close()
+
+	steps := make(map[int]models.Step)
+	for rows.Next() {
+		var step models.Step
+		if err := rows.Scan(&step.ID, &step.JobApplicationID, &step.Title, &step.Description, &step.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan step row: %w", err)
+		}
+		steps[step.JobApplicationID] = step
+	}
+
+	return steps, nil
+}
+
+// GetWorkflowsByName retrieves all workflow records with the given name, newest first
+func (db *DB) GetWorkflowsByName(workflowName string) ([]models.Workflow, error) {
+	rows, err := db.conn.Query(`
+		SELECT workflow_id, workflow_name, created_at, prompt, agent_model, output, parameters
+		FROM jobs_workflow
+		WHERE workflow_name = ?
+		ORDER BY created_at DESC, workflow_id DESC
+	`, workflowName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workflows: %w", err)
+	}
+	defer rows.Close()
+
+	var workflows []models.Workflow
+	for rows.Next() {
+		var w models.Workflow
+		err := rows.Scan(
+			&w.ID, &w.WorkflowName, &w.CreatedAt, &w.Prompt, &w.AgentModel, &w.Output, &w.Parameters,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workflow row: %w", err)
+		}
+		workflows = append(workflows, w)
+	}
+
+	return workflows, nil
+}
+
+// GetAllWorkAchievements retrieves all work achievements together with the work experience they belong to
+func (db *DB) GetAllWorkAchievements() ([]models.WorkAchievement, error) {
+	rows, err := db.conn.Query(`
+		SELECT a.id, e.id, e.job_title, e.company_name, a.description
+		FROM jobs_workachievement a
+		JOIN jobs_workexperience e ON e.id = a.work_experience_id
+		ORDER BY e.start_date DESC, a.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query work achievements: %w", err)
+	}
+	defer rows.Close()
+
+	var achievements []models.WorkAchievement
+	for rows.Next() {
+		var a models.WorkAchievement
+		err := rows.Scan(&a.ID, &a.WorkExperienceID, &a.JobTitle, &a.CompanyName, &a.Description)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan work achievement row: %w", err)
+		}
+		achievements = append(achievements, a)
+	}
+
+	return achievements, nil
+}
+
+// GetAllResearchData retrieves the research notes of all job applications
+func (db *DB) GetAllResearchData() ([]models.ResearchData, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, job_application_id, category, info, created_at, updated_at
+		FROM jobs_researchdata
+		ORDER BY job_application_id, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query research data: %w", err)
+	}
+	defer rows.Close()
+
+	var researchData []models.ResearchData
+	for rows.Next() {
+		var r models.ResearchData
+		err := rows.Scan(&r.ID, &r.JobApplicationID, &r.Category, &r.Info, &r.CreatedAt, &r.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan research data row: %w", err)
+		}
+		researchData = append(researchData, r)
+	}
+
+	return researchData, nil
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"data-analyzer/models"
+)
+
+func TestWrittenTimesAreUTC(t *testing.T) {
+	// a local time zone far from UTC shifts local times by hours when they are read back as UTC
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	database := openTestDB(t, newSourceDB(t))
+	id, err := database.InsertJobApplication(models.JobApplication{JobTitle: "Backend Engineer", CompanyName: "Acme", Status: models.StatusApplied})
+	if err != nil {
+		t.Fatalf("InsertJobApplication() error = %v", err)
+	}
+	if err := database.ChangeJobApplicationStatus(id, models.StatusHRInterview, models.StepInput{Title: "HR Interview"}); err != nil {
+		t.Fatalf("ChangeJobApplicationStatus() error = %v", err)
+	}
+
+	jobs, err := database.GetAllJobApplications()
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("GetAllJobApplications() = %v, %v, want one job application", jobs, err)
+	}
+	steps, err := database.GetLatestSteps(nil)
+	if err != nil {
+		t.Fatalf("GetLatestSteps() error = %v", err)
+	}
+
+	now := time.Now()
+	for name, written := range map[string]time.Time{
+		"job application created_at": jobs[0].CreatedAt,
+		"job application updated_at": jobs[0].UpdatedAt,
+		"step created_at":            steps[id].CreatedAt,
+	} {
+		if difference := now.Sub(written); difference < -time.Minute || difference > time.Minute {
+			t.Errorf("%s = %v, want about %v", name, written, now.UTC())
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"data-analyzer/models"
+)
+
+// ErrEmailNotFound is returned when no ingested email has the given ID
+var ErrEmailNotFound = errors.New("email not found")
+
+// EmailExists reports whether an email with the given Message-ID was already ingested
+func (db *DB) EmailExists(messageID string) (bool, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM analyzer_email WHERE message_id = ?`, messageID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to query email: %w", err)
+	}
+	return count > 0, nil
+}
+
+// InsertEmail stores an ingested email in a single transaction with its effect on the matched job
+// application: the step is added when it is not nil and the status is updated when it is not empty.
+func (db *DB) InsertEmail(email models.Email, step *models.StepInput, status string) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO analyzer_email (message_id, from_address, subject, received_at, job_application_id,
+			match_reason, match_confidence, category, category_confidence, summary, proposed_status, state, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, email.MessageID, email.From, email.Subject, email.ReceivedAt.UTC().Format("2006-01-02 15:04:05"), email.JobApplicationID,
+		email.MatchReason, email.MatchConfidence, email.Category, email.CategoryConfidence, email.Summary,
+		email.ProposedStatus, email.State, time.Now().UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert email: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if err := addStepAndStatus(tx, email.JobApplicationID, step, status); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit email: %w", err)
+	}
+	return int(id), nil
+}
+
+// ResolveEmail saves the outcome of reviewing an email together with its effect on the job application,
+// the same way InsertEmail does
+func (db *DB) ResolveEmail(email models.Email, step *models.StepInput, status string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE analyzer_email SET job_application_id = ?, match_reason = ?, proposed_status = ?, state = ?
+		WHERE id = ?
+	`, email.JobApplicationID, email.MatchReason, email.ProposedStatus, email.State, email.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	if err := addStepAndStatus(tx, email.JobApplicationID, step, status); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit email: %w", err)
+	}
+	return nil
+}
+
+// GetEmails retrieves the ingested emails in the given states, newest first. All emails are returned when no state is given.
+func (db *DB) GetEmails(states []string) ([]models.Email, error) {
+	query := `
+		SELECT id, message_id, from_address, subject, received_at, job_application_id, match_reason,
+			match_confidence, category, category_confidence, summary, proposed_status, state, created_at
+		FROM analyzer_email
+	`
+	args := make([]interface{}, len(states))
+	if len(states) > 0 {
+		query += fmt.Sprintf("WHERE state IN (%s)\n", strings.TrimSuffix(strings.Repeat("?,", len(states)), ","))
+		for i, state := range states {
+			args[i] = state
+		}
+	}
+	query += "ORDER BY received_at DESC, id DESC"
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query emails: %w", err)
+	}
+	defer rows.Close()
+
+	emails := []models.Email{}
+	for rows.Next() {
+		email, err := scanEmail(rows)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+
+	return emails, nil
+}
+
+// GetEmail retrieves a single ingested email
+func (db *DB) GetEmail(id int) (models.Email, error) {
+	row := db.conn.QueryRow(`
+		SELECT id, message_id, from_address, subject, received_at, job_application_id, match_reason,
+			match_confidence, category, category_confidence, summary, proposed_status, state, created_at
+		FROM analyzer_email
+		WHERE id = ?
+	`, id)
+	email, err := scanEmail(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Email{}, ErrEmailNotFound
+	}
+	return email, err
+}
+
+// scanner is implemented by both sql.Row and sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEmail(row scanner) (models.Email, error) {
+	var e models.Email
+	err := row.Scan(&e.ID, &e.MessageID, &e.From, &e.Subject, &e.ReceivedAt, &e.JobApplicationID, &e.MatchReason,
+		&e.MatchConfidence, &e.Category, &e.CategoryConfidence, &e.Summary, &e.ProposedStatus, &e.State, &e.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return e, err
+	}
+	if err != nil {
+		return e, fmt.Errorf("failed to scan email row: %w", err)
+	}
+	return e, nil
+}
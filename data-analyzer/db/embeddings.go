@@ -0,0 +1,96 @@
+package db
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"data-analyzer/models"
+)
+
+// GetEmbeddings retrieves every stored embedding produced by the given model
+func (db *DB) GetEmbeddings(model string) ([]models.Embedding, error) {
+	rows, err := db.conn.Query(`
+		SELECT item_type, item_key, job_application_id, model, content_hash, content, vector, updated_at
+		FROM analyzer_embedding
+		WHERE model = ?
+	`, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var embeddings []models.Embedding
+	for rows.Next() {
+		var e models.Embedding
+		var vector []byte
+		err := rows.Scan(&e.ItemType, &e.ItemKey, &e.JobApplicationID, &e.Model, &e.ContentHash, &e.Content, &vector, &e.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan embedding row: %w", err)
+		}
+		e.Vector = decodeVector(vector)
+		embeddings = append(embeddings, e)
+	}
+
+	return embeddings, nil
+}
+
+// UpsertEmbeddings stores the embeddings in a single transaction, replacing older vectors of the same items
+func (db *DB) UpsertEmbeddings(embeddings []models.Embedding) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, e := range embeddings {
+		_, err := tx.Exec(`
+			INSERT INTO analyzer_embedding (item_type, item_key, job_application_id, model, content_hash, content, vector, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (model, item_type, item_key) DO UPDATE SET
+				job_application_id = excluded.job_application_id,
+				content_hash = excluded.content_hash,
+				content = excluded.content,
+				vector = excluded.vector,
+				updated_at = excluded.updated_at
+		`, e.ItemType, e.ItemKey, e.JobApplicationID, e.Model, e.ContentHash, e.Content, encodeVector(e.Vector), time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to upsert embedding: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit embeddings: %w", err)
+	}
+	return nil
+}
+
+// DeleteEmbedding removes the embedding of an item that no longer exists
+func (db *DB) DeleteEmbedding(model string, itemType string, itemKey string) error {
+	_, err := db.conn.Exec(`
+		DELETE FROM analyzer_embedding
+		WHERE model = ? AND item_type = ? AND item_key = ?
+	`, model, itemType, itemKey)
+	if err != nil {
+		return fmt.Errorf("failed to delete embedding: %w", err)
+	}
+	return nil
+}
+
+// encodeVector stores a vector as little-endian float32 values
+func encodeVector(vector []float32) []byte {
+	encoded := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(encoded[4*i:], math.Float32bits(value))
+	}
+	return encoded
+}
+
+func decodeVector(encoded []byte) []float32 {
+	vector := make([]float32, len(encoded)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(encoded[4*i:]))
+	}
+	return vector
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"data-analyzer/domains"
+	"data-analyzer/models"
+)
+
+// ErrInvalidImportRows is returned when an import has invalid rows and they are not skipped.
+// Nothing is written in that case.
+var ErrInvalidImportRows = errors.New("the import has invalid rows, fix them or skip them")
+
+// ImportJobApplications inserts the rows of an import file in a single transaction and adds an
+// "Imported" step to every created job application. A row is a duplicate, and skipped, when an
+// existing job application or an earlier row has the same company and job title.
+// Dry runs, and imports with invalid rows that are not skipped, are rolled back and report what
+// would have happened.
+func (db *DB) ImportJobApplications(rows []models.ImportRow, options models.ImportOptions) (models.ImportSummary, error) {
+	summary := models.ImportSummary{
+		DryRun: options.DryRun,
+		Rows:   make([]models.ImportRowResult, 0, len(rows)),
+	}
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			summary.Invalid++
+		}
+	}
+	rollback := options.DryRun || (summary.Invalid > 0 && !options.SkipInvalid)
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return summary, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing := make(map[string]int)
+	existingRows, err := tx.Query(`SELECT id, company_name, job_title FROM jobs_jobapplication ORDER BY id`)
+	if err != nil {
+		return summary, fmt.Errorf("failed to query job applications: %w", err)
+	}
+	for existingRows.Next() {
+		var id int
+		var companyName, jobTitle string
+		if err := existingRows.Scan(&id, &companyName, &jobTitle); err != nil {
+			existingRows.Close()
+			return summary, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if key := importKey(companyName, jobTitle); existing[key] == 0 {
+			existing[key] = id
+		}
+	}
+	existingRows.Close()
+	if err := existingRows.Err(); err != nil {
+		return summary, fmt.Errorf("failed to query job applications: %w", err)
+	}
+
+	seen := make(map[string]int)
+	now := time.Now()
+	for _, row := range rows {
+		app := row.JobApplication
+		result := models.ImportRowResult{
+			Line:        row.Line,
+			JobTitle:    app.JobTitle,
+			CompanyName: app.CompanyName,
+			Status:      app.Status,
+			Source:      app.Source,
+		}
+
+		key := importKey(app.CompanyName, app.JobTitle)
+		switch {
+		case len(row.Errors) > 0:
+			result.Action = models.ImportActionInvalid
+			result.Errors = row.Errors
+		case existing[key] > 0:
+			result.Action = models.ImportActionDuplicate
+			result.DuplicateOf = existing[key]
+			summary.Duplicates++
+		case seen[key] > 0:
+			result.Action = models.ImportActionDuplicate
+			result.DuplicateOfLine = seen[key]
+			summary.Duplicates++
+		default:
+			createdAt := now
+			if row.HasCreatedAt {
+				createdAt = app.CreatedAt
+			}
+			id, err := insertJobApplication(tx, app, createdAt)
+			if err != nil {
+				return summary, fmt.Errorf("failed to import line %d: %w", row.Line, err)
+			}
+			err = addStep(tx, id, models.StepInput{
+				Title:       "Imported",
+				Description: fmt.Sprintf("Imported from %s, line %d", options.Origin, row.Line),
+			})
+			if err != nil {
+				return summary, err
+			}
+			result.Action = models.ImportActionCreate
+			result.JobApplicationID = id
+			seen[key] = row.Line
+			summary.Created++
+		}
+		summary.Rows = append(summary.Rows, result)
+	}
+
+	if rollback {
+		// the IDs were only valid inside the rolled back transaction
+		for i := range summary.Rows {
+			summary.Rows[i].JobApplicationID = 0
+		}
+		if !options.DryRun {
+			return summary, ErrInvalidImportRows
+		}
+		return summary, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return summary, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return summary, nil
+}
+
+// importKey identifies a job application by its company and title, ignoring case, punctuation
+// and the company's legal form
+func importKey(companyName string, jobTitle string) string {
+	return domains.CompanyName(companyName) + "|" + strings.Join(domains.Words(jobTitle), " ")
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"data-analyzer/models"
+)
+
+// ErrResearchDocumentNotFound is returned when no research document has the given ID
+var ErrResearchDocumentNotFound = errors.New("research document not found")
+
+// InsertResearchDocument stores a document with its indexed chunks in a single transaction. A document
+// with the same text already stored for the company is kept instead, and its ID is returned with false.
+func (db *DB) InsertResearchDocument(document models.ResearchDocument, chunks []models.ResearchChunk) (int, bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existingID int
+	err = tx.QueryRow(`
+		SELECT id FROM analyzer_research_document WHERE company_key = ? AND content_hash = ?
+	`, document.CompanyKey, document.ContentHash).Scan(&existingID)
+	if err == nil {
+		return existingID, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, fmt.Errorf("failed to query research document: %w", err)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO analyzer_research_document (company_key, name, title, format, content_hash, text, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, document.CompanyKey, document.Name, document.Title, document.Format, document.ContentHash, document.Text,
+		time.Now().UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to insert research document: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if err := insertResearchChunks(tx, int(id), chunks); err != nil {
+		return 0, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("failed to commit research document: %w", err)
+	}
+	return int(id), true, nil
+}
+
+// ReplaceResearchChunks replaces the indexed chunks of a document, e.g. after the embedding model changed
+func (db *DB) ReplaceResearchChunks(documentID int, chunks []models.ResearchChunk) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM analyzer_research_chunk WHERE document_id = ?`, documentID); err != nil {
+		return fmt.Errorf("failed to delete research chunks: %w", err)
+	}
+	if err := insertResearchChunks(tx, documentID, chunks); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit research chunks: %w", err)
+	}
+	return nil
+}
+
+func insertResearchChunks(tx *sql.Tx, documentID int, chunks []models.ResearchChunk) error {
+	for _, chunk := range chunks {
+		_, err := tx.Exec(`
+			INSERT INTO analyzer_research_chunk (document_id, chunk_index, start_offset, end_offset, content, model, vector)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, documentID, chunk.ChunkIndex, chunk.StartOffset, chunk.EndOffset, chunk.Content, chunk.Model, encodeVector(chunk.Vector))
+		if err != nil {
+			return fmt.Errorf("failed to insert research chunk: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetResearchDocuments retrieves the documents of a company, or of every company when the key is empty,
+// without their text
+func (db *DB) GetResearchDocuments(companyKey string) ([]models.ResearchDocument, error) {
+	rows, err := db.conn.Query(`
+		SELECT d.id, d.company_key, d.name, d.title, d.format, d.content_hash, LENGTH(CAST(d.text AS BLOB)), d.created_at,
+			(SELECT COUNT(*) FROM analyzer_research_chunk c WHERE c.document_id = d.id)
+		FROM analyzer_research_document d
+		WHERE ? = '' OR d.company_key = ?
+		ORDER BY d.company_key, d.id
+	`, companyKey, companyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query research documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []models.ResearchDocument{}
+	for rows.Next() {
+		var d models.ResearchDocument
+		err := rows.Scan(&d.ID, &d.CompanyKey, &d.Name, &d.Title, &d.Format, &d.ContentHash, &d.Length, &d.CreatedAt, &d.Chunks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan research document row: %w", err)
+		}
+		documents = append(documents, d)
+	}
+
+	return documents, nil
+}
+
+// GetResearchDocument retrieves a document with its text
+func (db *DB) GetResearchDocument(id int) (models.ResearchDocument, error) {
+	var d models.ResearchDocument
+	err := db.conn.QueryRow(`
+		SELECT d.id, d.company_key, d.name, d.title, d.format, d.content_hash, d.text, d.created_at,
+			(SELECT COUNT(*) FROM analyzer_research_chunk c WHERE c.document_id = d.id)
+		FROM analyzer_research_document d
+		WHERE d.id = ?
+	`, id).Scan(&d.ID, &d.CompanyKey, &d.Name, &d.Title, &d.Format, &d.ContentHash, &d.Text, &d.CreatedAt, &d.Chunks)
+	if errors.Is(err, sql.ErrNoRows) {
+		return d, ErrResearchDocumentNotFound
+	}
+	if err != nil {
+		return d, fmt.Errorf("failed to query research document: %w", err)
+	}
+	d.Length = len(d.Text)
+	return d, nil
+}
+
+// DeleteResearchDocument removes a document and its chunks
+func (db *DB) DeleteResearchDocument(id int) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM analyzer_research_document WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete research document: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return ErrResearchDocumentNotFound
+	}
+	if _, err := tx.Exec(`DELETE FROM analyzer_research_chunk WHERE document_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete research chunks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit research document deletion: %w", err)
+	}
+	return nil
+}
+
+// GetResearchChunks retrieves the indexed chunks of every document of a company
+func (db *DB) GetResearchChunks(companyKey string) ([]models.ResearchChunk, error) {
+	rows, err := db.conn.Query(`
+		SELECT c.document_id, c.chunk_index, c.start_offset, c.end_offset, c.content, c.model, c.vector
+		FROM analyzer_research_chunk c
+		JOIN analyzer_research_document d ON d.id = c.document_id
+		WHERE d.company_key = ?
+		ORDER BY c.document_id, c.chunk_index
+	`, companyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query research chunks: %w", err)
+	}
+	defer rows.Close()
+
+	chunks := []models.ResearchChunk{}
+	for rows.Next() {
+		var c models.ResearchChunk
+		var vector []byte
+		if err := rows.Scan(&c.DocumentID, &c.ChunkIndex, &c.StartOffset, &c.EndOffset, &c.Content, &c.Model, &vector); err != nil {
+			return nil, fmt.Errorf("failed to scan research chunk row: %w", err)
+		}
+		c.Vector = decodeVector(vector)
+		chunks = append(chunks, c)
+	}
+
+	return chunks, nil
+}
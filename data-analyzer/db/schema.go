@@ -0,0 +1,149 @@
+package db
+
+import (
+	"fmt"
+
+	"data-analyzer/models"
+)
+
+// schema holds the tables owned by the analyzer. The jobs_ tables are managed by the Django
+// server's migrations; the analyzer keeps its own data in analyzer_ tables next to them.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS analyzer_embedding (
+		item_type TEXT NOT NULL,
+		item_key TEXT NOT NULL,
+		job_application_id INTEGER NOT NULL DEFAULT 0,
+		model TEXT NOT NULL,
+		content_hash TEXT NOT NULL,
+		content TEXT NOT NULL,
+		vector BLOB NOT NULL,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (model, item_type, item_key)
+	)`,
+	`CREATE TABLE IF NOT EXISTS analyzer_email (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		message_id TEXT NOT NULL UNIQUE,
+		from_address TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		received_at DATETIME NOT NULL,
+		job_application_id INTEGER NOT NULL DEFAULT 0,
+		match_reason TEXT NOT NULL,
+		match_confidence REAL NOT NULL,
+		category TEXT NOT NULL,
+		category_confidence REAL NOT NULL,
+		summary TEXT NOT NULL,
+		proposed_status TEXT NOT NULL,
+		state TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS analyzer_company_research (
+		key TEXT PRIMARY KEY,
+		company_name TEXT NOT NULL,
+		workflow_id INTEGER NOT NULL,
+		research TEXT NOT NULL,
+		researched_at DATETIME NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS analyzer_research_document (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		company_key TEXT NOT NULL,
+		name TEXT NOT NULL,
+		title TEXT NOT NULL,
+		format TEXT NOT NULL,
+		content_hash TEXT NOT NULL,
+		text TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		UNIQUE (company_key, content_hash)
+	)`,
+	`CREATE TABLE IF NOT EXISTS analyzer_research_chunk (
+		document_id INTEGER NOT NULL,
+		chunk_index INTEGER NOT NULL,
+		start_offset INTEGER NOT NULL,
+		end_offset INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		model TEXT NOT NULL,
+		vector BLOB NOT NULL,
+		PRIMARY KEY (document_id, chunk_index)
+	)`,
+	`CREATE TABLE IF NOT EXISTS analyzer_company (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		domain TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS analyzer_company_domain ON analyzer_company (domain) WHERE domain != ''`,
+	`CREATE TABLE IF NOT EXISTS analyzer_company_alias (
+		company_id INTEGER NOT NULL,
+		alias TEXT NOT NULL,
+		PRIMARY KEY (company_id, alias)
+	)`,
+	`CREATE TABLE IF NOT EXISTS analyzer_company_job (
+		job_application_id INTEGER PRIMARY KEY,
+		company_id INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS analyzer_webhook_subscription (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		event_types TEXT NOT NULL,
+		description TEXT NOT NULL,
+		active INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS analyzer_webhook_event (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_type TEXT NOT NULL,
+		event_key TEXT NOT NULL UNIQUE,
+		job_application_id INTEGER NOT NULL DEFAULT 0,
+		ref_id INTEGER NOT NULL DEFAULT 0,
+		data TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		dispatched INTEGER NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS analyzer_webhook_event_dispatched ON analyzer_webhook_event (dispatched, id)`,
+	`CREATE TABLE IF NOT EXISTS analyzer_webhook_delivery (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		subscription_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME NOT NULL,
+		last_status_code INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		delivered_at DATETIME,
+		UNIQUE (subscription_id, event_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS analyzer_webhook_delivery_due ON analyzer_webhook_delivery (status, next_attempt_at)`,
+	// steps and workflows are recorded by triggers so the steps added by the Django server are sent
+	// too; nothing is recorded while there is no active subscription
+	webhookTrigger("jobs_step", models.WebhookEventStepAdded, "'step:' || new.id", "new.job_application_id", "new.id"),
+	webhookTrigger("jobs_workflow", models.WebhookEventWorkflowCompleted, "'workflow:' || new.workflow_id", "0", "new.workflow_id"),
+}
+
+// webhookTrigger builds the trigger recording an event when a row is inserted in the table. The
+// arguments are SQL expressions over the new row.
+func webhookTrigger(table string, eventType string, key string, jobColumn string, refColumn string) string {
+	return fmt.Sprintf(`
+		CREATE TRIGGER IF NOT EXISTS analyzer_webhook_%[1]s_insert AFTER INSERT ON %[1]s
+		WHEN EXISTS (SELECT 1 FROM analyzer_webhook_subscription WHERE active = 1)
+		BEGIN
+			INSERT OR IGNORE INTO analyzer_webhook_event (event_type, event_key, job_application_id, ref_id, created_at)
+			VALUES ('%[2]s', %[3]s, %[4]s, %[5]s, datetime('now'));
+		END
+	`, table, eventType, key, jobColumn, refColumn)
+}
+
+// migrate creates the analyzer tables that do not exist yet
+func (db *DB) migrate() error {
+	for _, statement := range schema {
+		if _, err := db.conn.Exec(statement); err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+	return db.migrateTextSearch()
+}
@@ -0,0 +1,291 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"data-analyzer/models"
+)
+
+// ErrFullTextSearchUnavailable is returned when SQLite was built without FTS5 (build with -tags sqlite_fts5)
+var ErrFullTextSearchUnavailable = errors.New("full-text search is not available, build the analyzer with -tags sqlite_fts5")
+
+// textSearchSchema creates the FTS5 index and the triggers keeping it in sync with the jobs_ tables.
+// Triggers also fire for rows written by the Django server, since both share the database file.
+var textSearchSchema = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS analyzer_search USING fts5(
+		item_type UNINDEXED,
+		item_id UNINDEXED,
+		job_application_id UNINDEXED,
+		title,
+		company,
+		body,
+		prefix = '2 3',
+		tokenize = 'unicode61 remove_diacritics 2'
+	)`,
+	searchTriggers("jobs_jobapplication", models.TextSearchItemJob, "id", "id", "job_title", "company_name", "job_description"),
+	searchTriggers("jobs_step", models.TextSearchItemStep, "id", "job_application_id", "title", "''", "description"),
+	searchTriggers("jobs_researchdata", models.TextSearchItemResearch, "id", "job_application_id", "''", "''", "info"),
+	// workflows are linked to their job applications after they are inserted, so the link is resolved when searching
+	searchTriggers("jobs_workflow", models.TextSearchItemWorkflow, "workflow_id", "0", "workflow_name", "''", "output"),
+}
+
+// searchIndexSources selects the indexed columns of every source table, used to rebuild the index
+var searchIndexSources = []string{
+	`SELECT '` + models.TextSearchItemJob + `', id, id, job_title, company_name, job_description FROM jobs_jobapplication`,
+	`SELECT '` + models.TextSearchItemStep + `', id, job_application_id, title, '', description FROM jobs_step`,
+	`SELECT '` + models.TextSearchItemResearch + `', id, job_application_id, '', '', info FROM jobs_researchdata`,
+	`SELECT '` + models.TextSearchItemWorkflow + `', workflow_id, 0, workflow_name, '', output FROM jobs_workflow`,
+}
+
+// searchTriggers builds the insert, update and delete triggers of a source table.
+// The column arguments are SQL expressions over the row.
+func searchTriggers(table string, itemType string, idColumn string, jobColumn string, titleColumn string, companyColumn string, bodyColumn string) string {
+	values := func(row string) string {
+		column := func(expression string) string {
+			if strings.HasPrefix(expression, "'") || expression == "0" {
+				return expression
+			}
+			return row + "." + expression
+		}
+		return fmt.Sprintf("'%s', %s, %s, %s, %s, %s", itemType, column(idColumn), column(jobColumn), column(titleColumn), column(companyColumn), column(bodyColumn))
+	}
+	remove := fmt.Sprintf("DELETE FROM analyzer_search WHERE item_type = '%s' AND item_id = old.%s;", itemType, idColumn)
+	insert := fmt.Sprintf("INSERT INTO analyzer_search (item_type, item_id, job_application_id, title, company, body) VALUES (%s);", values("new"))
+
+	return fmt.Sprintf(`
+		CREATE TRIGGER IF NOT EXISTS analyzer_search_%[1]s_insert AFTER INSERT ON %[1]s BEGIN %[3]s END;
+		CREATE TRIGGER IF NOT EXISTS analyzer_search_%[1]s_update AFTER UPDATE ON %[1]s BEGIN %[2]s %[3]s END;
+		CREATE TRIGGER IF NOT EXISTS analyzer_search_%[1]s_delete AFTER DELETE ON %[1]s BEGIN %[2]s END;
+	`, table, remove, insert)
+}
+
+// migrateTextSearch creates the full-text index and its triggers when SQLite supports FTS5. The index
+// is only rebuilt when it or one of its triggers was missing, so rows written in the meantime (e.g.
+// after a Django migration rebuilt a table) are indexed. Without FTS5 the triggers left by a build
+// that had it are dropped, since writing to the jobs_ tables would fail on the missing module.
+func (db *DB) migrateTextSearch() error {
+	var fts5 bool
+	if err := db.conn.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&fts5); err != nil {
+		return fmt.Errorf("failed to check full-text support: %w", err)
+	}
+	if !fts5 {
+		return db.dropTextSearchTriggers()
+	}
+
+	var existing int
+	err := db.conn.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE (type = 'table' AND name = 'analyzer_search') OR (type = 'trigger' AND name LIKE 'analyzer\_search\_%' ESCAPE '\')
+	`).Scan(&existing)
+	if err != nil {
+		return fmt.Errorf("failed to check full-text index: %w", err)
+	}
+
+	for _, statement := range textSearchSchema {
+		if _, err := db.conn.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create full-text index: %w", err)
+		}
+	}
+	db.textSearch = true
+
+	// one index and three triggers per source table
+	if existing == 1+3*(len(textSearchSchema)-1) {
+		return nil
+	}
+	return db.RebuildTextSearchIndex()
+}
+
+// dropTextSearchTriggers removes the full-text triggers, the index itself is kept for a build with FTS5
+func (db *DB) dropTextSearchTriggers() error {
+	rows, err := db.conn.Query(`SELECT name FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'analyzer\_search\_%' ESCAPE '\'`)
+	if err != nil {
+		return fmt.Errorf("failed to list full-text triggers: %w", err)
+	}
+	var triggers []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan full-text trigger: %w", err)
+		}
+		triggers = append(triggers, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list full-text triggers: %w", err)
+	}
+
+	for _, name := range triggers {
+		if _, err := db.conn.Exec(`DROP TRIGGER IF EXISTS "` + name + `"`); err != nil {
+			return fmt.Errorf("failed to drop full-text trigger %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// TextSearchAvailable reports whether the full-text index exists
+func (db *DB) TextSearchAvailable() bool {
+	return db.textSearch
+}
+
+// RebuildTextSearchIndex re-indexes every job application, step, research note and workflow output
+func (db *DB) RebuildTextSearchIndex() error {
+	if !db.textSearch {
+		return ErrFullTextSearchUnavailable
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM analyzer_search`); err != nil {
+		return fmt.Errorf("failed to clear full-text index: %w", err)
+	}
+	for _, source := range searchIndexSources {
+		if _, err := tx.Exec(`INSERT INTO analyzer_search (item_type, item_id, job_application_id, title, company, body) ` + source); err != nil {
+			return fmt.Errorf("failed to rebuild full-text index: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit full-text index: %w", err)
+	}
+	return nil
+}
+
+// SearchText runs a full-text query, best match first. Results whose job application is not
+// in one of the statuses are left out when statuses are given.
+func (db *DB) SearchText(query string, itemTypes []string, statuses []string, limit int) ([]models.TextSearchResult, error) {
+	if !db.textSearch {
+		return nil, ErrFullTextSearchUnavailable
+	}
+
+	match := BuildMatchQuery(query)
+	if match == "" {
+		return []models.TextSearchResult{}, nil
+	}
+
+	// workflows are linked to job applications through jobs_jobapplication_workflows
+	queryString := `
+		WITH matches AS (
+			SELECT item_type, item_id,
+				CASE WHEN item_type = ? THEN
+					COALESCE((SELECT MIN(jw.jobapplication_id) FROM jobs_jobapplication_workflows jw WHERE jw.workflow_id = item_id), 0)
+				ELSE job_application_id END AS job_application_id,
+				title,
+				snippet(analyzer_search, -1, '<mark>', '</mark>', '…', 16) AS snippet,
+				bm25(analyzer_search, 0, 0, 0, 5.0, 3.0, 1.0) AS rank
+			FROM analyzer_search
+			WHERE analyzer_search MATCH ?
+		)
+		SELECT m.item_type, m.item_id, m.job_application_id, m.title, m.snippet, m.rank,
+			COALESCE(j.job_title, ''), COALESCE(j.company_name, ''), COALESCE(j.status, '')
+		FROM matches m
+		LEFT JOIN jobs_jobapplication j ON j.id = m.job_application_id
+		WHERE 1 = 1
+	`
+	args := []interface{}{models.TextSearchItemWorkflow, match}
+	if len(itemTypes) > 0 {
+		queryString += ` AND m.item_type IN (` + placeholders(len(itemTypes)) + `)`
+		for _, itemType := range itemTypes {
+			args = append(args, itemType)
+		}
+	}
+	if len(statuses) > 0 {
+		queryString += ` AND j.status IN (` + placeholders(len(statuses)) + `)`
+		for _, status := range statuses {
+			args = append(args, status)
+		}
+	}
+	queryString += ` ORDER BY m.rank LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.conn.Query(queryString, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.TextSearchResult{}
+	for rows.Next() {
+		var r models.TextSearchResult
+		err := rows.Scan(&r.ItemType, &r.ItemID, &r.JobApplicationID, &r.Title, &r.Snippet, &r.Rank, &r.JobTitle, &r.CompanyName, &r.Status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+// BuildMatchQuery turns a user query into a safe FTS5 expression. Double-quoted text is searched
+// as a phrase, a trailing * matches a prefix, OR combines terms, and all other terms must match.
+// Any other FTS5 syntax is treated as plain text.
+func BuildMatchQuery(query string) string {
+	terms := []string{}
+	for len(query) > 0 {
+		query = strings.TrimLeftFunc(query, unicode.IsSpace)
+		if query == "" {
+			break
+		}
+
+		if query[0] == '"' {
+			end := strings.IndexByte(query[1:], '"')
+			phrase := query[1:]
+			query = ""
+			if end >= 0 {
+				phrase, query = phrase[:end], phrase[end+1:]
+			}
+			if term := quoteTerm(phrase); term != "" {
+				terms = append(terms, term)
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(query, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+		if end < 0 {
+			end = len(query)
+		}
+		word := query[:end]
+		query = query[end:]
+
+		if word == "OR" {
+			// only keep OR between two terms
+			if len(terms) > 0 && terms[len(terms)-1] != "OR" {
+				terms = append(terms, "OR")
+			}
+			continue
+		}
+		prefix := strings.HasSuffix(word, "*")
+		if term := quoteTerm(strings.TrimRight(word, "*")); term != "" {
+			if prefix {
+				term += "*"
+			}
+			terms = append(terms, term)
+		}
+	}
+
+	if len(terms) > 0 && terms[len(terms)-1] == "OR" {
+		terms = terms[:len(terms)-1]
+	}
+	return strings.Join(terms, " ")
+}
+
+// quoteTerm wraps text in double quotes so FTS5 treats it as a string, or returns an empty
+// string when it contains no searchable characters
+func quoteTerm(text string) string {
+	if !strings.ContainsFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+		return ""
+	}
+	return `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+}
+
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// sourceSchema creates the jobs_ tables indexed by the full-text search, as the Django server's migrations do
+var sourceSchema = []string{
+	`CREATE TABLE jobs_jobapplication (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		job_title VARCHAR(100) NOT NULL,
+		salary VARCHAR(100) NOT NULL,
+		company_name VARCHAR(100) NOT NULL,
+		company_url VARCHAR(200) NOT NULL,
+		job_description TEXT NOT NULL,
+		resume_version VARCHAR(100) NOT NULL,
+		status VARCHAR(50) NOT NULL,
+		source VARCHAR(50) NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		cover_letter TEXT NOT NULL
+	)`,
+	`CREATE TABLE jobs_step (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title VARCHAR(100) NOT NULL,
+		description TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		job_application_id BIGINT NOT NULL REFERENCES jobs_jobapplication (id)
+	)`,
+	`CREATE TABLE jobs_researchdata (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		category INTEGER NOT NULL,
+		info TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		job_application_id BIGINT NOT NULL REFERENCES jobs_jobapplication (id)
+	)`,
+	`CREATE TABLE jobs_workflow (
+		workflow_id INTEGER PRIMARY KEY AUTOINCREMENT,
+		workflow_name VARCHAR(200) NOT NULL,
+		created_at DATETIME NOT NULL,
+		prompt TEXT NOT NULL,
+		agent_model VARCHAR(200) NOT NULL,
+		output TEXT NOT NULL,
+		parameters TEXT NOT NULL
+	)`,
+}
+
+// newSourceDB creates a database file with the jobs_ tables and returns its path
+func newSourceDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _, statement := range sourceSchema {
+		if _, err := conn.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func openTestDB(t *testing.T, path string) *DB {
+	t.Helper()
+	database, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func insertTestJob(t *testing.T, database *DB, title string) {
+	t.Helper()
+	_, err := database.conn.Exec(`
+		INSERT INTO jobs_jobapplication (job_title, salary, company_name, company_url, job_description,
+			resume_version, status, source, created_at, updated_at, cover_letter)
+		VALUES (?, '', 'Acme', '', 'Go and Kubernetes', '', 'Applied', 'LinkedIn', datetime('now'), datetime('now'), '')
+	`, title)
+	if err != nil {
+		t.Fatalf("insert job application: %v", err)
+	}
+}
+
+func countIndexed(t *testing.T, database *DB) int {
+	t.Helper()
+	var count int
+	if err := database.conn.QueryRow(`SELECT COUNT(*) FROM analyzer_search`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestTextSearchWithoutFTS5DropsTriggers(t *testing.T) {
+	path := newSourceDB(t)
+	database := openTestDB(t, path)
+	if database.TextSearchAvailable() {
+		t.Skip("SQLite was built with FTS5")
+	}
+
+	// a trigger left behind by a build with FTS5
+	_, err := database.conn.Exec(`CREATE TRIGGER analyzer_search_jobs_jobapplication_insert AFTER INSERT ON jobs_jobapplication
+		BEGIN INSERT INTO analyzer_search (item_type, item_id) VALUES ('job', new.id); END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.Close()
+
+	database = openTestDB(t, path)
+	insertTestJob(t, database, "Backend Engineer")
+
+	var triggers int
+	err = database.conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'analyzer_search%'`).Scan(&triggers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if triggers != 0 {
+		t.Errorf("%d full-text triggers left, want 0", triggers)
+	}
+	if err := database.RebuildTextSearchIndex(); err != ErrFullTextSearchUnavailable {
+		t.Errorf("RebuildTextSearchIndex() = %v, want %v", err, ErrFullTextSearchUnavailable)
+	}
+}
+
+func TestTextSearchRebuildsOnlyWhenCreated(t *testing.T) {
+	path := newSourceDB(t)
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rows written before the index exists
+	_, err = conn.Exec(`
+		INSERT INTO jobs_jobapplication (job_title, salary, company_name, company_url, job_description,
+			resume_version, status, source, created_at, updated_at, cover_letter)
+		VALUES ('Platform Engineer', '', 'Umbrella', '', 'Terraform', '', 'Applied', 'LinkedIn', datetime('now'), datetime('now'), '')
+	`)
+	conn.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	database := openTestDB(t, path)
+	if !database.TextSearchAvailable() {
+		t.Skip("SQLite was built without FTS5, run with -tags sqlite_fts5")
+	}
+	if got := countIndexed(t, database); got != 1 {
+		t.Fatalf("indexed %d rows after creating the index, want 1", got)
+	}
+	insertTestJob(t, database, "Backend Engineer")
+	if got := countIndexed(t, database); got != 2 {
+		t.Fatalf("indexed %d rows after an insert, want 2", got)
+	}
+
+	// an existing index is not rebuilt on open
+	if _, err := database.conn.Exec(`DELETE FROM analyzer_search`); err != nil {
+		t.Fatal(err)
+	}
+	database.Close()
+	database = openTestDB(t, path)
+	if got := countIndexed(t, database); got != 0 {
+		t.Errorf("indexed %d rows after reopening, want 0", got)
+	}
+
+	// a missing trigger means rows may have been missed
+	if _, err := database.conn.Exec(`DROP TRIGGER analyzer_search_jobs_step_insert`); err != nil {
+		t.Fatal(err)
+	}
+	database.Close()
+	database = openTestDB(t, path)
+	if got := countIndexed(t, database); got != 2 {
+		t.Errorf("indexed %d rows after recreating a trigger, want 2", got)
+	}
+}
+
+func TestBuildMatchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"golang payments", `"golang" "payments"`},
+		{`"site reliability" engineer`, `"site reliability" "engineer"`},
+		{"kube*", `"kube"*`},
+		{"go OR rust", `"go" OR "rust"`},
+		{"OR go OR", `"go"`},
+		{"go OR OR rust", `"go" OR "rust"`},
+		{`c++ NEAR(a b)`, `"c++" "NEAR(a" "b)"`},
+		{`"unterminated phrase`, `"unterminated phrase"`},
+		{"- * ()", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := BuildMatchQuery(tt.query); got != tt.want {
+			t.Errorf("BuildMatchQuery(%q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
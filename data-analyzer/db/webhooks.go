@@ -0,0 +1,281 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"data-analyzer/models"
+)
+
+// ErrWebhookSubscriptionNotFound is returned when no webhook subscription has the given ID
+var ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+
+// CreateWebhookSubscription stores a subscription and returns it with its ID
+func (db *DB) CreateWebhookSubscription(subscription models.WebhookSubscription) (models.WebhookSubscription, error) {
+	now := time.Now().UTC()
+	result, err := db.conn.Exec(`
+		INSERT INTO analyzer_webhook_subscription (url, secret, event_types, description, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, subscription.URL, subscription.Secret, strings.Join(subscription.EventTypes, ","), subscription.Description,
+		subscription.Active, now.Format("2006-01-02 15:04:05"), now.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return subscription, fmt.Errorf("failed to insert webhook subscription: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return subscription, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	subscription.ID = int(id)
+	subscription.CreatedAt = now.Truncate(time.Second)
+	subscription.UpdatedAt = subscription.CreatedAt
+	return subscription, nil
+}
+
+// GetWebhookSubscriptions retrieves every subscription with its secret, oldest first
+func (db *DB) GetWebhookSubscriptions() ([]models.WebhookSubscription, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, url, secret, event_types, description, active, created_at, updated_at
+		FROM analyzer_webhook_subscription
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []models.WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// GetWebhookSubscription retrieves a subscription with its secret
+func (db *DB) GetWebhookSubscription(id int) (models.WebhookSubscription, error) {
+	row := db.conn.QueryRow(`
+		SELECT id, url, secret, event_types, description, active, created_at, updated_at
+		FROM analyzer_webhook_subscription
+		WHERE id = ?
+	`, id)
+	subscription, err := scanWebhookSubscription(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return subscription, ErrWebhookSubscriptionNotFound
+	}
+	return subscription, err
+}
+
+// UpdateWebhookSubscription saves the event types, description and state of a subscription
+func (db *DB) UpdateWebhookSubscription(subscription models.WebhookSubscription) error {
+	result, err := db.conn.Exec(`
+		UPDATE analyzer_webhook_subscription SET event_types = ?, description = ?, active = ?, updated_at = ?
+		WHERE id = ?
+	`, strings.Join(subscription.EventTypes, ","), subscription.Description, subscription.Active,
+		time.Now().UTC().Format("2006-01-02 15:04:05"), subscription.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return ErrWebhookSubscriptionNotFound
+	}
+	return nil
+}
+
+// DeleteWebhookSubscription removes a subscription and its deliveries
+func (db *DB) DeleteWebhookSubscription(id int) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM analyzer_webhook_subscription WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return ErrWebhookSubscriptionNotFound
+	}
+	if _, err := tx.Exec(`DELETE FROM analyzer_webhook_delivery WHERE subscription_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit webhook subscription deletion: %w", err)
+	}
+	return nil
+}
+
+func scanWebhookSubscription(row scanner) (models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	var eventTypes string
+	err := row.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &eventTypes, &subscription.Description,
+		&subscription.Active, &subscription.CreatedAt, &subscription.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return subscription, err
+	}
+	if err != nil {
+		return subscription, fmt.Errorf("failed to scan webhook subscription row: %w", err)
+	}
+	subscription.EventTypes = []string{}
+	if eventTypes != "" {
+		subscription.EventTypes = strings.Split(eventTypes, ",")
+	}
+	return subscription, nil
+}
+
+// RecordWebhookEvent stores an event to be sent to the subscriptions. Nothing is stored while there
+// is no active subscription or when an event with the same key was already recorded; the returned
+// boolean tells whether the event was stored.
+func (db *DB) RecordWebhookEvent(event models.WebhookEvent) (bool, error) {
+	result, err := db.conn.Exec(`
+		INSERT OR IGNORE INTO analyzer_webhook_event (event_type, event_key, job_application_id, ref_id, data, created_at)
+		SELECT ?, ?, ?, ?, ?, ?
+		WHERE EXISTS (SELECT 1 FROM analyzer_webhook_subscription WHERE active = 1)
+	`, event.Type, event.Key, event.JobApplicationID, event.RefID, event.Data, time.Now().UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return false, fmt.Errorf("failed to insert webhook event: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get inserted webhook events: %w", err)
+	}
+	return inserted > 0, nil
+}
+
+// GetPendingWebhookEvents retrieves the events not dispatched to the subscriptions yet, oldest first
+func (db *DB) GetPendingWebhookEvents(limit int) ([]models.WebhookEvent, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, event_type, event_key, job_application_id, ref_id, data, created_at
+		FROM analyzer_webhook_event
+		WHERE dispatched = 0
+		ORDER BY id
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.WebhookEvent{}
+	for rows.Next() {
+		var e models.WebhookEvent
+		if err := rows.Scan(&e.ID, &e.Type, &e.Key, &e.JobApplicationID, &e.RefID, &e.Data, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook event row: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read webhook events: %w", err)
+	}
+	return events, nil
+}
+
+// DispatchWebhookEvent stores the deliveries of an event and marks it dispatched in a single transaction
+func (db *DB) DispatchWebhookEvent(eventID int, deliveries []models.WebhookDelivery) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	for _, delivery := range deliveries {
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO analyzer_webhook_delivery (subscription_id, event_id, event_type, payload, status,
+				next_attempt_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, delivery.SubscriptionID, eventID, delivery.EventType, delivery.Payload, models.WebhookDeliveryPending,
+			delivery.NextAttemptAt.UTC().Format("2006-01-02 15:04:05"), now, now)
+		if err != nil {
+			return fmt.Errorf("failed to insert webhook delivery: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE analyzer_webhook_event SET dispatched = 1 WHERE id = ?`, eventID); err != nil {
+		return fmt.Errorf("failed to mark webhook event dispatched: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit webhook event dispatch: %w", err)
+	}
+	return nil
+}
+
+// GetDueWebhookDeliveries retrieves the pending deliveries of active subscriptions whose next attempt
+// is due, oldest first
+func (db *DB) GetDueWebhookDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	return db.queryWebhookDeliveries(`
+		WHERE d.status = ? AND d.next_attempt_at <= ?
+			AND d.subscription_id IN (SELECT id FROM analyzer_webhook_subscription WHERE active = 1)
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT ?
+	`, models.WebhookDeliveryPending, now.UTC().Format("2006-01-02 15:04:05"), limit)
+}
+
+// GetWebhookDeliveries retrieves the delivery log of a subscription, newest first
+func (db *DB) GetWebhookDeliveries(subscriptionID int, limit int) ([]models.WebhookDelivery, error) {
+	return db.queryWebhookDeliveries(`
+		WHERE d.subscription_id = ?
+		ORDER BY d.id DESC
+		LIMIT ?
+	`, subscriptionID, limit)
+}
+
+func (db *DB) queryWebhookDeliveries(where string, args ...interface{}) ([]models.WebhookDelivery, error) {
+	rows, err := db.conn.Query(`
+		SELECT d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+			d.last_status_code, d.last_error, d.created_at, d.updated_at, d.delivered_at
+		FROM analyzer_webhook_delivery d
+	`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var deliveredAt sql.NullTime
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt, &deliveredAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery row: %w", err)
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery saves the outcome of a delivery attempt
+func (db *DB) UpdateWebhookDelivery(delivery models.WebhookDelivery) error {
+	var deliveredAt interface{}
+	if delivery.DeliveredAt != nil {
+		deliveredAt = delivery.DeliveredAt.UTC().Format("2006-01-02 15:04:05")
+	}
+	_, err := db.conn.Exec(`
+		UPDATE analyzer_webhook_delivery
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, updated_at = ?, delivered_at = ?
+		WHERE id = ?
+	`, delivery.Status, delivery.Attempts, delivery.NextAttemptAt.UTC().Format("2006-01-02 15:04:05"), delivery.LastStatusCode,
+		delivery.LastError, time.Now().UTC().Format("2006-01-02 15:04:05"), deliveredAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
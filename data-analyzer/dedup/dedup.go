@@ -0,0 +1,344 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Options controls how job descriptions are compared
+type Options struct {
+	// ShingleSize is the number of consecutive words in a shingle
+	ShingleSize int
+	// NumHashes is the length of a MinHash signature; it must be divisible by Bands
+	NumHashes int
+	// Bands is the number of LSH bands a signature is split into when looking for candidate pairs
+	Bands int
+	// Threshold is the similarity above which two descriptions are duplicates
+	Threshold float64
+	// SameDomainThreshold is the lower similarity used when both jobs have the same company domain
+	SameDomainThreshold float64
+}
+
+// DefaultOptions returns options that find pairs with a similarity of roughly 0.5 and above as candidates
+func DefaultOptions() Options {
+	return Options{
+		ShingleSize:         3,
+		NumHashes:           128,
+		Bands:               32,
+		Threshold:           0.7,
+		SameDomainThreshold: 0.5,
+	}
+}
+
+// Document is a job description to compare
+type Document struct {
+	ID     int
+	Text   string
+	Domain string
+}
+
+// Pair is two documents whose descriptions are similar enough to be duplicates
+type Pair struct {
+	A          int     `json:"a"`
+	B          int     `json:"b"`
+	Similarity float64 `json:"similarity"`
+	SameDomain bool    `json:"same_domain"`
+}
+
+// Cluster is a group of documents connected by duplicate pairs
+type Cluster struct {
+	IDs   []int  `json:"ids"`
+	Pairs []Pair `json:"pairs"`
+}
+
+type indexedDocument struct {
+	Document
+	shingles  map[uint64]struct{}
+	signature []uint64
+}
+
+// Index holds the shingles and MinHash signatures of a set of documents
+type Index struct {
+	options   Options
+	seeds     []uint64
+	documents []indexedDocument
+	buckets   map[bandKey][]int
+	byDomain  map[string][]int
+}
+
+type bandKey struct {
+	band int
+	hash uint64
+}
+
+// NewIndex shingles and signs the documents. Documents without any words are ignored.
+func NewIndex(options Options, documents []Document) *Index {
+	if options.NumHashes <= 0 || options.Bands <= 0 || options.NumHashes%options.Bands != 0 {
+		defaults := DefaultOptions()
+		options.NumHashes, options.Bands = defaults.NumHashes, defaults.Bands
+	}
+	if options.ShingleSize <= 0 {
+		options.ShingleSize = DefaultOptions().ShingleSize
+	}
+
+	index := &Index{
+		options:  options,
+		seeds:    make([]uint64, options.NumHashes),
+		buckets:  make(map[bandKey][]int),
+		byDomain: make(map[string][]int),
+	}
+	// the seeds are derived deterministically so signatures are stable between runs
+	seed := uint64(0x5eed)
+	for i := range index.seeds {
+		seed = splitmix64(seed)
+		index.seeds[i] = seed
+	}
+
+	for _, document := range documents {
+		index.add(document)
+	}
+	return index
+}
+
+func (i *Index) add(document Document) {
+	indexed, ok := i.prepare(document)
+	if !ok {
+		return
+	}
+	position := len(i.documents)
+	i.documents = append(i.documents, indexed)
+	for _, key := range i.bandKeys(indexed.signature) {
+		i.buckets[key] = append(i.buckets[key], position)
+	}
+	if document.Domain != "" {
+		i.byDomain[document.Domain] = append(i.byDomain[document.Domain], position)
+	}
+}
+
+func (i *Index) prepare(document Document) (indexedDocument, bool) {
+	shingles := Shingles(document.Text, i.options.ShingleSize)
+	if len(shingles) == 0 {
+		return indexedDocument{}, false
+	}
+	return indexedDocument{
+		Document:  document,
+		shingles:  shingles,
+		signature: i.signature(shingles),
+	}, true
+}
+
+// Pairs returns every pair of indexed documents that are duplicates, most similar first
+func (i *Index) Pairs() []Pair {
+	pairs := []Pair{}
+	for a := range i.documents {
+		for _, b := range i.candidates(i.documents[a]) {
+			if b <= a {
+				continue
+			}
+			if pair, ok := i.compare(i.documents[a], i.documents[b]); ok {
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	sortPairs(pairs)
+	return pairs
+}
+
+// Match returns the indexed documents that are duplicates of a document that is not part of the index.
+// Pair.A is always the given document.
+func (i *Index) Match(document Document) []Pair {
+	pairs := []Pair{}
+	indexed, ok := i.prepare(document)
+	if !ok {
+		return pairs
+	}
+	for _, position := range i.candidates(indexed) {
+		if i.documents[position].ID == document.ID {
+			continue
+		}
+		if pair, ok := i.compare(indexed, i.documents[position]); ok {
+			pairs = append(pairs, pair)
+		}
+	}
+	sortPairs(pairs)
+	return pairs
+}
+
+// candidates returns the positions of the documents sharing an LSH band or the company domain
+func (i *Index) candidates(document indexedDocument) []int {
+	seen := make(map[int]bool)
+	for _, key := range i.bandKeys(document.signature) {
+		for _, position := range i.buckets[key] {
+			seen[position] = true
+		}
+	}
+	// documents of the same company are always compared, since their threshold is lower
+	if document.Domain != "" {
+		for _, position := range i.byDomain[document.Domain] {
+			seen[position] = true
+		}
+	}
+
+	positions := make([]int, 0, len(seen))
+	for position := range seen {
+		positions = append(positions, position)
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+// compare computes the exact Jaccard similarity of two candidates and applies the thresholds
+func (i *Index) compare(a indexedDocument, b indexedDocument) (Pair, bool) {
+	sameDomain := a.Domain != "" && a.Domain == b.Domain
+	similarity := Jaccard(a.shingles, b.shingles)
+
+	threshold := i.options.Threshold
+	if sameDomain {
+		threshold = math.Min(threshold, i.options.SameDomainThreshold)
+	}
+	if similarity < threshold {
+		return Pair{}, false
+	}
+
+	return Pair{
+		A:          a.ID,
+		B:          b.ID,
+		Similarity: math.Round(similarity*1000) / 1000,
+		SameDomain: sameDomain,
+	}, true
+}
+
+// signature is the MinHash signature of a shingle set
+func (i *Index) signature(shingles map[uint64]struct{}) []uint64 {
+	signature := make([]uint64, len(i.seeds))
+	for k := range signature {
+		signature[k] = math.MaxUint64
+	}
+	for shingle := range shingles {
+		for k, seed := range i.seeds {
+			if hashed := splitmix64(shingle ^ seed); hashed < signature[k] {
+				signature[k] = hashed
+			}
+		}
+	}
+	return signature
+}
+
+// bandKeys splits a signature into bands and hashes each of them
+func (i *Index) bandKeys(signature []uint64) []bandKey {
+	rows := len(signature) / i.options.Bands
+	keys := make([]bandKey, i.options.Bands)
+	for band := range keys {
+		hash := uint64(band)
+		for _, value := range signature[band*rows : (band+1)*rows] {
+			hash = splitmix64(hash ^ value)
+		}
+		keys[band] = bandKey{band: band, hash: hash}
+	}
+	return keys
+}
+
+// Shingles returns the hashed word shingles of a text. Case, punctuation and whitespace are ignored.
+// Texts shorter than the shingle size produce a single shingle.
+func Shingles(text string, size int) map[uint64]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+	shingles := make(map[uint64]struct{})
+	if len(words) == 0 {
+		return shingles
+	}
+
+	for start := 0; start+size <= len(words) || start == 0; start++ {
+		end := min(start+size, len(words))
+		hasher := fnv.New64a()
+		hasher.Write([]byte(strings.Join(words[start:end], " ")))
+		shingles[hasher.Sum64()] = struct{}{}
+	}
+	return shingles
+}
+
+// Jaccard is the size of the intersection of two shingle sets divided by the size of their union
+func Jaccard(a map[uint64]struct{}, b map[uint64]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	intersection := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// Clusters groups documents connected by duplicate pairs, largest cluster first
+func Clusters(pairs []Pair) []Cluster {
+	parent := make(map[int]int)
+	var find func(id int) int
+	find = func(id int) int {
+		if _, ok := parent[id]; !ok {
+			parent[id] = id
+		}
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	for _, pair := range pairs {
+		rootA, rootB := find(pair.A), find(pair.B)
+		if rootA != rootB {
+			parent[max(rootA, rootB)] = min(rootA, rootB)
+		}
+	}
+
+	byRoot := make(map[int]*Cluster)
+	for _, pair := range pairs {
+		root := find(pair.A)
+		cluster, ok := byRoot[root]
+		if !ok {
+			cluster = &Cluster{}
+			byRoot[root] = cluster
+		}
+		for _, id := range []int{pair.A, pair.B} {
+			if !slices.Contains(cluster.IDs, id) {
+				cluster.IDs = append(cluster.IDs, id)
+			}
+		}
+		cluster.Pairs = append(cluster.Pairs, pair)
+	}
+
+	clusters := make([]Cluster, 0, len(byRoot))
+	for _, cluster := range byRoot {
+		sort.Ints(cluster.IDs)
+		clusters = append(clusters, *cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].IDs) != len(clusters[j].IDs) {
+			return len(clusters[i].IDs) > len(clusters[j].IDs)
+		}
+		return clusters[i].IDs[0] < clusters[j].IDs[0]
+	})
+	return clusters
+}
+
+func sortPairs(pairs []Pair) {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Similarity > pairs[j].Similarity
+	})
+}
+
+// splitmix64 is a fast, well distributed 64-bit mixing function
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
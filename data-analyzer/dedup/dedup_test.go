@@ -0,0 +1,140 @@
+package dedup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShingles(t *testing.T) {
+	tests := []struct {
+		text string
+		size int
+		want int
+	}{
+		{"", 3, 0},
+		{" ,;- ", 3, 0},
+		{"Senior Go Engineer", 3, 1},
+		// shorter texts still produce one shingle
+		{"Go", 3, 1},
+		{"build and run services", 3, 2},
+		{"Go, go GO", 1, 1},
+		{"C++ and C#", 1, 3},
+		{"a b a b a", 2, 2},
+	}
+	for _, tt := range tests {
+		if got := Shingles(tt.text, tt.size); len(got) != tt.want {
+			t.Errorf("Shingles(%q, %d) has %d shingles, want %d", tt.text, tt.size, len(got), tt.want)
+		}
+	}
+
+	if !reflect.DeepEqual(Shingles("Build, and RUN services!", 3), Shingles("build and run\nservices", 3)) {
+		t.Errorf("Shingles() differ by case, punctuation or whitespace")
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want float64
+	}{
+		{"", "", 0},
+		{"go rust", "", 0},
+		{"go rust", "rust go", 1},
+		{"go rust java kotlin", "go rust java scala", 0.6},
+		{"go rust", "python ruby", 0},
+	}
+	for _, tt := range tests {
+		if got := Jaccard(Shingles(tt.a, 1), Shingles(tt.b, 1)); got != tt.want {
+			t.Errorf("Jaccard(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestClusters(t *testing.T) {
+	tests := []struct {
+		name  string
+		pairs []Pair
+		want  [][]int
+	}{
+		{"no pairs", nil, [][]int{}},
+		{"single pair", []Pair{{A: 2, B: 1}}, [][]int{{1, 2}}},
+		{
+			"transitive pairs",
+			[]Pair{{A: 5, B: 6}, {A: 2, B: 3}, {A: 7, B: 4}, {A: 1, B: 2}},
+			[][]int{{1, 2, 3}, {4, 7}, {5, 6}},
+		},
+		{"pairs joined later", []Pair{{A: 1, B: 2}, {A: 3, B: 4}, {A: 2, B: 4}}, [][]int{{1, 2, 3, 4}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters := Clusters(tt.pairs)
+			got := [][]int{}
+			pairs := 0
+			for _, cluster := range clusters {
+				got = append(got, cluster.IDs)
+				pairs += len(cluster.Pairs)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Clusters() = %v, want %v", got, tt.want)
+			}
+			if pairs != len(tt.pairs) {
+				t.Errorf("Clusters() kept %d pairs, want %d", pairs, len(tt.pairs))
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	description := "We are hiring a senior backend engineer to design and operate the payment services " +
+		"that move money for millions of customers. You will write Go and PostgreSQL, own the on call " +
+		"rotation for your services, mentor other engineers and work with product on the roadmap."
+	words := strings.Fields(description)
+	// the first half of the posting with a different second half
+	rewritten := strings.Join(words[:len(words)*2/3], " ") +
+		" and bring ideas about observability, testing culture, deployment tooling and incident reviews."
+
+	index := NewIndex(DefaultOptions(), []Document{
+		{ID: 1, Text: description, Domain: "acme.io"},
+		{ID: 2, Text: strings.ToUpper(description) + " Apply now!", Domain: "jobs.example.com"},
+		{ID: 3, Text: rewritten, Domain: "acme.io"},
+		{ID: 4, Text: rewritten, Domain: "globex.com"},
+		{ID: 5, Text: "Marketing manager for a retail chain, focused on brand campaigns and events."},
+		{ID: 6, Text: "   "},
+	})
+
+	similarity := Jaccard(Shingles(description, 3), Shingles(rewritten, 3))
+	if similarity < 0.5 || similarity >= 0.7 {
+		t.Fatalf("rewritten posting has similarity %v, want it between the thresholds", similarity)
+	}
+
+	got := map[[2]int]bool{}
+	for _, pair := range index.Pairs() {
+		got[[2]int{pair.A, pair.B}] = pair.SameDomain
+	}
+	want := map[[2]int]bool{
+		{1, 2}: false,
+		// only the same company uses the lower threshold
+		{1, 3}: true,
+		{3, 4}: false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Pairs() = %v, want %v", got, want)
+	}
+
+	matches := index.Match(Document{ID: 9, Text: description, Domain: "acme.io"})
+	ids := []int{}
+	for _, pair := range matches {
+		if pair.A != 9 {
+			t.Errorf("Match() pair A = %d, want the matched document", pair.A)
+		}
+		ids = append(ids, pair.B)
+	}
+	if len(ids) != 3 || ids[2] != 3 {
+		t.Errorf("Match() = %v, want the two copies of the posting first and the rewrite last", ids)
+	}
+	if got := index.Match(Document{ID: 1, Text: description}); len(got) != 1 || got[0].B != 2 {
+		t.Errorf("Match() of an indexed document = %+v, want only the other copy", got)
+	}
+}
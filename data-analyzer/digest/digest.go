@@ -0,0 +1,152 @@
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Digest is everything that happened in the job search over a period, usually the last week
+type Digest struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// NewApplications are the job applications added during the period
+	NewApplications []Application `json:"new_applications"`
+	// StatusChanges are the job applications that moved or heard from the company during the period
+	StatusChanges []StatusChange `json:"status_changes"`
+	// Upcoming are the interviews and follow-ups due in the days after the period
+	Upcoming []Upcoming `json:"upcoming"`
+	// RedFlags are the job applications whose red flags were detected during the period
+	RedFlags []RedFlags `json:"red_flags"`
+	// Trends are the technologies most asked for by the job applications of the period
+	Trends []Trend `json:"trends"`
+	Goal   Goal    `json:"goal"`
+}
+
+// Application is a job application as listed in the digest
+type Application struct {
+	ID          int       `json:"id"`
+	JobTitle    string    `json:"job_title"`
+	CompanyName string    `json:"company_name"`
+	Status      string    `json:"status"`
+	Source      string    `json:"source"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StatusChange is a job application with the steps added to it during the period
+type StatusChange struct {
+	Application
+	Events []Event `json:"events"`
+}
+
+// Event is a step of a job application
+type Event struct {
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Upcoming is an interview or follow-up from the calendar feed
+type Upcoming struct {
+	Date        time.Time `json:"date"`
+	AllDay      bool      `json:"all_day"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+}
+
+// RedFlags are the red flags of a job application
+type RedFlags struct {
+	Application
+	RiskScore float64   `json:"risk_score"`
+	Flags     []RedFlag `json:"flags"`
+}
+
+// RedFlag is a single red flag of a job application
+type RedFlag struct {
+	Category    string `json:"category"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// Trend is how often a technology is asked for by the job applications of the period compared to
+// the weeks before
+type Trend struct {
+	Technology string `json:"technology"`
+	Jobs       int    `json:"jobs"`
+	// Share and PreviousShare are the fractions of the job applications with a tech stack that ask for it
+	Share         float64 `json:"share"`
+	PreviousShare float64 `json:"previous_share"`
+}
+
+// Goal is the progress against the daily application goal
+type Goal struct {
+	DailyGoal   int   `json:"daily_goal"`
+	Days        []Day `json:"days"`
+	DaysReached int   `json:"days_reached"`
+	Total       int   `json:"total"`
+	Target      int   `json:"target"`
+}
+
+// Day is the number of job applications added on a day
+type Day struct {
+	Date    time.Time `json:"date"`
+	Count   int       `json:"count"`
+	Reached bool      `json:"reached"`
+}
+
+// Subject is the subject line of the digest email
+func (d Digest) Subject() string {
+	return fmt.Sprintf("Job search digest: %s to %s", d.Start.Format("Jan 2"), d.End.AddDate(0, 0, -1).Format("Jan 2"))
+}
+
+// FileName is the name of the digest file with the given extension, dated by the end of the period
+func (d Digest) FileName(extension string) string {
+	return fmt.Sprintf("digest-%s.%s", d.End.AddDate(0, 0, -1).Format("2006-01-02"), extension)
+}
+
+var templateFuncs = map[string]any{
+	"date":     func(t time.Time) string { return t.Format("Mon Jan 2") },
+	"datetime": func(t time.Time) string { return t.Format("Mon Jan 2 15:04") },
+	"percent":  func(value float64) string { return fmt.Sprintf("%.0f%%", value*100) },
+	"points": func(share float64, previous float64) string {
+		return fmt.Sprintf("%+.0f pts", (share-previous)*100)
+	},
+	"join": strings.Join,
+	// cell escapes the characters that would break a Markdown table
+	"cell": func(text string) string {
+		return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+	},
+}
+
+// RenderMarkdown renders the digest with the Markdown template
+func RenderMarkdown(d Digest) (string, error) {
+	tmpl, err := texttemplate.New("digest.md.tmpl").Funcs(templateFuncs).ParseFS(templateFiles, "templates/digest.md.tmpl")
+	if err != nil {
+		return "", fmt.Errorf("failed to parse Markdown template: %w", err)
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, d); err != nil {
+		return "", fmt.Errorf("failed to render Markdown digest: %w", err)
+	}
+	return buffer.String(), nil
+}
+
+// RenderHTML renders the digest with the HTML template, escaping everything that comes from job applications
+func RenderHTML(d Digest) (string, error) {
+	tmpl, err := htmltemplate.New("digest.html.tmpl").Funcs(templateFuncs).ParseFS(templateFiles, "templates/digest.html.tmpl")
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML template: %w", err)
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, d); err != nil {
+		return "", fmt.Errorf("failed to render HTML digest: %w", err)
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,102 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDigest is a week with one of everything, with names that need escaping
+func testDigest() Digest {
+	start := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	application := Application{ID: 7, JobTitle: "Backend | Go Engineer", CompanyName: "<Acme & Co>", Status: "Applied", Source: "linkedin", CreatedAt: start.Add(9 * time.Hour)}
+	return Digest{
+		Start:           start,
+		End:             start.AddDate(0, 0, 7),
+		GeneratedAt:     start.AddDate(0, 0, 7),
+		NewApplications: []Application{application},
+		StatusChanges: []StatusChange{{
+			Application: application,
+			Events:      []Event{{Title: "HR Interview", CreatedAt: start.Add(50 * time.Hour)}},
+		}},
+		Upcoming: []Upcoming{
+			{Date: start.AddDate(0, 0, 8).Add(14 * time.Hour), Summary: "Technical interview at Acme"},
+			{Date: start.AddDate(0, 0, 9), AllDay: true, Summary: "Follow up with Globex"},
+		},
+		RedFlags: []RedFlags{{
+			Application: application,
+			RiskScore:   59.6,
+			Flags:       []RedFlag{{Category: "ON_CALL", Severity: "high", Description: "24/7 on call"}},
+		}},
+		Trends: []Trend{{Technology: "Go", Jobs: 3, Share: 0.6, PreviousShare: 0.25}},
+		Goal: Goal{
+			DailyGoal:   2,
+			Days:        []Day{{Date: start, Count: 2, Reached: true}, {Date: start.AddDate(0, 0, 1), Count: 1}},
+			DaysReached: 1,
+			Total:       3,
+			Target:      4,
+		},
+	}
+}
+
+func TestDigestNames(t *testing.T) {
+	d := testDigest()
+	if got, want := d.Subject(), "Job search digest: Mar 3 to Mar 9"; got != want {
+		t.Errorf("Subject() = %q, want %q", got, want)
+	}
+	if got, want := d.FileName("md"), "digest-2025-03-09.md"; got != want {
+		t.Errorf("FileName() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	markdown, err := RenderMarkdown(testDigest())
+	if err != nil {
+		t.Fatalf("RenderMarkdown() error = %v", err)
+	}
+	for _, want := range []string{
+		"Mon Mar 3 to Sun Mar 9",
+		"3 of 4 applications, the goal of 2 a day was reached on 1 of 2 days.",
+		"| Mon Mar 3 | 2 | ✅ |",
+		`| 7 | Backend \| Go Engineer | <Acme & Co> | linkedin | Applied |`,
+		"  - Wed Mar 5 02:00: HR Interview",
+		"- Tue Mar 11 14:00: Technical interview at Acme",
+		"- Wed Mar 12: Follow up with Globex",
+		"risk 60",
+		"  - high ON_CALL: 24/7 on call",
+		"| Go | 3 | 60% | +35 pts |",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("RenderMarkdown() = %s\nwant it to contain %q", markdown, want)
+		}
+	}
+
+	empty, err := RenderMarkdown(Digest{})
+	if err != nil {
+		t.Fatalf("RenderMarkdown(empty) error = %v", err)
+	}
+	for _, want := range []string{"No job applications were added.", "Nothing moved.", "No interviews or follow-ups are due.", "No red flags were detected.", "No tech stacks were extracted"} {
+		if !strings.Contains(empty, want) {
+			t.Errorf("RenderMarkdown(empty) is missing %q", want)
+		}
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	html, err := RenderHTML(testDigest())
+	if err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+	if strings.Contains(html, "<Acme & Co>") || !strings.Contains(html, "&lt;Acme &amp; Co&gt;") {
+		t.Errorf("RenderHTML() does not escape the company name")
+	}
+	for _, want := range []string{"Backend | Go Engineer", "HR Interview", "Technical interview at Acme", "24/7 on call"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("RenderHTML() is missing %q", want)
+		}
+	}
+
+	if _, err := RenderHTML(Digest{}); err != nil {
+		t.Errorf("RenderHTML(empty) error = %v", err)
+	}
+}
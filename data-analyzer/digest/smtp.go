@@ -0,0 +1,155 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// SMTPSettings is the server the digest is sent through and who it is sent to
+type SMTPSettings struct {
+	Host string
+	Port int
+	// Username and Password authenticate with PLAIN auth; no authentication is done without a username
+	Username string
+	Password string
+	From     string
+	To       []string
+	// Timeout bounds connecting and every command, so an unreachable server does not block the schedule
+	Timeout time.Duration
+}
+
+// BuildMessage builds a multipart/alternative email with the Markdown digest as the plain text part
+// and the HTML digest as the preferred part
+func BuildMessage(from string, to []string, subject string, markdown string, html string, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", markdown},
+		{"text/html; charset=utf-8", html},
+	}
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create message part: %w", err)
+		}
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to encode message part: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode message part: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close message: %w", err)
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Message-ID: <%s@%s>\r\n", messageID(), domainOf(from))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", writer.Boundary())
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+// Send delivers the message over SMTP, upgrading to TLS when the server offers STARTTLS. A local
+// stand-in such as MailHog or `python -m aiosmtpd -n` works with a host, port and no username.
+func Send(settings SMTPSettings, message []byte) error {
+	if settings.Host == "" || settings.From == "" || len(settings.To) == 0 {
+		return fmt.Errorf("sending the digest needs an SMTP host, a sender and at least one recipient")
+	}
+	timeout := settings.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	address := net.JoinHostPort(settings.Host, fmt.Sprint(settings.Port))
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	client, err := smtp.NewClient(conn, settings.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: settings.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if settings.Username != "" {
+		// PlainAuth refuses to send the password without TLS, except to localhost
+		auth := smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	if err := client.Mail(addressOf(settings.From)); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, recipient := range settings.To {
+		if err := client.Rcpt(addressOf(recipient)); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", recipient, err)
+		}
+	}
+	data, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err := data.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := data.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return client.Quit()
+}
+
+// addressOf strips the display name of "Name <user@example.com>"
+func addressOf(address string) string {
+	if start := strings.LastIndex(address, "<"); start >= 0 {
+		if end := strings.Index(address[start:], ">"); end > 0 {
+			return address[start+1 : start+end]
+		}
+	}
+	return strings.TrimSpace(address)
+}
+
+func domainOf(address string) string {
+	address = addressOf(address)
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		return address[at+1:]
+	}
+	return "localhost"
+}
+
+func messageID() string {
+	random := make([]byte, 12)
+	rand.Read(random)
+	return hex.EncodeToString(random)
+}
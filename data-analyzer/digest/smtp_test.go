@@ -0,0 +1,229 @@
+package digest
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpSession is what a client told the SMTP stand-in
+type smtpSession struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPServer runs a minimal SMTP server accepting a single session, without STARTTLS so the
+// session stays readable. The session is returned once the client quit.
+func startSMTPServer(t *testing.T) (string, func() smtpSession) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var session smtpSession
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		reply("220 localhost ESMTP stand-in")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250-localhost")
+				reply("250-8BITMIME")
+				reply("250 AUTH PLAIN")
+			case strings.HasPrefix(command, "AUTH PLAIN "):
+				decoded, _ := base64.StdEncoding.DecodeString(line[len("AUTH PLAIN "):])
+				session.auth = string(decoded)
+				reply("235 2.7.0 Authentication successful")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				session.from = envelopeAddress(line)
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				session.to = append(session.to, envelopeAddress(line))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					// lines starting with a dot are dot-stuffed by the client
+					data.WriteString(strings.TrimPrefix(dataLine, "."))
+				}
+				session.data = data.String()
+				reply("250 OK: queued")
+			case command == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), func() smtpSession {
+		wg.Wait()
+		return session
+	}
+}
+
+func TestSend(t *testing.T) {
+	address, session := startSMTPServer(t)
+	host, port, _ := net.SplitHostPort(address)
+	portNumber, _ := strconv.Atoi(port)
+	settings := SMTPSettings{
+		Host:     host,
+		Port:     portNumber,
+		Username: "digest",
+		Password: "secret",
+		From:     "Job Tracker <digest@example.com>",
+		To:       []string{"me@example.com", "Partner <partner@example.com>"},
+		Timeout:  5 * time.Second,
+	}
+
+	markdown := "# Weekly digest\n\n- 3 applications\n- Öffentlicher Dienst: rejected\n" + strings.Repeat("long line ", 20) + "\n.leading dot\n"
+	html := "<h1>Weekly digest</h1><p>3 applications</p>"
+	date := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	message, err := BuildMessage(settings.From, settings.To, "Weekly digest — 3 applications", markdown, html, date)
+	if err != nil {
+		t.Fatalf("BuildMessage() error = %v", err)
+	}
+	if err := Send(settings, message); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	got := session()
+	if got.auth != "\x00digest\x00secret" {
+		t.Errorf("PLAIN auth = %q", got.auth)
+	}
+	if got.from != "digest@example.com" {
+		t.Errorf("MAIL FROM = %q, want the address without the display name", got.from)
+	}
+	if len(got.to) != 2 || got.to[0] != "me@example.com" || got.to[1] != "partner@example.com" {
+		t.Errorf("RCPT TO = %q", got.to)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(got.data))
+	if err != nil {
+		t.Fatalf("failed to parse the sent message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "Weekly digest — 3 applications" {
+		t.Errorf("subject = %q, %v", subject, err)
+	}
+	if parsed.Header.Get("To") != "me@example.com, Partner <partner@example.com>" {
+		t.Errorf("To = %q", parsed.Header.Get("To"))
+	}
+	if !strings.HasSuffix(parsed.Header.Get("Message-ID"), "@example.com>") {
+		t.Errorf("Message-ID = %q, want it at the sender's domain", parsed.Header.Get("Message-ID"))
+	}
+	if sent, err := parsed.Header.Date(); err != nil || !sent.Equal(date) {
+		t.Errorf("Date = %v, %v, want %v", sent, err, date)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, %v", parsed.Header.Get("Content-Type"), err)
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, want := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", markdown},
+		{"text/html; charset=utf-8", html},
+	} {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("missing %s part: %v", want.contentType, err)
+		}
+		if part.Header.Get("Content-Type") != want.contentType {
+			t.Errorf("part Content-Type = %q, want %q", part.Header.Get("Content-Type"), want.contentType)
+		}
+		// the reader decodes the quoted-printable transfer encoding, whose line breaks are CRLF
+		content, err := io.ReadAll(part)
+		if err != nil || strings.ReplaceAll(string(content), "\r\n", "\n") != want.content {
+			t.Errorf("%s part = %q, %v, want %q", want.contentType, content, err, want.content)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("message has more than two parts: %v", err)
+	}
+}
+
+func TestSendNeedsSettings(t *testing.T) {
+	tests := []SMTPSettings{
+		{From: "a@example.com", To: []string{"b@example.com"}},
+		{Host: "localhost", To: []string{"b@example.com"}},
+		{Host: "localhost", From: "a@example.com"},
+	}
+	for _, settings := range tests {
+		if err := Send(settings, []byte("Subject: test\r\n\r\n")); err == nil {
+			t.Errorf("Send(%+v) error = nil, want an error", settings)
+		}
+	}
+}
+
+func TestAddressOf(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+		domain  string
+	}{
+		{"Job Tracker <digest@example.com>", "digest@example.com", "example.com"},
+		{" me@example.org ", "me@example.org", "example.org"},
+		{"nobody", "nobody", "localhost"},
+	}
+	for _, tt := range tests {
+		if got := addressOf(tt.address); got != tt.want {
+			t.Errorf("addressOf(%q) = %q, want %q", tt.address, got, tt.want)
+		}
+		if got := domainOf(tt.address); got != tt.domain {
+			t.Errorf("domainOf(%q) = %q, want %q", tt.address, got, tt.domain)
+		}
+	}
+}
+
+// envelopeAddress is the address between the angle brackets of a MAIL FROM or RCPT TO command
+func envelopeAddress(command string) string {
+	start := strings.Index(command, "<")
+	end := strings.Index(command, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return command[start+1 : end]
+}
@@ -0,0 +1,81 @@
+package documents
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunk is a part of a document's text, located by byte offsets into it
+type Chunk struct {
+	Index int
+	Start int
+	End   int
+	Text  string
+}
+
+// breakSeparators are the places a chunk prefers to end at, from best to worst
+var breakSeparators = []string{"\n\n", "\n", ". ", "? ", "! ", "; ", ", ", " "}
+
+// Split cuts the text into chunks of at most size bytes, each starting overlap bytes before the end
+// of the previous one so that facts spanning a boundary are found in full. Chunks end at a paragraph,
+// line, sentence or word boundary in their second half when there is one.
+func Split(text string, size int, overlap int) []Chunk {
+	if size <= 0 {
+		size = len(text)
+	}
+	overlap = max(0, min(overlap, size/2))
+
+	chunks := []Chunk{}
+	start := 0
+	for start < len(text) {
+		end := len(text)
+		if end-start > size {
+			end = breakPoint(text, start, start+size)
+		}
+
+		chunkText := strings.TrimSpace(text[start:end])
+		if chunkText != "" {
+			// the offsets locate the trimmed text
+			leading := len(text[start:end]) - len(strings.TrimLeft(text[start:end], " \n\t"))
+			chunks = append(chunks, Chunk{
+				Index: len(chunks),
+				Start: start + leading,
+				End:   start + leading + len(chunkText),
+				Text:  chunkText,
+			})
+		}
+		if end >= len(text) {
+			break
+		}
+
+		next := max(end-overlap, start+1)
+		// start the overlap at a sentence when there is one in it, or at least at a word
+		if sentence := strings.Index(text[next:end], ". "); overlap > 0 && sentence >= 0 && next+sentence+2 < end {
+			next += sentence + 2
+		} else if space := strings.IndexAny(text[next:end], " \n"); overlap > 0 && space >= 0 {
+			next += space + 1
+		} else {
+			next = end
+		}
+		for next < len(text) && !utf8.RuneStart(text[next]) {
+			next++
+		}
+		start = next
+	}
+	return chunks
+}
+
+// breakPoint is the best end of a chunk running from start to at most limit
+func breakPoint(text string, start int, limit int) int {
+	window := text[start:limit]
+	for _, separator := range breakSeparators {
+		if index := strings.LastIndex(window, separator); index >= len(window)/2 {
+			return start + index + len(separator)
+		}
+	}
+	// no boundary, cut inside the word without splitting a character
+	for limit > start+1 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return limit
+}
@@ -0,0 +1,87 @@
+package documents
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		size    int
+		overlap int
+		want    []string
+	}{
+		{"empty", "", 100, 10, []string{}},
+		{"fits in one chunk", "  Acme builds payments.  ", 100, 10, []string{"Acme builds payments."}},
+		{"no size", "Acme builds payments.", 0, 10, []string{"Acme builds payments."}},
+		{
+			"paragraph boundary",
+			"Acme was founded in 2020.\n\nIt builds payment services in Go.",
+			40, 0,
+			[]string{"Acme was founded in 2020.", "It builds payment services in Go."},
+		},
+		{
+			"sentence boundary with overlap",
+			"Acme is in Berlin. It has 200 people. It uses Go and Kotlin.",
+			40, 20,
+			[]string{"Acme is in Berlin. It has 200 people.", "It has 200 people. It uses Go and", "It uses Go and Kotlin."},
+		},
+		{
+			"word boundary",
+			"alpha beta gamma delta epsilon",
+			12, 0,
+			[]string{"alpha beta", "gamma delta", "epsilon"},
+		},
+		{"no boundary", "abcdefghij", 4, 0, []string{"abcd", "efgh", "ij"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := Split(tt.text, tt.size, tt.overlap)
+			got := []string{}
+			for _, chunk := range chunks {
+				got = append(got, chunk.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitOffsets(t *testing.T) {
+	text := strings.Repeat("Die Straße führt nach München. Über 100 Mitarbeiter arbeiten dort\n", 20)
+	size := 150
+
+	chunks := Split(text, size, 40)
+	if len(chunks) < 2 {
+		t.Fatalf("Split() = %d chunks, want several", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.Index != i {
+			t.Errorf("chunk %d has index %d", i, chunk.Index)
+		}
+		if text[chunk.Start:chunk.End] != chunk.Text {
+			t.Errorf("chunk %d offsets %d-%d do not locate its text", i, chunk.Start, chunk.End)
+		}
+		if len(chunk.Text) > size || !utf8.ValidString(chunk.Text) {
+			t.Errorf("chunk %d is %d bytes or splits a character", i, len(chunk.Text))
+		}
+		if i > 0 && chunk.Start >= chunks[i-1].End {
+			t.Errorf("chunk %d starts at %d, want it to overlap the previous chunk ending at %d", i, chunk.Start, chunks[i-1].End)
+		}
+	}
+	if last := chunks[len(chunks)-1]; last.End != len(strings.TrimSpace(text)) {
+		t.Errorf("last chunk ends at %d, want the end of the text", last.End)
+	}
+
+	// a long word of multi-byte characters is cut between characters
+	for _, chunk := range Split(strings.Repeat("ü", 50), 9, 0) {
+		if !utf8.ValidString(chunk.Text) {
+			t.Errorf("Split() chunk %q splits a character", chunk.Text)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package documents
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// maxCMapRange caps the codes read from a single bfrange, a range never needs more than two bytes
+const maxCMapRange = 1 << 16
+
+// toUnicodeMap is a parsed ToUnicode CMap, mapping the character codes a font draws to text
+type toUnicodeMap struct {
+	codespaces []codespaceRange
+	chars      map[string]string
+	// codeLength is used when the CMap declares no codespace ranges
+	codeLength int
+}
+
+// codespaceRange is a range of character codes of the same byte length
+type codespaceRange struct {
+	low  []byte
+	high []byte
+}
+
+// parseToUnicode reads the codespace ranges, bfchar and bfrange sections of a ToUnicode CMap
+func parseToUnicode(data []byte) *toUnicodeMap {
+	cmap := &toUnicodeMap{chars: make(map[string]string)}
+	tokens := cmapTokens(data)
+	lengths := map[int]int{}
+
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "begincodespacerange":
+			for i += 1; i+1 < len(tokens) && tokens[i] != "endcodespacerange"; i += 2 {
+				low, high := decodeHex(tokens[i]), decodeHex(tokens[i+1])
+				if len(low) > 0 && len(low) == len(high) {
+					cmap.codespaces = append(cmap.codespaces, codespaceRange{low: low, high: high})
+				}
+			}
+		case "beginbfchar":
+			for i += 1; i+1 < len(tokens) && tokens[i] != "endbfchar"; i += 2 {
+				code := decodeHex(tokens[i])
+				cmap.chars[string(code)] = decodeUTF16(decodeHex(tokens[i+1]))
+				lengths[len(code)]++
+			}
+		case "beginbfrange":
+			for i += 1; i+2 < len(tokens) && tokens[i] != "endbfrange"; i += 3 {
+				low, high := decodeHex(tokens[i]), decodeHex(tokens[i+1])
+				if tokens[i+2] == "[" {
+					// one destination per code
+					destinations := []string{}
+					for i += 3; i < len(tokens) && tokens[i] != "]"; i++ {
+						destinations = append(destinations, decodeUTF16(decodeHex(tokens[i])))
+					}
+					i -= 2
+					cmap.addRange(low, high, func(offset int) (string, bool) {
+						if offset >= len(destinations) {
+							return "", false
+						}
+						return destinations[offset], true
+					})
+				} else {
+					// consecutive codes map to consecutive values of the last UTF-16 unit
+					destination := decodeHex(tokens[i+2])
+					cmap.addRange(low, high, func(offset int) (string, bool) {
+						return decodeUTF16(incrementLastUnit(destination, offset)), true
+					})
+				}
+				lengths[len(low)]++
+			}
+		}
+	}
+
+	// without codespace ranges, codes are as long as most of the mapped codes
+	cmap.codeLength = 1
+	if lengths[2] > lengths[1] {
+		cmap.codeLength = 2
+	}
+	return cmap
+}
+
+// addRange maps every code from low to high, which only differ in their last byte or two
+func (cmap *toUnicodeMap) addRange(low []byte, high []byte, destination func(offset int) (string, bool)) {
+	if len(low) == 0 || len(low) != len(high) {
+		return
+	}
+	first, last := codeValue(low), codeValue(high)
+	if last < first || last-first >= maxCMapRange {
+		return
+	}
+	code := bytes.Clone(low)
+	for offset := 0; offset <= last-first; offset++ {
+		text, ok := destination(offset)
+		if !ok {
+			return
+		}
+		setCodeValue(code, first+offset)
+		cmap.chars[string(code)] = text
+	}
+}
+
+// decode maps the character codes of a string drawn with the font to text. Codes missing from
+// the CMap are dropped.
+func (cmap *toUnicodeMap) decode(raw []byte) string {
+	var text strings.Builder
+	for i := 0; i < len(raw); {
+		length := min(cmap.length(raw[i:]), len(raw)-i)
+		text.WriteString(cmap.chars[string(raw[i:i+length])])
+		i += length
+	}
+	return text.String()
+}
+
+// length returns the byte length of the code at the start of raw, from the shortest matching codespace range
+func (cmap *toUnicodeMap) length(raw []byte) int {
+	best := 0
+	for _, codespace := range cmap.codespaces {
+		if len(codespace.low) > len(raw) || (best > 0 && len(codespace.low) >= best) {
+			continue
+		}
+		inRange := true
+		for j := range codespace.low {
+			if raw[j] < codespace.low[j] || raw[j] > codespace.high[j] {
+				inRange = false
+				break
+			}
+		}
+		if inRange {
+			best = len(codespace.low)
+		}
+	}
+	if best == 0 {
+		return cmap.codeLength
+	}
+	return best
+}
+
+// cmapTokens splits a CMap into hex strings, array brackets and keywords; names and numbers are kept
+// as keywords, nothing else in a CMap matters for the mapping
+func cmapTokens(data []byte) []string {
+	tokens := []string{}
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '<' && i+1 < len(data) && data[i+1] == '<':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(data[i:], '>')
+			if end < 0 {
+				return tokens
+			}
+			tokens = append(tokens, string(data[i:i+end+1]))
+			i += end + 1
+		case c == '[' || c == ']':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '%':
+			for i < len(data) && data[i] != '\n' && data[i] != '\r' {
+				i++
+			}
+		case isPDFDelimiter(c):
+			i++
+		default:
+			start := i
+			for i < len(data) && !isPDFDelimiter(data[i]) {
+				i++
+			}
+			tokens = append(tokens, string(data[start:i]))
+		}
+	}
+	return tokens
+}
+
+// decodeHex decodes the hex digits of a <hex string>, ignoring whitespace; an odd last digit is padded with 0
+func decodeHex(hex string) []byte {
+	hex = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '<' || r == '>' {
+			return -1
+		}
+		return r
+	}, hex)
+	if len(hex)%2 == 1 {
+		hex += "0"
+	}
+	decoded := make([]byte, 0, len(hex)/2)
+	for i := 0; i+1 < len(hex); i += 2 {
+		value, err := strconv.ParseUint(hex[i:i+2], 16, 8)
+		if err != nil {
+			return nil
+		}
+		decoded = append(decoded, byte(value))
+	}
+	return decoded
+}
+
+// decodeUTF16 decodes big-endian UTF-16, the encoding of ToUnicode destinations
+func decodeUTF16(raw []byte) string {
+	units := make([]uint16, 0, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// incrementLastUnit adds offset to the last UTF-16 unit of a destination
+func incrementLastUnit(destination []byte, offset int) []byte {
+	if len(destination) < 2 {
+		return destination
+	}
+	incremented := bytes.Clone(destination)
+	last := len(incremented) - 2
+	value := int(incremented[last])<<8 | int(incremented[last+1]) + offset
+	incremented[last], incremented[last+1] = byte(value>>8), byte(value)
+	return incremented
+}
+
+// codeValue reads a code of up to four bytes as a big-endian number
+func codeValue(code []byte) int {
+	value := 0
+	for _, b := range code {
+		value = value<<8 | int(b)
+	}
+	return value
+}
+
+func setCodeValue(code []byte, value int) {
+	for j := len(code) - 1; j >= 0; j-- {
+		code[j] = byte(value)
+		value >>= 8
+	}
+}
@@ -0,0 +1,133 @@
+package documents
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"data-analyzer/jobposting"
+
+	"golang.org/x/net/html/charset"
+)
+
+// Document formats
+const (
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+	FormatText = "text"
+)
+
+// ErrNoText is returned for documents without any extractable text, e.g. scanned PDFs
+var ErrNoText = errors.New("document has no extractable text")
+
+// Document is the plain text of a user-supplied file about a company
+type Document struct {
+	Name   string
+	Title  string
+	Format string
+	// Text is the extracted text; citations point into it with byte offsets
+	Text string
+	// ContentHash identifies the extracted text, so the same document is not stored twice
+	ContentHash string
+	// Warning tells why part of the text could not be extracted
+	Warning string
+}
+
+var (
+	blankLinesRe = regexp.MustCompile(`\n{3,}`)
+	spacesRe     = regexp.MustCompile(`[ \t\f\v]+`)
+)
+
+// DetectFormat guesses the format from the file name, or from the content when the name has no known extension
+func DetectFormat(name string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".html", ".htm", ".xhtml", ".mhtml":
+		return FormatHTML
+	case ".pdf":
+		return FormatPDF
+	case ".txt", ".md", ".markdown", ".text":
+		return FormatText
+	}
+	if bytes.HasPrefix(content, []byte("%PDF-")) {
+		return FormatPDF
+	}
+	if contentType := http.DetectContentType(content); strings.HasPrefix(contentType, "text/html") {
+		return FormatHTML
+	}
+	return FormatText
+}
+
+// Parse extracts the text of an HTML page, a PDF or a text note. The format is detected when it is not given.
+func Parse(name string, format string, content []byte) (Document, error) {
+	if format == "" {
+		format = DetectFormat(name, content)
+	}
+	document := Document{Name: name, Format: format}
+
+	switch format {
+	case FormatHTML:
+		page := jobposting.Page{HTML: decodeHTML(content)}
+		readable := jobposting.ExtractReadable(page)
+		document.Title = readable.Title
+		document.Text = readable.Text
+	case FormatPDF:
+		text, skipped, err := extractPDFText(content)
+		if err != nil {
+			return document, err
+		}
+		document.Text = text
+		if skipped {
+			document.Warning = "some text is drawn with fonts that have no ToUnicode map and was left out"
+		}
+	case FormatText:
+		document.Text = strings.ToValidUTF8(string(content), "")
+	default:
+		return document, fmt.Errorf("unknown document format %q, use %q, %q or %q", format, FormatHTML, FormatPDF, FormatText)
+	}
+
+	document.Text = cleanText(document.Text)
+	if document.Text == "" {
+		return document, ErrNoText
+	}
+	if document.Title == "" {
+		document.Title = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	}
+	hash := sha256.Sum256([]byte(document.Text))
+	document.ContentHash = hex.EncodeToString(hash[:])
+	return document, nil
+}
+
+// decodeHTML converts a saved page to UTF-8 using its declared charset
+func decodeHTML(content []byte) string {
+	if utf8.Valid(content) {
+		return string(content)
+	}
+	reader, err := charset.NewReader(bytes.NewReader(content), "text/html")
+	if err != nil {
+		return strings.ToValidUTF8(string(content), "")
+	}
+	var buffer bytes.Buffer
+	if _, err := buffer.ReadFrom(reader); err != nil {
+		return strings.ToValidUTF8(string(content), "")
+	}
+	return buffer.String()
+}
+
+// cleanText normalizes line endings and runs of spaces and blank lines
+func cleanText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spacesRe.ReplaceAllString(line, " "))
+	}
+	text = strings.Join(lines, "\n")
+	return strings.TrimSpace(blankLinesRe.ReplaceAllString(text, "\n\n"))
+}
@@ -0,0 +1,66 @@
+package documents
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"about.HTML", "", FormatHTML},
+		{"saved.mhtml", "", FormatHTML},
+		{"report.pdf", "", FormatPDF},
+		{"notes.md", "<html>", FormatText},
+		{"download", "%PDF-1.7\n", FormatPDF},
+		{"download", "<!DOCTYPE html><html><body>Acme</body></html>", FormatHTML},
+		{"download", "Acme notes", FormatText},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.name, []byte(tt.content)); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %q, want %q", tt.name, tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		content   string
+		wantTitle string
+		wantText  string
+	}{
+		{"notes/acme interview.txt", "", "Acme\r\nfounded   in 2020\r\n\r\n\r\n\r\nBerlin\xff", "acme interview", "Acme\nfounded in 2020\n\nBerlin"},
+		{
+			"about.html", "",
+			"<html><head><title>About Acme</title></head><body><nav>Menu</nav><article><p>Acme builds payment services for banks in Europe and ships them every week.</p></article></body></html>",
+			"About Acme", "Acme builds payment services for banks in Europe and ships them every week.",
+		},
+		{"page", FormatText, "<p>kept as text</p>", "page", "<p>kept as text</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := Parse(tt.name, tt.format, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if document.Title != tt.wantTitle || document.Text != tt.wantText {
+				t.Errorf("Parse() = title %q text %q, want %q %q", document.Title, document.Text, tt.wantTitle, tt.wantText)
+			}
+			if len(document.ContentHash) != 64 {
+				t.Errorf("Parse() content hash = %q, want a sha256", document.ContentHash)
+			}
+		})
+	}
+
+	if _, err := Parse("empty.txt", "", []byte(" \n\n ")); !errors.Is(err, ErrNoText) {
+		t.Errorf("Parse(empty) error = %v, want %v", err, ErrNoText)
+	}
+	if _, err := Parse("notes.doc", "docx", []byte("x")); err == nil {
+		t.Errorf("Parse(docx) error = nil, want an unknown format error")
+	}
+}
@@ -0,0 +1,584 @@
+package documents
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxStreamSize is the largest decompressed PDF stream read, larger streams are truncated
+const maxStreamSize = 16 << 20
+
+// maxPageTreeDepth bounds the walk up the page tree when looking for inherited resources
+const maxPageTreeDepth = 32
+
+var (
+	objectRe = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	streamRe = regexp.MustCompile(`stream\r?\n`)
+	// imageRe matches the dictionaries of streams that never hold text
+	imageRe     = regexp.MustCompile(`/Subtype\s*/(Image|XML)|/Type\s*/(XRef|Metadata|EmbeddedFile|ObjStm)|/Length1|/FontFile`)
+	objectStmRe = regexp.MustCompile(`/Type\s*/ObjStm`)
+	pageRe      = regexp.MustCompile(`/Type\s*/Page\b`)
+	type0Re     = regexp.MustCompile(`/Subtype\s*/Type0\b`)
+	// referenceRe is an indirect reference, e.g. "12 0 R"
+	referenceRe = regexp.MustCompile(`(\d+)\s+\d+\s+R\b`)
+	fontEntryRe = regexp.MustCompile(`/([^\s/<>\[\]()]+)\s*(\d+)\s+\d+\s+R\b`)
+)
+
+// pdfObject is an indirect object, with the decoded data of its stream when it has one
+type pdfObject struct {
+	dictionary string
+	isStream   bool
+	stream     []byte
+	// decoded is false for streams with a filter other than Flate, whose data is left out
+	decoded bool
+}
+
+// pdfFile is the objects of a PDF, with the stream objects in file order
+type pdfFile struct {
+	objects map[int]*pdfObject
+	streams []int
+}
+
+// pdfFont is how the strings drawn with a font are turned into text
+type pdfFont struct {
+	toUnicode *toUnicodeMap
+	// composite fonts draw multi-byte glyph codes, which mean nothing without a ToUnicode map
+	composite bool
+}
+
+// extractPDFText reads the text drawn by the content streams of a PDF. It understands the text
+// operators of uncompressed and Flate-compressed streams, and maps the glyph codes of fonts with a
+// ToUnicode CMap, which covers PDFs printed from browsers and word processors, including those
+// drawing with Type0/Identity-H fonts. Fonts with simple encodings and no CMap are read as Latin-1.
+// Text drawn with composite fonts that have no ToUnicode CMap cannot be read; it is skipped and
+// reported by the returned bool. Scanned pages have no text to extract.
+func extractPDFText(content []byte) (string, bool, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(content, " \r\n\t"), []byte("%PDF-")) {
+		return "", false, fmt.Errorf("not a PDF file")
+	}
+
+	file, err := parsePDF(content)
+	if err != nil {
+		return "", false, err
+	}
+	streamFonts, allFonts := file.fontResources()
+
+	var text strings.Builder
+	skipped := false
+	for _, number := range file.streams {
+		object := file.objects[number]
+		if !object.decoded || imageRe.MatchString(object.dictionary) || bytes.Contains(object.stream, []byte("begincmap")) {
+			continue
+		}
+		fonts, ok := streamFonts[number]
+		if !ok {
+			fonts = allFonts
+		}
+		streamText, streamSkipped := contentStreamText(object.stream, fonts)
+		skipped = skipped || streamSkipped
+		if streamText != "" {
+			text.WriteString(streamText)
+			text.WriteString("\n\n")
+		}
+	}
+
+	extracted := text.String()
+	if !mostlyPrintable(extracted) {
+		if skipped {
+			return "", true, fmt.Errorf("%w: its fonts have no ToUnicode map", ErrNoText)
+		}
+		return "", false, ErrNoText
+	}
+	return extracted, skipped, nil
+}
+
+// parsePDF reads the indirect objects of a PDF, including those packed in object streams. Objects
+// redefined by an incremental update replace the earlier ones.
+func parsePDF(content []byte) (pdfFile, error) {
+	file := pdfFile{objects: make(map[int]*pdfObject)}
+	packed := []*pdfObject{}
+
+	offset := 0
+	for {
+		location := objectRe.FindSubmatchIndex(content[offset:])
+		if location == nil {
+			break
+		}
+		number, _ := strconv.Atoi(string(content[offset+location[2] : offset+location[3]]))
+		bodyStart := offset + location[1]
+		objectEnd := bytes.Index(content[bodyStart:], []byte("endobj"))
+		if objectEnd < 0 {
+			objectEnd = len(content) - bodyStart
+		}
+		objectEnd += bodyStart
+
+		object := &pdfObject{}
+		stream := streamRe.FindIndex(content[bodyStart:objectEnd])
+		if stream == nil {
+			object.dictionary = string(content[bodyStart:objectEnd])
+			offset = objectEnd
+		} else {
+			// the stream data may contain "endobj", so the object ends after its endstream
+			dataStart := bodyStart + stream[1]
+			dataEnd := bytes.Index(content[dataStart:], []byte("endstream"))
+			if dataEnd < 0 {
+				break
+			}
+			dataEnd += dataStart
+			object.dictionary = string(content[bodyStart : bodyStart+stream[0]])
+			object.isStream = true
+			object.stream, object.decoded = decodeStream(object.dictionary, content[dataStart:dataEnd])
+			offset = dataEnd + len("endstream")
+			file.streams = append(file.streams, number)
+			if object.decoded && objectStmRe.MatchString(object.dictionary) {
+				packed = append(packed, object)
+			}
+		}
+		file.objects[number] = object
+	}
+
+	for _, objectStream := range packed {
+		objects, err := unpackObjectStream(objectStream)
+		if err != nil {
+			return file, err
+		}
+		for number, dictionary := range objects {
+			if _, ok := file.objects[number]; !ok {
+				file.objects[number] = &pdfObject{dictionary: dictionary}
+			}
+		}
+	}
+	return file, nil
+}
+
+// decodeStream inflates Flate-compressed stream data; streams with other filters hold images and fonts
+func decodeStream(dictionary string, data []byte) ([]byte, bool) {
+	if strings.Contains(dictionary, "/FlateDecode") {
+		reader, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, false
+		}
+		defer reader.Close()
+		// streams often end with stray bytes after the compressed data, keep what was inflated
+		inflated, _ := io.ReadAll(io.LimitReader(reader, maxStreamSize))
+		return inflated, true
+	}
+	if strings.Contains(dictionary, "/Filter") {
+		return nil, false
+	}
+	return data, true
+}
+
+// unpackObjectStream splits an object stream into its objects: its data starts with pairs of object
+// numbers and offsets, relative to /First, followed by the objects. Offsets outside the data or
+// smaller than the previous one make the object stream malformed.
+func unpackObjectStream(object *pdfObject) (map[int]string, error) {
+	first := dictionaryInt(object.dictionary, "First")
+	if first <= 0 || first > len(object.stream) {
+		return nil, nil
+	}
+	header := strings.Fields(string(object.stream[:first]))
+	objects := make(map[int]string)
+	for i := 0; i+1 < len(header); i += 2 {
+		number, err := strconv.Atoi(header[i])
+		if err != nil {
+			return objects, nil
+		}
+		start, err := strconv.Atoi(header[i+1])
+		if err != nil {
+			return objects, nil
+		}
+		if start < 0 || first+start > len(object.stream) {
+			return nil, fmt.Errorf("malformed object stream: offset %d of object %d is outside its data", start, number)
+		}
+		end := len(object.stream)
+		if i+3 < len(header) {
+			if next, err := strconv.Atoi(header[i+3]); err == nil {
+				if next < start {
+					return nil, fmt.Errorf("malformed object stream: offset %d of object %s is before offset %d of object %d", next, header[i+2], start, number)
+				}
+				end = min(first+next, end)
+			}
+		}
+		objects[number] = string(object.stream[first+start : end])
+	}
+	return objects, nil
+}
+
+// fontResources returns the fonts of every page content stream and form by resource name, and the
+// fonts of all resources for the streams no page refers to
+func (file pdfFile) fontResources() (map[int]map[string]*pdfFont, map[string]*pdfFont) {
+	fonts := make(map[int]*pdfFont)
+	font := func(number int) *pdfFont {
+		if cached, ok := fonts[number]; ok {
+			return cached
+		}
+		object, ok := file.objects[number]
+		if !ok {
+			return nil
+		}
+		parsed := &pdfFont{composite: type0Re.MatchString(object.dictionary)}
+		if reference, ok := dictionaryReference(object.dictionary, "ToUnicode"); ok {
+			if cmap, ok := file.objects[reference]; ok && cmap.decoded {
+				parsed.toUnicode = parseToUnicode(cmap.stream)
+			}
+		}
+		fonts[number] = parsed
+		return parsed
+	}
+
+	streamFonts := make(map[int]map[string]*pdfFont)
+	allFonts := make(map[string]*pdfFont)
+	for _, number := range slices.Sorted(maps.Keys(file.objects)) {
+		object := file.objects[number]
+		isPage := !object.isStream && pageRe.MatchString(object.dictionary)
+		if !isPage && !object.isStream {
+			continue
+		}
+		resources := file.fontDictionary(object.dictionary, isPage)
+		if len(resources) == 0 {
+			continue
+		}
+		named := make(map[string]*pdfFont)
+		for name, reference := range resources {
+			if parsed := font(reference); parsed != nil {
+				named[name] = parsed
+				if _, ok := allFonts[name]; !ok {
+					allFonts[name] = parsed
+				}
+			}
+		}
+
+		if !isPage {
+			// a form XObject with its own resources
+			streamFonts[number] = named
+			continue
+		}
+		for _, content := range file.pageContents(object.dictionary) {
+			if _, ok := streamFonts[content]; !ok {
+				streamFonts[content] = named
+			}
+		}
+	}
+	return streamFonts, allFonts
+}
+
+// fontDictionary returns the font references of the /Resources of an object by resource name.
+// Pages inherit the resources of their parents.
+func (file pdfFile) fontDictionary(dictionary string, inherit bool) map[string]int {
+	for depth := 0; depth < maxPageTreeDepth; depth++ {
+		resources, ok := file.entry(dictionary, "Resources")
+		if ok {
+			fontEntries, ok := file.entry(resources, "Font")
+			if !ok {
+				return nil
+			}
+			references := make(map[string]int)
+			for _, match := range fontEntryRe.FindAllStringSubmatch(fontEntries, -1) {
+				references[match[1]], _ = strconv.Atoi(match[2])
+			}
+			return references
+		}
+		parent, ok := dictionaryReference(dictionary, "Parent")
+		if !inherit || !ok || file.objects[parent] == nil {
+			return nil
+		}
+		dictionary = file.objects[parent].dictionary
+	}
+	return nil
+}
+
+// pageContents returns the content streams of a page, /Contents being a stream or an array of streams
+func (file pdfFile) pageContents(dictionary string) []int {
+	contents, ok := file.entry(dictionary, "Contents")
+	if !ok {
+		return nil
+	}
+	numbers := []int{}
+	for _, match := range referenceRe.FindAllStringSubmatch(contents, -1) {
+		number, _ := strconv.Atoi(match[1])
+		if object, ok := file.objects[number]; ok && !object.isStream {
+			// an indirect array of content streams
+			for _, inner := range referenceRe.FindAllStringSubmatch(object.dictionary, -1) {
+				innerNumber, _ := strconv.Atoi(inner[1])
+				numbers = append(numbers, innerNumber)
+			}
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// entry returns the value of a dictionary or array entry, resolving an indirect reference to its object
+func (file pdfFile) entry(dictionary string, key string) (string, bool) {
+	index := keyIndex(dictionary, key)
+	if index < 0 {
+		return "", false
+	}
+	value := strings.TrimLeft(dictionary[index:], " \t\r\n")
+	switch {
+	case strings.HasPrefix(value, "<<"):
+		return value[:matchingEnd(value, "<<", ">>")], true
+	case strings.HasPrefix(value, "["):
+		return value[:matchingEnd(value, "[", "]")], true
+	}
+	if location := referenceRe.FindStringSubmatchIndex(value); location != nil && location[0] == 0 {
+		number, _ := strconv.Atoi(value[location[2]:location[3]])
+		if object, ok := file.objects[number]; ok {
+			if object.isStream {
+				return value[:location[1]], true
+			}
+			return object.dictionary, true
+		}
+	}
+	return "", false
+}
+
+// keyIndex returns the index right after a /Key of a dictionary, which must not be the prefix of a longer key
+func keyIndex(dictionary string, key string) int {
+	for offset := 0; ; {
+		index := strings.Index(dictionary[offset:], "/"+key)
+		if index < 0 {
+			return -1
+		}
+		end := offset + index + 1 + len(key)
+		if end == len(dictionary) || isPDFDelimiter(dictionary[end]) {
+			return end
+		}
+		offset = end
+	}
+}
+
+// matchingEnd returns the index after the closing delimiter matching the opening one at the start of value
+func matchingEnd(value string, open string, close string) int {
+	depth := 0
+	for i := 0; i < len(value); {
+		switch {
+		case strings.HasPrefix(value[i:], open):
+			depth++
+			i += len(open)
+		case strings.HasPrefix(value[i:], close):
+			depth--
+			i += len(close)
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return len(value)
+}
+
+// dictionaryReference returns the object number of an indirect reference entry, e.g. /ToUnicode 12 0 R
+func dictionaryReference(dictionary string, key string) (int, bool) {
+	index := keyIndex(dictionary, key)
+	if index < 0 {
+		return 0, false
+	}
+	location := referenceRe.FindStringSubmatchIndex(dictionary[index:])
+	if location == nil || strings.TrimSpace(dictionary[index:index+location[0]]) != "" {
+		return 0, false
+	}
+	number, err := strconv.Atoi(dictionary[index+location[2] : index+location[3]])
+	return number, err == nil
+}
+
+// dictionaryInt returns the value of a direct integer entry, or 0
+func dictionaryInt(dictionary string, key string) int {
+	index := keyIndex(dictionary, key)
+	if index < 0 {
+		return 0
+	}
+	fields := strings.Fields(strings.TrimLeft(dictionary[index:], " \t\r\n"))
+	if len(fields) == 0 {
+		return 0
+	}
+	value, _ := strconv.Atoi(strings.TrimRight(fields[0], "/>]"))
+	return value
+}
+
+// contentStreamText interprets the text operators of a page content stream, decoding the strings with
+// the font selected by Tf. It reports whether strings drawn with a font that cannot be read were skipped.
+func contentStreamText(data []byte, fonts map[string]*pdfFont) (string, bool) {
+	var text strings.Builder
+	operands := []string{}
+	var font *pdfFont
+	name := ""
+	skipped := false
+	decode := func(raw []byte) string {
+		switch {
+		case font == nil:
+			return decodePDFString(raw)
+		case font.toUnicode != nil:
+			return font.toUnicode.decode(raw)
+		case font.composite:
+			skipped = skipped || len(raw) > 0
+			return ""
+		}
+		return decodePDFString(raw)
+	}
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '(':
+			literal, next := readLiteralString(data, i)
+			operands = append(operands, decode(literal))
+			i = next
+		case c == '<' && i+1 < len(data) && data[i+1] == '<':
+			// a dictionary operand, e.g. of marked content
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(data[i:], '>')
+			if end < 0 {
+				return text.String(), skipped
+			}
+			operands = append(operands, decode(decodeHex(string(data[i+1:i+end]))))
+			i += end + 1
+		case c == '/':
+			// a name operand, the font of a following Tf
+			i++
+			start := i
+			for i < len(data) && !isPDFDelimiter(data[i]) {
+				i++
+			}
+			name = string(data[start:i])
+		case c == '%':
+			// comments run to the end of the line
+			for i < len(data) && data[i] != '\n' && data[i] != '\r' {
+				i++
+			}
+		case c == '[' || c == ']':
+			if c == ']' && len(operands) > 0 {
+				// a TJ array: small kerning numbers are dropped, large ones are word gaps
+				operands = []string{strings.Join(operands, "")}
+			}
+			i++
+		case isPDFDelimiter(c):
+			i++
+		default:
+			start := i
+			for i < len(data) && !isPDFDelimiter(data[i]) {
+				i++
+			}
+			token := string(data[start:i])
+			if number, err := strconv.ParseFloat(token, 64); err == nil {
+				// in a TJ array a large negative adjustment is a space between words
+				if number < -200 && len(operands) > 0 {
+					operands[len(operands)-1] += " "
+				}
+				continue
+			}
+			switch token {
+			case "Tf":
+				font = fonts[name]
+			case "Tj", "TJ":
+				text.WriteString(strings.Join(operands, ""))
+			case "'", "\"":
+				text.WriteString("\n")
+				text.WriteString(strings.Join(operands, ""))
+			case "T*", "Td", "TD", "ET":
+				text.WriteString("\n")
+			}
+			operands = operands[:0]
+		}
+	}
+	return text.String(), skipped
+}
+
+// readLiteralString reads a (string) starting at data[start], with its escapes and nested parentheses
+func readLiteralString(data []byte, start int) ([]byte, int) {
+	var literal []byte
+	depth := 0
+	i := start
+	for i < len(data) {
+		c := data[i]
+		switch {
+		case c == '\\' && i+1 < len(data):
+			i++
+			switch escaped := data[i]; escaped {
+			case 'n':
+				literal = append(literal, '\n')
+			case 'r':
+				literal = append(literal, '\r')
+			case 't':
+				literal = append(literal, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// a line continuation
+			default:
+				if escaped >= '0' && escaped <= '7' {
+					end := i
+					for end < len(data) && end < i+3 && data[end] >= '0' && data[end] <= '7' {
+						end++
+					}
+					value, _ := strconv.ParseUint(string(data[i:end]), 8, 8)
+					literal = append(literal, byte(value))
+					i = end - 1
+				} else {
+					literal = append(literal, escaped)
+				}
+			}
+		case c == '(':
+			if depth > 0 {
+				literal = append(literal, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return literal, i + 1
+			}
+			literal = append(literal, c)
+		default:
+			literal = append(literal, c)
+		}
+		i++
+	}
+	return literal, i
+}
+
+// decodePDFString decodes UTF-16 strings with a byte order mark and treats the others as Latin-1,
+// which is close enough to PDFDocEncoding and WinAnsiEncoding for text
+func decodePDFString(raw []byte) string {
+	if len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF {
+		return decodeUTF16(raw[2:])
+	}
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// mostlyPrintable reports whether the text has letters and few control characters, which is not
+// the case for text drawn with fonts that use their own encoding
+func mostlyPrintable(text string) bool {
+	letters, unprintable := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r):
+			letters++
+		case !unicode.IsPrint(r) && !unicode.IsSpace(r):
+			unprintable++
+		}
+	}
+	return letters > 0 && unprintable*10 < letters
+}
@@ -0,0 +1,240 @@
+package documents
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// pdfTestObject is an indirect object of a test PDF, a stream when stream is set
+type pdfTestObject struct {
+	dictionary string
+	stream     string
+	compress   bool
+}
+
+// buildPDF writes the objects numbered from 1, with the catalog as the first one
+func buildPDF(objects ...pdfTestObject) []byte {
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	for i, object := range objects {
+		fmt.Fprintf(&pdf, "%d 0 obj\n", i+1)
+		if object.stream == "" && !object.compress {
+			fmt.Fprintf(&pdf, "%s\nendobj\n", object.dictionary)
+			continue
+		}
+		data := []byte(object.stream)
+		dictionary := object.dictionary
+		if object.compress {
+			var compressed bytes.Buffer
+			writer := zlib.NewWriter(&compressed)
+			writer.Write(data)
+			writer.Close()
+			data = compressed.Bytes()
+			dictionary = strings.Replace(dictionary, "<<", "<< /Filter /FlateDecode", 1)
+		}
+		dictionary = strings.Replace(dictionary, "<<", fmt.Sprintf("<< /Length %d", len(data)), 1)
+		fmt.Fprintf(&pdf, "%s\nstream\n%s\nendstream\nendobj\n", dictionary, data)
+	}
+	pdf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+// identityCMap maps the glyph IDs of a browser-printed font: single codes, a range and a ligature
+const identityCMap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def
+/CMapName /Adobe-Identity-UCS def
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+4 beginbfchar
+<0003> <0020>
+<0024> <0041>
+<0044> <0063>
+<0101> <00E9>
+endbfchar
+2 beginbfrange
+<0045> <0050> <0064>
+<0060> <0061> [<00660069> <0043>]
+endbfrange
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+
+func TestExtractPDFText(t *testing.T) {
+	simplePage := buildPDF(
+		pdfTestObject{dictionary: "<< /Type /Catalog /Pages 2 0 R >>"},
+		pdfTestObject{dictionary: "<< /Type /Pages /Kids [3 0 R] /Count 1 >>"},
+		pdfTestObject{dictionary: "<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>"},
+		pdfTestObject{dictionary: "<<>>", stream: "BT /F1 12 Tf 72 712 Td (Acme builds payments) Tj T* [(in) -250 (Go) 20 (lang)] TJ ET", compress: true},
+		pdfTestObject{dictionary: "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"},
+	)
+
+	// Identity-H font with a ToUnicode CMap, with the resources inherited from the page tree
+	identityPage := buildPDF(
+		pdfTestObject{dictionary: "<< /Type /Catalog /Pages 2 0 R >>"},
+		pdfTestObject{dictionary: "<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources 7 0 R >>"},
+		pdfTestObject{dictionary: "<< /Type /Page /Parent 2 0 R /Contents [4 0 R] >>"},
+		pdfTestObject{dictionary: "<<>>", stream: "BT /F0 11 Tf 1 0 0 -1 72 72 Tm [<0024004700490045>-300<00440060004B0101>] TJ ET\nBT /F0 11 Tf <0061004F0003004E004A0046> Tj ET", compress: true},
+		pdfTestObject{dictionary: "<< /Type /Font /Subtype /Type0 /BaseFont /AAAAAA+Roboto /Encoding /Identity-H /DescendantFonts [8 0 R] /ToUnicode 6 0 R >>"},
+		pdfTestObject{dictionary: "<<>>", stream: identityCMap, compress: true},
+		pdfTestObject{dictionary: "<< /Font << /F0 5 0 R >> >>"},
+		pdfTestObject{dictionary: "<< /Type /Font /Subtype /CIDFontType2 /BaseFont /AAAAAA+Roboto /CIDToGIDMap /Identity >>"},
+	)
+
+	tests := []struct {
+		name        string
+		pdf         []byte
+		want        []string
+		wantSkipped bool
+	}{
+		{"simple font", simplePage, []string{"Acme builds payments", "in Golang"}, false},
+		{"identity-h with tounicode", identityPage, []string{"Afhd cfijé", "Cn mie"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, skipped, err := extractPDFText(tt.pdf)
+			if err != nil {
+				t.Fatalf("extractPDFText() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("extractPDFText() = %q, want it to contain %q", text, want)
+				}
+			}
+			if skipped != tt.wantSkipped {
+				t.Errorf("extractPDFText() skipped = %v, want %v", skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestExtractPDFTextObjectStream(t *testing.T) {
+	// the page and its font are packed in an object stream, as PDF 1.5 writers do
+	packed := []string{
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 9 0 R >> >> /Contents 4 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Font /Subtype /Type0 /Encoding /Identity-H /ToUnicode 5 0 R >>",
+	}
+	header := fmt.Sprintf("8 0 9 %d ", len(packed[0])+1)
+	pdf := buildPDF(
+		pdfTestObject{dictionary: "<< /Type /Catalog /Pages 2 0 R >>"},
+		pdfTestObject{dictionary: "<< /Type /Pages /Kids [8 0 R] /Count 1 >>"},
+		pdfTestObject{dictionary: fmt.Sprintf("<< /Type /ObjStm /N 2 /First %d >>", len(header)), stream: header + strings.Join(packed, " "), compress: true},
+		pdfTestObject{dictionary: "<<>>", stream: "BT /F1 10 Tf <00240044004500460047> Tj ET", compress: true},
+		pdfTestObject{dictionary: "<<>>", stream: identityCMap, compress: true},
+		pdfTestObject{dictionary: "<< /Type /XRef /Size 10 /Root 1 0 R >>", stream: "\x01\x00\x00", compress: true},
+		pdfTestObject{dictionary: "<< /Type /Metadata >>", stream: "<x:xmpmeta/>"},
+	)
+
+	text, skipped, err := extractPDFText(pdf)
+	if err != nil {
+		t.Fatalf("extractPDFText() error = %v", err)
+	}
+	if strings.TrimSpace(text) != "Acdef" || skipped {
+		t.Errorf("extractPDFText() = %q, skipped %v, want the text decoded with the packed font", text, skipped)
+	}
+}
+
+func TestExtractPDFTextMalformedObjectStream(t *testing.T) {
+	packed := "<< /Type /Page >> << /Type /Font >>"
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"negative offset", "8 -5 9 18 "},
+		{"decreasing offsets", "8 18 9 0 "},
+		{"offset past the data", "8 0 9 500 "},
+	}
+	for _, tt := range tests {
+		pdf := buildPDF(
+			pdfTestObject{dictionary: "<< /Type /Catalog /Pages 2 0 R >>"},
+			pdfTestObject{dictionary: "<< /Type /Pages /Kids [8 0 R] /Count 1 >>"},
+			pdfTestObject{dictionary: fmt.Sprintf("<< /Type /ObjStm /N 2 /First %d >>", len(tt.header)), stream: tt.header + packed, compress: true},
+		)
+		_, _, err := extractPDFText(pdf)
+		if err == nil || errors.Is(err, ErrNoText) || !strings.Contains(err.Error(), "malformed object stream") {
+			t.Errorf("%s: extractPDFText() error = %v, want a malformed object stream", tt.name, err)
+		}
+	}
+}
+
+func TestExtractPDFTextWithoutToUnicode(t *testing.T) {
+	type0Only := buildPDF(
+		pdfTestObject{dictionary: "<< /Type /Catalog /Pages 2 0 R >>"},
+		pdfTestObject{dictionary: "<< /Type /Pages /Kids [3 0 R] /Count 1 >>"},
+		pdfTestObject{dictionary: "<< /Type /Page /Parent 2 0 R /Resources << /Font << /F0 5 0 R >> >> /Contents 4 0 R >>"},
+		pdfTestObject{dictionary: "<<>>", stream: "BT /F0 11 Tf <002400470049> Tj ET", compress: true},
+		pdfTestObject{dictionary: "<< /Type /Font /Subtype /Type0 /Encoding /Identity-H >>"},
+	)
+	_, skipped, err := extractPDFText(type0Only)
+	if !errors.Is(err, ErrNoText) || !strings.Contains(err.Error(), "ToUnicode") || !skipped {
+		t.Errorf("extractPDFText() error = %v, skipped %v, want %v about ToUnicode", err, skipped, ErrNoText)
+	}
+
+	mixed := buildPDF(
+		pdfTestObject{dictionary: "<< /Type /Catalog /Pages 2 0 R >>"},
+		pdfTestObject{dictionary: "<< /Type /Pages /Kids [3 0 R] /Count 1 >>"},
+		pdfTestObject{dictionary: "<< /Type /Page /Parent 2 0 R /Resources << /Font << /F0 5 0 R /F1 6 0 R >> >> /Contents 4 0 R >>"},
+		pdfTestObject{dictionary: "<<>>", stream: "BT /F0 11 Tf <002400470049> Tj ET BT /F1 11 Tf (Readable heading) Tj ET"},
+		pdfTestObject{dictionary: "<< /Type /Font /Subtype /Type0 /Encoding /Identity-H >>"},
+		pdfTestObject{dictionary: "<< /Type /Font /Subtype /TrueType /BaseFont /Arial >>"},
+	)
+	document, err := Parse("report.pdf", "", mixed)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if document.Text != "Readable heading" || document.Warning == "" {
+		t.Errorf("Parse() = %q with warning %q, want the readable text and a warning", document.Text, document.Warning)
+	}
+}
+
+func TestExtractPDFTextErrors(t *testing.T) {
+	if _, _, err := extractPDFText([]byte("<html>not a pdf</html>")); err == nil || errors.Is(err, ErrNoText) {
+		t.Errorf("extractPDFText(html) error = %v, want not a PDF", err)
+	}
+
+	scanned := buildPDF(
+		pdfTestObject{dictionary: "<< /Type /Catalog /Pages 2 0 R >>"},
+		pdfTestObject{dictionary: "<< /Type /Pages /Kids [3 0 R] /Count 1 >>"},
+		pdfTestObject{dictionary: "<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>"},
+		pdfTestObject{dictionary: "<<>>", stream: "q 612 0 0 792 0 0 cm /Im0 Do Q"},
+		pdfTestObject{dictionary: "<< /Type /XObject /Subtype /Image /Width 1 /Height 1 >>", stream: "\xff\xd8BT (x) Tj ET"},
+	)
+	if _, skipped, err := extractPDFText(scanned); !errors.Is(err, ErrNoText) || skipped {
+		t.Errorf("extractPDFText(scanned) error = %v, skipped %v, want %v", err, skipped, ErrNoText)
+	}
+}
+
+func TestToUnicodeMap(t *testing.T) {
+	cmap := parseToUnicode([]byte(identityCMap))
+
+	tests := []struct {
+		raw  []byte
+		want string
+	}{
+		{[]byte{0x00, 0x24}, "A"},
+		{[]byte{0x00, 0x45, 0x00, 0x50}, "do"},
+		{[]byte{0x00, 0x60, 0x00, 0x61}, "fiC"},
+		{[]byte{0x01, 0x01, 0x00, 0x03}, "é "},
+		{[]byte{0x00, 0x99}, ""},
+		// an odd trailing byte is not a whole code
+		{[]byte{0x00, 0x24, 0x00}, "A"},
+	}
+	for _, tt := range tests {
+		if got := cmap.decode(tt.raw); got != tt.want {
+			t.Errorf("decode(% x) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+
+	// single-byte ToUnicode maps of simple fonts, without codespace ranges
+	simple := parseToUnicode([]byte("2 beginbfchar <41> <0042> <42> <D83DDE00> endbfchar 1 beginbfrange <61> <63> <0078> endbfrange"))
+	if got := simple.decode([]byte("ABabc")); got != "B😀xyz" {
+		t.Errorf("decode(ABabc) = %q, want %q", got, "B😀xyz")
+	}
+}
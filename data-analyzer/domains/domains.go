@@ -0,0 +1,183 @@
+package domains
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// jobBoardHosts are hiring platforms that host many companies. For these the company
+// slug from the subdomain or the path is kept, so boards.greenhouse.io/acme and
+// boards.greenhouse.io/globex are not treated as the same company.
+var jobBoardHosts = map[string]bool{
+	"greenhouse.io":          true,
+	"lever.co":               true,
+	"workable.com":           true,
+	"ashbyhq.com":            true,
+	"smartrecruiters.com":    true,
+	"recruitee.com":          true,
+	"personio.de":            true,
+	"teamtailor.com":         true,
+	"myworkdayjobs.com":      true,
+	"linkedin.com":           true,
+	"indeed.com":             true,
+	"glassdoor.com":          true,
+	"stepstone.de":           true,
+	"xing.com":               true,
+	"wellfound.com":          true,
+	"welcometothejungle.com": true,
+}
+
+// jobBoardMailDomains are the domains job boards and applicant tracking systems send their
+// notifications from besides their own
+var jobBoardMailDomains = map[string]bool{
+	"greenhouse-mail.io": true,
+	"indeedemail.com":    true,
+	"myworkday.com":      true,
+	"smartrecruiters.io": true,
+	"ashbyhq.io":         true,
+}
+
+// genericSegments are subdomains and path segments of job boards that never name a company
+var genericSegments = map[string]bool{
+	"www": true, "boards": true, "job-boards": true, "jobs": true, "apply": true, "careers": true,
+	"company": true, "cmp": true, "en": true, "de": true,
+}
+
+// jobSegments are path segments of job boards after which the path names a job posting, not a
+// company, as in linkedin.com/jobs/view/123 or apply.workable.com/j/ABC123
+var jobSegments = map[string]bool{
+	"embed": true, "j": true, "o": true, "jobs": true, "job": true, "view": true, "viewjob": true,
+	"job_app": true, "comm": true, "search": true, "apply": true,
+}
+
+// companySuffixes are legal forms ignored when comparing company names, so "Acme" matches "Acme Inc."
+var companySuffixes = map[string]bool{
+	"inc": true, "ltd": true, "llc": true, "plc": true, "corp": true, "corporation": true, "co": true,
+	"company": true, "limited": true, "gmbh": true, "ag": true, "sa": true, "bv": true, "srl": true,
+}
+
+// Normalize reduces a company URL to its registrable domain, e.g.
+// "https://careers.acme.co.uk/jobs/1" becomes "acme.co.uk". On job boards the company slug is
+// kept, e.g. "greenhouse.io/acme", and a job board URL naming no company, such as a LinkedIn job
+// posting, gives an empty string since the board alone says nothing about the company.
+// URLs without a scheme are accepted. It returns an empty string when no host can be found.
+func Normalize(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" || !strings.Contains(host, ".") {
+		return ""
+	}
+	if net.ParseIP(host) != nil {
+		return host
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		domain = strings.TrimPrefix(host, "www.")
+	}
+
+	if jobBoardHosts[domain] {
+		slug := companySlug(strings.TrimSuffix(host, domain), parsed.Path, parsed.Query())
+		if slug == "" {
+			return ""
+		}
+		return domain + "/" + slug
+	}
+
+	return domain
+}
+
+// IsJobBoard reports whether the URL, host or email domain belongs to a job board or an applicant
+// tracking system, whose emails and links are shared by every company hiring through it
+func IsJobBoard(rawURL string) bool {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return false
+	}
+	return jobBoardHosts[domain] || jobBoardMailDomains[domain]
+}
+
+// companySlug finds the company on a job board URL, first in the subdomain (acme.workable.com),
+// then in the path (boards.greenhouse.io/acme) up to the segment starting the job posting, and
+// last in the "for" parameter of Greenhouse embeds (boards.greenhouse.io/embed/job_app?for=acme)
+func companySlug(subdomains string, path string, query url.Values) string {
+	for _, subdomain := range strings.Split(subdomains, ".") {
+		// two letter subdomains are countries, as in uk.linkedin.com
+		if len(subdomain) > 2 && !genericSegments[subdomain] && !looksLikeID(subdomain) {
+			return subdomain
+		}
+	}
+	for _, segment := range strings.Split(strings.ToLower(path), "/") {
+		if segment == "" || genericSegments[segment] && !jobSegments[segment] {
+			continue
+		}
+		if jobSegments[segment] || looksLikeID(segment) {
+			break
+		}
+		return segment
+	}
+	if slug := strings.ToLower(strings.TrimSpace(query.Get("for"))); slug != "" && !looksLikeID(slug) {
+		return slug
+	}
+	return ""
+}
+
+// looksLikeID reports whether a URL segment is a posting ID rather than a company slug: it has no
+// letters, or at least three digits as in "abc123" or a UUID. Slugs such as "n26" are kept.
+func looksLikeID(segment string) bool {
+	letters, digits := 0, 0
+	for _, r := range segment {
+		switch {
+		case unicode.IsLetter(r):
+			letters++
+		case unicode.IsDigit(r):
+			digits++
+		}
+	}
+	return letters == 0 || digits >= 3
+}
+
+// CompanyName reduces a company name to lower case words without punctuation or a trailing
+// legal form, e.g. "Acme, Inc." becomes "acme"
+func CompanyName(name string) string {
+	return strings.Join(companyWords(name), " ")
+}
+
+func companyWords(name string) []string {
+	words := Words(name)
+	for len(words) > 1 && companySuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return words
+}
+
+// Words splits text into lower case words, dropping punctuation but keeping "+" and "#" as in "C++" and "C#"
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+}
@@ -0,0 +1,87 @@
+package domains
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://careers.acme.co.uk/jobs/1", "acme.co.uk"},
+		{"acme.io/jobs", "acme.io"},
+		{"https://www.Acme.com./", "acme.com"},
+		{"", ""},
+		{"not a url", ""},
+		{"https://boards.greenhouse.io/acme/jobs/123", "greenhouse.io/acme"},
+		{"https://job-boards.greenhouse.io/globex", "greenhouse.io/globex"},
+		{"https://boards.greenhouse.io/embed/job_app?for=acme&token=123", "greenhouse.io/acme"},
+		{"https://boards.greenhouse.io/embed/job_app?token=123", ""},
+		{"https://jobs.lever.co/acme/0f3c2a9e-1b2c-4d5e-8f90-123456789abc", "lever.co/acme"},
+		{"https://acme.workable.com/", "workable.com/acme"},
+		{"https://apply.workable.com/acme/j/ABC123/", "workable.com/acme"},
+		{"https://apply.workable.com/j/ABC123", ""},
+		{"https://www.linkedin.com/jobs/view/3912345678/", ""},
+		{"https://uk.linkedin.com/jobs/view/senior-engineer-at-acme-3912345678", ""},
+		{"https://www.linkedin.com/company/acme/", "linkedin.com/acme"},
+		{"https://www.indeed.com/viewjob?jk=5f2a1b", ""},
+		{"https://www.indeed.com/cmp/Acme", "indeed.com/acme"},
+		{"https://jobs.ashbyhq.com/n26", "ashbyhq.com/n26"},
+		{"https://acme.wd5.myworkdayjobs.com/en-US/External", "myworkdayjobs.com/acme"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.url); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestIsJobBoard(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   bool
+	}{
+		{"linkedin.com", true},
+		{"https://www.linkedin.com/jobs/view/1", true},
+		{"us.greenhouse-mail.io", true},
+		{"hire.lever.co", true},
+		{"indeedemail.com", true},
+		{"globex.com", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsJobBoard(tt.domain); got != tt.want {
+			t.Errorf("IsJobBoard(%q) = %v, want %v", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestCompanyName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Acme, Inc.", "acme"},
+		{"Globex GmbH", "globex"},
+		{"Acme Software Ltd", "acme software"},
+		{"Company", "company"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := CompanyName(tt.name); got != tt.want {
+			t.Errorf("CompanyName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := Words("Senior C++/C# Engineer (Go)")
+	want := []string{"senior", "c++", "c#", "engineer", "go"}
+	if len(got) != len(want) {
+		t.Fatalf("Words() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Words() = %q, want %q", got, want)
+		}
+	}
+}
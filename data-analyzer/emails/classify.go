@@ -0,0 +1,52 @@
+package emails
+
+import (
+	"strings"
+
+	"data-analyzer/models"
+)
+
+// Interview stages an invitation can be for
+const (
+	StageHR        = "hr"
+	StageTechnical = "technical"
+)
+
+// categoryKeywords are checked in order, so the more decisive categories win when an email mentions several
+var categoryKeywords = []struct {
+	category string
+	keywords []string
+}{
+	{models.EmailCategoryOffer, []string{"offer letter", "pleased to offer", "happy to offer", "extend an offer", "extend you an offer", "job offer", "compensation package"}},
+	{models.EmailCategoryRejection, []string{"unfortunately", "not to move forward", "not be moving forward", "not moving forward", "decided to pursue other candidates", "other candidates", "position has been filled", "will not be proceeding", "regret to inform", "not selected"}},
+	{models.EmailCategoryTakeHome, []string{"take-home", "take home", "coding challenge", "coding assignment", "home assignment", "technical assignment", "technical test", "hackerrank", "codility", "codesignal"}},
+	{models.EmailCategoryInterviewInvite, []string{"interview", "schedule a call", "schedule a time", "availability", "calendly", "phone screen", "next round", "next step", "meet the team"}},
+}
+
+var technicalKeywords = []string{"technical", "coding", "system design", "pair programming", "whiteboard", "tech interview"}
+
+// Classify guesses the category of an email from keywords in its subject and body, used when
+// the agent is disabled. Keyword matches get a moderate confidence, generic emails a low one,
+// so the results go to review unless the threshold is lowered.
+func Classify(subject string, body string) (string, float64) {
+	text := strings.ToLower(subject + "\n" + body)
+	for _, candidate := range categoryKeywords {
+		for _, keyword := range candidate.keywords {
+			if strings.Contains(text, keyword) {
+				return candidate.category, 0.6
+			}
+		}
+	}
+	return models.EmailCategoryGeneric, 0.4
+}
+
+// InterviewStage guesses whether an interview invitation is for an HR screen or a technical interview
+func InterviewStage(subject string, body string) string {
+	text := strings.ToLower(subject + "\n" + body)
+	for _, keyword := range technicalKeywords {
+		if strings.Contains(text, keyword) {
+			return StageTechnical
+		}
+	}
+	return StageHR
+}
@@ -0,0 +1,50 @@
+package emails
+
+import (
+	"testing"
+
+	"data-analyzer/models"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		subject        string
+		body           string
+		wantCategory   string
+		wantConfidence float64
+	}{
+		{"Your application at Acme", "Unfortunately we decided not to move forward.", models.EmailCategoryRejection, 0.6},
+		{"Next steps", "Please share your availability for an interview next week.", models.EmailCategoryInterviewInvite, 0.6},
+		{"Coding challenge", "Here is the HackerRank link for the take-home.", models.EmailCategoryTakeHome, 0.6},
+		{"Offer Letter", "We are pleased to offer you the position.", models.EmailCategoryOffer, 0.6},
+		// an offer that mentions the interviews is still an offer
+		{"Great news", "After your interviews we would like to extend an offer.", models.EmailCategoryOffer, 0.6},
+		{"Unfortunately", "We will not schedule an interview.", models.EmailCategoryRejection, 0.6},
+		{"Thanks for applying", "We received your application.", models.EmailCategoryGeneric, 0.4},
+		{"", "", models.EmailCategoryGeneric, 0.4},
+	}
+	for _, tt := range tests {
+		category, confidence := Classify(tt.subject, tt.body)
+		if category != tt.wantCategory || confidence != tt.wantConfidence {
+			t.Errorf("Classify(%q, %q) = %q, %v, want %q, %v", tt.subject, tt.body, category, confidence, tt.wantCategory, tt.wantConfidence)
+		}
+	}
+}
+
+func TestInterviewStage(t *testing.T) {
+	tests := []struct {
+		subject string
+		body    string
+		want    string
+	}{
+		{"Interview invitation", "Let's have a 30 minute call with our recruiter.", StageHR},
+		{"Technical interview", "", StageTechnical},
+		{"Next round", "The session covers System Design and pair programming.", StageTechnical},
+		{"", "", StageHR},
+	}
+	for _, tt := range tests {
+		if got := InterviewStage(tt.subject, tt.body); got != tt.want {
+			t.Errorf("InterviewStage(%q, %q) = %q, want %q", tt.subject, tt.body, got, tt.want)
+		}
+	}
+}
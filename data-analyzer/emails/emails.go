@@ -0,0 +1,229 @@
+package emails
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"data-analyzer/jobposting"
+
+	"golang.org/x/net/html/charset"
+)
+
+// maxBodyLength is the number of characters of a message body that are kept
+const maxBodyLength = 8000
+
+// Message is a parsed email
+type Message struct {
+	// MessageID is the Message-ID header, or a hash of the message when it has none
+	MessageID   string
+	FromName    string
+	FromAddress string
+	Subject     string
+	Date        time.Time
+	// Body is the plain text of the message without quoted replies
+	Body string
+}
+
+// From formats the sender as "Name <address>"
+func (m Message) From() string {
+	if m.FromName == "" {
+		return m.FromAddress
+	}
+	return fmt.Sprintf("%s <%s>", m.FromName, m.FromAddress)
+}
+
+// Domain is the domain of the sender's address
+func (m Message) Domain() string {
+	_, domain, _ := strings.Cut(m.FromAddress, "@")
+	return strings.ToLower(domain)
+}
+
+var (
+	// replyHeaderRe matches the line introducing a quoted reply, e.g. "On Mon, 3 Feb 2025 Jane wrote:"
+	replyHeaderRe = regexp.MustCompile(`(?i)^(on .+ wrote:|am .+ schrieb .+:|-+ ?original message ?-+|-+ ?forwarded message ?-+)$`)
+	wordDecoder   = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+)
+
+// Parse reads the messages of an .eml file or an mbox. Files starting with an mbox "From " line,
+// or named *.mbox, are read as mboxes.
+func Parse(name string, content []byte) ([]Message, error) {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	if strings.EqualFold(filepath.Ext(name), ".mbox") || bytes.HasPrefix(content, []byte("From ")) {
+		return ParseMbox(content)
+	}
+	message, err := ParseEML(content)
+	if err != nil {
+		return nil, err
+	}
+	return []Message{message}, nil
+}
+
+// ParseMbox splits an mbox into its messages and parses each of them
+func ParseMbox(content []byte) ([]Message, error) {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+
+	var raw [][]byte
+	var current []byte
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		// a message starts with a "From " line, lines of the body starting with "From " are escaped as ">From "
+		if bytes.HasPrefix(line, []byte("From ")) && (len(current) == 0 || bytes.HasSuffix(current, []byte("\n\n"))) {
+			if len(bytes.TrimSpace(current)) > 0 {
+				raw = append(raw, current)
+			}
+			current = nil
+			continue
+		}
+		if bytes.HasPrefix(line, []byte(">From ")) {
+			line = line[1:]
+		}
+		current = append(current, line...)
+	}
+	if len(bytes.TrimSpace(current)) > 0 {
+		raw = append(raw, current)
+	}
+
+	messages := make([]Message, 0, len(raw))
+	for i, message := range raw {
+		parsed, err := ParseEML(message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse message %d of the mbox: %w", i+1, err)
+		}
+		messages = append(messages, parsed)
+	}
+	return messages, nil
+}
+
+// ParseEML parses a single RFC 5322 message
+func ParseEML(content []byte) (Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(content))
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to read email: %w", err)
+	}
+
+	message := Message{
+		MessageID: strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>"),
+		Subject:   decodeHeader(msg.Header.Get("Subject")),
+	}
+	parser := mail.AddressParser{WordDecoder: wordDecoder}
+	if from, err := parser.Parse(msg.Header.Get("From")); err == nil {
+		message.FromName, message.FromAddress = from.Name, strings.ToLower(from.Address)
+	} else {
+		message.FromAddress = strings.TrimSpace(msg.Header.Get("From"))
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		message.Date = date
+	}
+
+	body, err := readBody(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return Message{}, err
+	}
+	message.Body = jobposting.Clip(stripQuotedReply(body), maxBodyLength)
+
+	if message.MessageID == "" {
+		sum := sha256.Sum256([]byte(message.FromAddress + "\n" + message.Subject + "\n" + message.Date.String() + "\n" + message.Body))
+		message.MessageID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return message, nil
+}
+
+// readBody returns the text of a message part. For multipart messages the plain text
+// alternative is preferred over HTML, and attachments are ignored.
+func readBody(contentType string, transferEncoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		var plain, html string
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to read email part: %w", err)
+			}
+			if disposition, _, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition")); disposition == "attachment" {
+				continue
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			text, err := readBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case partType == "text/html" && html == "":
+				html = text
+			case plain == "" && text != "" && partType != "text/html":
+				plain = text
+			}
+		}
+		if plain != "" {
+			return plain, nil
+		}
+		return html, nil
+	}
+
+	if !strings.HasPrefix(mediaType, "text/") {
+		return "", nil
+	}
+	if label := params["charset"]; label != "" {
+		if decoded, err := charset.NewReaderLabel(label, body); err == nil {
+			body = decoded
+		}
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read email body: %w", err)
+	}
+	if mediaType == "text/html" {
+		return jobposting.HTMLToText(string(data)), nil
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// stripQuotedReply drops the quoted history below a reply, so only the new message is classified
+func stripQuotedReply(body string) string {
+	lines := []string{}
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if replyHeaderRe.MatchString(trimmed) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		decoded = value
+	}
+	return strings.Join(strings.Fields(decoded), " ")
+}
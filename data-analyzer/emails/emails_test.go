@@ -0,0 +1,174 @@
+package emails
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEML(t *testing.T) {
+	tests := []struct {
+		name        string
+		eml         string
+		wantFrom    string
+		wantSubject string
+		wantBody    string
+	}{
+		{
+			"plain text",
+			"Message-ID: <abc@acme.io>\nFrom: Jane Doe <Jane@Acme.io>\nSubject: Interview\nDate: Mon, 3 Feb 2025 10:00:00 +0100\n\nHi,\nlet's talk.\n",
+			"Jane Doe <jane@acme.io>", "Interview", "Hi,\nlet's talk.",
+		},
+		{
+			"encoded headers and quoted printable",
+			"From: =?UTF-8?Q?Ren=C3=A9_M=C3=BCller?= <rene@globex.de>\nSubject: =?UTF-8?B?RWlubGFkdW5n?=\n  zum Interview\nContent-Type: text/plain; charset=utf-8\nContent-Transfer-Encoding: quoted-printable\n\nGr=C3=BC=C3=9Fe aus M=C3=BCnchen\n",
+			"René Müller <rene@globex.de>", "Einladung zum Interview", "Grüße aus München",
+		},
+		{
+			"latin-1 body",
+			"From: hr@initech.fr\nSubject: Candidature\nContent-Type: text/plain; charset=iso-8859-1\n\nR\xe9ponse\n",
+			"hr@initech.fr", "Candidature", "Réponse",
+		},
+		{
+			"multipart prefers plain text and skips attachments",
+			"From: hr@acme.io\nSubject: Offer\nContent-Type: multipart/mixed; boundary=outer\n\n" +
+				"--outer\nContent-Type: multipart/alternative; boundary=inner\n\n" +
+				"--inner\nContent-Type: text/html\n\n<p>HTML <b>offer</b></p>\n" +
+				"--inner\nContent-Type: text/plain\n\nPlain offer\n" +
+				"--inner--\n" +
+				"--outer\nContent-Type: text/plain\nContent-Disposition: attachment; filename=offer.txt\n\nAttached terms\n" +
+				"--outer--\n",
+			"hr@acme.io", "Offer", "Plain offer",
+		},
+		{
+			"html only",
+			"From: hr@acme.io\nSubject: Update\nContent-Type: text/html\nContent-Transfer-Encoding: base64\n\nPHA+V2UgbW92ZWQgb248L3A+\n",
+			"hr@acme.io", "Update", "We moved on",
+		},
+		{
+			"quoted reply",
+			"From: hr@acme.io\nSubject: Re: Interview\n\nThursday works.\n\nOn Mon, 3 Feb 2025 Jane wrote:\n> Does Thursday work?\n",
+			"hr@acme.io", "Re: Interview", "Thursday works.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := ParseEML([]byte(tt.eml))
+			if err != nil {
+				t.Fatalf("ParseEML() error = %v", err)
+			}
+			if message.From() != tt.wantFrom || message.Subject != tt.wantSubject || message.Body != tt.wantBody {
+				t.Errorf("ParseEML() = from %q subject %q body %q, want %q %q %q", message.From(), message.Subject, message.Body, tt.wantFrom, tt.wantSubject, tt.wantBody)
+			}
+			if message.MessageID == "" {
+				t.Errorf("ParseEML() has no message ID")
+			}
+		})
+	}
+}
+
+func TestParseEMLMessageID(t *testing.T) {
+	eml := "Message-ID: <abc@acme.io>\nFrom: hr@acme.io\nDate: Mon, 3 Feb 2025 10:00:00 +0100\n\nHello\n"
+	message, err := ParseEML([]byte(eml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageID != "abc@acme.io" || message.Domain() != "acme.io" {
+		t.Errorf("ParseEML() = ID %q domain %q, want abc@acme.io and acme.io", message.MessageID, message.Domain())
+	}
+	if want := time.Date(2025, 2, 3, 9, 0, 0, 0, time.UTC); !message.Date.Equal(want) {
+		t.Errorf("ParseEML() date = %v, want %v", message.Date, want)
+	}
+
+	// without a Message-ID the same message always gets the same hash
+	withoutID := strings.TrimPrefix(eml, "Message-ID: <abc@acme.io>\n")
+	first, _ := ParseEML([]byte(withoutID))
+	second, _ := ParseEML([]byte(withoutID))
+	if !strings.HasPrefix(first.MessageID, "sha256:") || first.MessageID != second.MessageID {
+		t.Errorf("ParseEML() IDs = %q, %q, want the same hash", first.MessageID, second.MessageID)
+	}
+
+	if _, err := ParseEML([]byte("not an email")); err == nil {
+		t.Errorf("ParseEML() error = nil, want an error for a message without headers")
+	}
+}
+
+func TestParse(t *testing.T) {
+	mbox := "From hr@acme.io Mon Feb  3 10:00:00 2025\r\nFrom: hr@acme.io\r\nSubject: First\r\n\r\nHello\r\n>From the team\r\n\r\n" +
+		"From jobs@globex.com Tue Feb  4 10:00:00 2025\nFrom: jobs@globex.com\nSubject: Second\n\nHi,\nFrom now on we talk\n"
+
+	tests := []struct {
+		name         string
+		file         string
+		content      string
+		wantSubjects []string
+	}{
+		{"mbox by content", "export", mbox, []string{"First", "Second"}},
+		{"mbox by extension", "Inbox.MBOX", strings.SplitN(mbox, "\r\n", 2)[1], []string{"First", "Second"}},
+		{"single eml", "reply.eml", "From: hr@acme.io\nSubject: Only\n\nBody\n", []string{"Only"}},
+		{"empty mbox", "empty.mbox", "", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := Parse(tt.file, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			subjects := []string{}
+			for _, message := range messages {
+				subjects = append(subjects, message.Subject)
+			}
+			if strings.Join(subjects, ",") != strings.Join(tt.wantSubjects, ",") {
+				t.Errorf("Parse() subjects = %v, want %v", subjects, tt.wantSubjects)
+			}
+		})
+	}
+
+	messages, err := ParseMbox([]byte(mbox))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages[0].Body != "Hello\nFrom the team" {
+		t.Errorf("ParseMbox() body = %q, want the escaped From line restored", messages[0].Body)
+	}
+	if messages[1].Body != "Hi,\nFrom now on we talk" {
+		t.Errorf("ParseMbox() body = %q, want a From line inside a body kept", messages[1].Body)
+	}
+}
+
+func TestStripQuotedReply(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"Sounds good.  \n\n> earlier message\n> more", "Sounds good."},
+		{"See you then.\n\n-----Original Message-----\nFrom: Jane", "See you then."},
+		{"Danke!\nAm 3. Feb. 2025 schrieb Jane Doe <jane@acme.io>:\nHallo", "Danke!"},
+		{"FYI\n---------- Forwarded message ---------\nFrom: HR", "FYI"},
+		{"No quotes here", "No quotes here"},
+		{"> only quoted", ""},
+	}
+	for _, tt := range tests {
+		if got := stripQuotedReply(tt.body); got != tt.want {
+			t.Errorf("stripQuotedReply(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeHeader(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Interview  at\tAcme", "Interview at Acme"},
+		{"=?UTF-8?Q?Bewerbung_bei_M=C3=BCller?=", "Bewerbung bei Müller"},
+		{"=?ISO-8859-1?Q?R=E9ponse?= de Initech", "Réponse de Initech"},
+		{"=?bogus?Q?broken", "=?bogus?Q?broken"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := decodeHeader(tt.value); got != tt.want {
+			t.Errorf("decodeHeader(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,497 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"data-analyzer/domains"
+	"data-analyzer/jobposting"
+	"data-analyzer/models"
+)
+
+// Formats of an import file
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Job application fields that can be imported
+const (
+	FieldJobTitle       = "job_title"
+	FieldCompanyName    = "company_name"
+	FieldCompanyURL     = "company_url"
+	FieldJobDescription = "job_description"
+	FieldSalary         = "salary"
+	FieldStatus         = "status"
+	FieldSource         = "source"
+	FieldResumeVersion  = "resume_version"
+	FieldCreatedAt      = "created_at"
+	// FieldJobURL is the URL of the job posting. It is not stored, it tells the source and is the
+	// company URL of rows without one when it names the company.
+	FieldJobURL = "job_url"
+)
+
+var Fields = []string{FieldJobTitle, FieldCompanyName, FieldCompanyURL, FieldJobDescription, FieldSalary, FieldStatus, FieldSource, FieldResumeVersion, FieldCreatedAt, FieldJobURL}
+
+// ErrInvalidMapping is returned for mappings naming unknown fields, statuses or sources
+var ErrInvalidMapping = errors.New("invalid import mapping")
+
+// defaultColumns are the column names recognized for each field when the mapping does not name one,
+// including the columns of LinkedIn's "Job Applications.csv" export
+var defaultColumns = map[string][]string{
+	FieldJobTitle:       {"job_title", "Job Title", "Title", "Position", "Role"},
+	FieldCompanyName:    {"company_name", "Company Name", "Company", "Employer"},
+	FieldCompanyURL:     {"company_url", "Company URL", "Company Website", "Website"},
+	FieldJobDescription: {"job_description", "Job Description", "Description", "Notes"},
+	FieldSalary:         {"salary", "Salary", "Compensation", "Pay"},
+	FieldStatus:         {"status", "Status", "Stage"},
+	FieldSource:         {"source", "Source", "Channel"},
+	FieldResumeVersion:  {"resume_version", "Resume Version", "Resume Name", "Resume", "CV"},
+	FieldCreatedAt:      {"created_at", "Application Date", "Applied On", "Applied At", "Date Applied", "Date", "Created At"},
+	FieldJobURL:         {"job_url", "Job Url", "Job URL", "Job Link", "Posting URL", "URL", "Link"},
+}
+
+// statusAliases map common spreadsheet values, lower case with punctuation removed, to a status
+var statusAliases = map[string]string{
+	"preparing":      models.StatusPreparingApplication,
+	"to apply":       models.StatusPreparingApplication,
+	"todo":           models.StatusPreparingApplication,
+	"saved":          models.StatusPreparingApplication,
+	"wishlist":       models.StatusPreparingApplication,
+	"submitted":      models.StatusApplied,
+	"sent":           models.StatusApplied,
+	"no response":    models.StatusGhosted,
+	"no answer":      models.StatusGhosted,
+	"not interested": models.StatusAvoid,
+	"skip":           models.StatusAvoid,
+	"declined":       models.StatusRejected,
+	"rejection":      models.StatusRejected,
+	"interview":      models.StatusTechnicalInterview,
+	"interviewing":   models.StatusTechnicalInterview,
+	"tech interview": models.StatusTechnicalInterview,
+	"technical":      models.StatusTechnicalInterview,
+	"hr":             models.StatusHRInterview,
+	"screening":      models.StatusHRInterview,
+	"phone screen":   models.StatusHRInterview,
+	"recruiter call": models.StatusHRInterview,
+	"offered":        models.StatusOffer,
+	"offer received": models.StatusOffer,
+	"accepted":       models.StatusOffer,
+	"offer accepted": models.StatusOffer,
+}
+
+// sourceAliases map common spreadsheet values to a source. Other unknown sources become "Other".
+var sourceAliases = map[string]string{
+	"linked in":       models.SourceLinkedIn,
+	"linkedin easy":   models.SourceLinkedIn,
+	"easy apply":      models.SourceLinkedIn,
+	"careers":         models.SourceCareersWebsite,
+	"careers page":    models.SourceCareersWebsite,
+	"company website": models.SourceCareersWebsite,
+	"website":         models.SourceCareersWebsite,
+	"career site":     models.SourceCareersWebsite,
+}
+
+// dateLayouts are tried in order when the mapping has no date format. Dates with slashes are read month first.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"1/2/06, 3:04 PM",
+	"1/2/2006 15:04",
+	"1/2/2006",
+	"1/2/06",
+	"02.01.2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+// Mapping configures how the columns and values of an import file map onto job application fields.
+// Everything is optional; columns default to the names in defaultColumns.
+type Mapping struct {
+	// Columns maps a field, e.g. "job_title", to the column or JSON key holding it
+	Columns map[string]string `json:"columns"`
+	// Statuses maps values of the status column to Django's STATUS_CHOICES
+	Statuses map[string]string `json:"statuses"`
+	// Sources maps values of the source column to Django's SOURCE_CHOICES
+	Sources map[string]string `json:"sources"`
+	// DefaultStatus is used for rows without a status, "Preparing Application" if empty
+	DefaultStatus string `json:"default_status"`
+	// DefaultSource is used for rows without a source. If empty, rows linking to linkedin.com
+	// are from LinkedIn and other rows from "Careers Website".
+	DefaultSource string `json:"default_source"`
+	// DateFormat is the Go time layout of the creation date column, e.g. "02/01/2006".
+	// It is tried before the built-in layouts.
+	DateFormat string `json:"date_format"`
+}
+
+// LoadMapping reads a mapping from a JSON file. An empty path returns the default mapping.
+func LoadMapping(path string) (Mapping, error) {
+	var mapping Mapping
+	if path == "" {
+		return mapping, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mapping, fmt.Errorf("failed to read import mapping: %w", err)
+	}
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return mapping, fmt.Errorf("%w: %v", ErrInvalidMapping, err)
+	}
+	return mapping, mapping.Validate()
+}
+
+// Validate checks that the mapping only names known fields, statuses and sources
+func (m Mapping) Validate() error {
+	for field := range m.Columns {
+		if !slices.Contains(Fields, field) {
+			return fmt.Errorf("%w: unknown field %q, use one of %s", ErrInvalidMapping, field, strings.Join(Fields, ", "))
+		}
+	}
+	for value, status := range m.Statuses {
+		if !slices.Contains(models.StatusChoices, status) {
+			return fmt.Errorf("%w: status %q for %q is not one of %s", ErrInvalidMapping, status, value, strings.Join(models.StatusChoices, ", "))
+		}
+	}
+	for value, source := range m.Sources {
+		if !slices.Contains(models.SourceChoices, source) {
+			return fmt.Errorf("%w: source %q for %q is not one of %s", ErrInvalidMapping, source, value, strings.Join(models.SourceChoices, ", "))
+		}
+	}
+	if m.DefaultStatus != "" && !slices.Contains(models.StatusChoices, m.DefaultStatus) {
+		return fmt.Errorf("%w: default status %q is not one of %s", ErrInvalidMapping, m.DefaultStatus, strings.Join(models.StatusChoices, ", "))
+	}
+	if m.DefaultSource != "" && !slices.Contains(models.SourceChoices, m.DefaultSource) {
+		return fmt.Errorf("%w: default source %q is not one of %s", ErrInvalidMapping, m.DefaultSource, strings.Join(models.SourceChoices, ", "))
+	}
+	return nil
+}
+
+// DetectFormat guesses the format from the file name, or from the content when the name has no known extension
+func DetectFormat(name string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".csv", ".tsv", ".txt":
+		return FormatCSV
+	}
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return FormatJSON
+	}
+	return FormatCSV
+}
+
+// record is a row of an import file, keyed by column name
+type record struct {
+	line   int
+	values map[string]string
+}
+
+// Parse reads the rows of a CSV or JSON import file and maps them onto job applications.
+// Rows that cannot be mapped carry errors instead of failing the whole file; empty rows are left out.
+func Parse(format string, content []byte, mapping Mapping) ([]models.ImportRow, error) {
+	if err := mapping.Validate(); err != nil {
+		return nil, err
+	}
+
+	var headers []string
+	var records []record
+	var err error
+	switch format {
+	case FormatCSV:
+		headers, records, err = readCSV(content)
+	case FormatJSON:
+		headers, records, err = readJSON(content)
+	default:
+		return nil, fmt.Errorf("unknown import format %q, use %q or %q", format, FormatCSV, FormatJSON)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := mapping.resolveColumns(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]models.ImportRow, 0, len(records))
+	for _, r := range records {
+		if row, ok := mapping.mapRecord(r, columns); ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// readCSV reads a CSV file with a header row. Semicolon separated files, as exported by
+// spreadsheets in many locales, are detected from the header.
+func readCSV(content []byte) ([]string, []record, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	headerLine, _, _ := bytes.Cut(content, []byte("\n"))
+	if bytes.Count(headerLine, []byte(";")) > bytes.Count(headerLine, []byte(",")) {
+		reader.Comma = ';'
+	} else if bytes.Count(headerLine, []byte("\t")) > bytes.Count(headerLine, []byte(",")) {
+		reader.Comma = '\t'
+	}
+
+	headers, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil, fmt.Errorf("the CSV file is empty")
+		}
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i := range headers {
+		headers[i] = strings.TrimSpace(headers[i])
+	}
+
+	records := []record{}
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]string, len(headers))
+		for i, header := range headers {
+			if i < len(fields) {
+				values[header] = fields[i]
+			}
+		}
+		records = append(records, record{line: line, values: values})
+	}
+	return headers, records, nil
+}
+
+// readJSON reads a list of objects, or an object with a "job_applications" list.
+// Rows are numbered from 1 in the order of the list.
+func readJSON(content []byte) ([]string, []record, error) {
+	var objects []map[string]interface{}
+	if err := json.Unmarshal(content, &objects); err != nil {
+		var wrapped struct {
+			JobApplications []map[string]interface{} `json:"job_applications"`
+		}
+		if wrappedErr := json.Unmarshal(content, &wrapped); wrappedErr != nil || wrapped.JobApplications == nil {
+			return nil, nil, fmt.Errorf("failed to parse JSON, expected a list of objects: %w", err)
+		}
+		objects = wrapped.JobApplications
+	}
+
+	headers := []string{}
+	records := make([]record, len(objects))
+	for i, object := range objects {
+		values := make(map[string]string, len(object))
+		for key, value := range object {
+			if !slices.Contains(headers, key) {
+				headers = append(headers, key)
+			}
+			switch v := value.(type) {
+			case nil:
+			case string:
+				values[key] = v
+			case float64:
+				values[key] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				encoded, _ := json.Marshal(v)
+				values[key] = string(encoded)
+			}
+		}
+		records[i] = record{line: i + 1, values: values}
+	}
+	slices.Sort(headers)
+	return headers, records, nil
+}
+
+// resolveColumns finds the column of every field. Columns named by the mapping must exist;
+// default column names are matched ignoring case.
+func (m Mapping) resolveColumns(headers []string) (map[string]string, error) {
+	find := func(name string) (string, bool) {
+		for _, header := range headers {
+			if strings.EqualFold(header, strings.TrimSpace(name)) {
+				return header, true
+			}
+		}
+		return "", false
+	}
+
+	columns := make(map[string]string)
+	for _, field := range Fields {
+		if name, ok := m.Columns[field]; ok {
+			header, found := find(name)
+			if !found {
+				return nil, fmt.Errorf("%w: column %q mapped to %s not found, the file has %s", ErrInvalidMapping, name, field, strings.Join(headers, ", "))
+			}
+			columns[field] = header
+			continue
+		}
+		for _, name := range defaultColumns[field] {
+			if header, found := find(name); found {
+				columns[field] = header
+				break
+			}
+		}
+	}
+
+	if columns[FieldJobTitle] == "" || columns[FieldCompanyName] == "" {
+		return nil, fmt.Errorf("%w: no job title or company name column found in %s, map them with \"columns\"", ErrInvalidMapping, strings.Join(headers, ", "))
+	}
+	return columns, nil
+}
+
+// mapRecord builds the job application of a row. It returns false for rows without any value.
+func (m Mapping) mapRecord(r record, columns map[string]string) (models.ImportRow, bool) {
+	value := func(field string) string {
+		return strings.TrimSpace(r.values[columns[field]])
+	}
+	empty := true
+	for _, field := range Fields {
+		if columns[field] != "" && value(field) != "" {
+			empty = false
+		}
+	}
+	if empty {
+		return models.ImportRow{}, false
+	}
+
+	row := models.ImportRow{Line: r.line}
+	app := models.JobApplication{
+		JobTitle:       jobposting.Clip(strings.Join(strings.Fields(value(FieldJobTitle)), " "), models.MaxJobTitleLength),
+		CompanyName:    jobposting.Clip(strings.Join(strings.Fields(value(FieldCompanyName)), " "), models.MaxCompanyNameLength),
+		CompanyURL:     normalizeURL(value(FieldCompanyURL)),
+		JobDescription: value(FieldJobDescription),
+		Salary:         jobposting.Clip(value(FieldSalary), models.MaxSalaryLength),
+		ResumeVersion:  jobposting.Clip(value(FieldResumeVersion), models.MaxResumeVersionLength),
+	}
+	if app.JobTitle == "" {
+		row.Errors = append(row.Errors, "job title is empty")
+	}
+	if app.CompanyName == "" {
+		row.Errors = append(row.Errors, "company name is empty")
+	}
+	// a posting on a job board such as LinkedIn names no company, its URL would merge the row with every
+	// other job posted there
+	jobURL := normalizeURL(value(FieldJobURL))
+	if app.CompanyURL == "" && domains.Normalize(jobURL) != "" {
+		app.CompanyURL = jobURL
+	}
+	if len(app.CompanyURL) > models.MaxCompanyURLLength {
+		row.Errors = append(row.Errors, fmt.Sprintf("company URL is longer than %d characters", models.MaxCompanyURLLength))
+	}
+
+	status, err := m.mapStatus(value(FieldStatus))
+	if err != nil {
+		row.Errors = append(row.Errors, err.Error())
+	}
+	app.Status = status
+	app.Source = m.mapSource(value(FieldSource))
+	if value(FieldSource) == "" && m.DefaultSource == "" && strings.Contains(strings.ToLower(app.CompanyURL+" "+jobURL), "linkedin.com/") {
+		app.Source = models.SourceLinkedIn
+	}
+
+	if createdAt := value(FieldCreatedAt); createdAt != "" {
+		parsed, err := m.parseDate(createdAt)
+		if err != nil {
+			row.Errors = append(row.Errors, err.Error())
+		} else {
+			app.CreatedAt = parsed
+			row.HasCreatedAt = true
+		}
+	}
+
+	row.JobApplication = app
+	return row, true
+}
+
+// mapStatus maps a status value through the mapping, the status choices and the aliases, in that order
+func (m Mapping) mapStatus(value string) (string, error) {
+	if value == "" {
+		if m.DefaultStatus != "" {
+			return m.DefaultStatus, nil
+		}
+		return models.StatusPreparingApplication, nil
+	}
+	if status, ok := lookup(value, m.Statuses, models.StatusChoices, statusAliases); ok {
+		return status, nil
+	}
+	return value, fmt.Errorf("unknown status %q, add it to the status mapping", value)
+}
+
+// mapSource maps a source value like mapStatus, falling back to "Other" for unknown sources
+func (m Mapping) mapSource(value string) string {
+	if value == "" {
+		if m.DefaultSource != "" {
+			return m.DefaultSource
+		}
+		return models.SourceCareersWebsite
+	}
+	if source, ok := lookup(value, m.Sources, models.SourceChoices, sourceAliases); ok {
+		return source
+	}
+	return models.SourceOther
+}
+
+func lookup(value string, mapping map[string]string, choices []string, aliases map[string]string) (string, bool) {
+	for from, to := range mapping {
+		if strings.EqualFold(strings.TrimSpace(from), value) {
+			return to, true
+		}
+	}
+	key := aliasKey(value)
+	for _, choice := range choices {
+		if aliasKey(choice) == key {
+			return choice, true
+		}
+	}
+	mapped, ok := aliases[key]
+	return mapped, ok
+}
+
+// aliasKey lower cases a value and reduces punctuation to single spaces
+func aliasKey(value string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+func (m Mapping) parseDate(value string) (time.Time, error) {
+	layouts := dateLayouts
+	if m.DateFormat != "" {
+		layouts = append([]string{m.DateFormat}, dateLayouts...)
+	}
+	for _, layout := range layouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q, set \"date_format\" in the mapping", value)
+}
+
+// normalizeURL adds a scheme to bare domains such as "acme.io"
+func normalizeURL(value string) string {
+	if value == "" || strings.Contains(value, "://") || !strings.Contains(value, ".") || strings.ContainsAny(value, " \t") {
+		return value
+	}
+	return "https://" + value
+}
@@ -0,0 +1,125 @@
+package importer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"data-analyzer/models"
+)
+
+func TestParseLinkedInExport(t *testing.T) {
+	content := "\xef\xbb\xbfApplication Date,Contact Email,Contact Phone Number,Company Name,Job Title,Job Url,Resume Name,Question And Answers\n" +
+		"\"12/10/24, 3:31 PM\",,,Initech,Data Engineer,https://www.linkedin.com/jobs/view/3912345678/,resume.pdf,\n" +
+		"\"12/11/24, 9:05 AM\",,,Acme Inc.,Backend Engineer,https://boards.greenhouse.io/acme/jobs/1,,\n"
+
+	rows, err := Parse(FormatCSV, []byte(content), Mapping{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Parse() returned %d rows, want 2", len(rows))
+	}
+
+	linkedIn := rows[0].JobApplication
+	if linkedIn.CompanyURL != "" {
+		t.Errorf("company URL of a LinkedIn posting = %q, want none", linkedIn.CompanyURL)
+	}
+	if linkedIn.Source != models.SourceLinkedIn {
+		t.Errorf("source = %q, want %q", linkedIn.Source, models.SourceLinkedIn)
+	}
+	if want := time.Date(2024, 12, 10, 15, 31, 0, 0, time.UTC); !linkedIn.CreatedAt.Equal(want) || !rows[0].HasCreatedAt {
+		t.Errorf("created at = %v, want %v", linkedIn.CreatedAt, want)
+	}
+	if linkedIn.ResumeVersion != "resume.pdf" || linkedIn.Status != models.StatusPreparingApplication {
+		t.Errorf("resume version %q and status %q, want resume.pdf and the default status", linkedIn.ResumeVersion, linkedIn.Status)
+	}
+
+	greenhouse := rows[1].JobApplication
+	if greenhouse.CompanyURL != "https://boards.greenhouse.io/acme/jobs/1" {
+		t.Errorf("company URL of a posting naming the company = %q", greenhouse.CompanyURL)
+	}
+	if greenhouse.Source != models.SourceCareersWebsite {
+		t.Errorf("source = %q, want %q", greenhouse.Source, models.SourceCareersWebsite)
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	content := "Firma;Rolle;Datum;Stand;Kanal;Webseite\n" +
+		"Globex;Frontend Developer;03.02.2025;Waiting;Referral;globex.com\n" +
+		"Hooli;SRE;31.02.2025;Ghosting me;;\n" +
+		";;;;;\n"
+	mapping := Mapping{
+		Columns:    map[string]string{"company_name": "Firma", "job_title": "Rolle", "created_at": "Datum", "status": "Stand", "source": "Kanal", "company_url": "Webseite"},
+		Statuses:   map[string]string{"waiting": models.StatusApplied},
+		Sources:    map[string]string{"Referral": models.SourceOther},
+		DateFormat: "02.01.2006",
+	}
+
+	rows, err := Parse(DetectFormat("tracker.csv", []byte(content)), []byte(content), mapping)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Parse() returned %d rows, want 2 without the empty one", len(rows))
+	}
+
+	globex := rows[0]
+	if len(globex.Errors) != 0 {
+		t.Fatalf("errors = %v, want none", globex.Errors)
+	}
+	if globex.JobApplication.Status != models.StatusApplied || globex.JobApplication.Source != models.SourceOther {
+		t.Errorf("status %q and source %q, want %q and %q", globex.JobApplication.Status, globex.JobApplication.Source, models.StatusApplied, models.SourceOther)
+	}
+	if globex.JobApplication.CompanyURL != "https://globex.com" {
+		t.Errorf("company URL = %q, want https://globex.com", globex.JobApplication.CompanyURL)
+	}
+	if globex.Line != 2 {
+		t.Errorf("line = %d, want 2", globex.Line)
+	}
+
+	if len(rows[1].Errors) != 2 {
+		t.Errorf("errors = %v, want an unknown status and an invalid date", rows[1].Errors)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	content := `{"job_applications": [{"job_title": "SRE", "company_name": "Hooli", "status": "phone screen", "job_url": "https://jobs.lever.co/hooli/1"}]}`
+	rows, err := Parse(DetectFormat("export.json", []byte(content)), []byte(content), Mapping{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("Parse() returned %d rows, want 1", len(rows))
+	}
+	app := rows[0].JobApplication
+	if app.Status != models.StatusHRInterview || app.CompanyURL != "https://jobs.lever.co/hooli/1" {
+		t.Errorf("status %q and company URL %q", app.Status, app.CompanyURL)
+	}
+}
+
+func TestParseMissingColumns(t *testing.T) {
+	_, err := Parse(FormatCSV, []byte("Foo,Bar\n1,2\n"), Mapping{})
+	if !errors.Is(err, ErrInvalidMapping) {
+		t.Errorf("Parse() error = %v, want ErrInvalidMapping", err)
+	}
+}
+
+func TestMappingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping Mapping
+		wantErr bool
+	}{
+		{"empty", Mapping{}, false},
+		{"known field", Mapping{Columns: map[string]string{FieldJobURL: "Link"}}, false},
+		{"unknown field", Mapping{Columns: map[string]string{"salary_max": "Max"}}, true},
+		{"unknown status", Mapping{Statuses: map[string]string{"waiting": "Waiting"}}, true},
+		{"unknown default source", Mapping{DefaultSource: "Friends"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.mapping.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
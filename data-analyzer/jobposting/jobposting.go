@@ -0,0 +1,127 @@
+package jobposting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// maxPageSize is the largest page body read, larger pages are truncated
+const maxPageSize = 5 << 20
+
+// userAgent is sent with every request, some career sites refuse clients without one
+const userAgent = "Mozilla/5.0 (compatible; job-applications-tracker/1.0)"
+
+var (
+	// ErrInvalidURL is returned for URLs that are not absolute http(s) URLs
+	ErrInvalidURL = errors.New("invalid posting URL")
+	// ErrFetch is returned when the posting page could not be downloaded
+	ErrFetch = errors.New("failed to fetch posting")
+)
+
+// Posting is a job posting extracted from a web page. Fields the page does not provide are empty.
+type Posting struct {
+	JobTitle       string `json:"job_title"`
+	CompanyName    string `json:"company_name"`
+	CompanyURL     string `json:"company_url"`
+	Salary         string `json:"salary"`
+	JobDescription string `json:"job_description"`
+}
+
+// Complete reports whether the posting has everything needed to create a job application
+func (p Posting) Complete() bool {
+	return p.JobTitle != "" && p.CompanyName != "" && p.JobDescription != ""
+}
+
+// Merge fills the empty fields of the posting from another one
+func (p Posting) Merge(other Posting) Posting {
+	fill := func(value *string, fallback string) {
+		if *value == "" {
+			*value = fallback
+		}
+	}
+	fill(&p.JobTitle, other.JobTitle)
+	fill(&p.CompanyName, other.CompanyName)
+	fill(&p.CompanyURL, other.CompanyURL)
+	fill(&p.Salary, other.Salary)
+	fill(&p.JobDescription, other.JobDescription)
+	return p
+}
+
+// Page is a downloaded posting page
+type Page struct {
+	// URL is the address of the page after redirects
+	URL  string
+	HTML string
+}
+
+// ValidateURL checks that the URL is an absolute http or https URL
+func ValidateURL(rawURL string) (*url.URL, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: %q is not an http(s) URL", ErrInvalidURL, rawURL)
+	}
+	return parsed, nil
+}
+
+// Fetch downloads a posting page with the given HTTP client and decodes it to UTF-8
+func Fetch(ctx context.Context, client *http.Client, rawURL string) (Page, error) {
+	parsed, err := ValidateURL(rawURL)
+	if err != nil {
+		return Page{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return Page{}, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Page{}, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Page{}, fmt.Errorf("%w: %s returned %s", ErrFetch, parsed, resp.Status)
+	}
+
+	// pages in other encodings, named by the Content-Type header or a meta tag, are decoded to UTF-8
+	reader, err := charset.NewReader(io.LimitReader(resp.Body, maxPageSize), resp.Header.Get("Content-Type"))
+	if err != nil {
+		return Page{}, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return Page{}, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+
+	return Page{URL: resp.Request.URL.String(), HTML: string(body)}, nil
+}
+
+// Clip shortens text to at most maxRunes runes without adding an ellipsis, for columns with a maximum length
+func Clip(text string, maxRunes int) string {
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+	runes := 0
+	for i := range text {
+		if runes == maxRunes {
+			return strings.TrimSpace(text[:i])
+		}
+		runes++
+	}
+	return text
+}
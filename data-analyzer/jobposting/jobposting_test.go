@@ -0,0 +1,170 @@
+package jobposting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFixtureServer serves the files of testdata, each with the content type given for its name
+func newFixtureServer(t *testing.T, contentTypes map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			http.Error(w, "no user agent", http.StatusForbidden)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if name == "moved" {
+			http.Redirect(w, r, "/jsonld.html", http.StatusFound)
+			return
+		}
+		content, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		contentType, ok := contentTypes[name]
+		if !ok {
+			contentType = "text/html; charset=utf-8"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchJSONLD(t *testing.T) {
+	server := newFixtureServer(t, nil)
+
+	page, err := Fetch(context.Background(), server.Client(), server.URL+"/moved")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if page.URL != server.URL+"/jsonld.html" {
+		t.Errorf("page URL = %q, want the URL after the redirect", page.URL)
+	}
+
+	posting, ok := ParseJSONLD(page)
+	if !ok {
+		t.Fatal("ParseJSONLD() found no JobPosting")
+	}
+	want := Posting{
+		JobTitle:       "Senior Go Engineer",
+		CompanyName:    "Hooli",
+		CompanyURL:     "https://hooli.com",
+		Salary:         "EUR 90,000 - 110,000 per year",
+		JobDescription: "Build distributed systems in Go.\n- Kubernetes\n- PostgreSQL",
+	}
+	if posting != want {
+		t.Errorf("ParseJSONLD() = %+v, want %+v", posting, want)
+	}
+	if !posting.Complete() {
+		t.Error("Complete() = false for a posting with title, company and description")
+	}
+}
+
+func TestFetchReadability(t *testing.T) {
+	server := newFixtureServer(t, nil)
+
+	page, err := Fetch(context.Background(), server.Client(), server.URL+"/readability.html")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if _, ok := ParseJSONLD(page); ok {
+		t.Error("ParseJSONLD() found a JobPosting in a page without JSON-LD")
+	}
+
+	readable := ExtractReadable(page)
+	if readable.Title != "Platform Engineer" || readable.SiteName != "Globex" {
+		t.Errorf("title %q and site name %q, want Platform Engineer and Globex", readable.Title, readable.SiteName)
+	}
+	for _, want := range []string{"operate Kubernetes clusters", "Terraform and CI/CD pipelines", "€70,000 - €85,000 per year"} {
+		if !strings.Contains(readable.Text, want) {
+			t.Errorf("readable text is missing %q:\n%s", want, readable.Text)
+		}
+	}
+	for _, boilerplate := range []string{"All jobs", "cookies", "Designer", "Globex Corporation"} {
+		if strings.Contains(readable.Text, boilerplate) {
+			t.Errorf("readable text has the boilerplate %q:\n%s", boilerplate, readable.Text)
+		}
+	}
+}
+
+func TestFetchDecodesCharset(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{"from the header", "text/html; charset=iso-8859-1"},
+		// without a declared charset the bytes are sniffed as windows-1252
+		{"from the content", "text/html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFixtureServer(t, map[string]string{"latin1.html": tt.contentType})
+
+			page, err := Fetch(context.Background(), server.Client(), server.URL+"/latin1.html")
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			readable := ExtractReadable(page)
+			if readable.Title != "Développeur Backend" {
+				t.Errorf("title = %q, want Développeur Backend", readable.Title)
+			}
+			if !strings.Contains(readable.Text, "Société Générale à Paris") {
+				t.Errorf("text = %q, want it decoded to UTF-8", readable.Text)
+			}
+		})
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	server := newFixtureServer(t, nil)
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{server.URL + "/missing.html", ErrFetch},
+		{"ftp://example.com/job", ErrInvalidURL},
+		{"/jobs/1", ErrInvalidURL},
+		{"http://127.0.0.1:1/job", ErrFetch},
+	}
+	for _, tt := range tests {
+		if _, err := Fetch(context.Background(), server.Client(), tt.url); !errors.Is(err, tt.want) {
+			t.Errorf("Fetch(%q) error = %v, want %v", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	posting := Posting{JobTitle: "SRE", Salary: "EUR 90,000"}
+	merged := posting.Merge(Posting{JobTitle: "Site Reliability Engineer", CompanyName: "Hooli", JobDescription: "Keep things running."})
+	want := Posting{JobTitle: "SRE", CompanyName: "Hooli", Salary: "EUR 90,000", JobDescription: "Keep things running."}
+	if merged != want {
+		t.Errorf("Merge() = %+v, want %+v", merged, want)
+	}
+}
+
+func TestClip(t *testing.T) {
+	tests := []struct {
+		text     string
+		maxRunes int
+		want     string
+	}{
+		{"short", 10, "short"},
+		{"Développeur Backend", 11, "Développeur"},
+		{"exactly", 7, "exactly"},
+	}
+	for _, tt := range tests {
+		if got := Clip(tt.text, tt.maxRunes); got != tt.want {
+			t.Errorf("Clip(%q, %d) = %q, want %q", tt.text, tt.maxRunes, got, tt.want)
+		}
+	}
+}
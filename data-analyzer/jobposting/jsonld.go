@@ -0,0 +1,258 @@
+package jobposting
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ParseJSONLD extracts the first schema.org JobPosting from the JSON-LD scripts of a page.
+// It returns false when the page has no JobPosting.
+func ParseJSONLD(page Page) (Posting, bool) {
+	document, err := html.Parse(strings.NewReader(page.HTML))
+	if err != nil {
+		return Posting{}, false
+	}
+
+	for _, script := range findElements(document, "script") {
+		if !strings.EqualFold(strings.TrimSpace(attribute(script, "type")), "application/ld+json") {
+			continue
+		}
+		var data interface{}
+		// malformed scripts are common and simply skipped
+		if err := json.Unmarshal([]byte(textContent(script)), &data); err != nil {
+			continue
+		}
+		if jobPosting := findJobPosting(data); jobPosting != nil {
+			return newPostingFromJSONLD(jobPosting), true
+		}
+	}
+	return Posting{}, false
+}
+
+// findJobPosting looks for a JobPosting object in a JSON-LD value, including @graph lists
+func findJobPosting(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if jobPosting := findJobPosting(item); jobPosting != nil {
+				return jobPosting
+			}
+		}
+	case map[string]interface{}:
+		if hasType(v["@type"], "JobPosting") {
+			return v
+		}
+		for _, key := range []string{"@graph", "mainEntity"} {
+			if jobPosting := findJobPosting(v[key]); jobPosting != nil {
+				return jobPosting
+			}
+		}
+	}
+	return nil
+}
+
+// hasType reports whether an @type value, a string or a list of strings, names the type
+func hasType(value interface{}, name string) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.EqualFold(strings.TrimPrefix(v, "http://schema.org/"), name) ||
+			strings.EqualFold(strings.TrimPrefix(v, "https://schema.org/"), name)
+	case []interface{}:
+		for _, item := range v {
+			if hasType(item, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newPostingFromJSONLD(jobPosting map[string]interface{}) Posting {
+	posting := Posting{
+		JobTitle: cleanText(stringValue(jobPosting["title"])),
+	}
+	if posting.JobTitle == "" {
+		posting.JobTitle = cleanText(stringValue(jobPosting["name"]))
+	}
+
+	switch organization := jobPosting["hiringOrganization"].(type) {
+	case string:
+		posting.CompanyName = cleanText(organization)
+	case map[string]interface{}:
+		posting.CompanyName = cleanText(stringValue(organization["name"]))
+		posting.CompanyURL = firstString(organization["sameAs"])
+		if posting.CompanyURL == "" {
+			posting.CompanyURL = firstString(organization["url"])
+		}
+	}
+	if _, err := ValidateURL(posting.CompanyURL); err != nil {
+		posting.CompanyURL = ""
+	}
+
+	// descriptions are HTML, sometimes escaped a second time
+	description := stringValue(jobPosting["description"])
+	if strings.Contains(description, "&lt;") {
+		description = html.UnescapeString(description)
+	}
+	posting.JobDescription = HTMLToText(description)
+
+	posting.Salary = formatSalary(jobPosting["baseSalary"])
+	if posting.Salary == "" {
+		posting.Salary = formatSalary(jobPosting["estimatedSalary"])
+	}
+
+	return posting
+}
+
+// formatSalary renders a MonetaryAmount as text the compensation parser understands,
+// e.g. "USD 100,000 - 150,000 per year"
+func formatSalary(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return cleanText(v)
+	case float64:
+		return formatAmount(v)
+	case []interface{}:
+		if len(v) > 0 {
+			return formatSalary(v[0])
+		}
+	case map[string]interface{}:
+		currency := strings.ToUpper(stringValue(v["currency"]))
+		amount, unit := "", stringValue(v["unitText"])
+
+		switch quantity := v["value"].(type) {
+		case map[string]interface{}:
+			minimum, hasMinimum := numberValue(quantity["minValue"])
+			maximum, hasMaximum := numberValue(quantity["maxValue"])
+			exact, hasExact := numberValue(quantity["value"])
+			switch {
+			case hasMinimum && hasMaximum && minimum != maximum:
+				amount = formatAmount(minimum) + " - " + formatAmount(maximum)
+			case hasMinimum:
+				amount = formatAmount(minimum)
+			case hasMaximum:
+				amount = formatAmount(maximum)
+			case hasExact:
+				amount = formatAmount(exact)
+			}
+			if unitText := stringValue(quantity["unitText"]); unitText != "" {
+				unit = unitText
+			}
+		default:
+			if exact, ok := numberValue(quantity); ok {
+				amount = formatAmount(exact)
+			}
+		}
+
+		if amount == "" {
+			return ""
+		}
+		salary := strings.TrimSpace(currency + " " + amount)
+		if unit != "" {
+			salary += " per " + strings.ToLower(unit)
+		}
+		return salary
+	}
+	return ""
+}
+
+// formatAmount writes whole amounts with thousands separators
+func formatAmount(amount float64) string {
+	if amount != math.Trunc(amount) {
+		return strconv.FormatFloat(amount, 'f', 2, 64)
+	}
+	digits := strconv.FormatInt(int64(amount), 10)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// firstString returns a string value, or the first string of a list
+func firstString(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			if s := stringValue(item); s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+	return stringValue(value)
+}
+
+// numberValue accepts numbers and numeric strings, which many sites use
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, v > 0
+	case string:
+		number, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(v), ",", ""), 64)
+		return number, err == nil && number > 0
+	}
+	return 0, false
+}
+
+// cleanText decodes entities and collapses whitespace in a single line value
+func cleanText(text string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+func attribute(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, name) {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// findElements returns every element with the tag name, in document order
+func findElements(node *html.Node, tag string) []*html.Node {
+	elements := []*html.Node{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == tag {
+			elements = append(elements, n)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+	return elements
+}
+
+// textContent concatenates the raw text below a node, used for script contents
+func textContent(node *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+	return b.String()
+}
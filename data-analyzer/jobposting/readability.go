@@ -0,0 +1,225 @@
+package jobposting
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// minReadableLength is the shortest main content accepted before falling back to the whole body
+const minReadableLength = 200
+
+// Readable is the main content of a page with the boilerplate stripped
+type Readable struct {
+	Title    string
+	SiteName string
+	Text     string
+}
+
+// skippedTags never contain posting content
+var skippedTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true, "iframe": true,
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true, "button": true,
+	"select": true, "input": true, "textarea": true, "head": true,
+}
+
+// blockTags start a new line when rendered as text
+var blockTags = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true, "br": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "ul": true, "ol": true,
+	"li": true, "dl": true, "dt": true, "dd": true, "table": true, "tr": true, "pre": true, "blockquote": true,
+}
+
+// scoredTags hold the paragraphs whose containers are scored as candidates for the main content
+var scoredTags = map[string]bool{"p": true, "li": true, "pre": true, "td": true, "blockquote": true}
+
+var (
+	// unlikelyRe matches class names and ids of boilerplate blocks, as in Mozilla's Readability
+	unlikelyRe = regexp.MustCompile(`(?i)-ad-|banner|breadcrumb|combx|comment|community|cookie|consent|disqus|footer|gdpr|header|menu|modal|newsletter|pagination|pager|popup|related|share|shoutbox|sidebar|social|sponsor|subscribe`)
+	// maybeCandidateRe keeps blocks matching unlikelyRe that are probably the content anyway
+	maybeCandidateRe = regexp.MustCompile(`(?i)article|body|column|content|main|job|posting|description`)
+)
+
+// ExtractReadable finds the title, site name and main text of a page, similar to a reader view
+func ExtractReadable(page Page) Readable {
+	document, err := html.Parse(strings.NewReader(page.HTML))
+	if err != nil {
+		return Readable{}
+	}
+
+	readable := Readable{
+		Title:    metaContent(document, "og:title"),
+		SiteName: metaContent(document, "og:site_name"),
+	}
+	if readable.Title == "" {
+		if titles := findElements(document, "title"); len(titles) > 0 {
+			readable.Title = cleanText(textContent(titles[0]))
+		}
+	}
+	if readable.Title == "" {
+		if headings := findElements(document, "h1"); len(headings) > 0 {
+			readable.Title = cleanText(textContent(headings[0]))
+		}
+	}
+
+	removeBoilerplate(document)
+	bodies := findElements(document, "body")
+	if len(bodies) == 0 {
+		return readable
+	}
+
+	readable.Text = renderText(bodies[0])
+	if candidate := topCandidate(bodies[0]); candidate != nil {
+		if text := renderText(candidate); len(text) >= minReadableLength {
+			readable.Text = text
+		}
+	}
+	return readable
+}
+
+// HTMLToText renders an HTML fragment as plain text with every paragraph and list item on its own line
+func HTMLToText(fragment string) string {
+	document, err := html.Parse(strings.NewReader(fragment))
+	if err != nil {
+		return cleanText(fragment)
+	}
+	return renderText(document)
+}
+
+// removeBoilerplate detaches navigation, scripts, forms and blocks whose class or id look like boilerplate
+func removeBoilerplate(node *html.Node) {
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.CommentNode {
+			node.RemoveChild(child)
+		} else if child.Type == html.ElementNode && isBoilerplate(child) {
+			node.RemoveChild(child)
+		} else {
+			removeBoilerplate(child)
+		}
+		child = next
+	}
+}
+
+func isBoilerplate(node *html.Node) bool {
+	if skippedTags[node.Data] {
+		return true
+	}
+	if node.Data == "body" || node.Data == "main" || node.Data == "article" {
+		return false
+	}
+	if role := attribute(node, "role"); role == "navigation" || role == "banner" || role == "dialog" {
+		return true
+	}
+	names := attribute(node, "class") + " " + attribute(node, "id")
+	return unlikelyRe.MatchString(names) && !maybeCandidateRe.MatchString(names)
+}
+
+// topCandidate scores the parents of paragraphs by the amount of text they hold and
+// returns the best one, penalizing link-heavy blocks
+func topCandidate(body *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+	order := []*html.Node{}
+	addScore := func(node *html.Node, score float64) {
+		if node == nil || node.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[node]; !ok {
+			order = append(order, node)
+		}
+		scores[node] += score
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && scoredTags[n.Data] {
+			text := cleanText(textContent(n))
+			if len(text) >= 25 {
+				score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+				addScore(n.Parent, score)
+				if n.Parent != nil {
+					addScore(n.Parent.Parent, score/2)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(body)
+
+	var best *html.Node
+	bestScore := 0.0
+	for _, node := range order {
+		score := scores[node] * (1 - linkDensity(node))
+		if score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+	return best
+}
+
+// linkDensity is the share of a node's text that is inside links
+func linkDensity(node *html.Node) float64 {
+	total := len(cleanText(textContent(node)))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	for _, link := range findElements(node, "a") {
+		linked += len(cleanText(textContent(link)))
+	}
+	return float64(linked) / float64(total)
+}
+
+// renderText writes the text below a node with block elements on separate lines
+func renderText(node *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if skippedTags[n.Data] {
+				return
+			}
+		}
+
+		block := n.Type == html.ElementNode && blockTags[n.Data]
+		if block {
+			b.WriteString("\n")
+		}
+		if n.Type == html.ElementNode && n.Data == "li" {
+			b.WriteString("- ")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if block {
+			b.WriteString("\n")
+		}
+	}
+	walk(node)
+
+	// collapse whitespace within lines and put every paragraph and list item on a single line
+	lines := []string{}
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// metaContent returns the content of a <meta property=...> or <meta name=...> tag
+func metaContent(document *html.Node, property string) string {
+	for _, meta := range findElements(document, "meta") {
+		if strings.EqualFold(attribute(meta, "property"), property) || strings.EqualFold(attribute(meta, "name"), property) {
+			return cleanText(attribute(meta, "content"))
+		}
+	}
+	return ""
+}
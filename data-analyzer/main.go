@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 
 	"data-analyzer/agent"
 	"data-analyzer/api"
@@ -31,6 +32,13 @@ func main() {
 		}
 	}
 
+	if len(os.Args) > 1 {
+		if err := runCommand(context.TODO(), cfg, database, geminiClient, os.Args[1:]); err != nil {
+			log.Fatalf("Command failed: %v", err)
+		}
+		return
+	}
+
 	if cfg.ShouldRunServer {
 		server := api.NewServer(cfg, database, geminiClient)
 		server.Run()
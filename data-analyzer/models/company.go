@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Company is an employer, deduplicated by the canonical domain of its job applications' URLs. Job
+// applications without a URL are matched to a company by one of its aliases.
+type Company struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Domain is the normalized company URL, e.g. "acme.io" or "greenhouse.io/acme", empty for companies
+	// only known by name
+	Domain string `json:"domain"`
+	// Aliases are the names the company was written as, e.g. "Acme" and "Acme Inc."
+	Aliases           []string  `json:"aliases"`
+	JobApplicationIDs []int     `json:"job_application_ids"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
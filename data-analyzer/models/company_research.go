@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// CompanyResearch is the cached research of a company, shared by every job application at that company
+type CompanyResearch struct {
+	// Key is the normalized domain of the company URL, or "name:" and the normalized company name when
+	// the job applications have no URL
+	Key          string    `json:"key"`
+	CompanyName  string    `json:"company_name"`
+	WorkflowID   int       `json:"workflow_id"`
+	Research     string    `json:"research"`
+	ResearchedAt time.Time `json:"researched_at"`
+}
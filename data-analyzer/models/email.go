@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Categories of recruiter emails
+const (
+	EmailCategoryRejection       = "rejection"
+	EmailCategoryInterviewInvite = "interview_invite"
+	EmailCategoryTakeHome        = "take_home"
+	EmailCategoryOffer           = "offer"
+	EmailCategoryGeneric         = "generic"
+)
+
+var EmailCategories = []string{EmailCategoryRejection, EmailCategoryInterviewInvite, EmailCategoryTakeHome, EmailCategoryOffer, EmailCategoryGeneric}
+
+// States of an ingested email
+const (
+	// EmailStateApplied means a step was added and the status was updated
+	EmailStateApplied = "applied"
+	// EmailStateProposed means a step was added and a status update awaits approval
+	EmailStateProposed = "proposed"
+	// EmailStateRecorded means a step was added and no status update is needed
+	EmailStateRecorded = "recorded"
+	// EmailStateReview means the match or classification is not confident enough, nothing was written yet
+	EmailStateReview = "review"
+	// EmailStateDismissed means the email was rejected during review
+	EmailStateDismissed = "dismissed"
+)
+
+var EmailStates = []string{EmailStateApplied, EmailStateProposed, EmailStateRecorded, EmailStateReview, EmailStateDismissed}
+
+// Email is a recruiter email matched to a job application and classified
+type Email struct {
+	ID                 int       `json:"id"`
+	MessageID          string    `json:"message_id"`
+	From               string    `json:"from"`
+	Subject            string    `json:"subject"`
+	ReceivedAt         time.Time `json:"received_at"`
+	JobApplicationID   int       `json:"job_application_id"`
+	MatchReason        string    `json:"match_reason"`
+	MatchConfidence    float64   `json:"match_confidence"`
+	Category           string    `json:"category"`
+	CategoryConfidence float64   `json:"category_confidence"`
+	Summary            string    `json:"summary"`
+	ProposedStatus     string    `json:"proposed_status"`
+	State              string    `json:"state"`
+	CreatedAt          time.Time `json:"created_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Types of items that are embedded for semantic search
+const (
+	EmbeddingItemJob         = "job"
+	EmbeddingItemRequirement = "requirement"
+	EmbeddingItemResearch    = "research"
+	EmbeddingItemAchievement = "achievement"
+)
+
+var EmbeddingItemTypes = []string{EmbeddingItemJob, EmbeddingItemRequirement, EmbeddingItemResearch, EmbeddingItemAchievement}
+
+// Embedding is the vector of a single item's text, produced by one embedding model.
+// ContentHash identifies the text that was embedded, so unchanged items are not embedded again.
+type Embedding struct {
+	ItemType         string
+	ItemKey          string
+	JobApplicationID int
+	Model            string
+	ContentHash      string
+	Content          string
+	Vector           []float32
+	UpdatedAt        time.Time
+}
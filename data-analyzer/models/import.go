@@ -0,0 +1,53 @@
+package models
+
+// What happened to a row of a bulk import
+const (
+	ImportActionCreate    = "create"
+	ImportActionDuplicate = "duplicate"
+	ImportActionInvalid   = "invalid"
+)
+
+// ImportRow is a job application read from a row of an import file.
+// Rows with errors are reported but never inserted.
+type ImportRow struct {
+	Line           int
+	JobApplication JobApplication
+	// HasCreatedAt is set when the row gives its own creation date
+	HasCreatedAt bool
+	Errors       []string
+}
+
+// ImportOptions controls how the rows of an import file are written
+type ImportOptions struct {
+	// DryRun runs the import in a transaction that is rolled back
+	DryRun bool
+	// SkipInvalid imports the valid rows even when some rows are invalid
+	SkipInvalid bool
+	// Origin describes the import file in the step added to every created job application
+	Origin string
+}
+
+// ImportRowResult is the outcome of a single row of an import
+type ImportRowResult struct {
+	Line             int    `json:"line"`
+	Action           string `json:"action"`
+	JobApplicationID int    `json:"job_application_id,omitempty"`
+	// DuplicateOf is the existing job application the row matches
+	DuplicateOf int `json:"duplicate_of,omitempty"`
+	// DuplicateOfLine is the earlier row of the same file the row matches
+	DuplicateOfLine int      `json:"duplicate_of_line,omitempty"`
+	JobTitle        string   `json:"job_title"`
+	CompanyName     string   `json:"company_name"`
+	Status          string   `json:"status"`
+	Source          string   `json:"source"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+// ImportSummary counts the outcomes of an import
+type ImportSummary struct {
+	DryRun     bool              `json:"dry_run"`
+	Created    int               `json:"created"`
+	Duplicates int               `json:"duplicates"`
+	Invalid    int               `json:"invalid"`
+	Rows       []ImportRowResult `json:"rows"`
+}
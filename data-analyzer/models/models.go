@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // JobApplication represents a job application record
 type JobApplication struct {
 	ID             int
@@ -7,4 +9,19 @@ type JobApplication struct {
 	JobDescription string
 	CompanyName    string
 	CompanyURL     string
+	Salary         string
+	ResumeVersion  string
+	Status         string
+	Source         string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
+
+// Maximum lengths of the jobs_jobapplication columns, mirroring the Django model
+const (
+	MaxJobTitleLength      = 100
+	MaxCompanyNameLength   = 100
+	MaxCompanyURLLength    = 200
+	MaxSalaryLength        = 100
+	MaxResumeVersionLength = 100
+)
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Research data categories, mirroring ResearchDataCategories of the Django app
+const (
+	ResearchCategoryResponsibility  = 1
+	ResearchCategoryRequirement     = 2
+	ResearchCategoryCompanyResearch = 3
+	ResearchCategoryRoleResearch    = 4
+)
+
+// ResearchData represents a research note attached to a job application
+type ResearchData struct {
+	ID               int       `json:"id"`
+	JobApplicationID int       `json:"job_application_id"`
+	Category         int       `json:"category"`
+	Info             string    `json:"info"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
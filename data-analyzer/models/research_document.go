@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ResearchDocument is a user-supplied document about a company, used for research without web search
+type ResearchDocument struct {
+	ID int `json:"id"`
+	// CompanyKey is the key of the company in the research cache, see CompanyResearch
+	CompanyKey  string `json:"company_key"`
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Format      string `json:"format"`
+	ContentHash string `json:"content_hash"`
+	// Text is the extracted text of the document, citations point into it with byte offsets
+	Text      string    `json:"text,omitempty"`
+	Length    int       `json:"length"`
+	Chunks    int       `json:"chunks"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResearchChunk is an indexed part of a research document and its vector from one embedding model
+type ResearchChunk struct {
+	DocumentID  int
+	ChunkIndex  int
+	StartOffset int
+	EndOffset   int
+	Content     string
+	Model       string
+	Vector      []float32
+}
@@ -0,0 +1,39 @@
+package models
+
+// Job application statuses, mirroring STATUS_CHOICES in the Django jobs app
+const (
+	StatusPreparingApplication = "Preparing Application"
+	StatusApplied              = "Applied"
+	StatusGhosted              = "Ghosted"
+	StatusAvoid                = "Avoid"
+	StatusRejected             = "Rejected"
+	StatusTechnicalInterview   = "Technical Interview"
+	StatusHRInterview          = "HR Interview"
+	StatusOffer                = "Offer"
+)
+
+// Job application sources, mirroring SOURCE_CHOICES in the Django jobs app
+const (
+	SourceLinkedIn       = "LinkedIn"
+	SourceCareersWebsite = "Careers Website"
+	SourceOther          = "Other"
+)
+
+// StatusChoices lists every valid job application status
+var StatusChoices = []string{
+	StatusPreparingApplication,
+	StatusApplied,
+	StatusGhosted,
+	StatusAvoid,
+	StatusRejected,
+	StatusTechnicalInterview,
+	StatusHRInterview,
+	StatusOffer,
+}
+
+// SourceChoices lists every valid job application source
+var SourceChoices = []string{
+	SourceLinkedIn,
+	SourceCareersWebsite,
+	SourceOther,
+}
@@ -1,9 +1,14 @@
 package models
 
+import "time"
+
 type Step struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	ID               int       `json:"id"`
+	JobApplicationID int       `json:"job_application_id"`
+	Title            string    `json:"title"`
+	Description      string    `json:"description"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 type StepInput struct {
@@ -0,0 +1,25 @@
+package models
+
+// Types of items in the full-text index
+const (
+	TextSearchItemJob      = "job"
+	TextSearchItemStep     = "step"
+	TextSearchItemResearch = "research"
+	TextSearchItemWorkflow = "workflow"
+)
+
+var TextSearchItemTypes = []string{TextSearchItemJob, TextSearchItemStep, TextSearchItemResearch, TextSearchItemWorkflow}
+
+// TextSearchResult is an item matching a full-text query. Snippet holds the best matching
+// part of the text with the matched terms wrapped in <mark> tags; a lower Rank is a better match.
+type TextSearchResult struct {
+	ItemType         string  `json:"item_type"`
+	ItemID           int     `json:"item_id"`
+	JobApplicationID int     `json:"job_application_id,omitempty"`
+	JobTitle         string  `json:"job_title,omitempty"`
+	CompanyName      string  `json:"company_name,omitempty"`
+	Status           string  `json:"status,omitempty"`
+	Title            string  `json:"title"`
+	Snippet          string  `json:"snippet"`
+	Rank             float64 `json:"rank"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Types of the events sent to webhooks
+const (
+	// WebhookEventWorkflowCompleted is sent when a workflow result is stored
+	WebhookEventWorkflowCompleted = "workflow.completed"
+	// WebhookEventWorkflowFailed is sent when a workflow fails before its result is stored
+	WebhookEventWorkflowFailed = "workflow.failed"
+	// WebhookEventStepAdded is sent when a step is added to a job application, by the analyzer or the Django server
+	WebhookEventStepAdded = "step.added"
+	// WebhookEventStatusSuggested is sent when the analyzer suggests a status change that was not applied
+	WebhookEventStatusSuggested = "status.suggested"
+)
+
+var WebhookEventTypes = []string{WebhookEventWorkflowCompleted, WebhookEventWorkflowFailed, WebhookEventStepAdded, WebhookEventStatusSuggested}
+
+// States of a webhook delivery
+const (
+	// WebhookDeliveryPending means the delivery has not succeeded yet and will be attempted again
+	WebhookDeliveryPending = "pending"
+	// WebhookDeliveryDelivered means the endpoint answered with a 2xx status
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryFailed means every attempt failed and the delivery was given up
+	WebhookDeliveryFailed = "failed"
+)
+
+// WebhookSubscription is an endpoint the events are sent to
+type WebhookSubscription struct {
+	ID  int    `json:"id"`
+	URL string `json:"url"`
+	// Secret signs the deliveries, it is only returned when the subscription is created
+	Secret string `json:"secret,omitempty"`
+	// EventTypes are the events sent to the endpoint, all of them when empty
+	EventTypes  []string  `json:"event_types"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookEvent is something that happened, waiting to be sent to the subscriptions
+type WebhookEvent struct {
+	ID   int    `json:"id"`
+	Type string `json:"type"`
+	// Key identifies what the event is about, the same event is only recorded once
+	Key              string `json:"key"`
+	JobApplicationID int    `json:"job_application_id"`
+	// RefID is the ID of the step or workflow of the event
+	RefID int `json:"ref_id"`
+	// Data is the JSON payload of the events recorded by the analyzer, empty for the events recorded
+	// by database triggers, whose payload is built from RefID when they are dispatched
+	Data      string    `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a subscription
+type WebhookDelivery struct {
+	ID             int    `json:"id"`
+	SubscriptionID int    `json:"subscription_id"`
+	EventID        int    `json:"event_id"`
+	EventType      string `json:"event_type"`
+	// Payload is the signed JSON body, the same for every attempt
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
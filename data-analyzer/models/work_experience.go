@@ -0,0 +1,10 @@
+package models
+
+// WorkAchievement represents an achievement recorded for one of the candidate's work experiences
+type WorkAchievement struct {
+	ID               int    `json:"id"`
+	WorkExperienceID int    `json:"work_experience_id"`
+	JobTitle         string `json:"job_title"`
+	CompanyName      string `json:"company_name"`
+	Description      string `json:"description"`
+}
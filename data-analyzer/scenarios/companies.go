@@ -0,0 +1,283 @@
+package scenarios
+
+import (
+	"data-analyzer/agent/workflows"
+	"data-analyzer/db"
+	"data-analyzer/domains"
+	"data-analyzer/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCompanyAlias is returned for aliases that cannot name a company
+var ErrInvalidCompanyAlias = errors.New("invalid company alias")
+
+// CompanyApplication is a job application at a company with everything the analyzer knows about it
+type CompanyApplication struct {
+	ID            int                        `json:"id"`
+	JobTitle      string                     `json:"job_title"`
+	CompanyName   string                     `json:"company_name"`
+	CompanyURL    string                     `json:"company_url"`
+	Status        string                     `json:"status"`
+	Source        string                     `json:"source"`
+	ResumeVersion string                     `json:"resume_version"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	UpdatedAt     time.Time                  `json:"updated_at"`
+	Steps         []models.Step              `json:"steps"`
+	RedFlags      *workflows.RedFlagsResult  `json:"red_flags"`
+	Salary        *workflows.JobCompensation `json:"salary"`
+}
+
+// CompanyView is the full history with a company: its applications, how far they got and its research
+type CompanyView struct {
+	models.Company
+	// StatusCounts counts the applications by their current status
+	StatusCounts map[string]int       `json:"status_counts"`
+	Applications []CompanyApplication `json:"applications"`
+	// Research is the most recent research of the company, nil when it was never researched
+	Research       *workflows.ResearchCompany `json:"research"`
+	FirstAppliedAt time.Time                  `json:"first_applied_at"`
+	LastActivityAt time.Time                  `json:"last_activity_at"`
+}
+
+type CompaniesScenario struct {
+	db *db.DB
+}
+
+func NewCompaniesScenario(db *db.DB) *CompaniesScenario {
+	return &CompaniesScenario{
+		db: db,
+	}
+}
+
+// Sync assigns every job application to a company. Job applications are matched by the normalized
+// domain of their company URL; those without one, or with a job board URL naming no company such as
+// a LinkedIn job posting, by company name against the aliases of the known companies. A company only
+// known by name gets the domain of the first job application with a URL that matches it by name.
+// Every spelling of a company name becomes an alias, and companies left without job applications are
+// removed.
+func (s *CompaniesScenario) Sync() ([]models.Company, error) {
+	stored, err := s.db.GetCompanies()
+	if err != nil {
+		return nil, err
+	}
+	// companies keyed by the bare domain of a job board merged every company hiring through it, their
+	// job applications are matched again by name
+	companies := []models.Company{}
+	for _, company := range stored {
+		if company.Domain == "" || !isBareJobBoard(company.Domain) {
+			companies = append(companies, company)
+		}
+	}
+	jobs, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return nil, err
+	}
+	// oldest first, so the first job application at a company names it
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+
+	byDomain := make(map[string]int)
+	byAlias := make(map[string]int)
+	for i := range companies {
+		companies[i].JobApplicationIDs = []int{}
+		if companies[i].Domain != "" {
+			byDomain[companies[i].Domain] = i
+		}
+	}
+	// a name is matched to a company with a domain before one only known by name, so adding a name
+	// to a company with a domain merges the company only known by that name into it
+	for _, withDomain := range []bool{true, false} {
+		for i, company := range companies {
+			if (company.Domain != "") != withDomain {
+				continue
+			}
+			for _, alias := range company.Aliases {
+				if _, ok := byAlias[domains.CompanyName(alias)]; !ok {
+					byAlias[domains.CompanyName(alias)] = i
+				}
+			}
+		}
+	}
+
+	names := make(map[int][]string)
+	for _, job := range jobs {
+		domain := domains.Normalize(job.CompanyURL)
+		name := domains.CompanyName(job.CompanyName)
+		if domain == "" && name == "" {
+			continue
+		}
+
+		i, ok := byDomain[domain]
+		if domain == "" || !ok {
+			i, ok = byAlias[name]
+			// a company with another domain is another company with the same name
+			if ok && domain != "" && companies[i].Domain != "" {
+				ok = false
+			}
+		}
+		if !ok {
+			companies = append(companies, models.Company{Aliases: []string{}, JobApplicationIDs: []int{}})
+			i = len(companies) - 1
+		}
+		if domain != "" && companies[i].Domain == "" {
+			companies[i].Domain = domain
+			byDomain[domain] = i
+		}
+		if name != "" {
+			if _, ok := byAlias[name]; !ok {
+				byAlias[name] = i
+			}
+			companyName := strings.TrimSpace(job.CompanyName)
+			if !containsFold(companies[i].Aliases, companyName) {
+				companies[i].Aliases = append(companies[i].Aliases, companyName)
+			}
+			names[i] = append(names[i], companyName)
+		}
+		companies[i].JobApplicationIDs = append(companies[i].JobApplicationIDs, job.ID)
+	}
+
+	kept := []models.Company{}
+	for i, company := range companies {
+		if len(company.JobApplicationIDs) == 0 {
+			continue
+		}
+		company.Name = mostCommonName(names[i], company)
+		kept = append(kept, company)
+	}
+	return s.db.SaveCompanies(kept)
+}
+
+// Get returns the full history with a company
+func (s *CompaniesScenario) Get(companyID int) (CompanyView, error) {
+	companies, err := s.Sync()
+	if err != nil {
+		return CompanyView{}, err
+	}
+	var view CompanyView
+	found := false
+	for _, company := range companies {
+		if company.ID == companyID {
+			view.Company = company
+			found = true
+			break
+		}
+	}
+	if !found {
+		return view, fmt.Errorf("%w: %d", db.ErrCompanyNotFound, companyID)
+	}
+
+	jobs, err := s.db.GetJobApplicationsById(view.JobApplicationIDs)
+	if err != nil {
+		return view, err
+	}
+	steps, err := s.db.GetAllSteps()
+	if err != nil {
+		return view, err
+	}
+	redFlags, err := GetStoredRedFlags(s.db)
+	if err != nil {
+		return view, err
+	}
+	compensations, err := ResolveCompensations(s.db, jobs)
+	if err != nil {
+		return view, err
+	}
+	research, err := GetStoredCompanyResearch(s.db)
+	if err != nil {
+		return view, err
+	}
+
+	stepsByJob := make(map[int][]models.Step)
+	for _, step := range steps {
+		stepsByJob[step.JobApplicationID] = append(stepsByJob[step.JobApplicationID], step)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	view.StatusCounts = make(map[string]int)
+	view.Applications = []CompanyApplication{}
+	for _, job := range jobs {
+		application := CompanyApplication{
+			ID:            job.ID,
+			JobTitle:      job.JobTitle,
+			CompanyName:   job.CompanyName,
+			CompanyURL:    job.CompanyURL,
+			Status:        job.Status,
+			Source:        job.Source,
+			ResumeVersion: job.ResumeVersion,
+			CreatedAt:     job.CreatedAt,
+			UpdatedAt:     job.UpdatedAt,
+			Steps:         stepsByJob[job.ID],
+		}
+		if application.Steps == nil {
+			application.Steps = []models.Step{}
+		}
+		if result, ok := redFlags[job.ID]; ok {
+			application.RedFlags = &result
+		}
+		if compensation, ok := compensations[job.ID]; ok && compensation.Compensation.HasAmount() {
+			application.Salary = &compensation
+		}
+		view.Applications = append(view.Applications, application)
+		view.StatusCounts[job.Status]++
+
+		if view.FirstAppliedAt.IsZero() || job.CreatedAt.Before(view.FirstAppliedAt) {
+			view.FirstAppliedAt = job.CreatedAt
+		}
+		lastActivity := job.UpdatedAt
+		for _, step := range application.Steps {
+			if step.CreatedAt.After(lastActivity) {
+				lastActivity = step.CreatedAt
+			}
+		}
+		if lastActivity.After(view.LastActivityAt) {
+			view.LastActivityAt = lastActivity
+		}
+		// the research is shared by the company's job applications, the newest job's copy is the latest
+		if companyResearch, ok := research[job.ID]; ok {
+			view.Research = &companyResearch
+		}
+	}
+
+	return view, nil
+}
+
+// AddAlias adds a name variant to a company and assigns the job applications using it
+func (s *CompaniesScenario) AddAlias(companyID int, alias string) (CompanyView, error) {
+	alias = strings.TrimSpace(alias)
+	if domains.CompanyName(alias) == "" {
+		return CompanyView{}, fmt.Errorf("%w: the alias has no letters or digits", ErrInvalidCompanyAlias)
+	}
+	if err := s.db.AddCompanyAlias(companyID, alias); err != nil {
+		return CompanyView{}, err
+	}
+	return s.Get(companyID)
+}
+
+// mostCommonName is the spelling of the company name used by most of its job applications, or its
+// current name when it has none
+func mostCommonName(names []string, company models.Company) string {
+	counts := make(map[string]int)
+	best := company.Name
+	for _, name := range names {
+		counts[name]++
+		if best == "" || counts[name] > counts[best] {
+			best = name
+		}
+	}
+	if best == "" {
+		return company.Domain
+	}
+	return best
+}
+
+func containsFold(values []string, value string) bool {
+	for _, existing := range values {
+		if strings.EqualFold(existing, value) {
+			return true
+		}
+	}
+	return false
+}
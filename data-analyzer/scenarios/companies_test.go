@@ -0,0 +1,30 @@
+package scenarios
+
+import (
+	"testing"
+
+	"data-analyzer/models"
+)
+
+func TestMostCommonName(t *testing.T) {
+	company := models.Company{Name: "Acme", Domain: "acme.io"}
+
+	tests := []struct {
+		names   []string
+		company models.Company
+		want    string
+	}{
+		{[]string{"ACME Inc.", "Acme Inc", "ACME Inc."}, company, "ACME Inc."},
+		// on a tie the spelling seen first wins
+		{[]string{"Acme", "ACME"}, company, "Acme"},
+		{[]string{"ACME", "Acme"}, company, "ACME"},
+		{nil, company, "Acme"},
+		{nil, models.Company{Domain: "globex.com"}, "globex.com"},
+		{[]string{"Globex"}, models.Company{Domain: "globex.com"}, "Globex"},
+	}
+	for _, tt := range tests {
+		if got := mostCommonName(tt.names, tt.company); got != tt.want {
+			t.Errorf("mostCommonName(%q, %q) = %q, want %q", tt.names, tt.company.Name, got, tt.want)
+		}
+	}
+}
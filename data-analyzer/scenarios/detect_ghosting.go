@@ -0,0 +1,198 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+)
+
+// analyzerStepTitles are the steps the analyzer adds on its own. They say nothing about the
+// company's activity, so they are ignored when looking for the latest step.
+var analyzerStepTitles = []string{
+	"Imported", "Imported Job Posting", "Possible Duplicate", "Extract Tech Stack", "Extract Salary",
+	"Extract Role Details", "Extract Job Metadata", "Research Company", "Skills Gap Analysis", "Generate Cover Letter",
+	"Prepare Interview", mockInterviewStepTitle,
+}
+
+// ghostedStepTitle is the title of the step added when a job application is moved to Ghosted
+const ghostedStepTitle = "Marked as Ghosted"
+
+// GhostingSuggestion is a job application that has been waiting for a reply for too long
+type GhostingSuggestion struct {
+	JobApplicationID int       `json:"job_application_id"`
+	JobTitle         string    `json:"job_title"`
+	CompanyName      string    `json:"company_name"`
+	Status           string    `json:"status"`
+	LastActivity     time.Time `json:"last_activity"`
+	// LastStep is the title of the latest step, empty when the job application has no steps
+	LastStep      string `json:"last_step"`
+	DaysInactive  int    `json:"days_inactive"`
+	ThresholdDays int    `json:"threshold_days"`
+	Reason        string `json:"reason"`
+}
+
+// GhostingReport lists the job applications that look ghosted and those that need a follow-up
+type GhostingReport struct {
+	CheckedAt time.Time            `json:"checked_at"`
+	Ghosted   []GhostingSuggestion `json:"ghosted"`
+	FollowUps []GhostingSuggestion `json:"follow_ups"`
+	// Moved are the IDs of the job applications that were moved to Ghosted
+	Moved []int `json:"moved"`
+}
+
+type DetectGhostingScenario struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewDetectGhostingScenario(cfg *config.Config, db *db.DB) *DetectGhostingScenario {
+	return &DetectGhostingScenario{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// Execute finds the job applications whose latest step, or their creation when they have no steps,
+// is older than the threshold of their status. Steps added by the analyzer's workflows do not count,
+// and only the statuses with a threshold are checked. Job applications past the follow-up threshold
+// but not the ghosting one are listed as follow-ups. Every ghosted job application is suggested to the
+// webhooks once per period of inactivity.
+func (s *DetectGhostingScenario) Execute(now time.Time) (GhostingReport, error) {
+	report := GhostingReport{
+		CheckedAt: now,
+		Ghosted:   []GhostingSuggestion{},
+		FollowUps: []GhostingSuggestion{},
+		Moved:     []int{},
+	}
+
+	jobs, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return report, err
+	}
+	latestSteps, err := s.db.GetLatestSteps(analyzerStepTitles)
+	if err != nil {
+		return report, err
+	}
+
+	for _, job := range jobs {
+		threshold, tracked := s.cfg.Ghosting.Thresholds[job.Status]
+		followUpThreshold, hasFollowUp := s.cfg.Ghosting.FollowUpThresholds[job.Status]
+		if !tracked && !hasFollowUp {
+			continue
+		}
+
+		suggestion := GhostingSuggestion{
+			JobApplicationID: job.ID,
+			JobTitle:         job.JobTitle,
+			CompanyName:      job.CompanyName,
+			Status:           job.Status,
+		}
+		suggestion.LastActivity, suggestion.LastStep = lastActivity(job, latestSteps)
+		suggestion.DaysInactive = int(now.Sub(suggestion.LastActivity).Hours() / 24)
+
+		since := "the job application was created"
+		if suggestion.LastStep != "" {
+			since = fmt.Sprintf("the step %q", suggestion.LastStep)
+		}
+
+		switch {
+		case tracked && suggestion.DaysInactive >= threshold:
+			suggestion.ThresholdDays = threshold
+			suggestion.Reason = fmt.Sprintf("No activity for %d days in %s since %s on %s, the ghosting threshold is %d days",
+				suggestion.DaysInactive, job.Status, since, suggestion.LastActivity.Format("2006-01-02"), threshold)
+			report.Ghosted = append(report.Ghosted, suggestion)
+		case hasFollowUp && suggestion.DaysInactive >= followUpThreshold:
+			suggestion.ThresholdDays = followUpThreshold
+			suggestion.Reason = fmt.Sprintf("No activity for %d days in %s since %s on %s, follow up after %d days",
+				suggestion.DaysInactive, job.Status, since, suggestion.LastActivity.Format("2006-01-02"), followUpThreshold)
+			report.FollowUps = append(report.FollowUps, suggestion)
+		}
+	}
+
+	// the same inactivity is only suggested once, a new step starts a new one
+	for _, suggestion := range report.Ghosted {
+		recordStatusSuggestion(s.db, fmt.Sprintf("ghosting:%d:%d", suggestion.JobApplicationID, suggestion.LastActivity.Unix()), WebhookStatusSuggested{
+			JobApplication: WebhookJobApplication{
+				ID:          suggestion.JobApplicationID,
+				JobTitle:    suggestion.JobTitle,
+				CompanyName: suggestion.CompanyName,
+				Status:      suggestion.Status,
+			},
+			SuggestedStatus: models.StatusGhosted,
+			Reason:          suggestion.Reason,
+			Source:          "ghosting",
+		})
+	}
+
+	// the longest waiting job applications come first
+	for _, list := range [][]GhostingSuggestion{report.Ghosted, report.FollowUps} {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].DaysInactive > list[j].DaysInactive
+		})
+	}
+
+	return report, nil
+}
+
+// lastActivity is the time of the latest step of the job application and its title, or its creation
+// when it has no steps
+func lastActivity(job models.JobApplication, latestSteps map[int]models.Step) (time.Time, string) {
+	if step, ok := latestSteps[job.ID]; ok && step.CreatedAt.After(job.CreatedAt) {
+		return step.CreatedAt, step.Title
+	}
+	return job.CreatedAt, ""
+}
+
+// MoveToGhosted moves the ghosted job applications of the report to the Ghosted status and adds a step
+// with the reason to each of them. When IDs are given only those job applications are moved.
+func (s *DetectGhostingScenario) MoveToGhosted(report GhostingReport, jobApplicationIDs []int) (GhostingReport, error) {
+	for _, suggestion := range report.Ghosted {
+		if len(jobApplicationIDs) > 0 && !slices.Contains(jobApplicationIDs, suggestion.JobApplicationID) {
+			continue
+		}
+		err := s.db.ChangeJobApplicationStatus(suggestion.JobApplicationID, models.StatusGhosted, models.StepInput{
+			Title:       ghostedStepTitle,
+			Description: suggestion.Reason,
+		})
+		if err != nil {
+			return report, err
+		}
+		report.Moved = append(report.Moved, suggestion.JobApplicationID)
+		fmt.Printf("👻 Moved job application %d (%s at %s) to Ghosted\n", suggestion.JobApplicationID, suggestion.JobTitle, suggestion.CompanyName)
+	}
+	return report, nil
+}
+
+// RunPeriodically checks for ghosting every GHOSTING_INTERVAL_HOURS until the context is done, and
+// with GHOSTING_AUTO_MOVE moves the ghosted job applications
+func (s *DetectGhostingScenario) RunPeriodically(ctx context.Context) {
+	interval := time.Duration(s.cfg.Ghosting.IntervalHours) * time.Hour
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := s.Execute(time.Now())
+		if err != nil {
+			log.Printf("Failed to detect ghosting: %v", err)
+		} else {
+			if s.cfg.Ghosting.AutoMove {
+				if report, err = s.MoveToGhosted(report, nil); err != nil {
+					log.Printf("Failed to move job applications to Ghosted: %v", err)
+				}
+			}
+			fmt.Printf("👻 Ghosting check: %d ghosted, %d follow-ups due, %d moved\n", len(report.Ghosted), len(report.FollowUps), len(report.Moved))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package scenarios
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"data-analyzer/models"
+)
+
+// ghostingTestStep is a step added some days before the check
+type ghostingTestStep struct {
+	title   string
+	daysAgo float64
+}
+
+// insertGhostingTestJob inserts a job application created some days before now, with its steps
+func insertGhostingTestJob(t *testing.T, path string, now time.Time, status string, daysAgo float64, steps []ghostingTestStep) int {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	daysBefore := func(days float64) string {
+		return now.Add(-time.Duration(days * 24 * float64(time.Hour))).UTC().Format("2006-01-02 15:04:05")
+	}
+	createdAt := daysBefore(daysAgo)
+	result, err := conn.Exec(`
+		INSERT INTO jobs_jobapplication (job_title, job_description, company_name, company_url, salary,
+			resume_version, status, source, cover_letter, created_at, updated_at)
+		VALUES ('Backend Engineer', '', 'Acme', '', '', '', ?, 'LinkedIn', '', ?, ?)
+	`, status, createdAt, createdAt)
+	if err != nil {
+		t.Fatalf("failed to insert job application: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range steps {
+		_, err := conn.Exec(`
+			INSERT INTO jobs_step (title, description, created_at, updated_at, job_application_id)
+			VALUES (?, '', ?, ?, ?)
+		`, step.title, daysBefore(step.daysAgo), daysBefore(step.daysAgo), id)
+		if err != nil {
+			t.Fatalf("failed to insert step: %v", err)
+		}
+	}
+	return int(id)
+}
+
+func TestDetectGhostingExecute(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		status       string
+		daysAgo      float64
+		steps        []ghostingTestStep
+		wantGhosted  bool
+		wantFollowUp bool
+		wantLastStep string
+		wantInactive int
+	}{
+		{
+			name:    "recent application",
+			status:  models.StatusApplied,
+			daysAgo: 3,
+		},
+		{
+			name:         "exactly at the ghosting threshold",
+			status:       models.StatusApplied,
+			daysAgo:      14,
+			wantGhosted:  true,
+			wantInactive: 14,
+		},
+		{
+			name:         "a minute before the ghosting threshold",
+			status:       models.StatusApplied,
+			daysAgo:      14 - 1.0/24/60,
+			wantFollowUp: true,
+			wantInactive: 13,
+		},
+		{
+			name:         "exactly at the follow-up threshold",
+			status:       models.StatusApplied,
+			daysAgo:      7,
+			wantFollowUp: true,
+			wantInactive: 7,
+		},
+		{
+			name:    "a minute before the follow-up threshold",
+			status:  models.StatusApplied,
+			daysAgo: 7 - 1.0/24/60,
+		},
+		{
+			name:         "the latest step counts as activity",
+			status:       models.StatusApplied,
+			daysAgo:      30,
+			steps:        []ghostingTestStep{{"Applied", 29}, {"Recruiter Call", 8}},
+			wantFollowUp: true,
+			wantLastStep: "Recruiter Call",
+			wantInactive: 8,
+		},
+		{
+			name:         "steps added by the analyzer are ignored",
+			status:       models.StatusApplied,
+			daysAgo:      30,
+			steps:        []ghostingTestStep{{"Recruiter Call", 20}, {"Extract Tech Stack", 1}, {"Research Company", 0}},
+			wantGhosted:  true,
+			wantLastStep: "Recruiter Call",
+			wantInactive: 20,
+		},
+		{
+			name:         "only analyzer steps fall back to the creation",
+			status:       models.StatusApplied,
+			daysAgo:      15,
+			steps:        []ghostingTestStep{{"Imported", 15}, {"Skills Gap Analysis", 2}},
+			wantGhosted:  true,
+			wantInactive: 15,
+		},
+		{
+			name:    "already ghosted",
+			status:  models.StatusGhosted,
+			daysAgo: 90,
+		},
+		{
+			name:    "status without a threshold",
+			status:  models.StatusOffer,
+			daysAgo: 90,
+		},
+	}
+	for _, tt := range tests {
+		database, path := newTestDB(t)
+		cfg := newTestConfig(t)
+		cfg.Ghosting.Thresholds = map[string]int{models.StatusApplied: 14}
+		cfg.Ghosting.FollowUpThresholds = map[string]int{models.StatusApplied: 7}
+		id := insertGhostingTestJob(t, path, now, tt.status, tt.daysAgo, tt.steps)
+
+		report, err := NewDetectGhostingScenario(cfg, database).Execute(now)
+		if err != nil {
+			t.Fatalf("%s: Execute() error = %v", tt.name, err)
+		}
+		if got := len(report.Ghosted) == 1; got != tt.wantGhosted {
+			t.Errorf("%s: ghosted = %+v, want ghosted %v", tt.name, report.Ghosted, tt.wantGhosted)
+		}
+		if got := len(report.FollowUps) == 1; got != tt.wantFollowUp {
+			t.Errorf("%s: follow-ups = %+v, want follow-up %v", tt.name, report.FollowUps, tt.wantFollowUp)
+		}
+
+		for _, suggestion := range append(report.Ghosted, report.FollowUps...) {
+			if suggestion.JobApplicationID != id || suggestion.LastStep != tt.wantLastStep || suggestion.DaysInactive != tt.wantInactive {
+				t.Errorf("%s: suggestion = {id %d, last step %q, %d days}, want {id %d, last step %q, %d days}", tt.name,
+					suggestion.JobApplicationID, suggestion.LastStep, suggestion.DaysInactive, id, tt.wantLastStep, tt.wantInactive)
+			}
+		}
+	}
+}
+
+func TestDetectGhostingMoveToGhosted(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	database, path := newTestDB(t)
+	cfg := newTestConfig(t)
+	cfg.Ghosting.Thresholds = map[string]int{models.StatusApplied: 14}
+	cfg.Ghosting.FollowUpThresholds = map[string]int{}
+
+	first := insertGhostingTestJob(t, path, now, models.StatusApplied, 20, nil)
+	second := insertGhostingTestJob(t, path, now, models.StatusApplied, 30, nil)
+	insertGhostingTestJob(t, path, now, models.StatusApplied, 2, nil)
+
+	scenario := NewDetectGhostingScenario(cfg, database)
+	report, err := scenario.Execute(now)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(report.Ghosted) != 2 {
+		t.Fatalf("Execute() ghosted %d job applications, want 2", len(report.Ghosted))
+	}
+
+	// only the requested job application is moved
+	report, err = scenario.MoveToGhosted(report, []int{first})
+	if err != nil {
+		t.Fatalf("MoveToGhosted() error = %v", err)
+	}
+	if len(report.Moved) != 1 || report.Moved[0] != first {
+		t.Errorf("MoveToGhosted() moved %v, want [%d]", report.Moved, first)
+	}
+
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var status string
+	if err := conn.QueryRow(`SELECT status FROM jobs_jobapplication WHERE id = ?`, first).Scan(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status != models.StatusGhosted {
+		t.Errorf("status of job application %d = %q, want %q", first, status, models.StatusGhosted)
+	}
+	var steps int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM jobs_step WHERE job_application_id = ? AND title = 'Marked as Ghosted'`, first).Scan(&steps); err != nil {
+		t.Fatal(err)
+	}
+	if steps != 1 {
+		t.Errorf("job application %d has %d ghosted steps, want 1", first, steps)
+	}
+
+	// a ghosted job application is not suggested or moved again
+	report, err = scenario.Execute(now.Add(24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(report.Ghosted) != 1 || report.Ghosted[0].JobApplicationID != second {
+		t.Errorf("Execute() after moving ghosted %+v, want only job application %d", report.Ghosted, second)
+	}
+	report, err = scenario.MoveToGhosted(report, nil)
+	if err != nil {
+		t.Fatalf("MoveToGhosted() error = %v", err)
+	}
+	if len(report.Moved) != 1 || report.Moved[0] != second {
+		t.Errorf("MoveToGhosted() moved %v, want [%d]", report.Moved, second)
+	}
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM jobs_step WHERE title = 'Marked as Ghosted'`).Scan(&steps); err != nil {
+		t.Fatal(err)
+	}
+	if steps != 2 {
+		t.Errorf("%d ghosted steps, want 2", steps)
+	}
+}
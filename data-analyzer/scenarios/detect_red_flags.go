@@ -0,0 +1,36 @@
+package scenarios
+
+import (
+	"data-analyzer/agent/workflows"
+	"data-analyzer/db"
+	"encoding/json"
+	"log"
+)
+
+// GetStoredRedFlags returns the most recent successful red flags result of every job application
+func GetStoredRedFlags(db *db.DB) (map[int]workflows.RedFlagsResult, error) {
+	storedWorkflows, err := db.GetWorkflowsByName("red_flags_detection")
+	if err != nil {
+		return nil, err
+	}
+
+	// workflows are ordered newest first, so the first result seen for a job wins
+	redFlags := make(map[int]workflows.RedFlagsResult)
+	for _, workflow := range storedWorkflows {
+		var result workflows.RedFlagsDetectionResult
+		if err := json.Unmarshal([]byte(workflow.Output), &result); err != nil {
+			log.Printf("Failed to unmarshal red flags workflow %d: %v", workflow.ID, err)
+			continue
+		}
+		for _, jobResult := range result.Results {
+			if jobResult.ErrorMessage != "" {
+				continue
+			}
+			if _, ok := redFlags[jobResult.JobID]; !ok {
+				redFlags[jobResult.JobID] = jobResult
+			}
+		}
+	}
+
+	return redFlags, nil
+}
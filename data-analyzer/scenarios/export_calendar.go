@@ -0,0 +1,151 @@
+package scenarios
+
+import (
+	"data-analyzer/calendar"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// uidDomain makes the event UIDs globally unique as RFC 5545 asks
+const uidDomain = "data-analyzer"
+
+var (
+	// interviewRe finds the steps that are about an interview or a call with the company
+	interviewRe = regexp.MustCompile(`(?i)\b(interview|screening|screen|call|onsite|on-site|meeting|panel)\b`)
+	// emailHeaderRe matches the header lines of emails pasted into, or recorded as, a step. Their dates
+	// are when the email was sent, not when the interview is.
+	emailHeaderRe = regexp.MustCompile(`(?im)^(from|to|subject|received|sent|date):.*$`)
+)
+
+type ExportCalendarScenario struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewExportCalendarScenario(cfg *config.Config, db *db.DB) *ExportCalendarScenario {
+	return &ExportCalendarScenario{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// Execute builds the calendar events of the job search:
+//   - an interview event for every step about an interview whose title or description has a date,
+//     lasting CALENDAR_INTERVIEW_MINUTES or the whole day when no time is given
+//   - an all-day follow-up reminder for every job application in a status with a follow-up
+//     threshold, on the day the follow-up is due or today when it is overdue. Ghosted job
+//     applications, past the ghosting threshold, get no reminder.
+//
+// Interview UIDs are derived from the step and reminder UIDs from the job application, so
+// subscribed calendars move an event when its date changes instead of adding another one.
+func (s *ExportCalendarScenario) Execute(now time.Time) ([]calendar.Event, error) {
+	jobs, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return nil, err
+	}
+	steps, err := s.db.GetAllSteps()
+	if err != nil {
+		return nil, err
+	}
+	latestSteps, err := s.db.GetLatestSteps(analyzerStepTitles)
+	if err != nil {
+		return nil, err
+	}
+
+	jobsByID := make(map[int]models.JobApplication, len(jobs))
+	for _, job := range jobs {
+		jobsByID[job.ID] = job
+	}
+
+	events := []calendar.Event{}
+	for _, step := range steps {
+		job, ok := jobsByID[step.JobApplicationID]
+		// the ghosting step quotes the date of the step it follows, which may be an interview
+		if !ok || slices.Contains(analyzerStepTitles, step.Title) || step.Title == ghostedStepTitle {
+			continue
+		}
+		if event, ok := s.interviewEvent(step, job); ok {
+			events = append(events, event)
+		}
+	}
+
+	localNow := now.In(s.cfg.Calendar.Location)
+	today := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, s.cfg.Calendar.Location)
+	for _, job := range jobs {
+		followUpDays, ok := s.cfg.Ghosting.FollowUpThresholds[job.Status]
+		if !ok {
+			continue
+		}
+		activity, lastStep := lastActivity(job, latestSteps)
+		inactive := int(now.Sub(activity).Hours() / 24)
+		if threshold, tracked := s.cfg.Ghosting.Thresholds[job.Status]; tracked && inactive >= threshold {
+			continue
+		}
+
+		local := activity.In(s.cfg.Calendar.Location)
+		due := time.Date(local.Year(), local.Month(), local.Day()+followUpDays, 0, 0, 0, 0, s.cfg.Calendar.Location)
+		since := "the job application was created"
+		if lastStep != "" {
+			since = fmt.Sprintf("the step %q", lastStep)
+		}
+		description := fmt.Sprintf("Follow up after %d days in %s without news since %s on %s.", followUpDays, job.Status, since, local.Format("2006-01-02"))
+		if due.Before(today) {
+			description += fmt.Sprintf(" Due since %s.", due.Format("2006-01-02"))
+			due = today
+		}
+
+		events = append(events, calendar.Event{
+			UID:         fmt.Sprintf("follow-up-%d@%s", job.ID, uidDomain),
+			Start:       due,
+			AllDay:      true,
+			Summary:     fmt.Sprintf("Follow up: %s at %s", job.JobTitle, job.CompanyName),
+			Description: description,
+			Updated:     activity,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+	return events, nil
+}
+
+// interviewEvent reads the interview date from a step, looking at the title first
+func (s *ExportCalendarScenario) interviewEvent(step models.Step, job models.JobApplication) (calendar.Event, bool) {
+	description := emailHeaderRe.ReplaceAllString(step.Description, "")
+	if !interviewRe.MatchString(step.Title + "\n" + description) {
+		return calendar.Event{}, false
+	}
+
+	// dates are resolved relative to when the step was written
+	start, hasTime, ok := calendar.FindDateTime(step.Title, step.CreatedAt, s.cfg.Calendar.Location)
+	if !ok {
+		start, hasTime, ok = calendar.FindDateTime(description, step.CreatedAt, s.cfg.Calendar.Location)
+	}
+	if !ok {
+		return calendar.Event{}, false
+	}
+
+	details := strings.TrimSpace(step.Description)
+	if details != "" {
+		details += "\n\n"
+	}
+	details += fmt.Sprintf("Job application #%d: %s at %s (%s)", job.ID, job.JobTitle, job.CompanyName, job.Status)
+
+	return calendar.Event{
+		UID:         fmt.Sprintf("step-%d@%s", step.ID, uidDomain),
+		Start:       start,
+		End:         start.Add(time.Duration(s.cfg.Calendar.InterviewMinutes) * time.Minute),
+		AllDay:      !hasTime,
+		Summary:     fmt.Sprintf("%s: %s at %s", step.Title, job.JobTitle, job.CompanyName),
+		Description: details,
+		Updated:     step.UpdatedAt,
+	}, true
+}
@@ -0,0 +1,105 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+type ExtractJobMetadataScenario struct {
+	geminiClient    *agent.Client
+	db              *db.DB
+	jobApplications []models.JobApplication
+}
+
+func NewExtractJobMetadataScenario(geminiClient *agent.Client, db *db.DB, jobApplications []models.JobApplication) *ExtractJobMetadataScenario {
+	return &ExtractJobMetadataScenario{
+		geminiClient:    geminiClient,
+		db:              db,
+		jobApplications: jobApplications,
+	}
+}
+
+func (s *ExtractJobMetadataScenario) Execute(ctx context.Context) ([]workflows.JobMetadataEntry, error) {
+	extractJobMetadataWorkflow := workflows.NewExtractJobMetadataWorkflow(s.geminiClient, s.jobApplications)
+
+	jobIDs := make([]int, len(s.jobApplications))
+	for i, job := range s.jobApplications {
+		jobIDs[i] = job.ID
+	}
+
+	result, err := extractJobMetadataWorkflow.Execute(ctx)
+	if err != nil {
+		workflows.RecordWorkflowFailure(s.db, "extract_job_metadata", jobIDs, err)
+		return nil, fmt.Errorf("failed to execute extract job metadata workflow: %w", err)
+	}
+
+	parametersJSON, err := json.Marshal(map[string]interface{}{
+		"job_ids": jobIDs,
+		"fields":  []string{"job_title", "job_description"},
+	})
+	if err != nil {
+		log.Printf("Failed to marshal parameters: %v", err)
+	}
+
+	// store the result in database
+	workflowRecord := models.Workflow{
+		WorkflowName: "extract_job_metadata",
+		Prompt:       result.Prompt,
+		AgentModel:   s.geminiClient.ModelName,
+		Output:       result.Result,
+		Parameters:   string(parametersJSON),
+	}
+
+	workflowID, err := s.db.InsertWorkflow(workflowRecord)
+	if err != nil {
+		log.Printf("Failed to store workflow: %v", err)
+	} else {
+		fmt.Printf("📝 Workflow stored with ID: %d\n", workflowID)
+	}
+	err = s.db.InsertJobApplicationsWorkflow(jobIDs, workflowID)
+	if err != nil {
+		log.Printf("Failed to store job application workflow: %v", err)
+	}
+	for _, jobID := range jobIDs {
+		err = s.db.AddStepToJobApplication(jobID, models.StepInput{
+			Title:       "Extract Job Metadata",
+			Description: fmt.Sprintf("Extracted seniority, location, remote policy and visa information via workflow %d", workflowID),
+		})
+		if err != nil {
+			log.Printf("Failed to store job application step: %v", err)
+		}
+	}
+
+	return result.Metadata, nil
+}
+
+// GetStoredJobMetadata returns the most recent stored metadata of every job application
+func GetStoredJobMetadata(db *db.DB) (map[int]workflows.JobMetadata, error) {
+	storedWorkflows, err := db.GetWorkflowsByName("extract_job_metadata")
+	if err != nil {
+		return nil, err
+	}
+
+	// workflows are ordered newest first, so the first metadata seen for a job wins
+	metadata := make(map[int]workflows.JobMetadata)
+	for _, workflow := range storedWorkflows {
+		var entries []workflows.JobMetadataEntry
+		if err := json.Unmarshal([]byte(workflow.Output), &entries); err != nil {
+			log.Printf("Failed to unmarshal job metadata workflow %d: %v", workflow.ID, err)
+			continue
+		}
+		for _, entry := range entries {
+			if _, ok := metadata[entry.JobId]; !ok {
+				metadata[entry.JobId] = entry.Metadata
+			}
+		}
+	}
+
+	return metadata, nil
+}
@@ -31,6 +31,7 @@ func (s *ExtractRoleDetailsScenario) Execute(ctx context.Context) ([]workflows.R
 
 	if result, err := extractJobResponsibilitiesWorkflow.Execute(ctx); err != nil {
 		log.Printf("Failed to execute extract job responsibilities workflow: %v", err)
+		workflows.RecordWorkflowFailure(s.db, "extract_role_details", jobApplicationIDs(s.jobApplications), err)
 	} else {
 		fmt.Println("\nExtracted Job Responsibilities:")
 		fmt.Println(strings.Repeat("-", 40))
@@ -88,3 +89,28 @@ func (s *ExtractRoleDetailsScenario) Execute(ctx context.Context) ([]workflows.R
 
 	return nil, nil
 }
+
+// GetStoredRoleDetails returns the most recent stored role details of every job application
+func GetStoredRoleDetails(db *db.DB) (map[int]workflows.RoleDetails, error) {
+	storedWorkflows, err := db.GetWorkflowsByName("extract_role_details")
+	if err != nil {
+		return nil, err
+	}
+
+	// workflows are ordered newest first, so the first role details seen for a job win
+	roleDetails := make(map[int]workflows.RoleDetails)
+	for _, workflow := range storedWorkflows {
+		var jobRoleDetails []workflows.RoleDetails
+		if err := json.Unmarshal([]byte(workflow.Output), &jobRoleDetails); err != nil {
+			log.Printf("Failed to unmarshal role details workflow %d: %v", workflow.ID, err)
+			continue
+		}
+		for _, roleDetail := range jobRoleDetails {
+			if _, ok := roleDetails[roleDetail.JobId]; !ok {
+				roleDetails[roleDetail.JobId] = roleDetail
+			}
+		}
+	}
+
+	return roleDetails, nil
+}
@@ -0,0 +1,125 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+type ExtractSalaryScenario struct {
+	geminiClient    *agent.Client
+	db              *db.DB
+	jobApplications []models.JobApplication
+}
+
+func NewExtractSalaryScenario(geminiClient *agent.Client, db *db.DB, jobApplications []models.JobApplication) *ExtractSalaryScenario {
+	return &ExtractSalaryScenario{
+		geminiClient:    geminiClient,
+		db:              db,
+		jobApplications: jobApplications,
+	}
+}
+
+func (s *ExtractSalaryScenario) Execute(ctx context.Context) ([]workflows.JobCompensation, error) {
+	extractSalaryWorkflow := workflows.NewExtractSalaryWorkflow(s.geminiClient, s.jobApplications)
+
+	jobIDs := make([]int, len(s.jobApplications))
+	for i, job := range s.jobApplications {
+		jobIDs[i] = job.ID
+	}
+
+	result, err := extractSalaryWorkflow.Execute(ctx)
+	if err != nil {
+		workflows.RecordWorkflowFailure(s.db, "extract_salary", jobIDs, err)
+		return nil, fmt.Errorf("failed to execute extract salary workflow: %w", err)
+	}
+
+	parametersJSON, err := json.Marshal(map[string]interface{}{
+		"job_ids": jobIDs,
+		"fields":  []string{"salary", "job_description"},
+	})
+	if err != nil {
+		log.Printf("Failed to marshal parameters: %v", err)
+	}
+
+	// store the result in database
+	workflowRecord := models.Workflow{
+		WorkflowName: "extract_salary",
+		Prompt:       result.Prompt,
+		AgentModel:   s.geminiClient.ModelName,
+		Output:       result.Result,
+		Parameters:   string(parametersJSON),
+	}
+
+	workflowID, err := s.db.InsertWorkflow(workflowRecord)
+	if err != nil {
+		log.Printf("Failed to store workflow: %v", err)
+	} else {
+		fmt.Printf("📝 Workflow stored with ID: %d\n", workflowID)
+	}
+	err = s.db.InsertJobApplicationsWorkflow(jobIDs, workflowID)
+	if err != nil {
+		log.Printf("Failed to store job application workflow: %v", err)
+	}
+	for _, jobID := range jobIDs {
+		err = s.db.AddStepToJobApplication(jobID, models.StepInput{
+			Title:       "Extract Salary",
+			Description: fmt.Sprintf("Extracted salary successfully via workflow %d", workflowID),
+		})
+		if err != nil {
+			log.Printf("Failed to store job application step: %v", err)
+		}
+	}
+
+	return result.Compensations, nil
+}
+
+// GetStoredCompensations returns the most recent stored compensation of every job application
+func GetStoredCompensations(db *db.DB) (map[int]workflows.JobCompensation, error) {
+	storedWorkflows, err := db.GetWorkflowsByName("extract_salary")
+	if err != nil {
+		return nil, err
+	}
+
+	// workflows are ordered newest first, so the first compensation seen for a job wins
+	compensations := make(map[int]workflows.JobCompensation)
+	for _, workflow := range storedWorkflows {
+		var jobCompensations []workflows.JobCompensation
+		if err := json.Unmarshal([]byte(workflow.Output), &jobCompensations); err != nil {
+			log.Printf("Failed to unmarshal salary workflow %d: %v", workflow.ID, err)
+			continue
+		}
+		for _, jobCompensation := range jobCompensations {
+			if _, ok := compensations[jobCompensation.JobId]; !ok {
+				compensations[jobCompensation.JobId] = jobCompensation
+			}
+		}
+	}
+
+	return compensations, nil
+}
+
+// ResolveCompensations returns the compensation of every given job application, using the stored
+// extract_salary result when there is one and the deterministic parser otherwise
+func ResolveCompensations(db *db.DB, jobApplications []models.JobApplication) (map[int]workflows.JobCompensation, error) {
+	compensations, err := GetStoredCompensations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[int]workflows.JobCompensation, len(jobApplications))
+	for _, jobApplication := range jobApplications {
+		if jobCompensation, ok := compensations[jobApplication.ID]; ok {
+			resolved[jobApplication.ID] = jobCompensation
+		} else {
+			resolved[jobApplication.ID] = workflows.DeterministicCompensation(jobApplication)
+		}
+	}
+
+	return resolved, nil
+}
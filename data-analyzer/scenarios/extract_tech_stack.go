@@ -0,0 +1,107 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+type ExtractTechStackScenario struct {
+	geminiClient    *agent.Client
+	db              *db.DB
+	taxonomy        *workflows.TechTaxonomy
+	jobApplications []models.JobApplication
+}
+
+func NewExtractTechStackScenario(geminiClient *agent.Client, db *db.DB, taxonomy *workflows.TechTaxonomy, jobApplications []models.JobApplication) *ExtractTechStackScenario {
+	return &ExtractTechStackScenario{
+		geminiClient:    geminiClient,
+		db:              db,
+		taxonomy:        taxonomy,
+		jobApplications: jobApplications,
+	}
+}
+
+func (s *ExtractTechStackScenario) Execute(ctx context.Context) ([]workflows.JobTechStack, error) {
+	extractTechStackWorkflow := workflows.NewExtractTechStackWorkflow(s.geminiClient, s.jobApplications, s.taxonomy)
+
+	jobIDs := make([]int, len(s.jobApplications))
+	for i, job := range s.jobApplications {
+		jobIDs[i] = job.ID
+	}
+
+	result, err := extractTechStackWorkflow.Execute(ctx)
+	if err != nil {
+		workflows.RecordWorkflowFailure(s.db, "extract_tech_stack", jobIDs, err)
+		return nil, fmt.Errorf("failed to execute extract tech stack workflow: %w", err)
+	}
+
+	parametersJSON, err := json.Marshal(map[string]interface{}{
+		"job_ids": jobIDs,
+		"fields":  []string{"job_description"},
+	})
+	if err != nil {
+		log.Printf("Failed to marshal parameters: %v", err)
+	}
+
+	// store the result in database
+	workflowRecord := models.Workflow{
+		WorkflowName: "extract_tech_stack",
+		Prompt:       result.Prompt,
+		AgentModel:   s.geminiClient.ModelName,
+		Output:       result.Result,
+		Parameters:   string(parametersJSON),
+	}
+
+	workflowID, err := s.db.InsertWorkflow(workflowRecord)
+	if err != nil {
+		log.Printf("Failed to store workflow: %v", err)
+	} else {
+		fmt.Printf("📝 Workflow stored with ID: %d\n", workflowID)
+	}
+	err = s.db.InsertJobApplicationsWorkflow(jobIDs, workflowID)
+	if err != nil {
+		log.Printf("Failed to store job application workflow: %v", err)
+	}
+	for _, jobID := range jobIDs {
+		err = s.db.AddStepToJobApplication(jobID, models.StepInput{
+			Title:       "Extract Tech Stack",
+			Description: fmt.Sprintf("Extracted tech stack successfully via workflow %d", workflowID),
+		})
+		if err != nil {
+			log.Printf("Failed to store job application step: %v", err)
+		}
+	}
+
+	return result.TechStacks, nil
+}
+
+// GetStoredTechStacks returns the most recent stored tech stack of every job application
+func GetStoredTechStacks(db *db.DB) (map[int]workflows.TechStack, error) {
+	storedWorkflows, err := db.GetWorkflowsByName("extract_tech_stack")
+	if err != nil {
+		return nil, err
+	}
+
+	// workflows are ordered newest first, so the first tech stack seen for a job wins
+	techStacks := make(map[int]workflows.TechStack)
+	for _, workflow := range storedWorkflows {
+		var jobTechStacks []workflows.JobTechStack
+		if err := json.Unmarshal([]byte(workflow.Output), &jobTechStacks); err != nil {
+			log.Printf("Failed to unmarshal tech stack workflow %d: %v", workflow.ID, err)
+			continue
+		}
+		for _, jobTechStack := range jobTechStacks {
+			if _, ok := techStacks[jobTechStack.JobId]; !ok {
+				techStacks[jobTechStack.JobId] = jobTechStack.TechStack
+			}
+		}
+	}
+
+	return techStacks, nil
+}
@@ -0,0 +1,160 @@
+package scenarios
+
+import (
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/dedup"
+	"data-analyzer/domains"
+	"data-analyzer/models"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// DuplicateJobApplication is a job application that is part of a duplicate cluster or match
+type DuplicateJobApplication struct {
+	JobApplicationID int       `json:"job_application_id"`
+	JobTitle         string    `json:"job_title"`
+	CompanyName      string    `json:"company_name"`
+	Domain           string    `json:"domain"`
+	Status           string    `json:"status"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// DuplicateCluster is a group of job applications whose descriptions are near-duplicates of each other
+type DuplicateCluster struct {
+	JobApplications []DuplicateJobApplication `json:"job_applications"`
+	Pairs           []dedup.Pair              `json:"pairs"`
+}
+
+// DuplicateMatch is an existing job application that closely matches a new one
+type DuplicateMatch struct {
+	DuplicateJobApplication
+	Similarity float64 `json:"similarity"`
+	SameDomain bool    `json:"same_domain"`
+}
+
+type FindDuplicatesScenario struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewFindDuplicatesScenario(cfg *config.Config, db *db.DB) *FindDuplicatesScenario {
+	return &FindDuplicatesScenario{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// DedupOptions builds the dedup options from the configuration
+func DedupOptions(cfg *config.Config) dedup.Options {
+	options := dedup.DefaultOptions()
+	options.Threshold = cfg.Dedup.Threshold
+	options.SameDomainThreshold = cfg.Dedup.SameDomainThreshold
+	options.ShingleSize = cfg.Dedup.ShingleSize
+	return options
+}
+
+// Execute finds every cluster of near-duplicate job applications, largest first
+func (s *FindDuplicatesScenario) Execute() ([]DuplicateCluster, error) {
+	index, byID, err := s.buildIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make([]DuplicateCluster, 0)
+	for _, cluster := range dedup.Clusters(index.Pairs()) {
+		duplicateCluster := DuplicateCluster{Pairs: cluster.Pairs}
+		for _, id := range cluster.IDs {
+			duplicateCluster.JobApplications = append(duplicateCluster.JobApplications, newDuplicateJobApplication(byID[id]))
+		}
+		clusters = append(clusters, duplicateCluster)
+	}
+
+	return clusters, nil
+}
+
+// Check returns the existing job applications that closely match the given one, most similar first.
+// The job application does not need to be stored yet; when it is, it is not matched against itself.
+// With DEDUP_WARN_ON_NEW enabled, a warning step is added to a stored job application that has matches.
+func (s *FindDuplicatesScenario) Check(jobApplication models.JobApplication) ([]DuplicateMatch, error) {
+	index, byID, err := s.buildIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]DuplicateMatch, 0)
+	for _, pair := range index.Match(dedupDocument(jobApplication)) {
+		matches = append(matches, DuplicateMatch{
+			DuplicateJobApplication: newDuplicateJobApplication(byID[pair.B]),
+			Similarity:              pair.Similarity,
+			SameDomain:              pair.SameDomain,
+		})
+	}
+
+	if s.cfg.Dedup.WarnOnNew && jobApplication.ID > 0 && len(matches) > 0 {
+		err := s.db.AddStepToJobApplication(jobApplication.ID, models.StepInput{
+			Title:       "Possible Duplicate",
+			Description: DuplicateWarning(matches),
+		})
+		if err != nil {
+			log.Printf("Failed to store job application step: %v", err)
+		}
+	}
+
+	return matches, nil
+}
+
+// DuplicateWarning describes the matches of a job application in a single sentence
+func DuplicateWarning(matches []DuplicateMatch) string {
+	if len(matches) == 0 {
+		return ""
+	}
+	descriptions := make([]string, len(matches))
+	for i, match := range matches {
+		descriptions[i] = fmt.Sprintf("#%d %s at %s (%.0f%% similar)", match.JobApplicationID, match.JobTitle, match.CompanyName, match.Similarity*100)
+	}
+	return "This job closely matches " + strings.Join(descriptions, ", ")
+}
+
+// buildIndex indexes the descriptions of all stored job applications
+func (s *FindDuplicatesScenario) buildIndex() (*dedup.Index, map[int]models.JobApplication, error) {
+	jobApplications, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[int]models.JobApplication, len(jobApplications))
+	for _, jobApplication := range jobApplications {
+		byID[jobApplication.ID] = jobApplication
+	}
+	return dedup.NewIndex(DedupOptions(s.cfg), dedupDocuments(jobApplications)), byID, nil
+}
+
+func dedupDocuments(jobApplications []models.JobApplication) []dedup.Document {
+	documents := make([]dedup.Document, len(jobApplications))
+	for i, jobApplication := range jobApplications {
+		documents[i] = dedupDocument(jobApplication)
+	}
+	return documents
+}
+
+func dedupDocument(jobApplication models.JobApplication) dedup.Document {
+	return dedup.Document{
+		ID:     jobApplication.ID,
+		Text:   jobApplication.JobDescription,
+		Domain: domains.Normalize(jobApplication.CompanyURL),
+	}
+}
+
+func newDuplicateJobApplication(jobApplication models.JobApplication) DuplicateJobApplication {
+	return DuplicateJobApplication{
+		JobApplicationID: jobApplication.ID,
+		JobTitle:         jobApplication.JobTitle,
+		CompanyName:      jobApplication.CompanyName,
+		Domain:           domains.Normalize(jobApplication.CompanyURL),
+		Status:           jobApplication.Status,
+		CreatedAt:        jobApplication.CreatedAt,
+	}
+}
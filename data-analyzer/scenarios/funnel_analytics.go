@@ -0,0 +1,48 @@
+package scenarios
+
+import (
+	"data-analyzer/analytics"
+	"data-analyzer/db"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidFunnelDimension is returned when breaking the funnel down by an unknown dimension
+var ErrInvalidFunnelDimension = errors.New("invalid funnel dimension")
+
+type FunnelAnalyticsScenario struct {
+	db *db.DB
+}
+
+func NewFunnelAnalyticsScenario(db *db.DB) *FunnelAnalyticsScenario {
+	return &FunnelAnalyticsScenario{
+		db: db,
+	}
+}
+
+// Execute computes the application funnel broken down by the dimensions, or by every dimension when
+// none is given. The analyzer's own steps and the ghosting step are not a response from the company.
+func (s *FunnelAnalyticsScenario) Execute(dimensions []string) (analytics.Report, error) {
+	if len(dimensions) == 0 {
+		dimensions = analytics.Dimensions
+	}
+	for _, dimension := range dimensions {
+		if !slices.Contains(analytics.Dimensions, dimension) {
+			return analytics.Report{}, fmt.Errorf("%w: %q, use %s", ErrInvalidFunnelDimension, dimension, strings.Join(analytics.Dimensions, ", "))
+		}
+	}
+
+	jobs, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return analytics.Report{}, err
+	}
+	steps, err := s.db.GetAllSteps()
+	if err != nil {
+		return analytics.Report{}, err
+	}
+
+	options := analytics.Options{IgnoredSteps: slices.Concat(analyzerStepTitles, []string{ghostedStepTitle})}
+	return analytics.Funnel(jobs, steps, dimensions, options), nil
+}
@@ -1,6 +1,7 @@
 package scenarios
 
 import (
+	"data-analyzer/agent"
 	"data-analyzer/config"
 	"data-analyzer/db"
 	"data-analyzer/models"
@@ -38,10 +39,7 @@ func (s *GetAllJobApplicationsScenario) PrintJobApplications(n int) {
 	}
 	for i, app := range s.JobApplications[:n] {
 		// select only first 5000 chars of job description for display
-		jobDescription := app.JobDescription
-		if len(jobDescription) > 5000 {
-			jobDescription = jobDescription[:5000] + "..."
-		}
+		jobDescription := agent.TruncateText(app.JobDescription, 5000)
 		fmt.Printf("%d. %s\n%s\n", i+1, app.JobTitle, jobDescription)
 	}
 	if len(s.JobApplications) > n {
@@ -0,0 +1,283 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/compensation"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/jobposting"
+	"data-analyzer/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// How the fields of an imported posting were extracted
+const (
+	ImportMethodJSONLD                = "json_ld"
+	ImportMethodJSONLDWithModel       = "json_ld+model"
+	ImportMethodJSONLDWithReadability = "json_ld+readability"
+	ImportMethodReadability           = "readability"
+	ImportMethodReadabilityWithModel  = "readability+model"
+)
+
+var (
+	// ErrInvalidImportOptions is returned for an unknown status or source
+	ErrInvalidImportOptions = errors.New("invalid import options")
+	// ErrIncompletePosting is returned when the title, company or description could not be extracted
+	ErrIncompletePosting = errors.New("incomplete job posting")
+)
+
+// ImportJobPostingOptions sets the fields of the new job application that the posting does not provide
+type ImportJobPostingOptions struct {
+	// Status defaults to "Preparing Application"
+	Status string
+	// Source defaults to LinkedIn for linkedin.com URLs and "Careers Website" otherwise
+	Source        string
+	ResumeVersion string
+	// DryRun extracts the posting without storing anything
+	DryRun bool
+}
+
+// ImportJobPostingResult is the extracted posting and, unless it was a dry run, the created job application
+type ImportJobPostingResult struct {
+	JobApplicationID int                `json:"job_application_id,omitempty"`
+	URL              string             `json:"url"`
+	Method           string             `json:"method"`
+	Status           string             `json:"status"`
+	Source           string             `json:"source"`
+	Posting          jobposting.Posting `json:"posting"`
+	Duplicates       []DuplicateMatch   `json:"duplicates"`
+	Warning          string             `json:"warning,omitempty"`
+}
+
+type ImportJobPostingScenario struct {
+	cfg          *config.Config
+	geminiClient *agent.Client
+	db           *db.DB
+	httpClient   *http.Client
+}
+
+func NewImportJobPostingScenario(cfg *config.Config, geminiClient *agent.Client, db *db.DB, httpClient *http.Client) *ImportJobPostingScenario {
+	return &ImportJobPostingScenario{
+		cfg:          cfg,
+		geminiClient: geminiClient,
+		db:           db,
+		httpClient:   httpClient,
+	}
+}
+
+// NewPostingHTTPClient creates the HTTP client used to fetch posting pages
+func NewPostingHTTPClient(cfg *config.Config) *http.Client {
+	return &http.Client{Timeout: time.Duration(cfg.ImportFetchTimeout) * time.Second}
+}
+
+// Execute fetches a posting page and creates a job application from it. The schema.org JobPosting
+// JSON-LD of the page is preferred. When it is missing or incomplete, the page's main content is
+// extracted and the model fills in the missing fields, or, without the agent, the page title,
+// site name and main content are used as they are.
+func (s *ImportJobPostingScenario) Execute(ctx context.Context, rawURL string, options ImportJobPostingOptions) (ImportJobPostingResult, error) {
+	if options.Status == "" {
+		options.Status = models.StatusPreparingApplication
+	}
+	if !slices.Contains(models.StatusChoices, options.Status) {
+		return ImportJobPostingResult{}, fmt.Errorf("%w: status must be one of %s", ErrInvalidImportOptions, strings.Join(models.StatusChoices, ", "))
+	}
+	if options.Source != "" && !slices.Contains(models.SourceChoices, options.Source) {
+		return ImportJobPostingResult{}, fmt.Errorf("%w: source must be one of %s", ErrInvalidImportOptions, strings.Join(models.SourceChoices, ", "))
+	}
+
+	page, err := jobposting.Fetch(ctx, s.httpClient, rawURL)
+	if err != nil {
+		return ImportJobPostingResult{}, err
+	}
+
+	posting, hasJSONLD := jobposting.ParseJSONLD(page)
+	method := ImportMethodJSONLD
+	var modelResult *workflows.JobPostingResult
+
+	if !posting.Complete() {
+		readable := jobposting.ExtractReadable(page)
+		if s.geminiClient.Enabled() {
+			result, err := workflows.NewExtractJobPostingWorkflow(s.geminiClient, readable).Execute(ctx)
+			if err != nil {
+				workflows.RecordWorkflowFailure(s.db, "extract_job_posting", nil, err)
+				return ImportJobPostingResult{}, fmt.Errorf("failed to execute extract job posting workflow: %w", err)
+			}
+			modelResult = &result
+			// fields from the JSON-LD are kept, the model only fills the gaps
+			posting = posting.Merge(result.Posting).Merge(readablePosting(readable, false))
+			method = ImportMethodReadabilityWithModel
+			if hasJSONLD {
+				method = ImportMethodJSONLDWithModel
+			}
+		} else {
+			posting = posting.Merge(readablePosting(readable, true))
+			method = ImportMethodReadability
+			if hasJSONLD {
+				method = ImportMethodJSONLDWithReadability
+			}
+		}
+	}
+
+	if !posting.Complete() {
+		err := fmt.Errorf("%w: could not find the %s in %s", ErrIncompletePosting, strings.Join(missingPostingFields(posting), ", "), page.URL)
+		if !s.geminiClient.Enabled() {
+			err = fmt.Errorf("%w, enable the agent (SHOULD_RUN_AGENT=true) to extract it with the model", err)
+		}
+		return ImportJobPostingResult{}, err
+	}
+
+	if posting.CompanyURL == "" {
+		posting.CompanyURL = postingCompanyURL(page.URL)
+	}
+	posting.JobTitle = jobposting.Clip(posting.JobTitle, models.MaxJobTitleLength)
+	posting.CompanyName = jobposting.Clip(posting.CompanyName, models.MaxCompanyNameLength)
+	posting.Salary = jobposting.Clip(posting.Salary, models.MaxSalaryLength)
+	if len(posting.CompanyURL) > models.MaxCompanyURLLength {
+		posting.CompanyURL = postingCompanyURL(posting.CompanyURL)
+	}
+
+	if options.Source == "" {
+		options.Source = postingSource(page.URL)
+	}
+
+	jobApplication := models.JobApplication{
+		JobTitle:       posting.JobTitle,
+		JobDescription: posting.JobDescription,
+		CompanyName:    posting.CompanyName,
+		CompanyURL:     posting.CompanyURL,
+		Salary:         posting.Salary,
+		ResumeVersion:  options.ResumeVersion,
+		Status:         options.Status,
+		Source:         options.Source,
+	}
+	result := ImportJobPostingResult{
+		URL:     page.URL,
+		Method:  method,
+		Status:  options.Status,
+		Source:  options.Source,
+		Posting: posting,
+	}
+
+	if !options.DryRun {
+		jobApplication.ID, err = s.db.InsertJobApplication(jobApplication)
+		if err != nil {
+			return ImportJobPostingResult{}, err
+		}
+		result.JobApplicationID = jobApplication.ID
+		fmt.Printf("📥 Imported job application %d: %s at %s\n", jobApplication.ID, jobApplication.JobTitle, jobApplication.CompanyName)
+
+		err = s.db.AddStepToJobApplication(jobApplication.ID, models.StepInput{
+			Title:       "Imported Job Posting",
+			Description: fmt.Sprintf("Imported from %s (%s)", page.URL, method),
+		})
+		if err != nil {
+			log.Printf("Failed to store job application step: %v", err)
+		}
+		if modelResult != nil {
+			s.storeWorkflow(*modelResult, jobApplication.ID, page.URL)
+		}
+	}
+
+	// the new job application is not matched against itself, and gets a warning step when DEDUP_WARN_ON_NEW is set
+	result.Duplicates, err = NewFindDuplicatesScenario(s.cfg, s.db).Check(jobApplication)
+	if err != nil {
+		log.Printf("Failed to check duplicates: %v", err)
+	}
+	result.Warning = DuplicateWarning(result.Duplicates)
+
+	return result, nil
+}
+
+func (s *ImportJobPostingScenario) storeWorkflow(result workflows.JobPostingResult, jobID int, pageURL string) {
+	parametersJSON, err := json.Marshal(map[string]interface{}{
+		"job_ids": []int{jobID},
+		"url":     pageURL,
+	})
+	if err != nil {
+		log.Printf("Failed to marshal parameters: %v", err)
+	}
+
+	// store the result in database
+	workflowRecord := models.Workflow{
+		WorkflowName: "extract_job_posting",
+		Prompt:       result.Prompt,
+		AgentModel:   s.geminiClient.ModelName,
+		Output:       result.Result,
+		Parameters:   string(parametersJSON),
+	}
+
+	workflowID, err := s.db.InsertWorkflow(workflowRecord)
+	if err != nil {
+		log.Printf("Failed to store workflow: %v", err)
+		return
+	}
+	fmt.Printf("📝 Workflow stored with ID: %d\n", workflowID)
+	if err := s.db.InsertJobApplicationsWorkflow([]int{jobID}, workflowID); err != nil {
+		log.Printf("Failed to store job application workflow: %v", err)
+	}
+}
+
+// readablePosting builds a posting from the main content of a page. The salary is found by the
+// compensation parser. The page title and site name are only used as the job title and company
+// when asked to, since they often carry the job board's name or other noise.
+func readablePosting(readable jobposting.Readable, withTitle bool) jobposting.Posting {
+	posting := jobposting.Posting{JobDescription: readable.Text}
+	// lines are parsed one by one so the salary text does not run into the neighbouring paragraphs
+	for _, line := range strings.Split(readable.Text, "\n") {
+		if pay := compensation.Parse(line); pay.HasAmount() {
+			posting.Salary = pay.RawText
+			break
+		}
+	}
+	if withTitle {
+		posting.JobTitle = readable.Title
+		posting.CompanyName = readable.SiteName
+	}
+	return posting
+}
+
+func missingPostingFields(posting jobposting.Posting) []string {
+	missing := []string{}
+	if posting.JobTitle == "" {
+		missing = append(missing, "job title")
+	}
+	if posting.CompanyName == "" {
+		missing = append(missing, "company name")
+	}
+	if posting.JobDescription == "" {
+		missing = append(missing, "job description")
+	}
+	return missing
+}
+
+// postingCompanyURL is the origin of the posting page, used when the posting does not link the company.
+// For job boards the company slug in the path is kept, so the domain normalization can tell companies apart.
+func postingCompanyURL(pageURL string) string {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	companyURL := parsed.Scheme + "://" + parsed.Host
+	if segments := strings.Split(strings.Trim(parsed.Path, "/"), "/"); segments[0] != "" {
+		companyURL += "/" + segments[0]
+	}
+	return jobposting.Clip(companyURL, models.MaxCompanyURLLength)
+}
+
+// postingSource guesses the job application source from the posting URL
+func postingSource(pageURL string) string {
+	if parsed, err := url.Parse(pageURL); err == nil && strings.Contains(parsed.Hostname(), "linkedin.com") {
+		return models.SourceLinkedIn
+	}
+	return models.SourceCareersWebsite
+}
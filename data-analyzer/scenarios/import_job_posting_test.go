@@ -0,0 +1,124 @@
+package scenarios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"data-analyzer/models"
+)
+
+var postingPages = map[string]string{
+	"/jsonld": `<html><head><script type="application/ld+json">
+		{"@type": "JobPosting", "title": "SRE", "hiringOrganization": {"name": "Hooli", "sameAs": "https://hooli.com"},
+		"description": "<p>Keep things running with Go and Kubernetes.</p>", "baseSalary": "EUR 90,000 per year"}
+		</script></head><body></body></html>`,
+	"/readable": `<html><head><meta property="og:title" content="Platform Engineer"><meta property="og:site_name" content="Globex"></head>
+		<body><nav><a href="/">Home</a></nav><article>
+		<p>Globex is looking for a Platform Engineer to run the infrastructure behind our logistics products.</p>
+		<p>You will build internal tooling in Go, operate Kubernetes clusters on AWS and help teams ship faster.</p>
+		<p>Salary: €70,000 - €85,000 per year.</p>
+		</article></body></html>`,
+	"/untitled": `<html><body><p>Apply now.</p></body></html>`,
+}
+
+func newPostingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := postingPages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestImportJobPostingWithoutAgent(t *testing.T) {
+	database, path := newTestDB(t)
+	server := newPostingServer(t)
+	// a nil client is a disabled agent
+	scenario := NewImportJobPostingScenario(newTestConfig(t), nil, database, server.Client())
+
+	t.Run("json-ld", func(t *testing.T) {
+		result, err := scenario.Execute(context.Background(), server.URL+"/jsonld", ImportJobPostingOptions{ResumeVersion: "v3"})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if result.Method != ImportMethodJSONLD || result.JobApplicationID == 0 {
+			t.Fatalf("method %q and job application %d, want %q and a stored job application", result.Method, result.JobApplicationID, ImportMethodJSONLD)
+		}
+		jobs, err := database.GetJobApplicationsById([]int{result.JobApplicationID})
+		if err != nil || len(jobs) != 1 {
+			t.Fatalf("stored job applications = %v, %v", jobs, err)
+		}
+		job := jobs[0]
+		if job.JobTitle != "SRE" || job.CompanyName != "Hooli" || job.CompanyURL != "https://hooli.com" ||
+			job.Salary != "EUR 90,000 per year" || job.ResumeVersion != "v3" ||
+			job.Status != models.StatusPreparingApplication || job.Source != models.SourceCareersWebsite {
+			t.Errorf("stored job application = %+v", job)
+		}
+		conn, err := sql.Open("sqlite3", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		var steps []string
+		rows, err := conn.Query(`SELECT title FROM jobs_step WHERE job_application_id = ?`, result.JobApplicationID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rows.Next() {
+			var title string
+			if err := rows.Scan(&title); err != nil {
+				t.Fatal(err)
+			}
+			steps = append(steps, title)
+		}
+		rows.Close()
+		if len(steps) != 1 || steps[0] != "Imported Job Posting" {
+			t.Errorf("steps = %q, want the import step", steps)
+		}
+	})
+
+	t.Run("readability", func(t *testing.T) {
+		result, err := scenario.Execute(context.Background(), server.URL+"/readable", ImportJobPostingOptions{DryRun: true})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if result.Method != ImportMethodReadability || result.JobApplicationID != 0 {
+			t.Errorf("method %q and job application %d, want %q and none for a dry run", result.Method, result.JobApplicationID, ImportMethodReadability)
+		}
+		posting := result.Posting
+		if posting.JobTitle != "Platform Engineer" || posting.CompanyName != "Globex" || !strings.Contains(posting.Salary, "€70,000 - €85,000 per year") {
+			t.Errorf("posting = %+v", posting)
+		}
+		if !strings.Contains(posting.JobDescription, "operate Kubernetes clusters") || strings.Contains(posting.JobDescription, "Home") {
+			t.Errorf("job description = %q", posting.JobDescription)
+		}
+		if posting.CompanyURL != server.URL+"/readable" {
+			t.Errorf("company URL = %q, want the page origin and first path segment", posting.CompanyURL)
+		}
+	})
+
+	t.Run("incomplete", func(t *testing.T) {
+		_, err := scenario.Execute(context.Background(), server.URL+"/untitled", ImportJobPostingOptions{DryRun: true})
+		if !errors.Is(err, ErrIncompletePosting) || !strings.Contains(err.Error(), "SHOULD_RUN_AGENT=true") {
+			t.Errorf("Execute() error = %v, want ErrIncompletePosting asking for the agent", err)
+		}
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		_, err := scenario.Execute(context.Background(), server.URL+"/jsonld", ImportJobPostingOptions{Status: "Hired"})
+		if !errors.Is(err, ErrInvalidImportOptions) {
+			t.Errorf("Execute() error = %v, want ErrInvalidImportOptions", err)
+		}
+	})
+}
@@ -0,0 +1,457 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/domains"
+	"data-analyzer/emails"
+	"data-analyzer/jobposting"
+	"data-analyzer/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Review actions for ingested emails
+const (
+	EmailReviewApprove = "approve"
+	EmailReviewDismiss = "dismiss"
+)
+
+// ErrInvalidEmailReview is returned when a review action cannot be applied to an email
+var ErrInvalidEmailReview = errors.New("invalid email review")
+
+// emailStepTitles are the titles of the steps added for each email category
+var emailStepTitles = map[string]string{
+	models.EmailCategoryRejection:       "Rejection Email",
+	models.EmailCategoryInterviewInvite: "Interview Invitation",
+	models.EmailCategoryTakeHome:        "Take-Home Assignment",
+	models.EmailCategoryOffer:           "Offer",
+	models.EmailCategoryGeneric:         "Recruiter Email",
+}
+
+// IngestedEmail is an ingested email with the job application it was matched to
+type IngestedEmail struct {
+	models.Email
+	JobTitle    string `json:"job_title,omitempty"`
+	CompanyName string `json:"company_name,omitempty"`
+}
+
+// IngestEmailsResult counts the ingested emails by the state they ended up in
+type IngestEmailsResult struct {
+	DryRun          bool            `json:"dry_run"`
+	Applied         int             `json:"applied"`
+	Proposed        int             `json:"proposed"`
+	Recorded        int             `json:"recorded"`
+	Review          int             `json:"review"`
+	AlreadyIngested int             `json:"already_ingested"`
+	Emails          []IngestedEmail `json:"emails"`
+}
+
+// ReviewEmailOptions is the decision taken on an email of the review queue. The job application
+// and status override what was matched and proposed during ingestion.
+type ReviewEmailOptions struct {
+	Action           string
+	JobApplicationID int
+	Status           string
+}
+
+// emailMatch is a job application an email may be about
+type emailMatch struct {
+	job        models.JobApplication
+	reason     string
+	confidence float64
+}
+
+type IngestEmailsScenario struct {
+	cfg          *config.Config
+	geminiClient *agent.Client
+	db           *db.DB
+}
+
+func NewIngestEmailsScenario(cfg *config.Config, geminiClient *agent.Client, db *db.DB) *IngestEmailsScenario {
+	return &IngestEmailsScenario{
+		cfg:          cfg,
+		geminiClient: geminiClient,
+		db:           db,
+	}
+}
+
+// Execute matches every email to a job application and classifies it. Confident emails add a step to
+// their job application and propose, or with EMAIL_AUTO_APPLY_STATUS apply, a status update.
+// Unmatched and low-confidence emails are only stored in the review queue. Emails that were
+// already ingested are skipped, and dry runs store nothing.
+func (s *IngestEmailsScenario) Execute(ctx context.Context, messages []emails.Message, dryRun bool) (IngestEmailsResult, error) {
+	result := IngestEmailsResult{DryRun: dryRun, Emails: []IngestedEmail{}}
+
+	// skip the emails ingested before and the copies of an email in the same input
+	var pending []emails.Message
+	seen := make(map[string]bool)
+	for _, message := range messages {
+		exists, err := s.db.EmailExists(message.MessageID)
+		if err != nil {
+			return result, err
+		}
+		if exists || seen[message.MessageID] {
+			result.AlreadyIngested++
+			continue
+		}
+		seen[message.MessageID] = true
+		pending = append(pending, message)
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	jobs, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return result, err
+	}
+
+	classifications, modelResult, err := s.classify(ctx, pending)
+	if err != nil {
+		return result, err
+	}
+
+	matchedJobIDs := []int{}
+	for i, message := range pending {
+		classification := classifications[i+1]
+		email := models.Email{
+			MessageID:          message.MessageID,
+			From:               message.From(),
+			Subject:            message.Subject,
+			ReceivedAt:         message.Date,
+			Category:           classification.Category,
+			CategoryConfidence: classification.Confidence,
+			Summary:            classification.Summary,
+			MatchReason:        "no matching job application",
+		}
+		if email.ReceivedAt.IsZero() {
+			email.ReceivedAt = time.Now()
+		}
+		ingested := IngestedEmail{}
+
+		match, ok := matchEmail(message, classification, jobs)
+		if ok {
+			email.JobApplicationID = match.job.ID
+			email.MatchReason = match.reason
+			email.MatchConfidence = match.confidence
+			email.ProposedStatus = proposeStatus(email.Category, classification.InterviewStage, match.job.Status)
+			ingested.JobTitle, ingested.CompanyName = match.job.JobTitle, match.job.CompanyName
+			if !slices.Contains(matchedJobIDs, match.job.ID) {
+				matchedJobIDs = append(matchedJobIDs, match.job.ID)
+			}
+		}
+
+		var step *models.StepInput
+		status := ""
+		switch {
+		case !ok || min(email.MatchConfidence, email.CategoryConfidence) < s.cfg.Email.ConfidenceThreshold:
+			email.State = models.EmailStateReview
+			result.Review++
+		case email.ProposedStatus == "":
+			email.State = models.EmailStateRecorded
+			step = emailStep(email)
+			result.Recorded++
+		case s.cfg.Email.AutoApplyStatus:
+			email.State = models.EmailStateApplied
+			step = emailStep(email)
+			status = email.ProposedStatus
+			result.Applied++
+		default:
+			email.State = models.EmailStateProposed
+			step = emailStep(email)
+			result.Proposed++
+		}
+
+		if !dryRun {
+			email.ID, err = s.db.InsertEmail(email, step, status)
+			if err != nil {
+				return result, err
+			}
+			email.CreatedAt = time.Now()
+			if email.State == models.EmailStateProposed {
+				recordStatusSuggestion(s.db, fmt.Sprintf("email:%d", email.ID), WebhookStatusSuggested{
+					JobApplication:  webhookJobApplication(match.job),
+					SuggestedStatus: email.ProposedStatus,
+					Reason:          fmt.Sprintf("%s email %q from %s", emailStepTitles[email.Category], email.Subject, email.From),
+					Source:          "email",
+					EmailID:         email.ID,
+				})
+			}
+		}
+		ingested.Email = email
+		result.Emails = append(result.Emails, ingested)
+	}
+
+	if !dryRun && modelResult != nil {
+		s.storeWorkflow(*modelResult, matchedJobIDs, pending)
+	}
+
+	return result, nil
+}
+
+// classify categorizes the emails with the model, or by keywords when the agent is disabled.
+// The classifications are keyed by the position of the email, starting at 1.
+func (s *IngestEmailsScenario) classify(ctx context.Context, messages []emails.Message) (map[int]workflows.EmailClassification, *workflows.ClassifyEmailsResult, error) {
+	if s.geminiClient.Enabled() {
+		result, err := workflows.NewClassifyEmailsWorkflow(s.geminiClient, messages).Execute(ctx)
+		if err != nil {
+			workflows.RecordWorkflowFailure(s.db, "classify_emails", nil, err)
+			return nil, nil, fmt.Errorf("failed to execute classify emails workflow: %w", err)
+		}
+		// emails the model skipped are treated as generic with no confidence, so they go to review
+		for i := range messages {
+			if _, ok := result.Classifications[i+1]; !ok {
+				result.Classifications[i+1] = workflows.EmailClassification{EmailID: i + 1, Category: models.EmailCategoryGeneric}
+			}
+		}
+		return result.Classifications, &result, nil
+	}
+
+	classifications := make(map[int]workflows.EmailClassification, len(messages))
+	for i, message := range messages {
+		category, confidence := emails.Classify(message.Subject, message.Body)
+		classification := workflows.EmailClassification{
+			EmailID:    i + 1,
+			Category:   category,
+			Confidence: confidence,
+			Summary:    jobposting.Clip(strings.Join(strings.Fields(message.Body), " "), 200),
+		}
+		if category == models.EmailCategoryInterviewInvite {
+			classification.InterviewStage = emails.InterviewStage(message.Subject, message.Body)
+		}
+		classifications[i+1] = classification
+	}
+	return classifications, nil, nil
+}
+
+func (s *IngestEmailsScenario) storeWorkflow(result workflows.ClassifyEmailsResult, jobIDs []int, messages []emails.Message) {
+	messageIDs := make([]string, len(messages))
+	for i, message := range messages {
+		messageIDs[i] = message.MessageID
+	}
+	parametersJSON, err := json.Marshal(map[string]interface{}{
+		"job_ids":     jobIDs,
+		"message_ids": messageIDs,
+	})
+	if err != nil {
+		log.Printf("Failed to marshal parameters: %v", err)
+	}
+
+	// store the result in database
+	workflowRecord := models.Workflow{
+		WorkflowName: "classify_emails",
+		Prompt:       result.Prompt,
+		AgentModel:   s.geminiClient.ModelName,
+		Output:       result.Result,
+		Parameters:   string(parametersJSON),
+	}
+
+	workflowID, err := s.db.InsertWorkflow(workflowRecord)
+	if err != nil {
+		log.Printf("Failed to store workflow: %v", err)
+		return
+	}
+	fmt.Printf("📝 Workflow stored with ID: %d\n", workflowID)
+	if len(jobIDs) == 0 {
+		return
+	}
+	if err := s.db.InsertJobApplicationsWorkflow(jobIDs, workflowID); err != nil {
+		log.Printf("Failed to store job application workflow: %v", err)
+	}
+}
+
+// Review resolves an email that is waiting in the review queue or has a proposed status update.
+// Approving an email from the review queue adds its step to the job application and applies the
+// proposed status; approving a proposal applies the status. Dismissing leaves the job application as it is.
+func (s *IngestEmailsScenario) Review(id int, options ReviewEmailOptions) (models.Email, error) {
+	email, err := s.db.GetEmail(id)
+	if err != nil {
+		return models.Email{}, err
+	}
+	if email.State != models.EmailStateReview && email.State != models.EmailStateProposed {
+		return models.Email{}, fmt.Errorf("%w: email %d was already %s", ErrInvalidEmailReview, id, email.State)
+	}
+	if options.Status != "" && !slices.Contains(models.StatusChoices, options.Status) {
+		return models.Email{}, fmt.Errorf("%w: status must be one of %s", ErrInvalidEmailReview, strings.Join(models.StatusChoices, ", "))
+	}
+
+	switch options.Action {
+	case EmailReviewDismiss:
+		email.State = models.EmailStateDismissed
+		return email, s.db.ResolveEmail(email, nil, "")
+	case EmailReviewApprove:
+	default:
+		return models.Email{}, fmt.Errorf("%w: action must be %s or %s", ErrInvalidEmailReview, EmailReviewApprove, EmailReviewDismiss)
+	}
+
+	rematched := false
+	if email.State == models.EmailStateReview {
+		if options.JobApplicationID != 0 && options.JobApplicationID != email.JobApplicationID {
+			email.JobApplicationID = options.JobApplicationID
+			email.MatchReason = "chosen during review"
+			rematched = true
+		}
+		if email.JobApplicationID == 0 {
+			return models.Email{}, fmt.Errorf("%w: email %d is not matched to a job application, choose one", ErrInvalidEmailReview, id)
+		}
+	} else if options.JobApplicationID != 0 && options.JobApplicationID != email.JobApplicationID {
+		return models.Email{}, fmt.Errorf("%w: email %d was already added to job application %d", ErrInvalidEmailReview, id, email.JobApplicationID)
+	}
+
+	jobs, err := s.db.GetJobApplicationsById([]int{email.JobApplicationID})
+	if err != nil {
+		return models.Email{}, err
+	}
+	if len(jobs) == 0 {
+		return models.Email{}, fmt.Errorf("%w: job application %d does not exist", ErrInvalidEmailReview, email.JobApplicationID)
+	}
+
+	var step *models.StepInput
+	if email.State == models.EmailStateReview {
+		if options.Status != "" {
+			email.ProposedStatus = options.Status
+		} else if rematched || email.ProposedStatus == "" {
+			// the proposal made at ingestion was for another job application, or there was none
+			email.ProposedStatus = proposeStatus(email.Category, "", jobs[0].Status)
+		}
+		step = emailStep(email)
+	} else if options.Status != "" {
+		email.ProposedStatus = options.Status
+	}
+
+	status := email.ProposedStatus
+	if status == jobs[0].Status {
+		status = ""
+	}
+	email.State = models.EmailStateRecorded
+	if status != "" {
+		email.State = models.EmailStateApplied
+	}
+
+	if err := s.db.ResolveEmail(email, step, status); err != nil {
+		return models.Email{}, err
+	}
+	return email, nil
+}
+
+// emailStep is the step added to the job application an email is about
+func emailStep(email models.Email) *models.StepInput {
+	var description strings.Builder
+	if email.Summary != "" {
+		description.WriteString(email.Summary + "\n\n")
+	}
+	description.WriteString(fmt.Sprintf("From: %s\nSubject: %s\nReceived: %s", email.From, email.Subject, email.ReceivedAt.Format("2006-01-02 15:04")))
+	if email.ProposedStatus != "" {
+		description.WriteString("\nSuggested status: " + email.ProposedStatus)
+	}
+	return &models.StepInput{
+		Title:       emailStepTitles[email.Category],
+		Description: description.String(),
+	}
+}
+
+// matchEmail finds the job application an email is about. The sender's domain is the strongest
+// signal, followed by the company name in the sender or subject, the company the model found and
+// the company name in the body. Job boards and applicant tracking systems send for every company
+// hiring through them, so their domains are no signal. When several job applications at the company
+// match equally, the one whose title is mentioned wins; without a mention the most recent one is
+// taken with a lower confidence.
+func matchEmail(message emails.Message, classification workflows.EmailClassification, jobs []models.JobApplication) (emailMatch, bool) {
+	senderDomain := ""
+	if !domains.IsJobBoard(message.Domain()) {
+		senderDomain = domains.Normalize(message.Domain())
+	}
+	header := " " + strings.Join(domains.Words(message.FromName+" "+message.Subject), " ") + " "
+	body := " " + strings.Join(domains.Words(message.Body), " ") + " "
+	modelCompany := domains.CompanyName(classification.CompanyName)
+
+	var best []emailMatch
+	for _, job := range jobs {
+		match := emailMatch{job: job}
+		company := domains.CompanyName(job.CompanyName)
+		switch {
+		case senderDomain != "" && senderDomain == domains.Normalize(job.CompanyURL):
+			match.reason, match.confidence = "sender domain "+senderDomain, 0.9
+		case company != "" && strings.Contains(header, " "+company+" "):
+			match.reason, match.confidence = "company name in sender or subject", 0.8
+		case company != "" && company == modelCompany:
+			match.reason, match.confidence = "company named by the model", 0.7
+		case company != "" && strings.Contains(body, " "+company+" "):
+			match.reason, match.confidence = "company name in body", 0.6
+		default:
+			continue
+		}
+		switch {
+		case len(best) == 0 || match.confidence > best[0].confidence:
+			best = []emailMatch{match}
+		case match.confidence == best[0].confidence:
+			best = append(best, match)
+		}
+	}
+	if len(best) == 0 {
+		return emailMatch{}, false
+	}
+	if len(best) == 1 {
+		return best[0], true
+	}
+
+	modelTitle := strings.Join(domains.Words(classification.JobTitle), " ")
+	for _, match := range best {
+		title := strings.Join(domains.Words(match.job.JobTitle), " ")
+		if title == "" {
+			continue
+		}
+		if title == modelTitle || strings.Contains(header, " "+title+" ") || strings.Contains(body, " "+title+" ") {
+			match.reason += ", job title mentioned"
+			return match, true
+		}
+	}
+	// jobs are ordered newest first
+	match := best[0]
+	match.reason += fmt.Sprintf(", %d job applications at this company", len(best))
+	match.confidence = max(match.confidence-0.2, 0)
+	return match, true
+}
+
+// proposeStatus maps the category of an email to the status the job application should move to.
+// Nothing is proposed when the job application already has that status, or when an HR invitation
+// arrives after the technical interviews started.
+func proposeStatus(category string, interviewStage string, current string) string {
+	proposed := ""
+	switch category {
+	case models.EmailCategoryRejection:
+		proposed = models.StatusRejected
+	case models.EmailCategoryOffer:
+		proposed = models.StatusOffer
+	case models.EmailCategoryTakeHome:
+		proposed = models.StatusTechnicalInterview
+	case models.EmailCategoryInterviewInvite:
+		switch {
+		case interviewStage == emails.StageTechnical:
+			proposed = models.StatusTechnicalInterview
+		case interviewStage == emails.StageHR:
+			proposed = models.StatusHRInterview
+		case current == models.StatusHRInterview:
+			// the round after the HR screen is usually technical
+			proposed = models.StatusTechnicalInterview
+		default:
+			proposed = models.StatusHRInterview
+		}
+		if proposed == models.StatusHRInterview && current == models.StatusTechnicalInterview {
+			return ""
+		}
+	}
+	if proposed == current {
+		return ""
+	}
+	return proposed
+}
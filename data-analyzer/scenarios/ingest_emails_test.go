@@ -0,0 +1,103 @@
+package scenarios
+
+import (
+	"testing"
+
+	"data-analyzer/agent/workflows"
+	"data-analyzer/emails"
+	"data-analyzer/models"
+)
+
+func TestMatchEmail(t *testing.T) {
+	// newest first, as the job applications are passed in
+	jobs := []models.JobApplication{
+		{ID: 4, JobTitle: "Data Engineer", CompanyName: "Initech", CompanyURL: "https://www.linkedin.com/jobs/view/3912345678/"},
+		{ID: 3, JobTitle: "Frontend Developer", CompanyName: "Globex", CompanyURL: "https://globex.com/careers"},
+		{ID: 2, JobTitle: "Backend Engineer", CompanyName: "Acme Inc.", CompanyURL: "https://boards.greenhouse.io/acme/jobs/1"},
+		{ID: 1, JobTitle: "Platform Engineer", CompanyName: "Hooli", CompanyURL: "https://www.linkedin.com/jobs/view/3900000000/"},
+	}
+	tests := []struct {
+		name           string
+		message        emails.Message
+		classification workflows.EmailClassification
+		wantJob        int
+		wantConfidence float64
+		wantMatch      bool
+	}{
+		{
+			name:           "sender domain",
+			message:        emails.Message{FromAddress: "jane@globex.com", Subject: "Your application"},
+			wantJob:        3,
+			wantConfidence: 0.9,
+			wantMatch:      true,
+		},
+		{
+			name:      "job board notification naming no company",
+			message:   emails.Message{FromAddress: "jobs-noreply@linkedin.com", Subject: "Your application was viewed"},
+			wantMatch: false,
+		},
+		{
+			name:      "applicant tracking system notification naming no company",
+			message:   emails.Message{FromAddress: "no-reply@greenhouse.io", Subject: "Thank you for applying"},
+			wantMatch: false,
+		},
+		{
+			name:           "job board notification naming the company",
+			message:        emails.Message{FromAddress: "jobs-noreply@linkedin.com", Subject: "Your application to Hooli"},
+			wantJob:        1,
+			wantConfidence: 0.8,
+			wantMatch:      true,
+		},
+		{
+			name:           "company named by the model",
+			message:        emails.Message{FromAddress: "no-reply@us.greenhouse-mail.io", Subject: "Update"},
+			classification: workflows.EmailClassification{CompanyName: "ACME"},
+			wantJob:        2,
+			wantConfidence: 0.7,
+			wantMatch:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := matchEmail(tt.message, tt.classification, jobs)
+			if ok != tt.wantMatch {
+				t.Fatalf("matchEmail() matched = %v (%s), want %v", ok, match.reason, tt.wantMatch)
+			}
+			if !ok {
+				return
+			}
+			if match.job.ID != tt.wantJob || match.confidence != tt.wantConfidence {
+				t.Errorf("matchEmail() = job %d at %.1f (%s), want job %d at %.1f",
+					match.job.ID, match.confidence, match.reason, tt.wantJob, tt.wantConfidence)
+			}
+		})
+	}
+}
+
+func TestProposeStatus(t *testing.T) {
+	tests := []struct {
+		category       string
+		interviewStage string
+		current        string
+		want           string
+	}{
+		{models.EmailCategoryRejection, "", models.StatusApplied, models.StatusRejected},
+		{models.EmailCategoryRejection, "", models.StatusRejected, ""},
+		{models.EmailCategoryOffer, "", models.StatusTechnicalInterview, models.StatusOffer},
+		{models.EmailCategoryTakeHome, "", models.StatusHRInterview, models.StatusTechnicalInterview},
+		{models.EmailCategoryInterviewInvite, emails.StageTechnical, models.StatusApplied, models.StatusTechnicalInterview},
+		{models.EmailCategoryInterviewInvite, emails.StageHR, models.StatusApplied, models.StatusHRInterview},
+		{models.EmailCategoryInterviewInvite, "", models.StatusApplied, models.StatusHRInterview},
+		// the round after the HR screen is usually technical
+		{models.EmailCategoryInterviewInvite, "", models.StatusHRInterview, models.StatusTechnicalInterview},
+		// an HR invitation does not move a job application back from the technical interviews
+		{models.EmailCategoryInterviewInvite, emails.StageHR, models.StatusTechnicalInterview, ""},
+		{models.EmailCategoryInterviewInvite, emails.StageTechnical, models.StatusTechnicalInterview, ""},
+		{models.EmailCategoryGeneric, "", models.StatusApplied, ""},
+	}
+	for _, tt := range tests {
+		if got := proposeStatus(tt.category, tt.interviewStage, tt.current); got != tt.want {
+			t.Errorf("proposeStatus(%q, %q, %q) = %q, want %q", tt.category, tt.interviewStage, tt.current, got, tt.want)
+		}
+	}
+}
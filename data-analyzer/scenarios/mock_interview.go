@@ -0,0 +1,335 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mockInterviewStepTitle is the title of the step recording the score of a completed mock interview
+const mockInterviewStepTitle = "Mock Interview"
+
+// maxMockInterviewQuestions caps the number of questions of a session
+const maxMockInterviewQuestions = 20
+
+var (
+	// ErrMockInterviewNotFound is returned when no mock interview has the given session ID
+	ErrMockInterviewNotFound = errors.New("mock interview not found")
+	// ErrMockInterviewCompleted is returned when answering or finishing a mock interview that was already graded
+	ErrMockInterviewCompleted = errors.New("mock interview is already completed")
+	// ErrInvalidMockInterview is returned for empty answers, finishing without answers and invalid question counts
+	ErrInvalidMockInterview = errors.New("invalid mock interview request")
+)
+
+// mockInterviewMu serializes the turns, so two answers sent at once cannot both extend the same transcript
+var mockInterviewMu sync.Mutex
+
+// mockInterviewParameters links the workflow records of a session: the first record is the session and
+// every later one points to it and to the record it continues
+type mockInterviewParameters struct {
+	JobIds             []int    `json:"job_ids"`
+	Fields             []string `json:"fields"`
+	SessionID          int      `json:"session_id,omitempty"`
+	PreviousWorkflowID int      `json:"previous_workflow_id,omitempty"`
+}
+
+type MockInterviewScenario struct {
+	cfg          *config.Config
+	geminiClient *agent.Client
+	db           *db.DB
+}
+
+func NewMockInterviewScenario(cfg *config.Config, geminiClient *agent.Client, db *db.DB) *MockInterviewScenario {
+	return &MockInterviewScenario{
+		cfg:          cfg,
+		geminiClient: geminiClient,
+		db:           db,
+	}
+}
+
+// Start builds the interviewer persona from the job's requirements and company research and asks the
+// first question. The session is stored right away, its ID is the ID of its first workflow record.
+// A question count of 0 uses MOCK_INTERVIEW_QUESTIONS.
+func (s *MockInterviewScenario) Start(ctx context.Context, jobApplication models.JobApplication, questionCount int) (workflows.MockInterviewSession, error) {
+	if !s.geminiClient.Enabled() {
+		return workflows.MockInterviewSession{}, fmt.Errorf("mock interviews need the agent, set SHOULD_RUN_AGENT=true")
+	}
+	if questionCount == 0 {
+		questionCount = s.cfg.InterviewPrep.MockQuestions
+	}
+	if questionCount < 1 || questionCount > maxMockInterviewQuestions {
+		return workflows.MockInterviewSession{}, fmt.Errorf("%w: questions must be between 1 and %d", ErrInvalidMockInterview, maxMockInterviewQuestions)
+	}
+
+	interviewContext, err := loadInterviewContext(s.db)
+	if err != nil {
+		return workflows.MockInterviewSession{}, err
+	}
+
+	now := time.Now()
+	session := workflows.MockInterviewSession{
+		JobId:         jobApplication.ID,
+		Status:        jobApplication.Status,
+		State:         workflows.MockInterviewInProgress,
+		Persona:       interviewerPersona(jobApplication, interviewContext),
+		QuestionCount: questionCount,
+		Turns:         []workflows.MockInterviewTurn{},
+		StartedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	turn, prompt, err := workflows.NewMockInterviewWorkflow(s.geminiClient).NextQuestion(ctx, session)
+	if err != nil {
+		workflows.RecordWorkflowFailure(s.db, "mock_interview", []int{session.JobId}, err)
+		return workflows.MockInterviewSession{}, fmt.Errorf("failed to ask the first question: %w", err)
+	}
+	session.Turns = append(session.Turns, turn)
+
+	workflowID, err := s.store(session, prompt, 0)
+	if err != nil {
+		return workflows.MockInterviewSession{}, err
+	}
+	session.SessionID = workflowID
+	return session, nil
+}
+
+// Answer records the answer to the pending question and asks the next one. The answer to the last
+// question completes the session and grades it.
+func (s *MockInterviewScenario) Answer(ctx context.Context, sessionID int, answer string) (workflows.MockInterviewSession, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return workflows.MockInterviewSession{}, fmt.Errorf("%w: the answer cannot be empty", ErrInvalidMockInterview)
+	}
+
+	mockInterviewMu.Lock()
+	defer mockInterviewMu.Unlock()
+
+	session, workflowID, err := s.load(sessionID)
+	if err != nil {
+		return session, err
+	}
+
+	now := time.Now()
+	last := &session.Turns[len(session.Turns)-1]
+	last.Answer = answer
+	last.AnsweredAt = &now
+	session.UpdatedAt = now
+
+	if len(session.Turns) >= session.QuestionCount {
+		return s.complete(ctx, session, workflowID)
+	}
+
+	turn, prompt, err := workflows.NewMockInterviewWorkflow(s.geminiClient).NextQuestion(ctx, session)
+	if err != nil {
+		workflows.RecordWorkflowFailure(s.db, "mock_interview", []int{session.JobId}, err)
+		return session, fmt.Errorf("failed to ask the next question: %w", err)
+	}
+	session.Turns = append(session.Turns, turn)
+	if _, err := s.store(session, prompt, workflowID); err != nil {
+		return session, err
+	}
+	return session, nil
+}
+
+// Finish ends the session before the last question and grades the questions answered so far. The
+// pending question is dropped.
+func (s *MockInterviewScenario) Finish(ctx context.Context, sessionID int) (workflows.MockInterviewSession, error) {
+	mockInterviewMu.Lock()
+	defer mockInterviewMu.Unlock()
+
+	session, workflowID, err := s.load(sessionID)
+	if err != nil {
+		return session, err
+	}
+	if session.Turns[len(session.Turns)-1].Answer == "" {
+		session.Turns = session.Turns[:len(session.Turns)-1]
+	}
+	if len(session.Turns) == 0 {
+		return session, fmt.Errorf("%w: answer at least one question before finishing", ErrInvalidMockInterview)
+	}
+	session.UpdatedAt = time.Now()
+	return s.complete(ctx, session, workflowID)
+}
+
+// complete grades the session, stores it and records the score as a step of the job application
+func (s *MockInterviewScenario) complete(ctx context.Context, session workflows.MockInterviewSession, previousWorkflowID int) (workflows.MockInterviewSession, error) {
+	session, prompt, err := workflows.NewMockInterviewWorkflow(s.geminiClient).Grade(ctx, session)
+	if err != nil {
+		workflows.RecordWorkflowFailure(s.db, "mock_interview", []int{session.JobId}, err)
+		return session, fmt.Errorf("failed to grade the mock interview: %w", err)
+	}
+	completedAt := time.Now()
+	session.State = workflows.MockInterviewCompleted
+	session.CompletedAt = &completedAt
+	session.UpdatedAt = completedAt
+
+	if _, err := s.store(session, prompt, previousWorkflowID); err != nil {
+		return session, err
+	}
+
+	err = s.db.AddStepToJobApplication(session.JobId, models.StepInput{
+		Title: mockInterviewStepTitle,
+		Description: fmt.Sprintf("Mock interview session %d completed with a score of %.0f%% over %d questions",
+			session.SessionID, session.Score, len(session.Turns)),
+	})
+	if err != nil {
+		log.Printf("Failed to store job application step: %v", err)
+	}
+	return session, nil
+}
+
+// store saves the state of the session as a new workflow record linked to the job application and to
+// the previous record of the session, and returns its ID
+func (s *MockInterviewScenario) store(session workflows.MockInterviewSession, prompt string, previousWorkflowID int) (int, error) {
+	outputJSON, err := json.Marshal(session)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal mock interview: %w", err)
+	}
+
+	parametersJSON, err := json.Marshal(mockInterviewParameters{
+		JobIds:             []int{session.JobId},
+		Fields:             []string{"requirements", "company_research", "answers"},
+		SessionID:          session.SessionID,
+		PreviousWorkflowID: previousWorkflowID,
+	})
+	if err != nil {
+		log.Printf("Failed to marshal parameters: %v", err)
+	}
+
+	workflowRecord := models.Workflow{
+		WorkflowName: "mock_interview",
+		Prompt:       prompt,
+		AgentModel:   s.geminiClient.ModelName,
+		Output:       string(outputJSON),
+		Parameters:   string(parametersJSON),
+	}
+
+	// unlike the other workflows the session cannot go on without its record
+	workflowID, err := s.db.InsertWorkflow(workflowRecord)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store mock interview: %w", err)
+	}
+	fmt.Printf("📝 Workflow stored with ID: %d\n", workflowID)
+
+	err = s.db.InsertJobApplicationsWorkflow([]int{session.JobId}, workflowID)
+	if err != nil {
+		log.Printf("Failed to store job application workflow: %v", err)
+	}
+	return int(workflowID), nil
+}
+
+// load returns the latest state of an in progress session and the ID of the workflow record holding it
+func (s *MockInterviewScenario) load(sessionID int) (workflows.MockInterviewSession, int, error) {
+	if !s.geminiClient.Enabled() {
+		return workflows.MockInterviewSession{}, 0, fmt.Errorf("mock interviews need the agent, set SHOULD_RUN_AGENT=true")
+	}
+	sessions, latestWorkflowIDs, err := storedMockInterviews(s.db)
+	if err != nil {
+		return workflows.MockInterviewSession{}, 0, err
+	}
+	session, ok := sessions[sessionID]
+	if !ok || len(session.Turns) == 0 {
+		return workflows.MockInterviewSession{}, 0, ErrMockInterviewNotFound
+	}
+	if session.State == workflows.MockInterviewCompleted {
+		return session, 0, ErrMockInterviewCompleted
+	}
+	return session, latestWorkflowIDs[sessionID], nil
+}
+
+// interviewerPersona describes the interviewer of the job, from its requirements and company research
+func interviewerPersona(jobApplication models.JobApplication, interviewContext interviewContext) string {
+	persona := fmt.Sprintf("You are an experienced interviewer at %s, interviewing a candidate for the %s position. ",
+		jobApplication.CompanyName, jobApplication.JobTitle)
+	persona += fmt.Sprintf("The candidate is at the %s stage of the hiring process.\n", jobApplication.Status)
+
+	requirements := interviewContext.requirements(jobApplication.ID)
+	if len(requirements) > 0 {
+		persona += "\nThe role requires:\n"
+		for _, requirement := range requirements {
+			persona += fmt.Sprintf("- %s\n", requirement)
+		}
+	} else {
+		persona += "\nThe job description:\n" + jobApplication.JobDescription + "\n"
+	}
+
+	if research := interviewContext.research(jobApplication.ID); len(research) > 0 {
+		persona += "\nWhat you know about your company:\n"
+		for _, note := range research {
+			persona += fmt.Sprintf("- %s\n", note)
+		}
+	}
+
+	persona += "\nStay in character, be professional and probe for specifics the way a real interviewer at this company would."
+	return persona
+}
+
+// GetStoredMockInterviews returns the latest state of every mock interview session, keyed by session ID
+func GetStoredMockInterviews(db *db.DB) (map[int]workflows.MockInterviewSession, error) {
+	sessions, _, err := storedMockInterviews(db)
+	return sessions, err
+}
+
+// GetMockInterviewHistory returns the sessions of a job application, or of all of them when the ID is 0,
+// oldest first so the scores show the progress across sessions
+func GetMockInterviewHistory(db *db.DB, jobApplicationID int) ([]workflows.MockInterviewSession, error) {
+	sessions, err := GetStoredMockInterviews(db)
+	if err != nil {
+		return nil, err
+	}
+	history := []workflows.MockInterviewSession{}
+	for _, session := range sessions {
+		if jobApplicationID == 0 || session.JobId == jobApplicationID {
+			history = append(history, session)
+		}
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].SessionID < history[j].SessionID
+	})
+	return history, nil
+}
+
+func storedMockInterviews(db *db.DB) (map[int]workflows.MockInterviewSession, map[int]int, error) {
+	storedWorkflows, err := db.GetWorkflowsByName("mock_interview")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// workflows are ordered newest first, so the first record seen for a session holds its latest state
+	sessions := make(map[int]workflows.MockInterviewSession)
+	latestWorkflowIDs := make(map[int]int)
+	for _, workflow := range storedWorkflows {
+		var parameters mockInterviewParameters
+		if err := json.Unmarshal([]byte(workflow.Parameters), &parameters); err != nil {
+			log.Printf("Failed to unmarshal mock interview parameters %d: %v", workflow.ID, err)
+			continue
+		}
+		var session workflows.MockInterviewSession
+		if err := json.Unmarshal([]byte(workflow.Output), &session); err != nil {
+			log.Printf("Failed to unmarshal mock interview workflow %d: %v", workflow.ID, err)
+			continue
+		}
+		// the first record of a session is the session
+		session.SessionID = parameters.SessionID
+		if session.SessionID == 0 {
+			session.SessionID = workflow.ID
+		}
+		if _, ok := sessions[session.SessionID]; !ok {
+			sessions[session.SessionID] = session
+			latestWorkflowIDs[session.SessionID] = workflow.ID
+		}
+	}
+
+	return sessions, latestWorkflowIDs, nil
+}
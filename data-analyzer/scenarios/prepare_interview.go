@@ -0,0 +1,270 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+type PrepareInterviewScenario struct {
+	cfg          *config.Config
+	geminiClient *agent.Client
+	db           *db.DB
+}
+
+func NewPrepareInterviewScenario(cfg *config.Config, geminiClient *agent.Client, db *db.DB) *PrepareInterviewScenario {
+	return &PrepareInterviewScenario{
+		cfg:          cfg,
+		geminiClient: geminiClient,
+		db:           db,
+	}
+}
+
+// Execute prepares the interviews of the job applications from their extracted requirements, the stored
+// company research and the candidate's work achievements. Job applications without extracted requirements
+// fall back to their requirement research notes and then to the job description.
+func (s *PrepareInterviewScenario) Execute(ctx context.Context, jobApplications []models.JobApplication) ([]workflows.InterviewPreparation, error) {
+	if !s.geminiClient.Enabled() {
+		return nil, fmt.Errorf("interview preparation needs the agent, set SHOULD_RUN_AGENT=true")
+	}
+
+	interviewContext, err := loadInterviewContext(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	achievements, err := s.db.GetAllWorkAchievements()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work achievements: %w", err)
+	}
+	if len(achievements) == 0 {
+		return nil, fmt.Errorf("no work achievements found, add your work experience first")
+	}
+
+	preparations := make([]workflows.InterviewPreparation, 0, len(jobApplications))
+	for _, jobApplication := range jobApplications {
+		input := workflows.InterviewPreparationInput{
+			Requirements:    interviewContext.requirements(jobApplication.ID),
+			CompanyResearch: interviewContext.research(jobApplication.ID),
+			Achievements:    achievements,
+		}
+
+		prepareInterviewWorkflow := workflows.NewPrepareInterviewWorkflow(s.geminiClient, s.db)
+		preparation, err := prepareInterviewWorkflow.Execute(ctx, jobApplication, input)
+		if err != nil {
+			workflows.RecordWorkflowFailure(s.db, "prepare_interview", []int{jobApplication.ID}, err)
+			return nil, fmt.Errorf("failed to prepare the interview for job application %d: %w", jobApplication.ID, err)
+		}
+		preparations = append(preparations, preparation)
+	}
+
+	return preparations, nil
+}
+
+// interviewContext is what is known about the jobs that interviews are about
+type interviewContext struct {
+	roleDetails     map[int]workflows.RoleDetails
+	companyResearch map[int]workflows.ResearchCompany
+	researchData    []models.ResearchData
+}
+
+func loadInterviewContext(db *db.DB) (interviewContext, error) {
+	roleDetails, err := GetStoredRoleDetails(db)
+	if err != nil {
+		return interviewContext{}, fmt.Errorf("failed to get role details: %w", err)
+	}
+	companyResearch, err := GetStoredCompanyResearch(db)
+	if err != nil {
+		return interviewContext{}, fmt.Errorf("failed to get company research: %w", err)
+	}
+	researchData, err := db.GetAllResearchData()
+	if err != nil {
+		return interviewContext{}, fmt.Errorf("failed to get research data: %w", err)
+	}
+	return interviewContext{
+		roleDetails:     roleDetails,
+		companyResearch: companyResearch,
+		researchData:    researchData,
+	}, nil
+}
+
+// requirements are the extracted requirements of the job, or its requirement research notes when
+// nothing was extracted
+func (c interviewContext) requirements(jobID int) []string {
+	if roleDetail, ok := c.roleDetails[jobID]; ok {
+		return roleDetail.Requirements
+	}
+	requirements := []string{}
+	for _, research := range c.researchData {
+		if research.JobApplicationID == jobID && research.Category == models.ResearchCategoryRequirement {
+			requirements = append(requirements, research.Info)
+		}
+	}
+	return requirements
+}
+
+// research flattens the stored company research into one note per finding, software engineering
+// first, followed by the company and role research notes
+func (c interviewContext) research(jobID int) []string {
+	notes := []string{}
+	add := func(aspect string, value string, example string) {
+		note := fmt.Sprintf("%s: %s", aspect, value)
+		if example != "" {
+			note += fmt.Sprintf(" (e.g. %s)", example)
+		}
+		notes = append(notes, note)
+	}
+	companyResearch := c.companyResearch[jobID]
+	for _, finding := range companyResearch.SoftwareEngineering {
+		add("Software engineering", finding.Value, finding.Example)
+	}
+	for _, finding := range companyResearch.Business {
+		add("Business", finding.Value, finding.Example)
+	}
+	for _, finding := range companyResearch.CompanyOverview {
+		add("Company overview", finding.Value, finding.Example)
+	}
+	for _, research := range c.researchData {
+		if research.JobApplicationID != jobID {
+			continue
+		}
+		if research.Category == models.ResearchCategoryCompanyResearch || research.Category == models.ResearchCategoryRoleResearch {
+			notes = append(notes, research.Info)
+		}
+	}
+	return notes
+}
+
+// Pending returns the job applications in one of the INTERVIEW_PREP_STATUSES that have no interview
+// preparation for their current status, that is the ones whose status changed since they were last prepared
+func (s *PrepareInterviewScenario) Pending() ([]models.JobApplication, error) {
+	jobs, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return nil, err
+	}
+	preparations, err := GetStoredInterviewPreparations(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []models.JobApplication{}
+	for _, job := range jobs {
+		if !slices.Contains(s.cfg.InterviewPrep.Statuses, job.Status) {
+			continue
+		}
+		if preparation, ok := preparations[job.ID]; ok && preparation.Status == job.Status {
+			continue
+		}
+		pending = append(pending, job)
+	}
+	return pending, nil
+}
+
+// RunPeriodically prepares the interviews of the pending job applications every
+// INTERVIEW_PREP_INTERVAL_HOURS until the context is done
+func (s *PrepareInterviewScenario) RunPeriodically(ctx context.Context) {
+	interval := time.Duration(s.cfg.InterviewPrep.IntervalHours) * time.Hour
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pending, err := s.Pending()
+		if err != nil {
+			log.Printf("Failed to find job applications to prepare interviews for: %v", err)
+		} else if len(pending) > 0 {
+			// one failing job application does not block the others
+			prepared := 0
+			for _, job := range pending {
+				if _, err := s.Execute(ctx, []models.JobApplication{job}); err != nil {
+					log.Printf("Failed to prepare interview: %v", err)
+					continue
+				}
+				prepared++
+			}
+			fmt.Printf("🎤 Interview preparation: %d of %d job applications prepared\n", prepared, len(pending))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetStoredInterviewPreparations returns the most recent stored interview preparation of every job application
+func GetStoredInterviewPreparations(db *db.DB) (map[int]workflows.InterviewPreparation, error) {
+	storedWorkflows, err := db.GetWorkflowsByName("prepare_interview")
+	if err != nil {
+		return nil, err
+	}
+
+	// workflows are ordered newest first, so the first preparation seen for a job wins
+	preparations := make(map[int]workflows.InterviewPreparation)
+	for _, workflow := range storedWorkflows {
+		var preparation workflows.InterviewPreparation
+		if err := json.Unmarshal([]byte(workflow.Output), &preparation); err != nil {
+			log.Printf("Failed to unmarshal interview preparation workflow %d: %v", workflow.ID, err)
+			continue
+		}
+		if _, ok := preparations[preparation.JobId]; !ok {
+			preparations[preparation.JobId] = preparation
+		}
+	}
+
+	return preparations, nil
+}
+
+// InterviewPreparationMarkdown exports an interview preparation as a Markdown document, naming the
+// achievements every answer is drawn from
+func InterviewPreparationMarkdown(job models.JobApplication, preparation workflows.InterviewPreparation, achievements []models.WorkAchievement) string {
+	achievementsByID := make(map[int]models.WorkAchievement, len(achievements))
+	for _, achievement := range achievements {
+		achievementsByID[achievement.ID] = achievement
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# Interview preparation: %s at %s\n\n", job.JobTitle, job.CompanyName)
+	fmt.Fprintf(&builder, "Prepared for the %s stage on %s.\n", preparation.Status, preparation.PreparedAt.Format("2006-01-02"))
+
+	for _, section := range []struct {
+		title     string
+		questions []workflows.InterviewQuestion
+	}{
+		{"Technical questions", preparation.TechnicalQuestions},
+		{"Behavioral questions", preparation.BehavioralQuestions},
+	} {
+		if len(section.questions) == 0 {
+			continue
+		}
+		fmt.Fprintf(&builder, "\n## %s\n", section.title)
+		for i, question := range section.questions {
+			fmt.Fprintf(&builder, "\n### %d. %s\n\n", i+1, question.Question)
+			if question.Reason != "" {
+				fmt.Fprintf(&builder, "_Why it may come up: %s_\n\n", question.Reason)
+			}
+			fmt.Fprintf(&builder, "- **Situation:** %s\n", question.Answer.Situation)
+			fmt.Fprintf(&builder, "- **Task:** %s\n", question.Answer.Task)
+			fmt.Fprintf(&builder, "- **Action:** %s\n", question.Answer.Action)
+			fmt.Fprintf(&builder, "- **Result:** %s\n", question.Answer.Result)
+			for _, id := range question.Answer.AchievementIDs {
+				if achievement, ok := achievementsByID[id]; ok {
+					fmt.Fprintf(&builder, "- Drawn from achievement %d (%s at %s): %s\n", id, achievement.JobTitle, achievement.CompanyName, achievement.Description)
+				} else {
+					fmt.Fprintf(&builder, "- Drawn from achievement %d\n", id)
+				}
+			}
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,50 @@
+package scenarios
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"data-analyzer/agent/workflows"
+	"data-analyzer/models"
+)
+
+func TestInterviewPreparationMarkdown(t *testing.T) {
+	job := models.JobApplication{ID: 4, JobTitle: "Backend Engineer", CompanyName: "Acme"}
+	preparation := workflows.InterviewPreparation{
+		JobId:      4,
+		Status:     models.StatusTechnicalInterview,
+		PreparedAt: time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC),
+		TechnicalQuestions: []workflows.InterviewQuestion{{
+			Question: "How would you design a rate limiter?",
+			Reason:   "The role owns a public API",
+			Answer: workflows.StarAnswer{
+				Situation:      "The API was overloaded",
+				Task:           "Protect it",
+				Action:         "Built a token bucket",
+				Result:         "No more outages",
+				AchievementIDs: []int{3, 7},
+			},
+		}},
+	}
+	achievements := []models.WorkAchievement{{ID: 3, JobTitle: "SRE", CompanyName: "Globex", Description: "Built rate limiting for the payments API"}}
+
+	markdown := InterviewPreparationMarkdown(job, preparation, achievements)
+	for _, want := range []string{
+		"# Interview preparation: Backend Engineer at Acme\n",
+		"Prepared for the Technical Interview stage on 2025-03-04.",
+		"## Technical questions\n\n### 1. How would you design a rate limiter?",
+		"_Why it may come up: The role owns a public API_",
+		"- **Situation:** The API was overloaded\n- **Task:** Protect it\n- **Action:** Built a token bucket\n- **Result:** No more outages\n",
+		"- Drawn from achievement 3 (SRE at Globex): Built rate limiting for the payments API\n",
+		// the achievement may have been deleted since the preparation
+		"- Drawn from achievement 7\n",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("InterviewPreparationMarkdown() = %s\nwant it to contain %q", markdown, want)
+		}
+	}
+	if strings.Contains(markdown, "Behavioral questions") {
+		t.Errorf("InterviewPreparationMarkdown() has a section without questions")
+	}
+}
@@ -0,0 +1,166 @@
+package scenarios
+
+import (
+	"data-analyzer/compensation"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ScoreComponent is one part of a job application's fit score.
+// Components without data are reported as unavailable and left out of the weighted average.
+type ScoreComponent struct {
+	Score     float64 `json:"score"`
+	Weight    float64 `json:"weight"`
+	Available bool    `json:"available"`
+	Detail    string  `json:"detail"`
+}
+
+// ScoreBreakdown holds every component of a job application's fit score
+type ScoreBreakdown struct {
+	Coverage ScoreComponent `json:"coverage"`
+	RedFlags ScoreComponent `json:"red_flags"`
+	Salary   ScoreComponent `json:"salary"`
+	Recency  ScoreComponent `json:"recency"`
+}
+
+// RankedJobApplication is a job application with its fit score from 0 to 100
+type RankedJobApplication struct {
+	JobApplicationID int            `json:"job_application_id"`
+	JobTitle         string         `json:"job_title"`
+	CompanyName      string         `json:"company_name"`
+	Status           string         `json:"status"`
+	Score            float64        `json:"score"`
+	Breakdown        ScoreBreakdown `json:"breakdown"`
+}
+
+type RankJobApplicationsScenario struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewRankJobApplicationsScenario(cfg *config.Config, db *db.DB) *RankJobApplicationsScenario {
+	return &RankJobApplicationsScenario{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// Execute scores every job application that is still being prepared, best fit first
+func (s *RankJobApplicationsScenario) Execute(now time.Time) ([]RankedJobApplication, error) {
+	jobApplications, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	skillsGaps, err := GetStoredSkillsGaps(s.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skills gaps: %w", err)
+	}
+
+	redFlags, err := GetStoredRedFlags(s.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get red flags: %w", err)
+	}
+
+	compensations, err := ResolveCompensations(s.db, jobApplications)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get salaries: %w", err)
+	}
+	rates := compensation.RateTable{BaseCurrency: s.cfg.BaseCurrency, Rates: s.cfg.CurrencyRates}
+
+	weights := s.cfg.RankingWeights
+	ranked := make([]RankedJobApplication, 0)
+	for _, jobApplication := range jobApplications {
+		if jobApplication.Status != models.StatusPreparingApplication {
+			continue
+		}
+
+		breakdown := ScoreBreakdown{
+			Coverage: ScoreComponent{Weight: weights.Coverage, Detail: "No skills gap analysis"},
+			RedFlags: ScoreComponent{Weight: weights.RedFlags, Detail: "No red flags detection"},
+			Salary:   ScoreComponent{Weight: weights.Salary, Detail: "No salary information"},
+			Recency:  ScoreComponent{Weight: weights.Recency},
+		}
+
+		if skillsGap, ok := skillsGaps[jobApplication.ID]; ok {
+			breakdown.Coverage.Score = skillsGap.FitPercentage / 100
+			breakdown.Coverage.Available = true
+			breakdown.Coverage.Detail = fmt.Sprintf("%.0f%% of %d requirements covered", skillsGap.FitPercentage, len(skillsGap.Requirements))
+		}
+
+		if result, ok := redFlags[jobApplication.ID]; ok {
+			breakdown.RedFlags.Score = 1 - result.RiskScore/100
+			breakdown.RedFlags.Available = true
+			breakdown.RedFlags.Detail = fmt.Sprintf("%d red flags, risk score %.1f", len(result.RedFlags), result.RiskScore)
+		}
+
+		if s.cfg.TargetSalary > 0 {
+			if score, salary, ok := salaryScore(rates, compensations[jobApplication.ID].Compensation, s.cfg.TargetSalary); ok {
+				breakdown.Salary.Score = score
+				breakdown.Salary.Available = true
+				breakdown.Salary.Detail = fmt.Sprintf("%.0f %s per year against a target of %.0f", salary, s.cfg.BaseCurrency, s.cfg.TargetSalary)
+			}
+		} else {
+			breakdown.Salary.Detail = "No target salary configured"
+		}
+
+		ageDays := now.Sub(jobApplication.CreatedAt).Hours() / 24
+		breakdown.Recency.Score = recencyScore(ageDays, s.cfg.RecencyHalfLife)
+		breakdown.Recency.Available = true
+		breakdown.Recency.Detail = fmt.Sprintf("Created %.0f days ago", ageDays)
+
+		ranked = append(ranked, RankedJobApplication{
+			JobApplicationID: jobApplication.ID,
+			JobTitle:         jobApplication.JobTitle,
+			CompanyName:      jobApplication.CompanyName,
+			Status:           jobApplication.Status,
+			Score:            breakdown.total(),
+			Breakdown:        breakdown,
+		})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	return ranked, nil
+}
+
+// salaryScore compares the middle of the yearly range, converted to the base currency, with the target.
+// It also returns that yearly salary, and false when the compensation cannot be converted.
+func salaryScore(rates compensation.RateTable, c compensation.Compensation, target float64) (float64, float64, bool) {
+	yearlyMin, yearlyMax, ok := rates.Yearly(c)
+	if !ok {
+		return 0, 0, false
+	}
+	salary := (yearlyMin + yearlyMax) / 2
+	return math.Min(1, salary/target), salary, true
+}
+
+// recencyScore halves every halfLifeDays days since the application was created.
+// Applications created in the future count as new.
+func recencyScore(ageDays float64, halfLifeDays int) float64 {
+	return math.Pow(0.5, math.Max(0, ageDays)/float64(halfLifeDays))
+}
+
+// total is the weighted average of the available components, scaled to 0-100
+func (b ScoreBreakdown) total() float64 {
+	weightedSum := 0.0
+	totalWeight := 0.0
+	for _, component := range []ScoreComponent{b.Coverage, b.RedFlags, b.Salary, b.Recency} {
+		if !component.Available {
+			continue
+		}
+		weightedSum += component.Score * component.Weight
+		totalWeight += component.Weight
+	}
+	if totalWeight == 0 {
+		return 0
+	}
+	return math.Round(weightedSum/totalWeight*1000) / 10
+}
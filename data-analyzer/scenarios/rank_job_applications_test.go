@@ -0,0 +1,128 @@
+package scenarios
+
+import (
+	"math"
+	"testing"
+
+	"data-analyzer/compensation"
+)
+
+func TestScoreBreakdownTotal(t *testing.T) {
+	component := func(score, weight float64, available bool) ScoreComponent {
+		return ScoreComponent{Score: score, Weight: weight, Available: available}
+	}
+
+	tests := []struct {
+		name      string
+		breakdown ScoreBreakdown
+		want      float64
+	}{
+		{
+			name: "all components available",
+			breakdown: ScoreBreakdown{
+				Coverage: component(0.8, 0.4, true),
+				RedFlags: component(0.5, 0.2, true),
+				Salary:   component(1, 0.25, true),
+				Recency:  component(0.5, 0.15, true),
+			},
+			// 0.32 + 0.1 + 0.25 + 0.075
+			want: 74.5,
+		},
+		{
+			name: "missing components are left out of the weights",
+			breakdown: ScoreBreakdown{
+				Coverage: component(0.8, 0.4, true),
+				RedFlags: component(0, 0.2, false),
+				Salary:   component(0, 0.25, false),
+				Recency:  component(0.5, 0.15, true),
+			},
+			// (0.32 + 0.075) / 0.55
+			want: 71.8,
+		},
+		{
+			name: "an unavailable score is ignored",
+			breakdown: ScoreBreakdown{
+				Coverage: component(1, 0.4, false),
+				Recency:  component(0.25, 0.15, true),
+			},
+			want: 25,
+		},
+		{
+			name: "an available zero score still counts",
+			breakdown: ScoreBreakdown{
+				Coverage: component(0, 0.4, true),
+				Recency:  component(1, 0.4, true),
+			},
+			want: 50,
+		},
+		{
+			name: "nothing available",
+			breakdown: ScoreBreakdown{
+				Coverage: component(1, 0.4, false),
+				Recency:  component(1, 0.15, false),
+			},
+			want: 0,
+		},
+		{
+			name: "available components without weight",
+			breakdown: ScoreBreakdown{
+				Coverage: component(1, 0, true),
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.breakdown.total(); got != tt.want {
+			t.Errorf("%s: total() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRecencyScore(t *testing.T) {
+	tests := []struct {
+		ageDays      float64
+		halfLifeDays int
+		want         float64
+	}{
+		{0, 14, 1},
+		{7, 14, 0.7071},
+		{14, 14, 0.5},
+		{28, 14, 0.25},
+		{42, 14, 0.125},
+		{3, 3, 0.5},
+		// applications dated in the future count as new
+		{-5, 14, 1},
+	}
+	for _, tt := range tests {
+		if got := recencyScore(tt.ageDays, tt.halfLifeDays); math.Abs(got-tt.want) > 0.0001 {
+			t.Errorf("recencyScore(%v, %d) = %v, want %v", tt.ageDays, tt.halfLifeDays, got, tt.want)
+		}
+	}
+}
+
+func TestSalaryScore(t *testing.T) {
+	rates := compensation.RateTable{BaseCurrency: "USD", Rates: map[string]float64{"EUR": 1.1}}
+
+	tests := []struct {
+		name       string
+		c          compensation.Compensation
+		target     float64
+		wantScore  float64
+		wantSalary float64
+		wantOK     bool
+	}{
+		{"middle of the range", compensation.Compensation{Min: 80000, Max: 120000, Period: compensation.PeriodYearly}, 125000, 0.8, 100000, true},
+		{"above the target is capped", compensation.Compensation{Min: 150000, Max: 170000, Currency: "USD", Period: compensation.PeriodYearly}, 100000, 1, 160000, true},
+		{"hourly", compensation.Compensation{Min: 50, Max: 50, Period: compensation.PeriodHourly}, 208000, 0.5, 104000, true},
+		{"monthly", compensation.Compensation{Min: 5000, Max: 5000, Period: compensation.PeriodMonthly}, 120000, 0.5, 60000, true},
+		{"converted to the base currency", compensation.Compensation{Min: 100000, Max: 100000, Currency: "EUR", Period: compensation.PeriodYearly}, 220000, 0.5, 110000, true},
+		{"currency without a rate", compensation.Compensation{Min: 100000, Max: 100000, Currency: "GBP", Period: compensation.PeriodYearly}, 100000, 0, 0, false},
+		{"no amount", compensation.Compensation{}, 100000, 0, 0, false},
+	}
+	for _, tt := range tests {
+		score, salary, ok := salaryScore(rates, tt.c, tt.target)
+		if math.Abs(score-tt.wantScore) > 0.0001 || salary != tt.wantSalary || ok != tt.wantOK {
+			t.Errorf("%s: salaryScore() = (%v, %v, %v), want (%v, %v, %v)", tt.name, score, salary, ok, tt.wantScore, tt.wantSalary, tt.wantOK)
+		}
+	}
+}
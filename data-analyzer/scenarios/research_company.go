@@ -0,0 +1,272 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/domains"
+	"data-analyzer/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// CompanyResearchResult is the research of the company of a job application and where it came from
+type CompanyResearchResult struct {
+	workflows.ResearchCompany
+	JobApplicationID int    `json:"job_application_id"`
+	CompanyKey       string `json:"company_key"`
+	WorkflowID       int    `json:"workflow_id"`
+	// Cached is true when the research was reused instead of run for this request
+	Cached       bool      `json:"cached"`
+	ResearchedAt time.Time `json:"researched_at"`
+	AgeDays      int       `json:"age_days"`
+}
+
+type ResearchCompanyScenario struct {
+	cfg          *config.Config
+	geminiClient *agent.Client
+	db           *db.DB
+}
+
+func NewResearchCompanyScenario(cfg *config.Config, geminiClient *agent.Client, db *db.DB) *ResearchCompanyScenario {
+	return &ResearchCompanyScenario{
+		cfg:          cfg,
+		geminiClient: geminiClient,
+		db:           db,
+	}
+}
+
+// Execute researches the companies of the job applications. Research is stored per company, keyed by
+// the normalized domain of the company URL, and reused by every job application at that company for
+// RESEARCH_CACHE_TTL_DAYS. With refresh the cache is bypassed and the research is run again.
+func (s *ResearchCompanyScenario) Execute(ctx context.Context, jobApplications []models.JobApplication, refresh bool) ([]CompanyResearchResult, error) {
+	cache, err := s.db.GetAllCompanyResearch()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.seedCache(cache); err != nil {
+		return nil, err
+	}
+
+	// job applications at the same company are researched once
+	keys := []string{}
+	groups := make(map[string][]models.JobApplication)
+	for _, jobApplication := range jobApplications {
+		key := companyResearchKey(jobApplication)
+		if key == "" {
+			// nothing to share the research with, the job application is researched on its own
+			key = fmt.Sprintf("job:%d", jobApplication.ID)
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], jobApplication)
+	}
+
+	// stored times have no sub-second part
+	now := time.Now().Truncate(time.Second)
+	ttl := time.Duration(s.cfg.Research.CacheTTLDays) * 24 * time.Hour
+	results := []CompanyResearchResult{}
+	for _, key := range keys {
+		group := groups[key]
+		entry, ok := cache[key]
+		cached := ok && !refresh && now.Sub(entry.ResearchedAt) < ttl
+
+		var research workflows.ResearchCompany
+		if cached {
+			if err := json.Unmarshal([]byte(entry.Research), &research); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal cached research of %s: %w", key, err)
+			}
+			s.linkCachedResearch(group, entry, now)
+			fmt.Printf("🗄️ Reusing the research of %s from workflow %d\n", key, entry.WorkflowID)
+		} else {
+			if !s.geminiClient.Enabled() {
+				return nil, fmt.Errorf("researching %s needs the agent, set SHOULD_RUN_AGENT=true", group[0].CompanyName)
+			}
+			var workflowID int64
+			research, workflowID, err = workflows.NewResearchCompanyWorkflow(s.geminiClient, s.db).Execute(ctx, group)
+			if err != nil {
+				workflows.RecordWorkflowFailure(s.db, "research_company", jobApplicationIDs(group), err)
+				return nil, fmt.Errorf("failed to research %s: %w", group[0].CompanyName, err)
+			}
+			researchJSON, err := json.Marshal(research)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal research: %w", err)
+			}
+			entry = models.CompanyResearch{
+				Key:          key,
+				CompanyName:  group[0].CompanyName,
+				WorkflowID:   int(workflowID),
+				Research:     string(researchJSON),
+				ResearchedAt: now,
+			}
+			if err := s.db.UpsertCompanyResearch(entry); err != nil {
+				log.Printf("Failed to cache company research: %v", err)
+			}
+		}
+
+		for _, jobApplication := range group {
+			results = append(results, CompanyResearchResult{
+				ResearchCompany:  research,
+				JobApplicationID: jobApplication.ID,
+				CompanyKey:       key,
+				WorkflowID:       entry.WorkflowID,
+				Cached:           cached,
+				ResearchedAt:     entry.ResearchedAt,
+				AgeDays:          int(now.Sub(entry.ResearchedAt).Hours() / 24),
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// linkCachedResearch links the cached research workflow to the job applications that do not have it yet,
+// with a step saying where the research came from
+func (s *ResearchCompanyScenario) linkCachedResearch(jobApplications []models.JobApplication, entry models.CompanyResearch, now time.Time) {
+	for _, jobApplication := range jobApplications {
+		linked, err := s.db.JobApplicationHasWorkflow(jobApplication.ID, entry.WorkflowID)
+		if err != nil {
+			log.Printf("Failed to check job application workflow: %v", err)
+			continue
+		}
+		if linked {
+			continue
+		}
+		if err := s.db.InsertJobApplicationsWorkflow([]int{jobApplication.ID}, int64(entry.WorkflowID)); err != nil {
+			log.Printf("Failed to store job application workflow: %v", err)
+		}
+		err = s.db.AddStepToJobApplication(jobApplication.ID, models.StepInput{
+			Title: "Research Company",
+			Description: fmt.Sprintf("Reused the research of %s from workflow %d, researched %d days ago",
+				entry.Key, entry.WorkflowID, int(now.Sub(entry.ResearchedAt).Hours()/24)),
+		})
+		if err != nil {
+			log.Printf("Failed to store job application step: %v", err)
+		}
+	}
+}
+
+// isBareJobBoard reports whether a company key or domain is the bare domain of a job board, which names
+// no company
+func isBareJobBoard(key string) bool {
+	return !strings.Contains(key, "/") && !strings.Contains(key, ":") && domains.IsJobBoard(key)
+}
+
+// seedCache adds the research workflows stored before the cache existed to it, so companies that were
+// already researched are not researched again
+func (s *ResearchCompanyScenario) seedCache(cache map[string]models.CompanyResearch) error {
+	legacy, err := latestResearchWorkflows(s.db)
+	if err != nil {
+		return err
+	}
+	if len(legacy) == 0 {
+		return nil
+	}
+	jobs, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		workflow, ok := legacy[job.ID]
+		key := companyResearchKey(job)
+		if !ok || key == "" {
+			continue
+		}
+		if entry, cached := cache[key]; cached && !entry.ResearchedAt.Before(workflow.CreatedAt) {
+			continue
+		}
+		entry := models.CompanyResearch{
+			Key:          key,
+			CompanyName:  job.CompanyName,
+			WorkflowID:   workflow.ID,
+			Research:     workflow.Output,
+			ResearchedAt: workflow.CreatedAt,
+		}
+		if err := s.db.UpsertCompanyResearch(entry); err != nil {
+			return err
+		}
+		cache[key] = entry
+	}
+	return nil
+}
+
+// companyResearchKey is the key of the company of a job application in the research cache: the normalized
+// domain of its URL, the job board and company slug for job board URLs, or its normalized name when the
+// URL does not name the company. A job board alone never identifies the company.
+func companyResearchKey(jobApplication models.JobApplication) string {
+	if domain := domains.Normalize(jobApplication.CompanyURL); domain != "" && !isBareJobBoard(domain) {
+		return domain
+	}
+	if name := domains.CompanyName(jobApplication.CompanyName); name != "" {
+		return "name:" + name
+	}
+	return ""
+}
+
+// latestResearchWorkflows returns the most recent research workflow of every job application it names
+func latestResearchWorkflows(db *db.DB) (map[int]models.Workflow, error) {
+	storedWorkflows, err := db.GetWorkflowsByName("research_company")
+	if err != nil {
+		return nil, err
+	}
+
+	// the research output does not name the job, it comes from the parameters. Workflows are ordered
+	// newest first, so the first research seen for a job wins.
+	latest := make(map[int]models.Workflow)
+	for _, workflow := range storedWorkflows {
+		var parameters models.WorkflowParameters
+		if err := json.Unmarshal([]byte(workflow.Parameters), &parameters); err != nil {
+			log.Printf("Failed to unmarshal research company parameters %d: %v", workflow.ID, err)
+			continue
+		}
+		for _, jobID := range parameters.JobIds {
+			if _, ok := latest[jobID]; !ok {
+				latest[jobID] = workflow
+			}
+		}
+	}
+	return latest, nil
+}
+
+// GetStoredCompanyResearch returns the stored research of the company of every job application, from
+// the company cache or, for research stored before it existed, from the workflows
+func GetStoredCompanyResearch(db *db.DB) (map[int]workflows.ResearchCompany, error) {
+	jobs, err := db.GetAllJobApplications()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := db.GetAllCompanyResearch()
+	if err != nil {
+		return nil, err
+	}
+	legacy, err := latestResearchWorkflows(db)
+	if err != nil {
+		return nil, err
+	}
+
+	research := make(map[int]workflows.ResearchCompany)
+	for _, job := range jobs {
+		output := ""
+		if entry, ok := cache[companyResearchKey(job)]; ok {
+			output = entry.Research
+		} else if workflow, ok := legacy[job.ID]; ok {
+			output = workflow.Output
+		} else {
+			continue
+		}
+		var companyResearch workflows.ResearchCompany
+		if err := json.Unmarshal([]byte(output), &companyResearch); err != nil {
+			log.Printf("Failed to unmarshal research of job application %d: %v", job.ID, err)
+			continue
+		}
+		research[job.ID] = companyResearch
+	}
+
+	return research, nil
+}
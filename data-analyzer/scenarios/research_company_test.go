@@ -0,0 +1,47 @@
+package scenarios
+
+import (
+	"testing"
+
+	"data-analyzer/models"
+)
+
+func TestCompanyResearchKey(t *testing.T) {
+	tests := []struct {
+		companyURL  string
+		companyName string
+		want        string
+	}{
+		{"https://careers.acme.io/jobs/1", "Acme", "acme.io"},
+		{"https://www.Acme.io/", "Acme, Inc.", "acme.io"},
+		{"https://boards.greenhouse.io/globex/jobs/12", "Globex", "greenhouse.io/globex"},
+		// a job board without a company slug names no company, the name is used instead
+		{"https://www.linkedin.com/jobs/view/3912345678/", "Globex GmbH", "name:globex"},
+		{"", "Initech Software Ltd", "name:initech software"},
+		{"not a url", "  ", ""},
+	}
+	for _, tt := range tests {
+		job := models.JobApplication{CompanyURL: tt.companyURL, CompanyName: tt.companyName}
+		if got := companyResearchKey(job); got != tt.want {
+			t.Errorf("companyResearchKey(%q, %q) = %q, want %q", tt.companyURL, tt.companyName, got, tt.want)
+		}
+	}
+}
+
+func TestIsBareJobBoard(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"linkedin.com", true},
+		{"greenhouse.io", true},
+		{"greenhouse.io/globex", false},
+		{"name:linkedin.com", false},
+		{"acme.io", false},
+	}
+	for _, tt := range tests {
+		if got := isBareJobBoard(tt.key); got != tt.want {
+			t.Errorf("isBareJobBoard(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
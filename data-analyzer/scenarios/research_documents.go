@@ -0,0 +1,295 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/documents"
+	"data-analyzer/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrInvalidResearchDocument is returned for documents that cannot be read
+	ErrInvalidResearchDocument = errors.New("invalid research document")
+	// ErrNoResearchDocuments is returned when researching a company that has no documents
+	ErrNoResearchDocuments = errors.New("the company has no research documents")
+)
+
+// researchDocumentQueries find the chunks relevant to each part of the research
+var researchDocumentQueries = []string{
+	"software engineering practices, engineering culture, tech stack, architecture, programming languages, tools, teams and development process",
+	"business model, products, customers, market, revenue, funding, investors, competitors and strategy",
+	"company overview, history, founders, mission, values, headcount, offices, locations and recent news",
+}
+
+type ResearchDocumentsScenario struct {
+	cfg          *config.Config
+	geminiClient *agent.Client
+	db           *db.DB
+	embedder     agent.Embedder
+}
+
+func NewResearchDocumentsScenario(cfg *config.Config, geminiClient *agent.Client, db *db.DB, embedder agent.Embedder) *ResearchDocumentsScenario {
+	return &ResearchDocumentsScenario{
+		cfg:          cfg,
+		geminiClient: geminiClient,
+		db:           db,
+		embedder:     embedder,
+	}
+}
+
+// AddDocument extracts the text of a saved HTML page, PDF or text note, chunks and indexes it, and
+// stores it for the company of the job application, where every job application at that company
+// finds it. A document whose text is already stored for the company is not stored again; the returned
+// bool is false then.
+func (s *ResearchDocumentsScenario) AddDocument(ctx context.Context, jobApplication models.JobApplication, name string, format string, content []byte) (models.ResearchDocument, bool, error) {
+	parsed, err := documents.Parse(name, format, content)
+	if err != nil {
+		return models.ResearchDocument{}, false, fmt.Errorf("%w: %s: %v", ErrInvalidResearchDocument, name, err)
+	}
+	if parsed.Warning != "" {
+		log.Printf("Incomplete text in %s: %s", name, parsed.Warning)
+	}
+
+	document := models.ResearchDocument{
+		CompanyKey:  researchDocumentsKey(jobApplication),
+		Name:        parsed.Name,
+		Title:       parsed.Title,
+		Format:      parsed.Format,
+		ContentHash: parsed.ContentHash,
+		Text:        parsed.Text,
+		Length:      len(parsed.Text),
+	}
+	chunks, err := s.index(ctx, document)
+	if err != nil {
+		return document, false, err
+	}
+
+	id, created, err := s.db.InsertResearchDocument(document, chunks)
+	if err != nil {
+		return document, false, err
+	}
+	stored, err := s.db.GetResearchDocument(id)
+	if err != nil {
+		return document, false, err
+	}
+	if created {
+		fmt.Printf("📄 Indexed %s for %s in %d chunks\n", name, document.CompanyKey, len(chunks))
+	}
+	return stored, created, nil
+}
+
+// Documents returns the documents of the company of the job application, without their text
+func (s *ResearchDocumentsScenario) Documents(jobApplication models.JobApplication) ([]models.ResearchDocument, error) {
+	return s.db.GetResearchDocuments(researchDocumentsKey(jobApplication))
+}
+
+// Execute researches the companies of the job applications from their documents, without web search.
+// Job applications at the same company are researched once. The research replaces the cached research
+// of the company, so everything reading company research uses it.
+func (s *ResearchDocumentsScenario) Execute(ctx context.Context, jobApplications []models.JobApplication) ([]CompanyResearchResult, error) {
+	keys := []string{}
+	groups := make(map[string][]models.JobApplication)
+	for _, jobApplication := range jobApplications {
+		key := researchDocumentsKey(jobApplication)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], jobApplication)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	results := []CompanyResearchResult{}
+	for _, key := range keys {
+		group := groups[key]
+		excerpts, err := s.retrieve(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(excerpts) == 0 {
+			return nil, fmt.Errorf("%w: add documents about %s first", ErrNoResearchDocuments, group[0].CompanyName)
+		}
+		if !s.geminiClient.Enabled() {
+			return nil, fmt.Errorf("researching %s needs the agent, set SHOULD_RUN_AGENT=true", group[0].CompanyName)
+		}
+
+		research, workflowID, err := workflows.NewResearchDocumentsWorkflow(s.geminiClient, s.db).Execute(ctx, group, excerpts)
+		if err != nil {
+			workflows.RecordWorkflowFailure(s.db, "research_company_documents", jobApplicationIDs(group), err)
+			return nil, fmt.Errorf("failed to research %s: %w", group[0].CompanyName, err)
+		}
+		researchJSON, err := json.Marshal(research)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal research: %w", err)
+		}
+		entry := models.CompanyResearch{
+			Key:          key,
+			CompanyName:  group[0].CompanyName,
+			WorkflowID:   int(workflowID),
+			Research:     string(researchJSON),
+			ResearchedAt: now,
+		}
+		if err := s.db.UpsertCompanyResearch(entry); err != nil {
+			log.Printf("Failed to cache company research: %v", err)
+		}
+
+		for _, jobApplication := range group {
+			results = append(results, CompanyResearchResult{
+				ResearchCompany:  research,
+				JobApplicationID: jobApplication.ID,
+				CompanyKey:       key,
+				WorkflowID:       entry.WorkflowID,
+				ResearchedAt:     entry.ResearchedAt,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// retrieve returns the chunks of the company's documents most similar to the research queries as
+// numbered excerpts, in document order. Documents indexed with another embedding model are indexed again.
+func (s *ResearchDocumentsScenario) retrieve(ctx context.Context, companyKey string) ([]workflows.DocumentExcerpt, error) {
+	if err := s.reindex(ctx, companyKey); err != nil {
+		return nil, err
+	}
+	chunks, err := s.db.GetResearchChunks(companyKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	storedDocuments, err := s.db.GetResearchDocuments(companyKey)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(storedDocuments))
+	for _, document := range storedDocuments {
+		names[document.ID] = document.Name
+	}
+
+	queries := make([][]float32, len(researchDocumentQueries))
+	for i, query := range researchDocumentQueries {
+		if queries[i], err = s.embedder.EmbedQuery(ctx, query); err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+	}
+
+	// a chunk scores by the research query it answers best, so every part of the research gets excerpts
+	type scoredChunk struct {
+		chunk models.ResearchChunk
+		score float64
+	}
+	scored := make([]scoredChunk, len(chunks))
+	for i, chunk := range chunks {
+		scored[i].chunk = chunk
+		for _, query := range queries {
+			scored[i].score = max(scored[i].score, agent.CosineSimilarity(query, chunk.Vector))
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+	selected := scored[:min(len(scored), s.cfg.Research.DocumentExcerpts)]
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].chunk.DocumentID != selected[j].chunk.DocumentID {
+			return selected[i].chunk.DocumentID < selected[j].chunk.DocumentID
+		}
+		return selected[i].chunk.StartOffset < selected[j].chunk.StartOffset
+	})
+
+	excerpts := make([]workflows.DocumentExcerpt, len(selected))
+	for i, candidate := range selected {
+		excerpts[i] = workflows.DocumentExcerpt{
+			Number:      i + 1,
+			DocumentID:  candidate.chunk.DocumentID,
+			Document:    names[candidate.chunk.DocumentID],
+			StartOffset: candidate.chunk.StartOffset,
+			EndOffset:   candidate.chunk.EndOffset,
+			Text:        candidate.chunk.Content,
+		}
+	}
+	return excerpts, nil
+}
+
+// reindex indexes the documents of the company again when they were not indexed with the current
+// embedding model
+func (s *ResearchDocumentsScenario) reindex(ctx context.Context, companyKey string) error {
+	chunks, err := s.db.GetResearchChunks(companyKey)
+	if err != nil {
+		return err
+	}
+	current := make(map[int]bool)
+	for _, chunk := range chunks {
+		if chunk.Model == s.embedder.Name() {
+			current[chunk.DocumentID] = true
+		}
+	}
+
+	storedDocuments, err := s.db.GetResearchDocuments(companyKey)
+	if err != nil {
+		return err
+	}
+	for _, stored := range storedDocuments {
+		if current[stored.ID] {
+			continue
+		}
+		document, err := s.db.GetResearchDocument(stored.ID)
+		if err != nil {
+			return err
+		}
+		documentChunks, err := s.index(ctx, document)
+		if err != nil {
+			return err
+		}
+		if err := s.db.ReplaceResearchChunks(document.ID, documentChunks); err != nil {
+			return err
+		}
+		fmt.Printf("📄 Indexed %s again with %s\n", document.Name, s.embedder.Name())
+	}
+	return nil
+}
+
+// index chunks the text of a document and embeds the chunks
+func (s *ResearchDocumentsScenario) index(ctx context.Context, document models.ResearchDocument) ([]models.ResearchChunk, error) {
+	split := documents.Split(document.Text, s.cfg.Research.ChunkSize, s.cfg.Research.ChunkOverlap)
+	texts := make([]string, len(split))
+	for i, chunk := range split {
+		texts[i] = chunk.Text
+	}
+	vectors, err := s.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed document chunks: %w", err)
+	}
+
+	chunks := make([]models.ResearchChunk, len(split))
+	for i, chunk := range split {
+		chunks[i] = models.ResearchChunk{
+			DocumentID:  document.ID,
+			ChunkIndex:  chunk.Index,
+			StartOffset: chunk.Start,
+			EndOffset:   chunk.End,
+			Content:     chunk.Text,
+			Model:       s.embedder.Name(),
+			Vector:      vectors[i],
+		}
+	}
+	return chunks, nil
+}
+
+// researchDocumentsKey is the company key documents are stored under, the key of the research cache
+func researchDocumentsKey(jobApplication models.JobApplication) string {
+	if key := companyResearchKey(jobApplication); key != "" {
+		return key
+	}
+	return fmt.Sprintf("job:%d", jobApplication.ID)
+}
@@ -0,0 +1,41 @@
+package scenarios
+
+import (
+	"data-analyzer/analytics"
+	"data-analyzer/db"
+	"slices"
+)
+
+type ResumeVersionsScenario struct {
+	db *db.DB
+}
+
+func NewResumeVersionsScenario(db *db.DB) *ResumeVersionsScenario {
+	return &ResumeVersionsScenario{
+		db: db,
+	}
+}
+
+// Execute compares the response and interview rates of the resume versions. The seniority controlled
+// for is the one of the extracted job metadata, read from the job title for job applications without it.
+func (s *ResumeVersionsScenario) Execute() (analytics.ResumeVersionReport, error) {
+	jobs, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return analytics.ResumeVersionReport{}, err
+	}
+	steps, err := s.db.GetAllSteps()
+	if err != nil {
+		return analytics.ResumeVersionReport{}, err
+	}
+	metadata, err := GetStoredJobMetadata(s.db)
+	if err != nil {
+		return analytics.ResumeVersionReport{}, err
+	}
+
+	seniorities := make(map[int]string, len(metadata))
+	for jobID, jobMetadata := range metadata {
+		seniorities[jobID] = jobMetadata.Seniority
+	}
+	options := analytics.Options{IgnoredSteps: slices.Concat(analyzerStepTitles, []string{ghostedStepTitle})}
+	return analytics.CompareResumeVersions(jobs, steps, seniorities, options), nil
+}
@@ -0,0 +1,317 @@
+package scenarios
+
+import (
+	"context"
+	"crypto/sha256"
+	"data-analyzer/agent"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ErrNoEmbedding is returned when a job application has not been embedded yet
+var ErrNoEmbedding = errors.New("no embedding")
+
+// embeddingPreviewLength is the number of characters of an item's text kept next to its vector
+const embeddingPreviewLength = 500
+
+// EmbeddingItem is a piece of stored text that can be found through semantic search
+type EmbeddingItem struct {
+	Type             string
+	Key              string
+	JobApplicationID int
+	Text             string
+}
+
+// EmbeddingRefreshResult counts what an incremental refresh did
+type EmbeddingRefreshResult struct {
+	Model     string `json:"model"`
+	Embedded  int    `json:"embedded"`
+	Unchanged int    `json:"unchanged"`
+	Deleted   int    `json:"deleted"`
+}
+
+// SearchResult is a stored item ranked by its similarity to a query
+type SearchResult struct {
+	ItemType         string  `json:"item_type"`
+	ItemKey          string  `json:"item_key"`
+	JobApplicationID int     `json:"job_application_id,omitempty"`
+	JobTitle         string  `json:"job_title,omitempty"`
+	CompanyName      string  `json:"company_name,omitempty"`
+	Score            float64 `json:"score"`
+	Content          string  `json:"content"`
+}
+
+type SemanticSearchScenario struct {
+	db       *db.DB
+	embedder agent.Embedder
+}
+
+func NewSemanticSearchScenario(db *db.DB, embedder agent.Embedder) *SemanticSearchScenario {
+	return &SemanticSearchScenario{
+		db:       db,
+		embedder: embedder,
+	}
+}
+
+// Refresh embeds the items whose text changed since they were last embedded and removes the
+// embeddings of items that no longer exist. Unchanged items are not sent to the embedder.
+func (s *SemanticSearchScenario) Refresh(ctx context.Context) (EmbeddingRefreshResult, error) {
+	result := EmbeddingRefreshResult{Model: s.embedder.Name()}
+
+	items, err := s.collectItems()
+	if err != nil {
+		return result, err
+	}
+
+	stored, err := s.db.GetEmbeddings(s.embedder.Name())
+	if err != nil {
+		return result, err
+	}
+	storedHashes := make(map[string]string, len(stored))
+	for _, embedding := range stored {
+		storedHashes[embedding.ItemType+"/"+embedding.ItemKey] = embedding.ContentHash
+	}
+
+	changed := make([]models.Embedding, 0)
+	texts := make([]string, 0)
+	current := make(map[string]bool, len(items))
+	for _, item := range items {
+		id := item.Type + "/" + item.Key
+		current[id] = true
+		hash := contentHash(item.Text)
+		if storedHashes[id] == hash {
+			result.Unchanged++
+			continue
+		}
+		changed = append(changed, models.Embedding{
+			ItemType:         item.Type,
+			ItemKey:          item.Key,
+			JobApplicationID: item.JobApplicationID,
+			Model:            s.embedder.Name(),
+			ContentHash:      hash,
+			Content:          agent.TruncateText(item.Text, embeddingPreviewLength),
+		})
+		texts = append(texts, item.Text)
+	}
+
+	if len(changed) > 0 {
+		vectors, err := s.embedder.EmbedDocuments(ctx, texts)
+		if err != nil {
+			return result, fmt.Errorf("failed to embed items: %w", err)
+		}
+		for i := range changed {
+			changed[i].Vector = vectors[i]
+		}
+		if err := s.db.UpsertEmbeddings(changed); err != nil {
+			return result, err
+		}
+		result.Embedded = len(changed)
+	}
+
+	for _, embedding := range stored {
+		if current[embedding.ItemType+"/"+embedding.ItemKey] {
+			continue
+		}
+		if err := s.db.DeleteEmbedding(embedding.Model, embedding.ItemType, embedding.ItemKey); err != nil {
+			return result, err
+		}
+		result.Deleted++
+	}
+
+	return result, nil
+}
+
+// RunPeriodically refreshes the embeddings every interval until the context is done
+func (s *SemanticSearchScenario) RunPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.Refresh(ctx)
+		if err != nil {
+			log.Printf("Failed to refresh embeddings: %v", err)
+		} else if result.Embedded > 0 || result.Deleted > 0 {
+			fmt.Printf("🔎 %s: %d embedded, %d unchanged, %d deleted\n", result.Model, result.Embedded, result.Unchanged, result.Deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Search returns the stored items most similar to the query, optionally restricted to some item types.
+// Only the query is embedded, the items are as fresh as the last refresh.
+func (s *SemanticSearchScenario) Search(ctx context.Context, query string, itemTypes []string, limit int) ([]SearchResult, error) {
+	vector, err := s.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	embeddings, err := s.db.GetEmbeddings(s.embedder.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rank(embeddings, vector, itemTypes, limit, func(models.Embedding) bool { return false })
+}
+
+// Similar returns the items most similar to a job application ("more like this"), excluding the job itself.
+// The job must have been embedded by a refresh.
+func (s *SemanticSearchScenario) Similar(ctx context.Context, jobApplicationID int, itemTypes []string, limit int) ([]SearchResult, error) {
+	embeddings, err := s.db.GetEmbeddings(s.embedder.Name())
+	if err != nil {
+		return nil, err
+	}
+	key := strconv.Itoa(jobApplicationID)
+	index := slices.IndexFunc(embeddings, func(embedding models.Embedding) bool {
+		return embedding.ItemType == models.EmbeddingItemJob && embedding.ItemKey == key
+	})
+	if index < 0 {
+		return nil, fmt.Errorf("%w: job application %d, refresh the embeddings first", ErrNoEmbedding, jobApplicationID)
+	}
+
+	// the job's own requirements and research are trivially similar, so they are left out too
+	return s.rank(embeddings, embeddings[index].Vector, itemTypes, limit, func(embedding models.Embedding) bool {
+		return embedding.JobApplicationID == jobApplicationID
+	})
+}
+
+// rank scores the embeddings against the vector, best match first
+func (s *SemanticSearchScenario) rank(embeddings []models.Embedding, vector []float32, itemTypes []string, limit int, exclude func(models.Embedding) bool) ([]SearchResult, error) {
+	results := make([]SearchResult, 0)
+	for _, embedding := range embeddings {
+		if len(itemTypes) > 0 && !slices.Contains(itemTypes, embedding.ItemType) {
+			continue
+		}
+		if exclude(embedding) {
+			continue
+		}
+		score := math.Round(agent.CosineSimilarity(vector, embedding.Vector)*1000) / 1000
+		if score <= 0 {
+			continue
+		}
+		results = append(results, SearchResult{
+			ItemType:         embedding.ItemType,
+			ItemKey:          embedding.ItemKey,
+			JobApplicationID: embedding.JobApplicationID,
+			Score:            score,
+			Content:          embedding.Content,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, s.addJobDetails(results)
+}
+
+// addJobDetails fills in the title and company of the job application each result belongs to
+func (s *SemanticSearchScenario) addJobDetails(results []SearchResult) error {
+	jobIDs := make([]int, 0)
+	for _, result := range results {
+		if result.JobApplicationID > 0 && !slices.Contains(jobIDs, result.JobApplicationID) {
+			jobIDs = append(jobIDs, result.JobApplicationID)
+		}
+	}
+	if len(jobIDs) == 0 {
+		return nil
+	}
+
+	jobApplications, err := s.db.GetJobApplicationsById(jobIDs)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int]models.JobApplication, len(jobApplications))
+	for _, jobApplication := range jobApplications {
+		byID[jobApplication.ID] = jobApplication
+	}
+	for i := range results {
+		if jobApplication, ok := byID[results[i].JobApplicationID]; ok {
+			results[i].JobTitle = jobApplication.JobTitle
+			results[i].CompanyName = jobApplication.CompanyName
+		}
+	}
+	return nil
+}
+
+// collectItems gathers the job descriptions, extracted requirements, research notes and work achievements
+func (s *SemanticSearchScenario) collectItems() ([]EmbeddingItem, error) {
+	items := make([]EmbeddingItem, 0)
+
+	jobApplications, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return nil, err
+	}
+	for _, jobApplication := range jobApplications {
+		items = append(items, EmbeddingItem{
+			Type:             models.EmbeddingItemJob,
+			Key:              strconv.Itoa(jobApplication.ID),
+			JobApplicationID: jobApplication.ID,
+			Text:             fmt.Sprintf("%s at %s\n%s", jobApplication.JobTitle, jobApplication.CompanyName, agent.SanitizeText(jobApplication.JobDescription)),
+		})
+	}
+
+	roleDetails, err := GetStoredRoleDetails(s.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role details: %w", err)
+	}
+	for jobID, roleDetail := range roleDetails {
+		for i, requirement := range roleDetail.Requirements {
+			items = append(items, EmbeddingItem{
+				Type:             models.EmbeddingItemRequirement,
+				Key:              fmt.Sprintf("%d:%d", jobID, i),
+				JobApplicationID: jobID,
+				Text:             requirement,
+			})
+		}
+	}
+
+	researchData, err := s.db.GetAllResearchData()
+	if err != nil {
+		return nil, err
+	}
+	for _, research := range researchData {
+		items = append(items, EmbeddingItem{
+			Type:             models.EmbeddingItemResearch,
+			Key:              strconv.Itoa(research.ID),
+			JobApplicationID: research.JobApplicationID,
+			Text:             research.Info,
+		})
+	}
+
+	achievements, err := s.db.GetAllWorkAchievements()
+	if err != nil {
+		return nil, err
+	}
+	for _, achievement := range achievements {
+		items = append(items, EmbeddingItem{
+			Type: models.EmbeddingItemAchievement,
+			Key:  strconv.Itoa(achievement.ID),
+			Text: fmt.Sprintf("%s at %s: %s", achievement.JobTitle, achievement.CompanyName, achievement.Description),
+		})
+	}
+
+	// empty texts have nothing to search for
+	return slices.DeleteFunc(items, func(item EmbeddingItem) bool { return item.Text == "" }), nil
+}
+
+func contentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package scenarios
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"data-analyzer/agent"
+	"data-analyzer/models"
+)
+
+// countingEmbedder counts the documents and queries sent to the embedder it wraps
+type countingEmbedder struct {
+	agent.Embedder
+	documents int
+	queries   int
+}
+
+func (e *countingEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	e.documents += len(texts)
+	return e.Embedder.EmbedDocuments(ctx, texts)
+}
+
+func (e *countingEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	e.queries++
+	return e.Embedder.EmbedQuery(ctx, text)
+}
+
+// insertTestJob inserts a job application as the Django app would and returns its ID
+func insertTestJob(t *testing.T, path string, job models.JobApplication) int {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	result, err := conn.Exec(`
+		INSERT INTO jobs_jobapplication (job_title, job_description, company_name, company_url, salary,
+			resume_version, status, source, cover_letter, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, '', ?, ?)
+	`, job.JobTitle, job.JobDescription, job.CompanyName, job.CompanyURL, job.Salary,
+		job.ResumeVersion, job.Status, job.Source, now, now)
+	if err != nil {
+		t.Fatalf("failed to insert job application: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int(id)
+}
+
+func TestSemanticSearchOnlyEmbedsTheQuery(t *testing.T) {
+	database, path := newTestDB(t)
+	cfg := newTestConfig(t)
+	cfg.EmbeddingBackend = agent.EmbeddingBackendLocal
+	local, err := agent.NewEmbedder(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedder := &countingEmbedder{Embedder: local}
+	scenario := NewSemanticSearchScenario(database, embedder)
+
+	jobs := []models.JobApplication{
+		{JobTitle: "Backend Engineer", CompanyName: "Acme", JobDescription: "Payments in Go and PostgreSQL", Status: models.StatusApplied, Source: models.SourceLinkedIn},
+		{JobTitle: "Frontend Engineer", CompanyName: "Globex", JobDescription: "React and TypeScript dashboards", Status: models.StatusApplied, Source: models.SourceLinkedIn},
+	}
+	for i := range jobs {
+		jobs[i].ID = insertTestJob(t, path, jobs[i])
+	}
+
+	if _, err := scenario.Similar(context.Background(), jobs[0].ID, nil, 5); !errors.Is(err, ErrNoEmbedding) {
+		t.Errorf("Similar() before a refresh error = %v, want %v", err, ErrNoEmbedding)
+	}
+
+	if _, err := scenario.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	embedder.documents = 0
+
+	// a job added after the refresh is not embedded by searching
+	insertTestJob(t, path, models.JobApplication{
+		JobTitle: "Go Developer", CompanyName: "Initech", JobDescription: "Go services", Status: models.StatusApplied, Source: models.SourceLinkedIn,
+	})
+
+	results, err := scenario.Search(context.Background(), "payments go", nil, 5)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) == 0 || results[0].JobApplicationID != jobs[0].ID {
+		t.Errorf("Search() = %+v, want job %d first", results, jobs[0].ID)
+	}
+	for _, result := range results {
+		if result.CompanyName == "Initech" {
+			t.Errorf("Search() found %+v, which was added after the refresh", result)
+		}
+	}
+	if _, err := scenario.Similar(context.Background(), jobs[1].ID, nil, 5); err != nil {
+		t.Errorf("Similar() error = %v", err)
+	}
+	if embedder.documents != 0 || embedder.queries != 1 {
+		t.Errorf("embedded %d documents and %d queries, want 0 and 1", embedder.documents, embedder.queries)
+	}
+}
@@ -0,0 +1,122 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+type SkillsGapScenario struct {
+	geminiClient    *agent.Client
+	db              *db.DB
+	jobApplications []models.JobApplication
+}
+
+func NewSkillsGapScenario(geminiClient *agent.Client, db *db.DB, jobApplications []models.JobApplication) *SkillsGapScenario {
+	return &SkillsGapScenario{
+		geminiClient:    geminiClient,
+		db:              db,
+		jobApplications: jobApplications,
+	}
+}
+
+// Execute compares the extracted requirements of every job application against the candidate's work achievements
+func (s *SkillsGapScenario) Execute(ctx context.Context) ([]workflows.SkillsGap, error) {
+	roleDetails, err := GetStoredRoleDetails(s.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role details: %w", err)
+	}
+
+	achievements, err := s.db.GetAllWorkAchievements()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work achievements: %w", err)
+	}
+	if len(achievements) == 0 {
+		return nil, fmt.Errorf("no work achievements found, add your work experience first")
+	}
+
+	skillsGaps := make([]workflows.SkillsGap, 0, len(s.jobApplications))
+	for _, jobApplication := range s.jobApplications {
+		roleDetail, ok := roleDetails[jobApplication.ID]
+		if !ok {
+			return nil, fmt.Errorf("job application %d has no extracted role details, generate insights first", jobApplication.ID)
+		}
+
+		skillsGapWorkflow := workflows.NewSkillsGapWorkflow(s.geminiClient, jobApplication, roleDetail.Requirements, achievements)
+		result, err := skillsGapWorkflow.Execute(ctx)
+		if err != nil {
+			workflows.RecordWorkflowFailure(s.db, "skills_gap", []int{jobApplication.ID}, err)
+			return nil, fmt.Errorf("failed to analyze skills gap for job application %d: %w", jobApplication.ID, err)
+		}
+		s.storeWorkflow(result, jobApplication.ID)
+		skillsGaps = append(skillsGaps, result.SkillsGap)
+	}
+
+	return skillsGaps, nil
+}
+
+func (s *SkillsGapScenario) storeWorkflow(result workflows.SkillsGapResult, jobID int) {
+	parametersJSON, err := json.Marshal(map[string]interface{}{
+		"job_ids": []int{jobID},
+		"fields":  []string{"requirements", "work_achievements"},
+	})
+	if err != nil {
+		log.Printf("Failed to marshal parameters: %v", err)
+	}
+
+	// store the result in database
+	workflowRecord := models.Workflow{
+		WorkflowName: "skills_gap",
+		Prompt:       result.Prompt,
+		AgentModel:   s.geminiClient.ModelName,
+		Output:       result.Result,
+		Parameters:   string(parametersJSON),
+	}
+
+	workflowID, err := s.db.InsertWorkflow(workflowRecord)
+	if err != nil {
+		log.Printf("Failed to store workflow: %v", err)
+	} else {
+		fmt.Printf("📝 Workflow stored with ID: %d\n", workflowID)
+	}
+	err = s.db.InsertJobApplicationsWorkflow([]int{jobID}, workflowID)
+	if err != nil {
+		log.Printf("Failed to store job application workflow: %v", err)
+	}
+
+	err = s.db.AddStepToJobApplication(jobID, models.StepInput{
+		Title:       "Skills Gap Analysis",
+		Description: fmt.Sprintf("Skills gap analysis completed with %.0f%% fit via workflow %d", result.SkillsGap.FitPercentage, workflowID),
+	})
+	if err != nil {
+		log.Printf("Failed to store job application step: %v", err)
+	}
+}
+
+// GetStoredSkillsGaps returns the most recent stored skills gap analysis of every job application
+func GetStoredSkillsGaps(db *db.DB) (map[int]workflows.SkillsGap, error) {
+	storedWorkflows, err := db.GetWorkflowsByName("skills_gap")
+	if err != nil {
+		return nil, err
+	}
+
+	// workflows are ordered newest first, so the first analysis seen for a job wins
+	skillsGaps := make(map[int]workflows.SkillsGap)
+	for _, workflow := range storedWorkflows {
+		var skillsGap workflows.SkillsGap
+		if err := json.Unmarshal([]byte(workflow.Output), &skillsGap); err != nil {
+			log.Printf("Failed to unmarshal skills gap workflow %d: %v", workflow.ID, err)
+			continue
+		}
+		if _, ok := skillsGaps[skillsGap.JobId]; !ok {
+			skillsGaps[skillsGap.JobId] = skillsGap
+		}
+	}
+
+	return skillsGaps, nil
+}
@@ -0,0 +1,111 @@
+package scenarios
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"data-analyzer/config"
+	"data-analyzer/db"
+)
+
+// djangoSchema creates the jobs_ tables as the Django server's migrations do
+var djangoSchema = []string{
+	`CREATE TABLE jobs_jobapplication (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		job_title VARCHAR(100) NOT NULL,
+		salary VARCHAR(100) NOT NULL,
+		company_name VARCHAR(100) NOT NULL,
+		company_url VARCHAR(200) NOT NULL,
+		job_description TEXT NOT NULL,
+		resume_version VARCHAR(100) NOT NULL,
+		status VARCHAR(50) NOT NULL,
+		source VARCHAR(50) NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		cover_letter TEXT NOT NULL
+	)`,
+	`CREATE TABLE jobs_step (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title VARCHAR(100) NOT NULL,
+		description TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		job_application_id BIGINT NOT NULL REFERENCES jobs_jobapplication (id)
+	)`,
+	`CREATE TABLE jobs_researchdata (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		category INTEGER NOT NULL,
+		info TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		job_application_id BIGINT NOT NULL REFERENCES jobs_jobapplication (id)
+	)`,
+	`CREATE TABLE jobs_workflow (
+		workflow_id INTEGER PRIMARY KEY AUTOINCREMENT,
+		workflow_name VARCHAR(200) NOT NULL,
+		created_at DATETIME NOT NULL,
+		prompt TEXT NOT NULL,
+		agent_model VARCHAR(200) NOT NULL,
+		output TEXT NOT NULL,
+		parameters TEXT NOT NULL
+	)`,
+	`CREATE TABLE jobs_jobapplication_workflows (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		jobapplication_id BIGINT NOT NULL REFERENCES jobs_jobapplication (id),
+		workflow_id INTEGER NOT NULL REFERENCES jobs_workflow (workflow_id)
+	)`,
+	`CREATE TABLE jobs_workexperience (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		job_title VARCHAR(100) NOT NULL,
+		company_name VARCHAR(100) NOT NULL,
+		company_url VARCHAR(200) NOT NULL,
+		start_date DATE NOT NULL,
+		end_date DATE NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`,
+	`CREATE TABLE jobs_workachievement (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		description TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		work_experience_id BIGINT NOT NULL REFERENCES jobs_workexperience (id)
+	)`,
+}
+
+// newTestDB creates a database with the Django tables in a temporary directory and opens it
+func newTestDB(t *testing.T) (*db.DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	for _, statement := range djangoSchema {
+		if _, err := conn.Exec(statement); err != nil {
+			t.Fatalf("failed to create Django table: %v", err)
+		}
+	}
+	conn.Close()
+
+	database, err := db.New(path)
+	if err != nil {
+		t.Fatalf("db.New() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database, path
+}
+
+// newTestConfig loads the default configuration, ignoring the environment of the developer
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	for _, name := range []string{"SHOULD_RUN_AGENT", "DEDUP_WARN_ON_NEW", "EMBEDDING_BACKEND", "GHOSTING_AUTO_MOVE", "EMAIL_AUTO_APPLY_STATUS"} {
+		t.Setenv(name, "")
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("config.LoadConfig() error = %v", err)
+	}
+	return cfg
+}
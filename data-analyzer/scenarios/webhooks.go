@@ -0,0 +1,406 @@
+package scenarios
+
+import (
+	"context"
+	"crypto/rand"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/models"
+	"data-analyzer/webhooks"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidWebhookSubscription is returned when a subscription has a bad URL or event type
+var ErrInvalidWebhookSubscription = errors.New("invalid webhook subscription")
+
+// webhookEventBatch and webhookDeliveryBatch bound the work of a single dispatch
+const (
+	webhookEventBatch    = 100
+	webhookDeliveryBatch = 50
+)
+
+// webhookDeliveryLog is the number of deliveries listed for a subscription
+const webhookDeliveryLog = 100
+
+// webhookMu keeps the periodic dispatch and the ones requested through the API from sending the same delivery twice
+var webhookMu sync.Mutex
+
+// WebhookSubscriptionInput is a subscription to create or the changes to make to one. Fields left
+// empty are not changed when updating.
+type WebhookSubscriptionInput struct {
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// WebhookDispatchResult counts what a dispatch did
+type WebhookDispatchResult struct {
+	// Events is the number of new events, Queued the number of deliveries they were fanned out to
+	Events    int `json:"events"`
+	Queued    int `json:"queued"`
+	Delivered int `json:"delivered"`
+	Retrying  int `json:"retrying"`
+	Failed    int `json:"failed"`
+}
+
+// WebhookJobApplication is the job application an event is about
+type WebhookJobApplication struct {
+	ID          int    `json:"id"`
+	JobTitle    string `json:"job_title"`
+	CompanyName string `json:"company_name"`
+	Status      string `json:"status"`
+}
+
+// WebhookStepAdded is the data of a step.added event
+type WebhookStepAdded struct {
+	Step           models.Step           `json:"step"`
+	JobApplication WebhookJobApplication `json:"job_application"`
+}
+
+// WebhookWorkflowCompleted is the data of a workflow.completed event. The output is the stored
+// result of the workflow, as JSON when it is JSON.
+type WebhookWorkflowCompleted struct {
+	WorkflowID        int             `json:"workflow_id"`
+	WorkflowName      string          `json:"workflow_name"`
+	AgentModel        string          `json:"agent_model"`
+	JobApplicationIDs []int           `json:"job_application_ids"`
+	Output            json.RawMessage `json:"output"`
+}
+
+// WebhookStatusSuggested is the data of a status.suggested event
+type WebhookStatusSuggested struct {
+	JobApplication  WebhookJobApplication `json:"job_application"`
+	SuggestedStatus string                `json:"suggested_status"`
+	Reason          string                `json:"reason"`
+	// Source is what made the suggestion, "email" or "ghosting"
+	Source string `json:"source"`
+	// EmailID is the ingested email behind the suggestion, approve it with POST /emails/review
+	EmailID int `json:"email_id,omitempty"`
+}
+
+type WebhooksScenario struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewWebhooksScenario(cfg *config.Config, db *db.DB) *WebhooksScenario {
+	return &WebhooksScenario{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// Subscribe creates an active subscription with a new secret, which is only returned here
+func (s *WebhooksScenario) Subscribe(input WebhookSubscriptionInput) (models.WebhookSubscription, error) {
+	endpoint, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return models.WebhookSubscription{}, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhookSubscription)
+	}
+	eventTypes, err := parseWebhookEventTypes(input.EventTypes)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	subscription := models.WebhookSubscription{
+		URL:         endpoint.String(),
+		Secret:      "whsec_" + hex.EncodeToString(secret),
+		EventTypes:  eventTypes,
+		Description: strings.TrimSpace(input.Description),
+		Active:      input.Active == nil || *input.Active,
+	}
+	return s.db.CreateWebhookSubscription(subscription)
+}
+
+// List returns every subscription without its secret
+func (s *WebhooksScenario) List() ([]models.WebhookSubscription, error) {
+	subscriptions, err := s.db.GetWebhookSubscriptions()
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, err
+}
+
+// Get returns a subscription without its secret
+func (s *WebhooksScenario) Get(id int) (models.WebhookSubscription, error) {
+	subscription, err := s.db.GetWebhookSubscription(id)
+	subscription.Secret = ""
+	return subscription, err
+}
+
+// Update changes the event types, description or state of a subscription. The URL cannot change,
+// since the receiver's secret belongs to it; create a new subscription instead.
+func (s *WebhooksScenario) Update(id int, input WebhookSubscriptionInput) (models.WebhookSubscription, error) {
+	subscription, err := s.db.GetWebhookSubscription(id)
+	if err != nil {
+		return subscription, err
+	}
+	if input.URL != "" && input.URL != subscription.URL {
+		return models.WebhookSubscription{}, fmt.Errorf("%w: the url of a subscription cannot change, create a new one", ErrInvalidWebhookSubscription)
+	}
+	if input.EventTypes != nil {
+		if subscription.EventTypes, err = parseWebhookEventTypes(input.EventTypes); err != nil {
+			return models.WebhookSubscription{}, err
+		}
+	}
+	if input.Description != "" {
+		subscription.Description = strings.TrimSpace(input.Description)
+	}
+	if input.Active != nil {
+		subscription.Active = *input.Active
+	}
+	if err := s.db.UpdateWebhookSubscription(subscription); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	return s.Get(id)
+}
+
+// Unsubscribe deletes a subscription and its delivery log
+func (s *WebhooksScenario) Unsubscribe(id int) error {
+	return s.db.DeleteWebhookSubscription(id)
+}
+
+// Deliveries returns the latest deliveries of a subscription, newest first
+func (s *WebhooksScenario) Deliveries(id int) ([]models.WebhookDelivery, error) {
+	if _, err := s.db.GetWebhookSubscription(id); err != nil {
+		return nil, err
+	}
+	return s.db.GetWebhookDeliveries(id, webhookDeliveryLog)
+}
+
+// Dispatch fans the new events out to the active subscriptions that want them and were created
+// before them, then sends the deliveries that are due. A failed delivery is retried after
+// WEBHOOK_BACKOFF_SECONDS, doubled after every attempt, and given up after WEBHOOK_MAX_ATTEMPTS.
+func (s *WebhooksScenario) Dispatch(ctx context.Context, now time.Time) (WebhookDispatchResult, error) {
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+
+	result := WebhookDispatchResult{}
+	subscriptions, err := s.db.GetWebhookSubscriptions()
+	if err != nil {
+		return result, err
+	}
+	subscriptionsByID := make(map[int]models.WebhookSubscription, len(subscriptions))
+	for _, subscription := range subscriptions {
+		subscriptionsByID[subscription.ID] = subscription
+	}
+
+	events, err := s.db.GetPendingWebhookEvents(webhookEventBatch)
+	if err != nil {
+		return result, err
+	}
+	for _, event := range events {
+		deliveries := []models.WebhookDelivery{}
+		for _, subscription := range subscriptions {
+			if !subscription.Active || subscription.CreatedAt.After(event.CreatedAt) ||
+				(len(subscription.EventTypes) > 0 && !slices.Contains(subscription.EventTypes, event.Type)) {
+				continue
+			}
+			deliveries = append(deliveries, models.WebhookDelivery{SubscriptionID: subscription.ID, EventType: event.Type, NextAttemptAt: now})
+		}
+		if len(deliveries) > 0 {
+			payload, err := s.payload(event)
+			if err != nil {
+				return result, err
+			}
+			if payload == nil {
+				// the step or workflow was deleted before the event was sent
+				deliveries = nil
+			}
+			for i := range deliveries {
+				deliveries[i].Payload = string(payload)
+			}
+		}
+		if err := s.db.DispatchWebhookEvent(event.ID, deliveries); err != nil {
+			return result, err
+		}
+		result.Events++
+		result.Queued += len(deliveries)
+	}
+
+	due, err := s.db.GetDueWebhookDeliveries(now, webhookDeliveryBatch)
+	if err != nil {
+		return result, err
+	}
+	client := &http.Client{Timeout: time.Duration(s.cfg.Webhooks.TimeoutSeconds) * time.Second}
+	for _, delivery := range due {
+		subscription := subscriptionsByID[delivery.SubscriptionID]
+		response := webhooks.Send(ctx, client, webhooks.Request{
+			URL:        subscription.URL,
+			Secret:     subscription.Secret,
+			DeliveryID: delivery.ID,
+			EventType:  delivery.EventType,
+			Payload:    []byte(delivery.Payload),
+			Timestamp:  time.Now(),
+		})
+
+		delivery.Attempts++
+		delivery.LastStatusCode = response.StatusCode
+		delivery.LastError = response.Error
+		switch {
+		case response.Delivered():
+			deliveredAt := time.Now()
+			delivery.Status = models.WebhookDeliveryDelivered
+			delivery.DeliveredAt = &deliveredAt
+			result.Delivered++
+		case delivery.Attempts >= s.cfg.Webhooks.MaxAttempts:
+			delivery.Status = models.WebhookDeliveryFailed
+			result.Failed++
+			log.Printf("Gave up webhook delivery %d to %s after %d attempts: %s", delivery.ID, subscription.URL, delivery.Attempts, response.Error)
+		default:
+			delivery.NextAttemptAt = now.Add(webhooks.Backoff(time.Duration(s.cfg.Webhooks.BackoffSeconds)*time.Second, delivery.Attempts))
+			result.Retrying++
+		}
+		if err := s.db.UpdateWebhookDelivery(delivery); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// RunPeriodically dispatches the webhook events every WEBHOOK_POLL_SECONDS until the context is done
+func (s *WebhooksScenario) RunPeriodically(ctx context.Context) {
+	interval := time.Duration(s.cfg.Webhooks.PollSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.Dispatch(ctx, time.Now())
+		if err != nil {
+			log.Printf("Failed to dispatch webhooks: %v", err)
+		} else if result.Events > 0 || result.Delivered > 0 || result.Retrying > 0 || result.Failed > 0 {
+			fmt.Printf("📡 Webhooks: %d events, %d delivered, %d retrying, %d failed\n", result.Events, result.Delivered, result.Retrying, result.Failed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// payload builds the body sent for an event. Events recorded by the database triggers only know the
+// step or workflow they are about, which is read now; nil is returned when it no longer exists.
+func (s *WebhooksScenario) payload(event models.WebhookEvent) ([]byte, error) {
+	data := json.RawMessage(event.Data)
+	switch event.Type {
+	case models.WebhookEventStepAdded:
+		step, err := s.db.GetStep(event.RefID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		stepAdded := WebhookStepAdded{Step: step, JobApplication: WebhookJobApplication{ID: step.JobApplicationID}}
+		jobs, err := s.db.GetJobApplicationsById([]int{step.JobApplicationID})
+		if err != nil {
+			return nil, err
+		}
+		if len(jobs) > 0 {
+			stepAdded.JobApplication = webhookJobApplication(jobs[0])
+		}
+		if data, err = json.Marshal(stepAdded); err != nil {
+			return nil, fmt.Errorf("failed to marshal webhook event: %w", err)
+		}
+	case models.WebhookEventWorkflowCompleted:
+		workflow, jobApplicationIDs, err := s.db.GetWorkflow(event.RefID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		completed := WebhookWorkflowCompleted{
+			WorkflowID:        workflow.ID,
+			WorkflowName:      workflow.WorkflowName,
+			AgentModel:        workflow.AgentModel,
+			JobApplicationIDs: jobApplicationIDs,
+			Output:            json.RawMessage(workflow.Output),
+		}
+		if !json.Valid(completed.Output) {
+			completed.Output, _ = json.Marshal(workflow.Output)
+		}
+		if data, err = json.Marshal(completed); err != nil {
+			return nil, fmt.Errorf("failed to marshal webhook event: %w", err)
+		}
+	}
+
+	body, err := json.Marshal(webhooks.Event{
+		ID:        fmt.Sprintf("evt_%d", event.ID),
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+	return body, nil
+}
+
+// recordStatusSuggestion records a status.suggested event, once per key. Failing to record it does
+// not fail the suggestion, so the error is only logged.
+func recordStatusSuggestion(database *db.DB, key string, suggestion WebhookStatusSuggested) {
+	data, err := json.Marshal(suggestion)
+	if err != nil {
+		log.Printf("Failed to marshal status suggestion: %v", err)
+		return
+	}
+	_, err = database.RecordWebhookEvent(models.WebhookEvent{
+		Type:             models.WebhookEventStatusSuggested,
+		Key:              key,
+		JobApplicationID: suggestion.JobApplication.ID,
+		Data:             string(data),
+	})
+	if err != nil {
+		log.Printf("Failed to record status suggestion: %v", err)
+	}
+}
+
+// parseWebhookEventTypes validates and deduplicates the event types of a subscription
+func parseWebhookEventTypes(eventTypes []string) ([]string, error) {
+	parsed := []string{}
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !slices.Contains(models.WebhookEventTypes, eventType) {
+			return nil, fmt.Errorf("%w: event types must be among %s", ErrInvalidWebhookSubscription, strings.Join(models.WebhookEventTypes, ", "))
+		}
+		if !slices.Contains(parsed, eventType) {
+			parsed = append(parsed, eventType)
+		}
+	}
+	return parsed, nil
+}
+
+func jobApplicationIDs(jobs []models.JobApplication) []int {
+	ids := make([]int, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	return ids
+}
+
+func webhookJobApplication(job models.JobApplication) WebhookJobApplication {
+	return WebhookJobApplication{
+		ID:          job.ID,
+		JobTitle:    job.JobTitle,
+		CompanyName: job.CompanyName,
+		Status:      job.Status,
+	}
+}
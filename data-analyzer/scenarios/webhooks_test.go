@@ -0,0 +1,40 @@
+package scenarios
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"data-analyzer/models"
+)
+
+func TestParseWebhookEventTypes(t *testing.T) {
+	tests := []struct {
+		eventTypes []string
+		want       []string
+		wantErr    bool
+	}{
+		{nil, []string{}, false},
+		{[]string{models.WebhookEventStepAdded}, []string{models.WebhookEventStepAdded}, false},
+		{
+			[]string{" workflow.completed ", models.WebhookEventStatusSuggested, "workflow.completed"},
+			[]string{models.WebhookEventWorkflowCompleted, models.WebhookEventStatusSuggested},
+			false,
+		},
+		{[]string{models.WebhookEventStepAdded, "step.deleted"}, nil, true},
+		{[]string{""}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseWebhookEventTypes(tt.eventTypes)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseWebhookEventTypes(%q) error = %v, want error %v", tt.eventTypes, err, tt.wantErr)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrInvalidWebhookSubscription) {
+			t.Errorf("parseWebhookEventTypes(%q) error = %v, want %v", tt.eventTypes, err, ErrInvalidWebhookSubscription)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseWebhookEventTypes(%q) = %q, want %q", tt.eventTypes, got, tt.want)
+		}
+	}
+}
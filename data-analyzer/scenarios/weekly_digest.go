@@ -0,0 +1,325 @@
+package scenarios
+
+import (
+	"context"
+	"data-analyzer/agent/workflows"
+	"data-analyzer/config"
+	"data-analyzer/db"
+	"data-analyzer/digest"
+	"data-analyzer/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ErrDigestNotConfigured is returned when delivering the digest without a directory or an SMTP server
+var ErrDigestNotConfigured = errors.New("the digest has nowhere to go, set DIGEST_DIR or DIGEST_SMTP_HOST and DIGEST_TO")
+
+// digestTrendWeeks is the number of periods before the digest's that its market trends are compared to
+const digestTrendWeeks = 4
+
+// digestTrends is the number of technologies listed in the market trends
+const digestTrends = 10
+
+// DigestDelivery is where a digest went
+type DigestDelivery struct {
+	Files  []string `json:"files"`
+	SentTo []string `json:"sent_to"`
+}
+
+type WeeklyDigestScenario struct {
+	cfg *config.Config
+	db  *db.DB
+}
+
+func NewWeeklyDigestScenario(cfg *config.Config, db *db.DB) *WeeklyDigestScenario {
+	return &WeeklyDigestScenario{
+		cfg: cfg,
+		db:  db,
+	}
+}
+
+// Build gathers the digest of the last DIGEST_DAYS days up to and including today, in the calendar
+// time zone: the job applications added, the steps added to job applications, the interviews and
+// follow-ups due in as many days from today, the red flags detected, the technologies most asked for
+// compared to the periods before and the progress against the daily goal.
+func (s *WeeklyDigestScenario) Build(now time.Time) (digest.Digest, error) {
+	location := s.cfg.Calendar.Location
+	days := s.cfg.Digest.Days
+	local := now.In(location)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+
+	result := digest.Digest{
+		Start:           today.AddDate(0, 0, 1-days),
+		End:             today.AddDate(0, 0, 1),
+		GeneratedAt:     now,
+		NewApplications: []digest.Application{},
+		StatusChanges:   []digest.StatusChange{},
+		Upcoming:        []digest.Upcoming{},
+		RedFlags:        []digest.RedFlags{},
+		Trends:          []digest.Trend{},
+	}
+	inPeriod := func(t time.Time) bool {
+		return !t.Before(result.Start) && t.Before(result.End)
+	}
+
+	jobs, err := s.db.GetAllJobApplications()
+	if err != nil {
+		return result, err
+	}
+	steps, err := s.db.GetAllSteps()
+	if err != nil {
+		return result, err
+	}
+	jobsByID := make(map[int]models.JobApplication, len(jobs))
+	for _, job := range jobs {
+		jobsByID[job.ID] = job
+		if inPeriod(job.CreatedAt) {
+			result.NewApplications = append(result.NewApplications, digestApplication(job))
+		}
+	}
+	sort.Slice(result.NewApplications, func(i, j int) bool {
+		return result.NewApplications[i].CreatedAt.Before(result.NewApplications[j].CreatedAt)
+	})
+
+	// the steps are oldest first, so are the events and the job applications by their first event
+	changes := make(map[int]int)
+	for _, step := range steps {
+		job, ok := jobsByID[step.JobApplicationID]
+		if !ok || !inPeriod(step.CreatedAt) || slices.Contains(analyzerStepTitles, step.Title) {
+			continue
+		}
+		i, ok := changes[job.ID]
+		if !ok {
+			result.StatusChanges = append(result.StatusChanges, digest.StatusChange{Application: digestApplication(job), Events: []digest.Event{}})
+			i = len(result.StatusChanges) - 1
+			changes[job.ID] = i
+		}
+		result.StatusChanges[i].Events = append(result.StatusChanges[i].Events, digest.Event{Title: step.Title, CreatedAt: step.CreatedAt.In(location)})
+	}
+
+	events, err := NewExportCalendarScenario(s.cfg, s.db).Execute(now)
+	if err != nil {
+		return result, err
+	}
+	for _, event := range events {
+		if !event.Start.Before(today) && event.Start.Before(today.AddDate(0, 0, days)) {
+			result.Upcoming = append(result.Upcoming, digest.Upcoming{
+				Date:        event.Start.In(location),
+				AllDay:      event.AllDay,
+				Summary:     event.Summary,
+				Description: event.Description,
+			})
+		}
+	}
+
+	if result.RedFlags, err = s.redFlags(jobsByID, inPeriod); err != nil {
+		return result, err
+	}
+	if result.Trends, err = s.trends(jobs, result.Start, days); err != nil {
+		return result, err
+	}
+
+	result.Goal = digest.Goal{DailyGoal: s.cfg.Digest.DailyGoal, Days: []digest.Day{}, Target: s.cfg.Digest.DailyGoal * days}
+	for day := result.Start; day.Before(result.End); day = day.AddDate(0, 0, 1) {
+		count := 0
+		for _, job := range jobs {
+			if !job.CreatedAt.Before(day) && job.CreatedAt.Before(day.AddDate(0, 0, 1)) {
+				count++
+			}
+		}
+		reached := count >= s.cfg.Digest.DailyGoal
+		if reached {
+			result.Goal.DaysReached++
+		}
+		result.Goal.Total += count
+		result.Goal.Days = append(result.Goal.Days, digest.Day{Date: day, Count: count, Reached: reached})
+	}
+
+	return result, nil
+}
+
+// Deliver writes the digest as Markdown and HTML to the directory when one is given, and sends it to
+// DIGEST_TO through DIGEST_SMTP_HOST when send is true
+func (s *WeeklyDigestScenario) Deliver(result digest.Digest, dir string, send bool) (DigestDelivery, error) {
+	delivery := DigestDelivery{Files: []string{}, SentTo: []string{}}
+	if dir == "" && !send {
+		return delivery, ErrDigestNotConfigured
+	}
+	markdown, err := digest.RenderMarkdown(result)
+	if err != nil {
+		return delivery, err
+	}
+	html, err := digest.RenderHTML(result)
+	if err != nil {
+		return delivery, err
+	}
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return delivery, fmt.Errorf("failed to create digest directory: %w", err)
+		}
+		for extension, content := range map[string]string{"md": markdown, "html": html} {
+			path := filepath.Join(dir, result.FileName(extension))
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				return delivery, fmt.Errorf("failed to write digest: %w", err)
+			}
+			delivery.Files = append(delivery.Files, path)
+		}
+		sort.Strings(delivery.Files)
+		fmt.Printf("📝 Digest written to %s\n", dir)
+	}
+
+	if send {
+		if s.cfg.Digest.SMTPHost == "" || len(s.cfg.Digest.To) == 0 {
+			return delivery, ErrDigestNotConfigured
+		}
+		message, err := digest.BuildMessage(s.cfg.Digest.From, s.cfg.Digest.To, result.Subject(), markdown, html, result.GeneratedAt)
+		if err != nil {
+			return delivery, err
+		}
+		settings := digest.SMTPSettings{
+			Host:     s.cfg.Digest.SMTPHost,
+			Port:     s.cfg.Digest.SMTPPort,
+			Username: s.cfg.Digest.SMTPUsername,
+			Password: s.cfg.Digest.SMTPPassword,
+			From:     s.cfg.Digest.From,
+			To:       s.cfg.Digest.To,
+		}
+		if err := digest.Send(settings, message); err != nil {
+			return delivery, fmt.Errorf("failed to send digest: %w", err)
+		}
+		delivery.SentTo = s.cfg.Digest.To
+		fmt.Printf("📬 Digest sent to %d recipients\n", len(s.cfg.Digest.To))
+	}
+
+	return delivery, nil
+}
+
+// RunPeriodically builds and delivers the digest every DIGEST_INTERVAL_HOURS until the context is done,
+// to DIGEST_DIR and by email when DIGEST_SMTP_HOST is set
+func (s *WeeklyDigestScenario) RunPeriodically(ctx context.Context) {
+	interval := time.Duration(s.cfg.Digest.IntervalHours) * time.Hour
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		result, err := s.Build(time.Now())
+		if err != nil {
+			log.Printf("Failed to build digest: %v", err)
+			continue
+		}
+		if _, err := s.Deliver(result, s.cfg.Digest.Dir, s.cfg.Digest.SMTPHost != ""); err != nil {
+			log.Printf("Failed to deliver digest: %v", err)
+		}
+	}
+}
+
+// redFlags returns the red flags detected during the period, riskiest job applications first
+func (s *WeeklyDigestScenario) redFlags(jobsByID map[int]models.JobApplication, inPeriod func(time.Time) bool) ([]digest.RedFlags, error) {
+	storedWorkflows, err := s.db.GetWorkflowsByName("red_flags_detection")
+	if err != nil {
+		return nil, err
+	}
+
+	// workflows are ordered newest first, so the first result seen for a job wins
+	seen := make(map[int]bool)
+	results := []digest.RedFlags{}
+	for _, workflow := range storedWorkflows {
+		if !inPeriod(workflow.CreatedAt) {
+			continue
+		}
+		var output workflows.RedFlagsDetectionResult
+		if err := json.Unmarshal([]byte(workflow.Output), &output); err != nil {
+			log.Printf("Failed to unmarshal red flags workflow %d: %v", workflow.ID, err)
+			continue
+		}
+		for _, jobResult := range output.Results {
+			job, ok := jobsByID[jobResult.JobID]
+			if !ok || seen[job.ID] || jobResult.ErrorMessage != "" {
+				continue
+			}
+			seen[job.ID] = true
+			if len(jobResult.RedFlags) == 0 {
+				continue
+			}
+			entry := digest.RedFlags{Application: digestApplication(job), RiskScore: jobResult.RiskScore, Flags: []digest.RedFlag{}}
+			for _, flag := range jobResult.RedFlags {
+				entry.Flags = append(entry.Flags, digest.RedFlag{Category: flag.Category, Severity: flag.Severity, Description: flag.Description})
+			}
+			results = append(results, entry)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].RiskScore > results[j].RiskScore })
+	return results, nil
+}
+
+// trends counts the technologies of the job applications added during the period, compared to their
+// share in the job applications of the periods before
+func (s *WeeklyDigestScenario) trends(jobs []models.JobApplication, start time.Time, days int) ([]digest.Trend, error) {
+	techStacks, err := GetStoredTechStacks(s.db)
+	if err != nil {
+		return nil, err
+	}
+	previousStart := start.AddDate(0, 0, -days*digestTrendWeeks)
+
+	current, previous := make(map[string]int), make(map[string]int)
+	currentJobs, previousJobs := 0, 0
+	for _, job := range jobs {
+		techStack, ok := techStacks[job.ID]
+		if !ok || job.CreatedAt.Before(previousStart) {
+			continue
+		}
+		counts := previous
+		if job.CreatedAt.Before(start) {
+			previousJobs++
+		} else {
+			counts = current
+			currentJobs++
+		}
+		technologies := slices.Concat(techStack.Languages, techStack.Frameworks, techStack.Databases, techStack.Cloud, techStack.Tools)
+		slices.Sort(technologies)
+		for _, technology := range slices.Compact(technologies) {
+			counts[technology]++
+		}
+	}
+
+	trends := []digest.Trend{}
+	for technology, count := range current {
+		trend := digest.Trend{Technology: technology, Jobs: count, Share: float64(count) / float64(currentJobs)}
+		if previousJobs > 0 {
+			trend.PreviousShare = float64(previous[technology]) / float64(previousJobs)
+		}
+		trends = append(trends, trend)
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Jobs != trends[j].Jobs {
+			return trends[i].Jobs > trends[j].Jobs
+		}
+		return trends[i].Technology < trends[j].Technology
+	})
+	return trends[:min(len(trends), digestTrends)], nil
+}
+
+func digestApplication(job models.JobApplication) digest.Application {
+	return digest.Application{
+		ID:          job.ID,
+		JobTitle:    job.JobTitle,
+		CompanyName: job.CompanyName,
+		Status:      job.Status,
+		Source:      job.Source,
+		CreatedAt:   job.CreatedAt,
+	}
+}
@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature is "sha256=" followed by the hex HMAC-SHA256 of the timestamp, a dot and the body
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxBackoff caps the wait between two attempts
+const maxBackoff = 6 * time.Hour
+
+// Event is the JSON body of every delivery
+type Event struct {
+	// ID is the same for every subscription the event is sent to, receivers can use it to drop duplicates
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Request is a delivery attempt
+type Request struct {
+	URL        string
+	Secret     string
+	DeliveryID int
+	EventType  string
+	Payload    []byte
+	Timestamp  time.Time
+}
+
+// Response is the outcome of a delivery attempt, StatusCode is 0 when the endpoint could not be reached
+type Response struct {
+	StatusCode int
+	Error      string
+}
+
+// Delivered reports whether the endpoint accepted the delivery
+func (r Response) Delivered() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Sign returns the signature header value of a body sent at the timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff is the wait after the given failed attempt, starting at 1: the base doubled after every
+// attempt, capped at 6 hours
+func Backoff(base time.Duration, attempt int) time.Duration {
+	wait := base
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+// Send posts the signed payload to the endpoint. Redirects are not followed, and any answer other
+// than a 2xx status is a failure.
+func Send(ctx context.Context, client *http.Client, request Request) Response {
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Payload))
+	if err != nil {
+		return Response{Error: fmt.Sprintf("failed to create request: %v", err)}
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set("User-Agent", "data-analyzer-webhooks")
+	httpRequest.Header.Set(HeaderEvent, request.EventType)
+	httpRequest.Header.Set(HeaderDelivery, strconv.Itoa(request.DeliveryID))
+	httpRequest.Header.Set(HeaderTimestamp, strconv.FormatInt(request.Timestamp.Unix(), 10))
+	httpRequest.Header.Set(HeaderSignature, Sign(request.Secret, request.Timestamp, request.Payload))
+
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := noRedirects.Do(httpRequest)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	// keep the start of the body of failed deliveries to tell why in the delivery log
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	response := Response{StatusCode: resp.StatusCode}
+	if !response.Delivered() {
+		response.Error = resp.Status
+		if text := strings.TrimSpace(string(body)); text != "" {
+			response.Error += ": " + text
+		}
+	}
+	return response
+}
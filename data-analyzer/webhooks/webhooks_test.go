@@ -0,0 +1,150 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		secret string
+		body   string
+		want   string
+	}{
+		{"whsec_test", `{"id":"evt_1"}`, "sha256=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925"},
+		{"", "", "sha256=c1da1b6c6b8e9da7f4bbb90f7cab0820f271ad19ccbf80c88479c4e14f37d1c6"},
+	}
+	for _, tt := range tests {
+		if got := Sign(tt.secret, timestamp, []byte(tt.body)); got != tt.want {
+			t.Errorf("Sign(%q, %q) = %q, want %q", tt.secret, tt.body, got, tt.want)
+		}
+	}
+
+	// the timestamp is signed, so a captured delivery cannot be replayed with another one
+	if Sign("whsec_test", timestamp.Add(time.Second), []byte(`{"id":"evt_1"}`)) == tests[0].want {
+		t.Errorf("Sign() ignores the timestamp")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		base    time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{time.Minute, 1, time.Minute},
+		{time.Minute, 2, 2 * time.Minute},
+		{time.Minute, 5, 16 * time.Minute},
+		{time.Minute, 9, 256 * time.Minute},
+		{time.Minute, 10, 6 * time.Hour},
+		{time.Minute, 1000, 6 * time.Hour},
+		{10 * time.Hour, 1, 6 * time.Hour},
+		{time.Minute, 0, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.base, tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%v, %d) = %v, want %v", tt.base, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	payload := `{"id":"evt_1"}`
+
+	var received *http.Request
+	var receivedBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received, receivedBody = r, string(body)
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusNoContent)
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/error":
+			http.Error(w, "  invalid signature\n"+strings.Repeat("x", 1000), http.StatusUnauthorized)
+		case "/empty":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantError  string
+	}{
+		{"/ok", http.StatusNoContent, ""},
+		// redirects are not followed, the receiver must be configured with its final URL
+		{"/redirect", http.StatusFound, "302 Found"},
+		{"/error", http.StatusUnauthorized, "401 Unauthorized: invalid signature\n" + strings.Repeat("x", 512-len("  invalid signature\n"))},
+		{"/empty", http.StatusServiceUnavailable, "503 Service Unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			received = nil
+			response := Send(context.Background(), server.Client(), Request{
+				URL:        server.URL + tt.path,
+				Secret:     "whsec_test",
+				DeliveryID: 42,
+				EventType:  "step.created",
+				Payload:    []byte(payload),
+				Timestamp:  timestamp,
+			})
+			if response.StatusCode != tt.wantStatus || response.Error != tt.wantError {
+				t.Errorf("Send() = %d %q, want %d %q", response.StatusCode, response.Error, tt.wantStatus, tt.wantError)
+			}
+			if response.Delivered() != (tt.wantError == "") {
+				t.Errorf("Delivered() = %v for status %d", response.Delivered(), response.StatusCode)
+			}
+			if received == nil || received.URL.Path != tt.path {
+				t.Fatalf("the endpoint received %v, want a single request to %s", received, tt.path)
+			}
+
+			headers := map[string]string{
+				"Content-Type":  "application/json",
+				HeaderEvent:     "step.created",
+				HeaderDelivery:  "42",
+				HeaderTimestamp: "1700000000",
+				HeaderSignature: Sign("whsec_test", timestamp, []byte(payload)),
+			}
+			for name, want := range headers {
+				if got := received.Header.Get(name); got != want {
+					t.Errorf("header %s = %q, want %q", name, got, want)
+				}
+			}
+			if received.Method != http.MethodPost || receivedBody != payload {
+				t.Errorf("the endpoint received %s %q, want POST %q", received.Method, receivedBody, payload)
+			}
+		})
+	}
+
+	unreachable := Send(context.Background(), server.Client(), Request{URL: "http://127.0.0.1:1/hook", Timestamp: timestamp})
+	if unreachable.StatusCode != 0 || unreachable.Error == "" || unreachable.Delivered() {
+		t.Errorf("Send(unreachable) = %+v, want status 0 and an error", unreachable)
+	}
+	if invalid := Send(context.Background(), server.Client(), Request{URL: "://bad"}); invalid.Error == "" {
+		t.Errorf("Send(invalid URL) error is empty")
+	}
+}
+
+func TestSendKeepsClientRedirectPolicy(t *testing.T) {
+	client := &http.Client{Timeout: time.Second}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/elsewhere", http.StatusMovedPermanently)
+	}))
+	defer server.Close()
+
+	Send(context.Background(), client, Request{URL: server.URL, Timestamp: time.Now()})
+	if client.CheckRedirect != nil {
+		t.Errorf("Send() changed the redirect policy of the shared client")
+	}
+}